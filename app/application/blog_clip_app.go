@@ -0,0 +1,156 @@
+package application
+
+import (
+	"context"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/lupguo/copilot_develop/app/domain/entity"
+	"github.com/lupguo/copilot_develop/app/domain/repos"
+	"github.com/lupguo/copilot_develop/app/domain/service"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// BlogClipApp 外部网页的摘录App
+type BlogClipApp struct {
+	aiSrv       service.IServicesSummaryAI
+	sqliteInfra repos.IReposSQLiteWebClip
+	crawler     repos.IReposWebCrawler
+	clipDir     string // 摘录笔记的目录
+}
+
+// NewBlogClipApp 初始一个BlogClipApp，crawler抓取外部网页，摘录笔记写入clipDir
+func NewBlogClipApp(aiSrv service.IServicesSummaryAI, sqliteInfra repos.IReposSQLiteWebClip,
+	crawler repos.IReposWebCrawler, clipDir string) *BlogClipApp {
+	return &BlogClipApp{
+		aiSrv:       aiSrv,
+		sqliteInfra: sqliteInfra,
+		crawler:     crawler,
+		clipDir:     clipDir,
+	}
+}
+
+// ClipURL 抓取网页正文，经AI生成摘要、关键字后写入摘录笔记目录；已摘录过的网页跳过，force时重新生成并覆盖原笔记
+func (app *BlogClipApp) ClipURL(ctx context.Context, url string, force bool) (clip *entity.WebClip, skip string, err error) {
+	if app.crawler == nil || app.clipDir == "" {
+		return nil, "", errors.New("web clip needs crawler and clip dir")
+	}
+	url = strings.TrimSpace(url)
+	if i := strings.Index(url, "#"); i >= 0 {
+		url = url[:i]
+	}
+
+	// 抓取前后各按url查一次，重定向、规范化后的地址也算已摘录
+	existing, err := app.sqliteInfra.SelWebClip(ctx, url)
+	if err != nil {
+		return nil, "", errors.Wrapf(err, "app sel web clip[%s] got err", url)
+	}
+	if existing != nil && !force {
+		return existing, "already clipped", nil
+	}
+
+	page, err := app.crawler.FetchReadable(ctx, url)
+	if err != nil {
+		return nil, "", errors.Wrapf(err, "app fetch web page[%s] got err", url)
+	}
+	if existing == nil && page.Url != url {
+		if existing, err = app.sqliteInfra.SelWebClip(ctx, page.Url); err != nil {
+			return nil, "", errors.Wrapf(err, "app sel web clip[%s] got err", page.Url)
+		}
+		if existing != nil && !force {
+			return existing, "already clipped", nil
+		}
+	}
+
+	// 复用文章摘要的AI流程
+	md := entity.NewWebPageMD(page)
+	if md.IsContentWordsTooSmall() {
+		return nil, "", errors.Errorf("web page[%s] content is too small, needn't request OpenAI", page.Url)
+	}
+	if md.IsMinContentTooLong(entity.OpenAIMaxTokenSize) {
+		return nil, "", errors.Errorf("web page[%s] min content is over max token size(%d), cannot request OpenAI", page.Url, entity.OpenAIMaxTokenSize)
+	}
+	summary, err := app.aiSrv.SummaryBlogMD(ctx, md)
+	if err != nil {
+		return nil, "", errors.Wrapf(err, "aiSrv summary web page[%s] got err", page.Url)
+	}
+
+	fetched := time.Now()
+	path := ""
+	if existing != nil {
+		path = existing.Filepath
+	} else {
+		if err = os.MkdirAll(app.clipDir, 0755); err != nil {
+			return nil, "", errors.Wrapf(err, "app make clip dir[%s] got err", app.clipDir)
+		}
+		path = entity.UniqueClipPath(app.clipDir, entity.ClipNoteFilename(page, fetched), page.Url)
+	}
+	if err = entity.NewClipNote(path, page, summary, fetched).ReplaceWithNewYamlHeader(); err != nil {
+		return nil, "", errors.Wrapf(err, "app write clip note[%s] got err", path)
+	}
+
+	clip = &entity.WebClip{
+		Url:         page.Url,
+		Title:       page.Title,
+		Filepath:    path,
+		Keywords:    summary.Keywords,
+		Summary:     summary.Summary,
+		Description: summary.Description,
+		ContentHash: md.ContentHash(),
+	}
+	if existing != nil {
+		clip.Url = existing.Url
+	}
+	if err = app.sqliteInfra.ReplaceWebClip(ctx, clip); err != nil {
+		return nil, "", errors.Wrapf(err, "app replace web clip[%s] got err", clip.Url)
+	}
+	log.Infof("web page[%s] clipped into [%s]", clip.Url, path)
+	return clip, "", nil
+}
+
+// ClipSite 从seed开始爬取maxDepth内同站点的网页，逐个摘录url匹配match(为空时不过滤)的网页，返回新摘录的记录
+func (app *BlogClipApp) ClipSite(ctx context.Context, seed string, maxDepth int, match string, force bool) ([]*entity.WebClip, error) {
+	if app.crawler == nil {
+		return nil, errors.New("web clip needs crawler")
+	}
+	var matcher *regexp.Regexp
+	if match != "" {
+		var err error
+		if matcher, err = regexp.Compile(match); err != nil {
+			return nil, errors.Wrapf(err, "compile clip match regex[%s] got err", match)
+		}
+	}
+
+	urls, err := app.crawler.FoundURLsFromUrlBFS(ctx, seed, maxDepth)
+	if err != nil {
+		return nil, errors.Wrapf(err, "app crawl urls from seed[%s] got err", seed)
+	}
+
+	var clips []*entity.WebClip
+	total, failed := 0, 0
+	for _, url := range urls {
+		if matcher != nil && !matcher.MatchString(url) {
+			continue
+		}
+		total++
+		clip, skip, err := app.ClipURL(ctx, url, force)
+		switch {
+		case err != nil && ctx.Err() != nil:
+			return clips, ctx.Err()
+		case err != nil:
+			log.Errorf("app clip web page[%s] got err: %s", url, err)
+			failed++
+		case skip != "":
+			log.Infof("web page[%s] skipped: %s", url, skip)
+		default:
+			clips = append(clips, clip)
+		}
+	}
+	if failed > 0 {
+		return clips, errors.Errorf("%d of %d web pages failed to clip", failed, total)
+	}
+	return clips, nil
+}
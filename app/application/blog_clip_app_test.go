@@ -0,0 +1,129 @@
+package application
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/lupguo/copilot_develop/app/domain/entity"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// fakeCrawler 固定url => 网页正文，url重定向到规范地址；订阅地址 => 订阅，ETag相同时返回未变化；
+// 站外链接 => 状态码，未设置的返回请求失败
+type fakeCrawler struct {
+	pages     map[string]*entity.WebPage
+	redirects map[string]string
+	feeds     map[string]*entity.WebFeed
+	links     map[string]int
+	fetched   int
+	checked   int
+}
+
+func (c *fakeCrawler) FetchReadable(ctx context.Context, url string) (*entity.WebPage, error) {
+	if target, ok := c.redirects[url]; ok {
+		url = target
+	}
+	page, ok := c.pages[url]
+	if !ok {
+		return nil, errors.Errorf("page[%s] not found", url)
+	}
+	c.fetched++
+	return page, nil
+}
+
+func (c *fakeCrawler) FoundURLsFromUrlBFS(ctx context.Context, url string, maxDepth int) ([]string, error) {
+	urls := []string{url}
+	for u := range c.pages {
+		if u != url {
+			urls = append(urls, u)
+		}
+	}
+	return urls, nil
+}
+
+func (c *fakeCrawler) FetchFeed(ctx context.Context, url string, etag, lastModified string) (*entity.WebFeed, error) {
+	feed, ok := c.feeds[url]
+	if !ok {
+		return nil, errors.Errorf("feed[%s] not found", url)
+	}
+	if etag != "" && etag == feed.Etag {
+		return &entity.WebFeed{Url: url, Etag: etag, NotModified: true}, nil
+	}
+	return feed, nil
+}
+
+func (c *fakeCrawler) CheckLink(ctx context.Context, url string) (*entity.LinkCheck, error) {
+	c.checked++
+	status, ok := c.links[url]
+	if !ok {
+		return &entity.LinkCheck{Url: url, Error: "connection refused"}, nil
+	}
+	return &entity.LinkCheck{Url: url, StatusCode: status}, nil
+}
+
+func TestBlogClipApp_ClipURL(t *testing.T) {
+	ctx := context.Background()
+	clipDir := filepath.Join(t.TempDir(), "clips")
+	body := testPostBody
+	crawler := &fakeCrawler{
+		pages: map[string]*entity.WebPage{
+			"https://example.com/":          {Url: "https://example.com/", Title: "Home", Content: "hi"},
+			"https://example.com/posts/go/": {Url: "https://example.com/posts/go/", Title: "Go Channels", Content: body},
+			"https://example.com/posts/rs/": {Url: "https://example.com/posts/rs/", Title: "Rust", Content: body},
+		},
+		redirects: map[string]string{"https://example.com/go": "https://example.com/posts/go/"},
+	}
+
+	_, infra, aiSrv := newTestApp(t)
+	aiSrv.On("SummaryBlogMD", mock.Anything, mock.Anything).Return(&entity.ArticleSummary{
+		Keywords: "go", Summary: "summary", Description: "description",
+	}, nil)
+
+	_, _, err := NewBlogClipApp(aiSrv, infra, nil, clipDir).ClipURL(ctx, "https://example.com/go", false)
+	assert.Error(t, err)
+	app := NewBlogClipApp(aiSrv, infra, crawler, clipDir)
+
+	// 重定向后以规范地址记录
+	clip, skip, err := app.ClipURL(ctx, "https://example.com/go#intro", false)
+	assert.NoError(t, err)
+	assert.Empty(t, skip)
+	assert.Equal(t, "https://example.com/posts/go/", clip.Url)
+	assert.Equal(t, clipDir, filepath.Dir(clip.Filepath))
+	assert.True(t, strings.HasSuffix(clip.Filepath, "-go-channels.md"))
+	md, err := entity.NewBlogMD(clip.Filepath)
+	assert.NoError(t, err)
+	assert.Equal(t, "summary", md.MDHeader.Summary)
+	assert.Equal(t, "Go Channels", aiSrv.Calls[0].Arguments.Get(1).(*entity.BlogMD).MDHeader.Title)
+
+	// 已摘录的不再抓取、请求AI
+	_, skip, err = app.ClipURL(ctx, "https://example.com/posts/go/", false)
+	assert.NoError(t, err)
+	assert.Equal(t, "already clipped", skip)
+	_, skip, err = app.ClipURL(ctx, "https://example.com/go", false)
+	assert.NoError(t, err)
+	assert.Equal(t, "already clipped", skip)
+	assert.Equal(t, 2, crawler.fetched)
+	aiSrv.AssertNumberOfCalls(t, "SummaryBlogMD", 1)
+
+	// force时覆盖原笔记
+	forced, _, err := app.ClipURL(ctx, "https://example.com/posts/go/", true)
+	assert.NoError(t, err)
+	assert.Equal(t, clip.Filepath, forced.Filepath)
+	aiSrv.AssertNumberOfCalls(t, "SummaryBlogMD", 2)
+
+	// 批量摘录：仅匹配的url，已摘录的跳过
+	clips, err := app.ClipSite(ctx, "https://example.com/", 1, `/posts/`, false)
+	assert.NoError(t, err)
+	assert.Len(t, clips, 1)
+	assert.Equal(t, "https://example.com/posts/rs/", clips[0].Url)
+
+	// 正文过短的网页摘录失败
+	clips, err = app.ClipSite(ctx, "https://example.com/", 1, "", false)
+	assert.Error(t, err)
+	assert.Empty(t, clips)
+	aiSrv.AssertNumberOfCalls(t, "SummaryBlogMD", 3)
+}
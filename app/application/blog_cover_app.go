@@ -0,0 +1,175 @@
+package application
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+
+	"github.com/lupguo/copilot_develop/app/domain/entity"
+	"github.com/lupguo/copilot_develop/app/domain/repos"
+	"github.com/lupguo/copilot_develop/config"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// CoverOptions 文章封面图生成选项
+type CoverOptions struct {
+	Provider       string // 文生图服务名称，记录在生成记录中
+	Model          string // 模型
+	Size           string // 图片尺寸
+	Seed           int64  // 随机种子，<=0时随机
+	NegativePrompt string // 反向提示词
+	PromptTemplate string // 提示词模板，为空时使用默认模板
+	Key            string // 写入的front matter字段
+	Dir            string // 非page bundle文章的封面图保存在static下的目录
+	StaticDir      string // Hugo static目录，为空时为content目录同级的static
+	Force          bool   // 已有封面图的文章也重新生成
+}
+
+// CoverOptionsFromConfig 配置的文章封面图生成选项
+func CoverOptionsFromConfig(site *config.SiteConfig, cfg *config.CoverConfig) *CoverOptions {
+	return &CoverOptions{
+		Provider:       cfg.Provider,
+		Model:          cfg.Model,
+		Size:           cfg.Size,
+		Seed:           cfg.Seed,
+		NegativePrompt: cfg.NegativePrompt,
+		PromptTemplate: cfg.PromptTemplate,
+		Key:            cfg.Key,
+		Dir:            cfg.Dir,
+		StaticDir:      site.StaticDir,
+	}
+}
+
+// BlogCoverApp 文章封面图的App
+type BlogCoverApp struct {
+	sqliteInfra repos.IReposSQLiteCoverImage
+	scanRules   *entity.BlogScanRules
+	imageGen    repos.IReposImageGenerator
+}
+
+// NewBlogCoverApp 初始一个BlogCoverApp，按scanRules扫描文章，imageGen为生成封面图的文生图服务
+func NewBlogCoverApp(sqliteInfra repos.IReposSQLiteCoverImage, scanRules *entity.BlogScanRules,
+	imageGen repos.IReposImageGenerator) *BlogCoverApp {
+	return &BlogCoverApp{
+		sqliteInfra: sqliteInfra,
+		scanRules:   scanRules,
+		imageGen:    imageGen,
+	}
+}
+
+// GenerateCovers 为path(文章文件或目录，为空时为blogRoot)下没有封面图的文章(目录下不含草稿)生成封面图：
+// 按模板基于标题、摘要生成提示词请求文生图服务，图片保存到page bundle或static目录，写入front matter，
+// 提示词、模型、随机种子记录到DB；单篇文章失败时记录日志后继续
+func (app *BlogCoverApp) GenerateCovers(ctx context.Context, blogRoot, path string, opts *CoverOptions) ([]*entity.CoverImage, error) {
+	if app.imageGen == nil {
+		return nil, errors.New("generate covers needs image generator")
+	}
+	mds, err := pathArticleMDs(ctx, blogRoot, path, app.scanRules)
+	if err != nil {
+		return nil, err
+	}
+	staticDir := siteStaticDir(opts.StaticDir, blogRoot)
+
+	var covers []*entity.CoverImage
+	for _, md := range mds {
+		if ctx.Err() != nil {
+			return covers, ctx.Err()
+		}
+		if md.MDHeader.HasCover(opts.Key) && !opts.Force {
+			continue
+		}
+		cover, err := app.generateCover(ctx, md, staticDir, opts)
+		if err != nil {
+			log.Errorf("app generate cover for md[%s] got err: %s", md.Filepath, err)
+			continue
+		}
+		covers = append(covers, cover)
+	}
+	return covers, nil
+}
+
+// generateCover 为单篇文章生成封面图，写入图片文件、front matter及生成记录
+func (app *BlogCoverApp) generateCover(ctx context.Context, md *entity.BlogMD, staticDir string, opts *CoverOptions) (*entity.CoverImage, error) {
+	prompt, err := entity.CoverPrompt(opts.PromptTemplate, md)
+	if err != nil {
+		return nil, err
+	}
+	image, err := app.imageGen.GenerateImage(ctx, &entity.ImageGenRequest{
+		Prompt:         prompt,
+		NegativePrompt: opts.NegativePrompt,
+		Model:          opts.Model,
+		Size:           opts.Size,
+		Seed:           opts.Seed,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "image generator generate image got err")
+	}
+
+	file, ref := entity.CoverLocation(md.Filepath, staticDir, opts.Dir, image.Ext)
+	if err = os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return nil, errors.Wrapf(err, "mkdir for cover[%s] got err", file)
+	}
+	if err = os.WriteFile(file, image.Data, 0644); err != nil {
+		return nil, errors.Wrapf(err, "write cover[%s] got err", file)
+	}
+	md.MDHeader.SetCover(opts.Key, ref)
+	if err = md.SafeReplaceYamlHeader(); err != nil {
+		return nil, errors.Wrap(err, "replace md yaml header got err")
+	}
+	log.Infof("app generate cover[%s] for md[%s]", file, md.Filepath)
+
+	cover := &entity.CoverImage{
+		Path:          md.Filepath,
+		Provider:      opts.Provider,
+		Model:         image.Model,
+		Prompt:        prompt,
+		RevisedPrompt: image.RevisedPrompt,
+		Seed:          image.Seed,
+		File:          file,
+		Ref:           ref,
+	}
+	if err = app.sqliteInfra.ReplaceCoverImage(ctx, cover); err != nil {
+		return nil, errors.Wrap(err, "replace cover image record got err")
+	}
+	return cover, nil
+}
+
+// pathArticleMDs path(文章文件或目录，为空时为blogRoot)下的文章，目录下不含草稿
+func pathArticleMDs(ctx context.Context, blogRoot, path string, rules *entity.BlogScanRules) ([]*entity.BlogMD, error) {
+	if path == "" {
+		path = filepath.Clean(blogRoot)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "app stat path[%s] got err", path)
+	}
+	if !info.IsDir() {
+		md, err := entity.NewBlogMD(path)
+		if err != nil {
+			return nil, errors.Wrapf(err, "app new md[%s] got err", path)
+		}
+		return []*entity.BlogMD{md}, nil
+	}
+
+	scanned, err := scanArticleMDs(ctx, path, rules, false)
+	if err != nil {
+		return nil, err
+	}
+	var mds []*entity.BlogMD
+	for _, md := range scanned {
+		if !md.IsDraft() {
+			mds = append(mds, md)
+		}
+	}
+	return mds, nil
+}
+
+// siteStaticDir Hugo static目录，未配置时为content目录同级的static
+func siteStaticDir(staticDir, blogRoot string) string {
+	if staticDir != "" {
+		return staticDir
+	}
+	contentDir := filepath.Clean(siteContentDir(config.GetSiteConfig(), filepath.Clean(blogRoot)))
+	return filepath.Join(filepath.Dir(contentDir), "static")
+}
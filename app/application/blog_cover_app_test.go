@@ -0,0 +1,71 @@
+package application
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/lupguo/copilot_develop/app/domain/entity"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeImageGen 模拟文生图服务，返回提示词作为图片内容
+type fakeImageGen struct {
+	prompts []string
+}
+
+func (f *fakeImageGen) GenerateImage(ctx context.Context, req *entity.ImageGenRequest) (*entity.GeneratedImage, error) {
+	f.prompts = append(f.prompts, req.Prompt)
+	return &entity.GeneratedImage{Data: []byte(req.Prompt), Ext: ".png", Model: req.Model, Seed: 7}, nil
+}
+
+func TestBlogCoverApp_GenerateCovers(t *testing.T) {
+	ctx := context.Background()
+	root := t.TempDir()
+	contentDir := filepath.Join(root, "content")
+	body := testPostBody
+	writeFile := newTestFileWriter(t, contentDir)
+	goPost := writeFile("posts/go.md", "---\ntitle: Go调度\nsummary: GMP模型\nweight: 7\n---\n"+body)
+	bundle := writeFile("posts/bundle/index.md", "---\ntitle: Bundle\n---\n"+body)
+	writeFile("posts/covered.md", "---\ntitle: Covered\ncover: /img/a.png\n---\n"+body)
+	writeFile("posts/draft.md", "---\ntitle: Draft\ndraft: true\n---\n"+body)
+
+	infra := newTestInfra(t)
+	opts := &CoverOptions{Provider: "sd", Model: "sdxl", Size: "1024x576", PromptTemplate: "{{.Title}}: {{.Summary}}", Key: "cover", Dir: "images/covers"}
+
+	_, err := NewBlogCoverApp(infra, nil, nil).GenerateCovers(ctx, contentDir, "", opts)
+	assert.Error(t, err)
+	gen := &fakeImageGen{}
+	app := NewBlogCoverApp(infra, nil, gen)
+
+	covers, err := app.GenerateCovers(ctx, contentDir, "", opts)
+	assert.NoError(t, err)
+	assert.Len(t, covers, 2)
+	assert.ElementsMatch(t, []string{"Go调度: GMP模型", "Bundle:"}, gen.prompts)
+
+	data, err := os.ReadFile(filepath.Join(root, "static/images/covers/go.png"))
+	assert.NoError(t, err)
+	assert.Equal(t, "Go调度: GMP模型", string(data))
+	md, err := entity.NewBlogMD(goPost)
+	assert.NoError(t, err)
+	assert.Equal(t, "/images/covers/go.png", md.MDHeader.Extra["cover"])
+	assert.Equal(t, []interface{}{"/images/covers/go.png"}, md.MDHeader.Extra["images"])
+	assert.Equal(t, body, md.MDContent)
+	assert.Equal(t, 7, md.MDHeader.Weight) // 保留配置的权重
+	_, err = os.Stat(filepath.Join(filepath.Dir(bundle), "cover.png"))
+	assert.NoError(t, err)
+
+	record, err := infra.SelCoverImage(ctx, goPost)
+	assert.NoError(t, err)
+	assert.Equal(t, [4]interface{}{"sd", "sdxl", "Go调度: GMP模型", int64(7)}, [4]interface{}{record.Provider, record.Model, record.Prompt, record.Seed})
+
+	// 已有封面图的文章跳过，--force 或指定文件时重新生成
+	covers, err = app.GenerateCovers(ctx, contentDir, "", opts)
+	assert.NoError(t, err)
+	assert.Empty(t, covers)
+	opts.Force = true
+	covers, err = app.GenerateCovers(ctx, contentDir, goPost, opts)
+	assert.NoError(t, err)
+	assert.Len(t, covers, 1)
+}
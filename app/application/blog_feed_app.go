@@ -0,0 +1,258 @@
+package application
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/hold7techs/go-shim/shim"
+	"github.com/lupguo/copilot_develop/app/domain/entity"
+	"github.com/lupguo/copilot_develop/app/domain/repos"
+	"github.com/lupguo/copilot_develop/app/domain/service"
+	"github.com/lupguo/copilot_develop/config"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// FeedOptions 订阅源新文章的处理选项
+type FeedOptions struct {
+	Dir        string            // 每日摘要文章、摘录笔记的目录
+	Output     entity.FeedOutput // 输出方式，默认每日摘要文章
+	MaxEntries int               // 每个订阅源每次最多摘要的新文章数，其余仅记录；<=0时不限制
+}
+
+// FeedPollResult 单个订阅源的检查结果
+type FeedPollResult struct {
+	Url         string
+	Title       string
+	NotModified bool                // 订阅未变化(304)
+	Entries     []*entity.FeedEntry // 本次摘要的新文章
+	Skipped     int                 // 超过单次数量或内容过少，仅记录未摘要的新文章数
+	Failed      int                 // 摘要失败的新文章数，下次检查时重试
+	Err         error               // 请求、解析订阅失败
+}
+
+// FeedOptionsFromConfig 配置的订阅源处理选项
+func FeedOptionsFromConfig(cfg *config.FeedsConfig) *FeedOptions {
+	return &FeedOptions{Dir: cfg.Dir, Output: entity.FeedOutput(cfg.Output), MaxEntries: cfg.MaxEntries}
+}
+
+// BlogFeedApp RSS/Atom订阅的App：检查订阅源，经AI摘要新文章
+type BlogFeedApp struct {
+	aiSrv       service.IServicesSummaryAI
+	sqliteInfra repos.IReposSQLiteFeed
+	crawler     repos.IReposWebCrawler
+	opts        *FeedOptions
+}
+
+// NewBlogFeedApp 初始一个BlogFeedApp，crawler抓取订阅及原文全文，opts为新文章的处理选项
+func NewBlogFeedApp(aiSrv service.IServicesSummaryAI, sqliteInfra repos.IReposSQLiteFeed,
+	crawler repos.IReposWebCrawler, opts *FeedOptions) *BlogFeedApp {
+	return &BlogFeedApp{
+		aiSrv:       aiSrv,
+		sqliteInfra: sqliteInfra,
+		crawler:     crawler,
+		opts:        opts,
+	}
+}
+
+// PollFeeds 依次检查订阅源(ETag/Last-Modified条件请求)，新文章只有摘录时抓取原文全文，经AI摘要后
+// 汇总写入当天的摘要文章或逐篇生成摘录笔记；返回每个订阅源的结果，有订阅源失败时同时返回错误
+func (app *BlogFeedApp) PollFeeds(ctx context.Context, urls []string) ([]*FeedPollResult, error) {
+	if app.crawler == nil || app.opts == nil || app.opts.Dir == "" {
+		return nil, errors.New("feeds need crawler and feed dir")
+	}
+	if err := os.MkdirAll(app.opts.Dir, 0755); err != nil {
+		return nil, errors.Wrapf(err, "app make feed dir[%s] got err", app.opts.Dir)
+	}
+
+	now := time.Now()
+	var results []*FeedPollResult
+	digest, failed := false, 0
+	for _, url := range urls {
+		result := app.pollFeed(ctx, url, now)
+		results = append(results, result)
+		if ctx.Err() != nil {
+			return results, ctx.Err()
+		}
+		if result.Err != nil || result.Failed > 0 {
+			failed++
+		}
+		digest = digest || len(result.Entries) > 0
+	}
+
+	if digest && app.opts.Output != entity.FeedOutputNotes {
+		if err := app.writeFeedDigest(ctx, now); err != nil {
+			return results, err
+		}
+	}
+	if failed > 0 {
+		return results, errors.Errorf("%d of %d feeds failed", failed, len(urls))
+	}
+	return results, nil
+}
+
+// StartFeedPoller 每隔interval检查一次订阅源，直到ctx结束
+func (app *BlogFeedApp) StartFeedPoller(ctx context.Context, urls []string, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if results, err := app.PollFeeds(ctx, urls); err != nil {
+			log.Errorf("app poll feeds got err: %s", err)
+		} else {
+			for _, result := range results {
+				if len(result.Entries) > 0 {
+					log.Infof("feed[%s] got %d new entries", result.Url, len(result.Entries))
+				}
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// pollFeed 检查单个订阅源，按订阅地址+guid识别新文章；有文章摘要失败时不更新ETag/Last-Modified，下次完整请求后重试
+func (app *BlogFeedApp) pollFeed(ctx context.Context, url string, now time.Time) *FeedPollResult {
+	result := &FeedPollResult{Url: url}
+	record, err := app.sqliteInfra.SelFeed(ctx, url)
+	if err != nil {
+		result.Err = errors.Wrapf(err, "app sel feed[%s] got err", url)
+		return result
+	}
+	update := &entity.Feed{Url: url, CheckedAt: now.Format(shim.StdDateTimeLayout)}
+	if record != nil {
+		update.Title, update.Etag, update.LastModified = record.Title, record.Etag, record.LastModified
+	}
+
+	feed, err := app.crawler.FetchFeed(ctx, url, update.Etag, update.LastModified)
+	if err != nil {
+		result.Err = errors.Wrapf(err, "app fetch feed[%s] got err", url)
+		return result
+	}
+	if feed.NotModified {
+		result.Title, result.NotModified = update.Title, true
+		if err = app.sqliteInfra.ReplaceFeed(ctx, update); err != nil {
+			result.Err = errors.Wrapf(err, "app replace feed[%s] got err", url)
+		}
+		return result
+	}
+
+	result.Title = feed.Title
+	summarized := 0
+	for _, item := range feed.Items {
+		existing, err := app.sqliteInfra.SelFeedEntry(ctx, url, item.Guid)
+		if err != nil {
+			result.Err = errors.Wrapf(err, "app sel feed[%s] entry[%s] got err", url, item.Guid)
+			return result
+		}
+		if existing != nil {
+			continue
+		}
+
+		entry := &entity.FeedEntry{
+			FeedUrl:   url,
+			FeedTitle: feed.Title,
+			Guid:      item.Guid,
+			Url:       item.Url,
+			Title:     item.Title,
+			Status:    entity.FeedEntrySkipped,
+		}
+		if !item.Published.IsZero() {
+			entry.Published = item.Published.Local().Format(shim.StdDateTimeLayout)
+		}
+		if limit := app.opts.MaxEntries; limit <= 0 || summarized < limit {
+			if err = app.summaryFeedEntry(ctx, feed, item, entry, now); err != nil {
+				if ctx.Err() != nil {
+					result.Err = ctx.Err()
+					return result
+				}
+				log.Errorf("app summary feed[%s] entry[%s] got err: %s", url, item.Url, err)
+				result.Failed++
+				continue
+			}
+		}
+		if err = app.sqliteInfra.AddFeedEntry(ctx, entry); err != nil {
+			result.Err = errors.Wrapf(err, "app add feed[%s] entry[%s] got err", url, item.Guid)
+			return result
+		}
+		if entry.Status == entity.FeedEntrySummarized {
+			summarized++
+			result.Entries = append(result.Entries, entry)
+		} else {
+			result.Skipped++
+		}
+	}
+
+	update.Title = feed.Title
+	if result.Failed == 0 {
+		update.Etag, update.LastModified = feed.Etag, feed.LastModified
+	}
+	if err = app.sqliteInfra.ReplaceFeed(ctx, update); err != nil {
+		result.Err = errors.Wrapf(err, "app replace feed[%s] got err", url)
+	}
+	return result
+}
+
+// summaryFeedEntry 摘要订阅文章：只有摘录时抓取原文全文(失败时使用摘录)，内容过少或过长时标记跳过；
+// notes模式写入摘录笔记，digest模式记录当天摘要文章的路径
+func (app *BlogFeedApp) summaryFeedEntry(ctx context.Context, feed *entity.WebFeed, item *entity.WebFeedItem,
+	entry *entity.FeedEntry, now time.Time) error {
+	page := &entity.WebPage{Url: item.Url, Title: item.Title, SiteName: feed.Title, Content: item.Content}
+	if item.IsExcerpt() && item.Url != "" {
+		full, err := app.crawler.FetchReadable(ctx, item.Url)
+		switch {
+		case err != nil && ctx.Err() != nil:
+			return ctx.Err()
+		case err != nil:
+			log.Warnf("app fetch feed entry[%s] full content got err, use the excerpt: %s", item.Url, err)
+		case len(full.Content) > len(page.Content):
+			page.Content = full.Content
+		}
+	}
+
+	md := entity.NewWebPageMD(page)
+	if md.IsContentWordsTooSmall() || md.IsMinContentTooLong(entity.OpenAIMaxTokenSize) {
+		log.Infof("feed entry[%s] skipped: content is too small or too long", item.Url)
+		return nil
+	}
+	summary, err := app.aiSrv.SummaryBlogMD(ctx, md)
+	if err != nil {
+		return errors.Wrapf(err, "aiSrv summary feed entry[%s] got err", item.Url)
+	}
+
+	if app.opts.Output == entity.FeedOutputNotes {
+		published := now
+		if !item.Published.IsZero() {
+			published = item.Published.Local()
+		}
+		path := entity.UniqueClipPath(app.opts.Dir, entity.ClipNoteFilename(page, published), page.Url)
+		if err = entity.NewClipNote(path, page, summary, published).ReplaceWithNewYamlHeader(); err != nil {
+			return errors.Wrapf(err, "app write feed note[%s] got err", path)
+		}
+		entry.Filepath = path
+	} else {
+		entry.Filepath = filepath.Join(app.opts.Dir, entity.FeedDigestFilename(now))
+	}
+	entry.Status = entity.FeedEntrySummarized
+	entry.Keywords, entry.Summary, entry.Description = summary.Keywords, summary.Summary, summary.Description
+	return nil
+}
+
+// writeFeedDigest 按当天摘要文章的全部订阅文章重新生成摘要文章(一天内多次检查时追加新文章)
+func (app *BlogFeedApp) writeFeedDigest(ctx context.Context, now time.Time) error {
+	path := filepath.Join(app.opts.Dir, entity.FeedDigestFilename(now))
+	entries, err := app.sqliteInfra.SelFeedEntriesByFilepath(ctx, path)
+	if err != nil {
+		return errors.Wrapf(err, "app sel feed entries of digest[%s] got err", path)
+	}
+	if err = entity.NewFeedDigest(path, now, entries).ReplaceWithNewYamlHeader(); err != nil {
+		return errors.Wrapf(err, "app write feed digest[%s] got err", path)
+	}
+	log.Infof("feed digest[%s] updated with %d entries", path, len(entries))
+	return nil
+}
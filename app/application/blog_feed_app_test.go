@@ -0,0 +1,98 @@
+package application
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lupguo/copilot_develop/app/domain/entity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestBlogFeedApp_PollFeeds(t *testing.T) {
+	ctx := context.Background()
+	feedDir := filepath.Join(t.TempDir(), "feeds")
+	body := strings.Repeat("goroutine channel select ", 120)
+	day := time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC)
+	feedUrl := "https://go.dev/blog/feed.atom"
+	crawler := &fakeCrawler{
+		pages: map[string]*entity.WebPage{
+			"https://go.dev/blog/b": {Url: "https://go.dev/blog/b", Title: "B", Content: body},
+		},
+		feeds: map[string]*entity.WebFeed{
+			feedUrl: {Url: feedUrl, Title: "Go Blog", Etag: `"v1"`, Items: []*entity.WebFeedItem{
+				{Guid: "a", Url: "https://go.dev/blog/a", Title: "A", Published: day, Content: body},
+				{Guid: "b", Url: "https://go.dev/blog/b", Title: "B", Published: day.Add(-time.Hour), Content: "excerpt"},
+				{Guid: "c", Url: "https://go.dev/blog/c", Title: "C", Published: day.Add(-2 * time.Hour), Content: body},
+			}},
+		},
+	}
+
+	_, infra, aiSrv := newTestApp(t)
+	aiSrv.On("SummaryBlogMD", mock.Anything, mock.Anything).Return(&entity.ArticleSummary{
+		Keywords: "go,blog", Summary: "summary", Description: "description",
+	}, nil)
+
+	_, err := NewBlogFeedApp(aiSrv, infra, crawler, &FeedOptions{}).PollFeeds(ctx, []string{feedUrl})
+	assert.Error(t, err)
+	app := NewBlogFeedApp(aiSrv, infra, crawler, &FeedOptions{Dir: feedDir, MaxEntries: 2})
+
+	// 最新的2篇摘要，只有摘录的抓取原文全文，其余仅记录
+	results, err := app.PollFeeds(ctx, []string{feedUrl})
+	assert.NoError(t, err)
+	assert.Len(t, results, 1)
+	assert.Equal(t, "Go Blog", results[0].Title)
+	assert.Len(t, results[0].Entries, 2)
+	assert.Equal(t, 1, results[0].Skipped)
+	assert.Equal(t, 1, crawler.fetched)
+	aiSrv.AssertNumberOfCalls(t, "SummaryBlogMD", 2)
+
+	digestPath := filepath.Join(feedDir, entity.FeedDigestFilename(time.Now()))
+	assert.Equal(t, digestPath, results[0].Entries[0].Filepath)
+	digest, err := os.ReadFile(digestPath)
+	assert.NoError(t, err)
+	assert.Contains(t, string(digest), "## [A](https://go.dev/blog/a)")
+	assert.Contains(t, string(digest), "## [B](https://go.dev/blog/b)")
+	assert.NotContains(t, string(digest), "blog/c")
+
+	// 订阅未变化
+	results, err = app.PollFeeds(ctx, []string{feedUrl})
+	assert.NoError(t, err)
+	assert.True(t, results[0].NotModified)
+	aiSrv.AssertNumberOfCalls(t, "SummaryBlogMD", 2)
+
+	// 新文章追加到当天的摘要文章
+	feed := crawler.feeds[feedUrl]
+	feed.Etag = `"v2"`
+	feed.Items = append([]*entity.WebFeedItem{{Guid: "d", Url: "https://go.dev/blog/d", Title: "D", Content: body}}, feed.Items...)
+	results, err = app.PollFeeds(ctx, []string{feedUrl})
+	assert.NoError(t, err)
+	assert.Len(t, results[0].Entries, 1)
+	assert.Equal(t, "D", results[0].Entries[0].Title)
+	md, err := entity.NewBlogMD(digestPath)
+	assert.NoError(t, err)
+	assert.Contains(t, md.MDContent, "## [D](https://go.dev/blog/d)")
+	assert.Contains(t, md.MDContent, "## [A](https://go.dev/blog/a)")
+	assert.Equal(t, "go,blog", md.MDHeader.Keywords)
+
+	// 逐篇生成摘录笔记
+	noteDir := filepath.Join(t.TempDir(), "notes")
+	app = NewBlogFeedApp(aiSrv, infra, crawler, &FeedOptions{Dir: noteDir, Output: entity.FeedOutputNotes})
+	feed.Etag = `"v3"`
+	feed.Items = append([]*entity.WebFeedItem{{Guid: "e", Url: "https://go.dev/blog/e", Title: "Range Funcs", Published: day, Content: body}}, feed.Items...)
+	results, err = app.PollFeeds(ctx, []string{feedUrl})
+	assert.NoError(t, err)
+	assert.Len(t, results[0].Entries, 1)
+	assert.Equal(t, filepath.Join(noteDir, "2024-05-01-range-funcs.md"), results[0].Entries[0].Filepath)
+	note, err := entity.NewBlogMD(results[0].Entries[0].Filepath)
+	assert.NoError(t, err)
+	assert.Equal(t, "https://go.dev/blog/e", note.MDHeader.Extra[entity.ClipSourceKey])
+
+	// 订阅请求失败
+	_, err = app.PollFeeds(ctx, []string{"https://example.com/missing.xml"})
+	assert.Error(t, err)
+}
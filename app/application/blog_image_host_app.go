@@ -0,0 +1,254 @@
+package application
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"image"
+	_ "image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"sort"
+
+	"github.com/lupguo/copilot_develop/app/domain/entity"
+	"github.com/lupguo/copilot_develop/app/domain/repos"
+	"github.com/lupguo/copilot_develop/config"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+var (
+	// ErrImageHostNotConfigured 未设置图床的存储后端
+	ErrImageHostNotConfigured = errors.New("image host is not configured")
+
+	// ErrImageTooLarge 图片超过大小上限
+	ErrImageTooLarge = errors.New("image too large")
+
+	// ErrUnsupportedImage 不支持的图片类型，或图片已损坏
+	ErrUnsupportedImage = errors.New("unsupported image, only png, jpeg, gif and webp are allowed")
+)
+
+// ImageHostOptions 图床上传选项
+type ImageHostOptions struct {
+	MaxBytes    int64 // 单张图片大小上限，<=0时不限制
+	MaxPixels   int64 // 单张图片像素数上限，<=0时不限制
+	Widths      []int // 缩放版本的宽度
+	JPEGQuality int   // 缩放版本的JPEG质量
+	WebPQuality int   // WebP质量
+}
+
+// ImageHostOptionsFromConfig 配置的图床上传选项
+func ImageHostOptionsFromConfig(cfg *config.ImageHostConfig) *ImageHostOptions {
+	return &ImageHostOptions{
+		MaxBytes:    int64(cfg.MaxSizeMB) << 20,
+		MaxPixels:   int64(cfg.MaxPixels),
+		Widths:      cfg.Widths,
+		JPEGQuality: cfg.JPEGQuality,
+		WebPQuality: cfg.WebPQuality,
+	}
+}
+
+// UploadedImage 图床中的图片及其缩放、WebP版本
+type UploadedImage struct {
+	Image     *entity.HostedImage
+	Variants  []*entity.ImageVariant
+	Duplicate bool // 相同内容的图片已上传过
+}
+
+// BlogImageHostApp 图床的App：上传、读取图片，生成缩放及WebP版本
+type BlogImageHostApp struct {
+	sqliteInfra  repos.IReposSQLiteHostedImage
+	imageStorage repos.IReposImageStorage
+	webpEncoder  repos.IReposWebPEncoder
+	opts         *ImageHostOptions
+}
+
+// NewBlogImageHostApp 初始一个BlogImageHostApp，imageStorage为图床的存储后端，webpEncoder为nil时不生成WebP版本
+func NewBlogImageHostApp(sqliteInfra repos.IReposSQLiteHostedImage, imageStorage repos.IReposImageStorage,
+	webpEncoder repos.IReposWebPEncoder, opts *ImageHostOptions) *BlogImageHostApp {
+	return &BlogImageHostApp{
+		sqliteInfra:  sqliteInfra,
+		imageStorage: imageStorage,
+		webpEncoder:  webpEncoder,
+		opts:         opts,
+	}
+}
+
+// ImageURL 图床中图片的访问地址
+func (app *BlogImageHostApp) ImageURL(key string) string {
+	if app.imageStorage == nil {
+		return ""
+	}
+	return app.imageStorage.URL(key)
+}
+
+// OpenImage 读取图床中的图片
+func (app *BlogImageHostApp) OpenImage(ctx context.Context, key string) (io.ReadCloser, error) {
+	if app.imageStorage == nil {
+		return nil, ErrImageHostNotConfigured
+	}
+	return app.imageStorage.Open(ctx, key)
+}
+
+// ListImages 最近上传的图片
+func (app *BlogImageHostApp) ListImages(ctx context.Context, limit int) ([]*UploadedImage, error) {
+	images, err := app.sqliteInfra.ListHostedImages(ctx, limit)
+	if err != nil {
+		return nil, errors.Wrap(err, "app list hosted images got err")
+	}
+	uploaded := make([]*UploadedImage, 0, len(images))
+	for _, img := range images {
+		uploaded = append(uploaded, &UploadedImage{Image: img, Variants: hostedImageVariants(img)})
+	}
+	return uploaded, nil
+}
+
+// UploadImage 上传图片到图床：按内容sha256寻址保存原图，相同内容已上传过时直接返回(Duplicate)；
+// PNG、JPEG生成小于原图的各宽度缩放版本，设置了WebP编码时另生成WebP版本，版本生成失败时只记录日志
+func (app *BlogImageHostApp) UploadImage(ctx context.Context, name string, data []byte) (*UploadedImage, error) {
+	if app.imageStorage == nil || app.opts == nil {
+		return nil, ErrImageHostNotConfigured
+	}
+	if limit := app.opts.MaxBytes; limit > 0 && int64(len(data)) > limit {
+		return nil, errors.Wrapf(ErrImageTooLarge, "%d bytes exceeds %d bytes", len(data), limit)
+	}
+	mimeType, ext := entity.DetectImageType(data)
+	if mimeType == "" {
+		return nil, ErrUnsupportedImage
+	}
+
+	hash := entity.ImageHash(data)
+	record, err := app.sqliteInfra.SelHostedImage(ctx, hash)
+	if err != nil {
+		return nil, errors.Wrap(err, "app sel hosted image got err")
+	}
+	if record != nil {
+		ok, err := app.imageStorage.Exists(ctx, record.StorageKey)
+		if err != nil {
+			return nil, errors.Wrap(err, "image storage check exists got err")
+		}
+		if ok {
+			return &UploadedImage{Image: record, Variants: hostedImageVariants(record), Duplicate: true}, nil
+		}
+		// 存储中的图片丢失，重新保存
+		log.Warnf("app hosted image[%s] missing in storage, store again", record.StorageKey)
+		name = record.Name
+	}
+
+	img := &entity.HostedImage{
+		Hash:       hash,
+		Name:       name,
+		MimeType:   mimeType,
+		StorageKey: entity.ImageStorageKey(hash, "", ext),
+		Size:       len(data),
+	}
+	// 先只读取尺寸，超过像素上限的图片不解码(避免解压炸弹)；PNG、JPEG再完整解码(排除损坏的图片，缩放时使用)，
+	// GIF只读取尺寸，标准库不能解码WebP，WebP图片不记录尺寸
+	var src image.Image
+	switch mimeType {
+	case "image/png", "image/jpeg", "image/gif":
+		cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+		if err != nil {
+			return nil, errors.Wrapf(ErrUnsupportedImage, "decode image config got err: %s", err)
+		}
+		if limit, pixels := app.opts.MaxPixels, int64(cfg.Width)*int64(cfg.Height); limit > 0 && pixels > limit {
+			return nil, errors.Wrapf(ErrImageTooLarge, "%dx%d pixels exceeds %d pixels", cfg.Width, cfg.Height, limit)
+		}
+		img.Width, img.Height = cfg.Width, cfg.Height
+		if mimeType == "image/gif" {
+			break
+		}
+		if src, _, err = image.Decode(bytes.NewReader(data)); err != nil {
+			return nil, errors.Wrapf(ErrUnsupportedImage, "decode image got err: %s", err)
+		}
+	}
+	if err = app.imageStorage.Put(ctx, img.StorageKey, data, mimeType); err != nil {
+		return nil, errors.Wrapf(err, "image storage put[%s] got err", img.StorageKey)
+	}
+
+	var variants []*entity.ImageVariant
+	if src != nil {
+		variants = app.imageVariants(ctx, img, src, data)
+	}
+	if len(variants) > 0 {
+		b, err := json.Marshal(variants)
+		if err != nil {
+			return nil, errors.Wrap(err, "marshal image variants got err")
+		}
+		img.Variants = string(b)
+	}
+	if err = app.sqliteInfra.ReplaceHostedImage(ctx, img); err != nil {
+		return nil, errors.Wrap(err, "app replace hosted image got err")
+	}
+	log.Infof("app upload image[%s] %s %dx%d with %d variants", name, img.StorageKey, img.Width, img.Height, len(variants))
+	return &UploadedImage{Image: img, Variants: variants}, nil
+}
+
+// imageVariants 生成并保存PNG、JPEG图片的缩放及WebP版本，GIF(可能为动图)、WebP图片只保存原图
+func (app *BlogImageHostApp) imageVariants(ctx context.Context, img *entity.HostedImage, src image.Image, data []byte) []*entity.ImageVariant {
+	var variants []*entity.ImageVariant
+	put := func(name, suffix, ext, mimeType string, encoded []byte, w, h int) {
+		key := entity.ImageStorageKey(img.Hash, suffix, ext)
+		if err := app.imageStorage.Put(ctx, key, encoded, mimeType); err != nil {
+			log.Warnf("app put image variant[%s] got err: %s", key, err)
+			return
+		}
+		variants = append(variants, &entity.ImageVariant{Name: name, Key: key, MimeType: mimeType, Width: w, Height: h, Size: len(encoded)})
+	}
+	webp := func(name, suffix string, encoded []byte, w, h int) {
+		if app.webpEncoder == nil {
+			return
+		}
+		out, err := app.webpEncoder.EncodeWebP(ctx, encoded, app.opts.WebPQuality)
+		if err != nil {
+			log.Warnf("app encode webp for image[%s] got err: %s", img.StorageKey, err)
+			return
+		}
+		put(name, suffix, ".webp", "image/webp", out, w, h)
+	}
+
+	webp("webp", "", data, img.Width, img.Height)
+	widths := append([]int(nil), app.opts.Widths...)
+	sort.Ints(widths)
+	for i, width := range widths {
+		if width <= 0 || width >= img.Width || (i > 0 && width == widths[i-1]) {
+			continue
+		}
+		resized := entity.ResizeImage(src, width)
+		var b bytes.Buffer
+		var err error
+		if img.MimeType == "image/jpeg" {
+			err = jpeg.Encode(&b, resized, &jpeg.Options{Quality: app.opts.JPEGQuality})
+		} else {
+			err = png.Encode(&b, resized)
+		}
+		if err != nil {
+			log.Warnf("app encode resized image[%s] got err: %s", img.StorageKey, err)
+			continue
+		}
+		name, suffix := fmt.Sprintf("w%d", width), fmt.Sprintf("_w%d", width)
+		ext := ".png"
+		if img.MimeType == "image/jpeg" {
+			ext = ".jpg"
+		}
+		size := resized.Bounds().Size()
+		put(name, suffix, ext, img.MimeType, b.Bytes(), size.X, size.Y)
+		webp(name+".webp", suffix, b.Bytes(), size.X, size.Y)
+	}
+	return variants
+}
+
+// hostedImageVariants 图片记录中的缩放、WebP版本
+func hostedImageVariants(img *entity.HostedImage) []*entity.ImageVariant {
+	if img.Variants == "" {
+		return nil
+	}
+	var variants []*entity.ImageVariant
+	if err := json.Unmarshal([]byte(img.Variants), &variants); err != nil {
+		log.Warnf("app unmarshal variants of image[%s] got err: %s", img.Hash, err)
+		return nil
+	}
+	return variants
+}
@@ -0,0 +1,133 @@
+package application
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"io/fs"
+	"strings"
+	"testing"
+
+	"github.com/lupguo/copilot_develop/app/domain/entity"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+// memImageStorage 内存中的图床存储
+type memImageStorage struct {
+	files map[string][]byte
+}
+
+func (s *memImageStorage) Put(ctx context.Context, key string, data []byte, mimeType string) error {
+	s.files[key] = data
+	return nil
+}
+
+func (s *memImageStorage) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	data, ok := s.files[key]
+	if !ok {
+		return nil, errors.Wrap(fs.ErrNotExist, key)
+	}
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+func (s *memImageStorage) Exists(ctx context.Context, key string) (bool, error) {
+	_, ok := s.files[key]
+	return ok, nil
+}
+
+func (s *memImageStorage) URL(key string) string {
+	return "/i/" + key
+}
+
+// fakeWebPEncoder 模拟WebP编码，在输入前加上前缀
+type fakeWebPEncoder struct{}
+
+func (fakeWebPEncoder) EncodeWebP(ctx context.Context, data []byte, quality int) ([]byte, error) {
+	return append([]byte("RIFFwebp"), data...), nil
+}
+
+func testPNG(t *testing.T, w, h int) []byte {
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for x := 0; x < w; x++ {
+		img.SetNRGBA(x, 0, color.NRGBA{R: uint8(x), A: 255})
+	}
+	var b bytes.Buffer
+	assert.NoError(t, png.Encode(&b, img))
+	return b.Bytes()
+}
+
+func TestBlogImageHostApp_UploadImage(t *testing.T) {
+	ctx := context.Background()
+	infra := newTestInfra(t)
+	data := testPNG(t, 1200, 600)
+	opts := &ImageHostOptions{MaxBytes: 1 << 20, Widths: []int{1920, 480, 960, 480}, JPEGQuality: 85, WebPQuality: 80}
+
+	_, err := NewBlogImageHostApp(infra, nil, nil, opts).UploadImage(ctx, "shot.png", data)
+	assert.True(t, errors.Is(err, ErrImageHostNotConfigured))
+
+	storage := &memImageStorage{files: map[string][]byte{}}
+	app := NewBlogImageHostApp(infra, storage, fakeWebPEncoder{}, opts)
+
+	// 原图、小于原图宽度的缩放版本及各自的WebP版本
+	uploaded, err := app.UploadImage(ctx, "shot.png", data)
+	assert.NoError(t, err)
+	assert.False(t, uploaded.Duplicate)
+	img := uploaded.Image
+	assert.Equal(t, entity.ImageHash(data), img.Hash)
+	assert.Equal(t, "image/png", img.MimeType)
+	assert.Equal(t, []int{1200, 600}, []int{img.Width, img.Height})
+	assert.Equal(t, data, storage.files[img.StorageKey])
+	var names []string
+	for _, v := range uploaded.Variants {
+		names = append(names, v.Name)
+		assert.Contains(t, storage.files, v.Key)
+		assert.True(t, strings.HasPrefix(v.Key, img.Hash[:2]+"/"+img.Hash[2:4]+"/"+img.Hash), v.Key)
+	}
+	assert.Equal(t, []string{"webp", "w480", "w480.webp", "w960", "w960.webp"}, names)
+	assert.Equal(t, []int{480, 240}, []int{uploaded.Variants[1].Width, uploaded.Variants[1].Height})
+	assert.Equal(t, "image/webp", uploaded.Variants[2].MimeType)
+	assert.Len(t, storage.files, 6)
+
+	// 相同内容直接返回
+	again, err := app.UploadImage(ctx, "other.png", data)
+	assert.NoError(t, err)
+	assert.True(t, again.Duplicate)
+	assert.Equal(t, "shot.png", again.Image.Name)
+	assert.Len(t, again.Variants, 5)
+
+	// 存储中的原图丢失时重新保存
+	delete(storage.files, img.StorageKey)
+	again, err = app.UploadImage(ctx, "other.png", data)
+	assert.NoError(t, err)
+	assert.False(t, again.Duplicate)
+	assert.Equal(t, "shot.png", again.Image.Name)
+	assert.Contains(t, storage.files, img.StorageKey)
+
+	images, err := app.ListImages(ctx, 10)
+	assert.NoError(t, err)
+	assert.Len(t, images, 1)
+	assert.Len(t, images[0].Variants, 5)
+
+	// 超过大小、像素上限，不支持的类型
+	_, err = app.UploadImage(ctx, "big.png", make([]byte, 2<<20))
+	assert.True(t, errors.Is(err, ErrImageTooLarge))
+	app.opts.MaxPixels = 1200 * 599
+	_, err = app.UploadImage(ctx, "huge.png", testPNG(t, 1200, 601))
+	assert.True(t, errors.Is(err, ErrImageTooLarge))
+	app.opts.MaxPixels = 0
+	_, err = app.UploadImage(ctx, "x.svg", []byte(`<svg xmlns="http://www.w3.org/2000/svg"></svg>`))
+	assert.True(t, errors.Is(err, ErrUnsupportedImage))
+	_, err = app.UploadImage(ctx, "broken.png", data[:64])
+	assert.True(t, errors.Is(err, ErrUnsupportedImage))
+
+	// GIF只保存原图
+	gif := []byte("GIF89a\x01\x00\x01\x00\x00\x00\x00;")
+	uploaded, err = app.UploadImage(ctx, "anim.gif", gif)
+	assert.NoError(t, err)
+	assert.Empty(t, uploaded.Variants)
+	assert.True(t, strings.HasSuffix(uploaded.Image.StorageKey, ".gif"))
+}
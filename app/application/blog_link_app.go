@@ -0,0 +1,340 @@
+package application
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hold7techs/go-shim/shim"
+	"github.com/lupguo/copilot_develop/app/domain/entity"
+	"github.com/lupguo/copilot_develop/app/domain/repos"
+	"github.com/lupguo/copilot_develop/app/domain/service"
+	"github.com/lupguo/copilot_develop/config"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// linkSuggestCandidates 每个失效链接交给AI挑选的候选文章数
+const linkSuggestCandidates = 10
+
+// LinkCheckOptions 失效链接检查选项
+type LinkCheckOptions struct {
+	StaticDir   string        // Hugo static目录，为空时为content目录同级的static
+	Ignore      []string      // 忽略的链接前缀或glob
+	External    bool          // 检查站外链接
+	Concurrency int           // 站外链接并发检查数
+	CacheTTL    time.Duration // 站外链接检查结果的缓存时长，<=0时不缓存
+	Suggest     bool          // 请求AI为失效链接建议替换的站内文章
+}
+
+// LinkCheckOptionsFromConfig 配置的失效链接检查选项
+func LinkCheckOptionsFromConfig(site *config.SiteConfig, cfg *config.LinksConfig) *LinkCheckOptions {
+	return &LinkCheckOptions{
+		StaticDir:   site.StaticDir,
+		Ignore:      cfg.Ignore,
+		External:    cfg.External,
+		Concurrency: cfg.Concurrency,
+		CacheTTL:    time.Duration(cfg.CacheHours) * time.Hour,
+	}
+}
+
+// BlogLinkApp 失效链接检查的App
+type BlogLinkApp struct {
+	aiSrv       service.IServicesSummaryAI
+	sqliteInfra repos.IReposSQLiteLinkCheck
+	scanRules   *entity.BlogScanRules
+	crawler     repos.IReposWebCrawler
+}
+
+// NewBlogLinkApp 初始一个BlogLinkApp，按scanRules扫描文章，crawler检查站外链接(不检查时可为nil)
+func NewBlogLinkApp(aiSrv service.IServicesSummaryAI, sqliteInfra repos.IReposSQLiteLinkCheck,
+	scanRules *entity.BlogScanRules, crawler repos.IReposWebCrawler) *BlogLinkApp {
+	return &BlogLinkApp{
+		aiSrv:       aiSrv,
+		sqliteInfra: sqliteInfra,
+		scanRules:   scanRules,
+		crawler:     crawler,
+	}
+}
+
+// externalLinkRef 站外链接及其出现的位置
+type externalLinkRef struct {
+	path string
+	ref  *entity.MDLinkRef
+}
+
+// CheckLinks 检查blogRoot下所有文章(含栏目首页)的链接及图片：站内链接按文章、栏目、static及page bundle文件解析，
+// 站外链接(External时)去重后并发检查并缓存结果；未被引用的content、static目录下的图片报告为孤立图片，
+// Suggest时请求AI从站内文章中为失效链接挑选替换链接
+func (app *BlogLinkApp) CheckLinks(ctx context.Context, blogRoot string, opts *LinkCheckOptions) (*entity.LinkReport, error) {
+	if opts.External && app.crawler == nil {
+		return nil, errors.New("check external links needs crawler")
+	}
+	blogRoot = filepath.Clean(blogRoot)
+	mds, err := scanArticleMDs(ctx, blogRoot, app.scanRules, true)
+	if err != nil {
+		return nil, err
+	}
+
+	site := config.GetSiteConfig()
+	contentDir := filepath.Clean(siteContentDir(site, blogRoot))
+	staticDir := opts.StaticDir
+	if staticDir == "" {
+		staticDir = filepath.Join(filepath.Dir(contentDir), "static")
+	}
+	articles := linkArticles(mds)
+	index := entity.NewLinkIndex(site.BaseURL, site.Permalink, contentDir, articles)
+	checker := entity.NewLinkChecker(index, staticDir, opts.Ignore)
+
+	report := &entity.LinkReport{Files: len(mds)}
+	referenced := make(map[string]bool)
+	externals := make(map[string][]*externalLinkRef)
+	var externalUrls []string
+	for _, md := range mds {
+		raw, err := os.ReadFile(md.Filepath)
+		if err != nil {
+			return nil, errors.Wrapf(err, "app read md[%s] got err", md.Filepath)
+		}
+		refs := append(entity.ExtractMDLinkRefs(string(raw)), entity.HeaderImageRefs(md.MDHeader)...)
+		for _, ref := range refs {
+			if ref.Image {
+				report.Images++
+			} else {
+				report.Links++
+			}
+
+			switch checker.Classify(ref.Target) {
+			case entity.LinkTargetExternal:
+				u := ref.Target
+				if i := strings.Index(u, "#"); i >= 0 {
+					u = u[:i]
+				}
+				if _, ok := externals[u]; !ok {
+					externalUrls = append(externalUrls, u)
+				}
+				externals[u] = append(externals[u], &externalLinkRef{path: md.Filepath, ref: ref})
+			case entity.LinkTargetInternal:
+				if target := checker.Resolve(md.Filepath, ref); target != "" {
+					referenced[target] = true
+					continue
+				}
+				kind := entity.LinkIssueBroken
+				if ref.Image {
+					kind = entity.LinkIssueMissingImage
+				}
+				report.Issues = append(report.Issues, &entity.LinkIssue{
+					Path: md.Filepath, Line: ref.Line, Kind: kind, Target: ref.Target, Text: ref.Text,
+				})
+			}
+		}
+	}
+
+	if opts.External {
+		report.External = len(externalUrls)
+		checks, err := app.checkExternalLinks(ctx, externalUrls, opts)
+		if err != nil {
+			return nil, err
+		}
+		for _, u := range externalUrls {
+			check := checks[u]
+			if check == nil || !check.IsBroken() {
+				continue
+			}
+			for _, ext := range externals[u] {
+				report.Issues = append(report.Issues, &entity.LinkIssue{
+					Path: ext.path, Line: ext.ref.Line, Kind: entity.LinkIssueExternal,
+					Target: ext.ref.Target, Text: ext.ref.Text, Reason: check.Reason(),
+				})
+			}
+		}
+	}
+
+	orphans, err := orphanImages(ctx, checker, referenced, contentDir, staticDir)
+	if err != nil {
+		return nil, err
+	}
+	report.Issues = append(report.Issues, orphans...)
+
+	if opts.Suggest {
+		if err = app.suggestLinkReplacements(ctx, mds, report.Issues, site, contentDir); err != nil {
+			return nil, err
+		}
+	}
+	entity.SortLinkIssues(report.Issues)
+	return report, nil
+}
+
+// checkExternalLinks 并发检查站外链接，缓存有效期内的直接使用上次的检查结果
+func (app *BlogLinkApp) checkExternalLinks(ctx context.Context, urls []string, opts *LinkCheckOptions) (map[string]*entity.LinkCheck, error) {
+	concurrency := opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+
+	var mu sync.Mutex
+	checks := make(map[string]*entity.LinkCheck, len(urls))
+	jobs := make(chan string)
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for u := range jobs {
+				check, err := app.checkExternalLink(ctx, u, opts.CacheTTL)
+				if err != nil {
+					if ctx.Err() == nil {
+						log.Errorf("app check link[%s] got err: %s", u, err)
+					}
+					continue
+				}
+				mu.Lock()
+				checks[u] = check
+				mu.Unlock()
+			}
+		}()
+	}
+
+	for _, u := range urls {
+		select {
+		case jobs <- u:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	close(jobs)
+	wg.Wait()
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	return checks, nil
+}
+
+// checkExternalLink 检查单个站外链接，结果写回缓存(失败只影响下次是否重新请求)
+func (app *BlogLinkApp) checkExternalLink(ctx context.Context, u string, ttl time.Duration) (*entity.LinkCheck, error) {
+	now := time.Now()
+	if ttl > 0 {
+		cached, err := app.sqliteInfra.SelLinkCheck(ctx, u)
+		if err != nil {
+			log.Warnf("app sel link check[%s] got err: %s", u, err)
+		} else if entity.IsLinkCheckFresh(cached, ttl, now) {
+			return cached, nil
+		}
+	}
+
+	check, err := app.crawler.CheckLink(ctx, u)
+	if err != nil {
+		return nil, err
+	}
+	check.Url, check.CheckedAt = u, now.Format(shim.StdDateTimeLayout)
+	if err = app.sqliteInfra.ReplaceLinkCheck(ctx, check); err != nil {
+		log.Warnf("app replace link check[%s] got err: %s", u, err)
+	}
+	return check, nil
+}
+
+// orphanImages content、static目录下未被引用(且不匹配忽略规则)的图片，跳过隐藏目录
+func orphanImages(ctx context.Context, checker *entity.LinkChecker, referenced map[string]bool, dirs ...string) ([]*entity.LinkIssue, error) {
+	var issues []*entity.LinkIssue
+	seen := make(map[string]bool)
+	for _, dir := range dirs {
+		if _, err := os.Stat(dir); err != nil {
+			continue
+		}
+		err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			if d.IsDir() {
+				if path != dir && strings.HasPrefix(d.Name(), ".") {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if seen[path] || !entity.IsImageFile(path) || referenced[path] {
+				return nil
+			}
+			seen[path] = true
+			rel, err := filepath.Rel(dir, path)
+			if err != nil || checker.Ignored("/"+filepath.ToSlash(rel)) {
+				return nil
+			}
+			issues = append(issues, &entity.LinkIssue{Path: path, Kind: entity.LinkIssueOrphanImage})
+			return nil
+		})
+		if err != nil {
+			return nil, errors.Wrapf(err, "app walk images in dir[%s] got err", dir)
+		}
+	}
+	return issues, nil
+}
+
+// suggestLinkReplacements 按文章请求AI为失效的站内、站外链接建议替换的站内文章，失败时仅记录日志
+func (app *BlogLinkApp) suggestLinkReplacements(ctx context.Context, mds []*entity.BlogMD, issues []*entity.LinkIssue,
+	site *config.SiteConfig, contentDir string) error {
+	var candidates []*entity.LinkCandidate
+	byPath := make(map[string]*entity.BlogMD, len(mds))
+	for _, md := range mds {
+		byPath[md.Filepath] = md
+		if filepath.Base(md.Filepath) == entity.SectionIndexFile || md.MDHeader.Draft {
+			continue
+		}
+		article := &entity.BlogArticle{Path: md.Filepath, Date: md.MDHeader.Date, Slug: md.MDHeader.Slug}
+		candidates = append(candidates, &entity.LinkCandidate{
+			Path:     md.Filepath,
+			Title:    md.MDHeader.Title,
+			Keywords: md.MDHeader.Keywords,
+			Url:      entity.Permalink(site.Permalink, contentDir, article),
+		})
+	}
+
+	// 按文章分组，每篇文章请求一次
+	var paths []string
+	broken := make(map[string][]*entity.LinkIssue)
+	for _, issue := range issues {
+		if issue.Kind != entity.LinkIssueBroken && issue.Kind != entity.LinkIssueExternal {
+			continue
+		}
+		if _, ok := broken[issue.Path]; !ok {
+			paths = append(paths, issue.Path)
+		}
+		broken[issue.Path] = append(broken[issue.Path], issue)
+	}
+
+	for _, path := range paths {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		var fileCandidates []*entity.LinkCandidate
+		added := make(map[string]bool)
+		for _, issue := range broken[path] {
+			for _, candidate := range entity.RankLinkCandidates(issue, candidates, linkSuggestCandidates) {
+				if candidate.Path != path && !added[candidate.Url] {
+					added[candidate.Url] = true
+					fileCandidates = append(fileCandidates, candidate)
+				}
+			}
+		}
+		if len(fileCandidates) == 0 {
+			continue
+		}
+
+		suggestions, err := app.aiSrv.SuggestLinkReplacements(ctx, byPath[path], broken[path], fileCandidates)
+		if err != nil {
+			log.Errorf("aiSrv suggest link replacements for md[%s] got err: %s", path, err)
+			continue
+		}
+		for _, issue := range broken[path] {
+			issue.Suggestion = suggestions[issue.Target]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package application
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/lupguo/copilot_develop/app/domain/entity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestBlogLinkApp_CheckLinks(t *testing.T) {
+	ctx := context.Background()
+	root := t.TempDir()
+	contentDir := filepath.Join(root, "content")
+	writeFile := newTestFileWriter(t, root)
+	goPost := writeFile("content/posts/go.md", "---\ntitle: Go channel详解\ndate: 2023-01-01\n---\n"+
+		"[rust](rust.md) [old](/posts/go-channels-old/) [tag](/tags/go/)\n"+
+		"![logo](/img/logo.png) ![missing](missing.png)\n"+
+		"[ok](https://example.com/ok#top) [gone](https://example.com/gone)\n")
+	writeFile("content/posts/rust.md", "---\ntitle: Rust\ndate: 2023-06-01\ncover: /img/cover.png\n---\n"+
+		"[again](https://example.com/ok) [go](/posts/go/)\n")
+	writeFile("content/posts/channel.md", "---\ntitle: Go channel进阶\ndate: 2023-02-01\n---\n[go](go.md)\n"+
+		testPostBody)
+	writeFile("content/posts/bundle/index.md", "---\ntitle: Bundle\n---\n![a](a.png)\n")
+	writeFile("content/posts/bundle/a.png", "")
+	writeFile("content/posts/bundle/unused.png", "")
+	writeFile("static/img/logo.png", "")
+	writeFile("static/img/cover.png", "")
+	orphan := writeFile("static/img/orphan.jpg", "")
+
+	_, infra, aiSrv := newTestApp(t)
+	crawler := &fakeCrawler{links: map[string]int{"https://example.com/ok": 200, "https://example.com/gone": 404}}
+	aiSrv.On("SuggestLinkReplacements", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(map[string]string{"/posts/go-channels-old/": "/posts/channel/"}, nil)
+	opts := &LinkCheckOptions{Ignore: []string{"/tags/"}, External: true, Concurrency: 2, CacheTTL: time.Hour}
+
+	_, err := NewBlogLinkApp(aiSrv, infra, nil, nil).CheckLinks(ctx, contentDir, opts)
+	assert.Error(t, err)
+	app := NewBlogLinkApp(aiSrv, infra, nil, crawler)
+
+	report, err := app.CheckLinks(ctx, contentDir, opts)
+	assert.NoError(t, err)
+	assert.Equal(t, 4, report.Files)
+	assert.Equal(t, 2, report.External)
+	assert.Equal(t, 2, crawler.checked)
+	var got [][4]interface{}
+	for _, issue := range report.Issues {
+		got = append(got, [4]interface{}{issue.Path, issue.Line, issue.Kind, issue.Target})
+	}
+	assert.Equal(t, [][4]interface{}{
+		{filepath.Join(contentDir, "posts/bundle/unused.png"), 0, entity.LinkIssueOrphanImage, ""},
+		{goPost, 5, entity.LinkIssueBroken, "/posts/go-channels-old/"},
+		{goPost, 6, entity.LinkIssueMissingImage, "missing.png"},
+		{goPost, 7, entity.LinkIssueExternal, "https://example.com/gone"},
+		{orphan, 0, entity.LinkIssueOrphanImage, ""},
+	}, got)
+	assert.Equal(t, "status 404", report.Issues[3].Reason)
+
+	// 缓存有效期内不再请求站外链接；Suggest时为失效链接建议替换
+	opts.Suggest = true
+	report, err = app.CheckLinks(ctx, contentDir, opts)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, crawler.checked)
+	assert.Equal(t, "/posts/channel/", report.Issues[1].Suggestion)
+	assert.Equal(t, "", report.Issues[3].Suggestion)
+	aiSrv.AssertNumberOfCalls(t, "SuggestLinkReplacements", 1)
+}
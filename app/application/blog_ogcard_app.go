@@ -0,0 +1,131 @@
+package application
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+
+	"github.com/lupguo/copilot_develop/app/domain/entity"
+	"github.com/lupguo/copilot_develop/app/domain/repos"
+	"github.com/lupguo/copilot_develop/config"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// OGCardOptions 社交分享卡片生成选项
+type OGCardOptions struct {
+	SiteName  string // 卡片上的站点名
+	Key       string // 写入的front matter字段
+	Dir       string // 非page bundle文章的卡片保存在static下的目录
+	StaticDir string // Hugo static目录，为空时为content目录同级的static
+	Force     bool   // 标题、描述未变化的文章也重新生成
+}
+
+// OGCardOptionsFromConfig 配置的社交分享卡片生成选项
+func OGCardOptionsFromConfig(site *config.SiteConfig, cfg *config.OGCardConfig) *OGCardOptions {
+	return &OGCardOptions{
+		SiteName:  cfg.SiteName,
+		Key:       cfg.Key,
+		Dir:       cfg.Dir,
+		StaticDir: site.StaticDir,
+	}
+}
+
+// BlogOGCardApp 社交分享卡片的App
+type BlogOGCardApp struct {
+	sqliteInfra  repos.IReposSQLiteOGCard
+	scanRules    *entity.BlogScanRules
+	cardRenderer repos.IReposCardRenderer
+}
+
+// NewBlogOGCardApp 初始一个BlogOGCardApp，按scanRules扫描文章，cardRenderer渲染文章的og.png
+func NewBlogOGCardApp(sqliteInfra repos.IReposSQLiteOGCard, scanRules *entity.BlogScanRules,
+	cardRenderer repos.IReposCardRenderer) *BlogOGCardApp {
+	return &BlogOGCardApp{
+		sqliteInfra:  sqliteInfra,
+		scanRules:    scanRules,
+		cardRenderer: cardRenderer,
+	}
+}
+
+// GenerateOGCards 为path(文章文件或目录，为空时为blogRoot)下的文章(目录下不含草稿)渲染社交分享卡片，写入front matter；
+// DB中记录生成时标题、描述的hash，两者都未变化且卡片文件存在时不重新生成。返回本次生成的卡片，单篇文章失败时记录日志后继续
+func (app *BlogOGCardApp) GenerateOGCards(ctx context.Context, blogRoot, path string, opts *OGCardOptions) ([]*entity.OGCardImage, error) {
+	if app.cardRenderer == nil {
+		return nil, errors.New("generate og cards needs card renderer")
+	}
+	mds, err := pathArticleMDs(ctx, blogRoot, path, app.scanRules)
+	if err != nil {
+		return nil, err
+	}
+	staticDir := siteStaticDir(opts.StaticDir, blogRoot)
+
+	var cards []*entity.OGCardImage
+	for _, md := range mds {
+		if ctx.Err() != nil {
+			return cards, ctx.Err()
+		}
+		card, err := app.generateOGCard(ctx, md, staticDir, opts)
+		if err != nil {
+			log.Errorf("app generate og card for md[%s] got err: %s", md.Filepath, err)
+			continue
+		}
+		if card != nil {
+			cards = append(cards, card)
+		}
+	}
+	return cards, nil
+}
+
+// generateOGCard 渲染单篇文章的卡片并写入front matter及生成记录，标题、描述未变化时跳过(返回nil)
+func (app *BlogOGCardApp) generateOGCard(ctx context.Context, md *entity.BlogMD, staticDir string, opts *OGCardOptions) (*entity.OGCardImage, error) {
+	card := entity.NewOGCard(md, opts.SiteName)
+	if card.Title == "" {
+		return nil, nil
+	}
+	file, ref := entity.OGCardLocation(md.Filepath, staticDir, opts.Dir)
+	record, err := app.sqliteInfra.SelOGCard(ctx, md.Filepath)
+	if err != nil {
+		return nil, errors.Wrap(err, "sel og card got err")
+	}
+
+	hash := card.Hash()
+	if !opts.Force && record != nil && record.Hash == hash && record.File == file {
+		if _, err = os.Stat(file); err == nil {
+			// 卡片未变化，front matter中的引用被删除时补上
+			return nil, setOGCardRef(md, opts.Key, ref)
+		}
+	}
+
+	data, err := app.cardRenderer.RenderCard(ctx, card)
+	if err != nil {
+		return nil, errors.Wrap(err, "card renderer render card got err")
+	}
+	if err = os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return nil, errors.Wrapf(err, "mkdir for og card[%s] got err", file)
+	}
+	if err = os.WriteFile(file, data, 0644); err != nil {
+		return nil, errors.Wrapf(err, "write og card[%s] got err", file)
+	}
+	if err = setOGCardRef(md, opts.Key, ref); err != nil {
+		return nil, err
+	}
+	log.Infof("app generate og card[%s] for md[%s]", file, md.Filepath)
+
+	ogCard := &entity.OGCardImage{Path: md.Filepath, Hash: hash, File: file, Ref: ref}
+	if err = app.sqliteInfra.ReplaceOGCard(ctx, ogCard); err != nil {
+		return nil, errors.Wrap(err, "replace og card record got err")
+	}
+	return ogCard, nil
+}
+
+// setOGCardRef front matter中写入卡片引用，没有变化时不改写文章
+func setOGCardRef(md *entity.BlogMD, key, ref string) error {
+	if !md.MDHeader.SetCover(key, ref) {
+		return nil
+	}
+	if err := md.SafeReplaceYamlHeader(); err != nil {
+		return errors.Wrap(err, "replace md yaml header got err")
+	}
+	return nil
+}
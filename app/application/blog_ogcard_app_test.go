@@ -0,0 +1,85 @@
+package application
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/lupguo/copilot_develop/app/domain/entity"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeCardRenderer 模拟卡片渲染，返回标题、描述作为图片内容
+type fakeCardRenderer struct {
+	titles []string
+}
+
+func (f *fakeCardRenderer) RenderCard(ctx context.Context, card *entity.OGCard) ([]byte, error) {
+	f.titles = append(f.titles, card.Title)
+	return []byte(card.SiteName + "|" + card.Title + "|" + card.Description), nil
+}
+
+func TestBlogOGCardApp_GenerateOGCards(t *testing.T) {
+	ctx := context.Background()
+	root := t.TempDir()
+	contentDir := filepath.Join(root, "content")
+	body := testPostBody
+	writeFile := newTestFileWriter(t, contentDir)
+	goPost := writeFile("posts/go.md", "---\ntitle: Go调度\ndescription: GMP模型\ntags: [go]\n---\n"+body)
+	bundle := writeFile("posts/bundle/index.en.md", "---\ntitle: Bundle\nsummary: bundle summary\n---\n"+body)
+	writeFile("posts/draft.md", "---\ntitle: Draft\ndraft: true\n---\n"+body)
+
+	infra := newTestInfra(t)
+	opts := &OGCardOptions{SiteName: "tkstorm.com", Key: "og_image", Dir: "images/og"}
+
+	_, err := NewBlogOGCardApp(infra, nil, nil).GenerateOGCards(ctx, contentDir, "", opts)
+	assert.Error(t, err)
+	renderer := &fakeCardRenderer{}
+	app := NewBlogOGCardApp(infra, nil, renderer)
+
+	cards, err := app.GenerateOGCards(ctx, contentDir, "", opts)
+	assert.NoError(t, err)
+	assert.Len(t, cards, 2)
+	assert.ElementsMatch(t, []string{"Go调度", "Bundle"}, renderer.titles)
+
+	data, err := os.ReadFile(filepath.Join(root, "static/images/og/go/og.png"))
+	assert.NoError(t, err)
+	assert.Equal(t, "tkstorm.com|Go调度|GMP模型", string(data))
+	data, err = os.ReadFile(filepath.Join(filepath.Dir(bundle), "og.en.png"))
+	assert.NoError(t, err)
+	assert.Equal(t, "tkstorm.com|Bundle|bundle summary", string(data))
+
+	md, err := entity.NewBlogMD(goPost)
+	assert.NoError(t, err)
+	assert.Equal(t, "/images/og/go/og.png", md.MDHeader.Extra["og_image"])
+	assert.Equal(t, []interface{}{"/images/og/go/og.png"}, md.MDHeader.Extra["images"])
+	assert.Equal(t, body, md.MDContent)
+
+	// 标题、描述未变化时不重新生成，标签变化不影响
+	renderer.titles = nil
+	md.MDHeader.Tags = []string{"go", "runtime"}
+	assert.NoError(t, md.SafeReplaceYamlHeader())
+	cards, err = app.GenerateOGCards(ctx, contentDir, "", opts)
+	assert.NoError(t, err)
+	assert.Empty(t, cards)
+	assert.Empty(t, renderer.titles)
+
+	// 描述变化、卡片文件被删除、--force 时重新生成
+	md.MDHeader.Description = "GMP模型详解"
+	assert.NoError(t, md.SafeReplaceYamlHeader())
+	assert.NoError(t, os.Remove(filepath.Join(filepath.Dir(bundle), "og.en.png")))
+	cards, err = app.GenerateOGCards(ctx, contentDir, "", opts)
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{"Go调度", "Bundle"}, renderer.titles)
+	assert.Len(t, cards, 2)
+
+	record, err := infra.SelOGCard(ctx, goPost)
+	assert.NoError(t, err)
+	assert.Equal(t, entity.NewOGCard(md, "").Hash(), record.Hash)
+
+	opts.Force = true
+	cards, err = app.GenerateOGCards(ctx, contentDir, goPost, opts)
+	assert.NoError(t, err)
+	assert.Len(t, cards, 1)
+}
@@ -0,0 +1,189 @@
+package application
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"mime"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/lupguo/copilot_develop/app/domain/entity"
+	"github.com/lupguo/copilot_develop/config"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// AltTextOptions 图片alt文字生成选项
+type AltTextOptions struct {
+	StaticDir     string // Hugo static目录，为空时为content目录同级的static
+	Vision        bool   // 由支持图片输入的模型看图生成
+	ContextChars  int    // 交给AI的图片上下文字符数
+	MaxImageBytes int64  // vision模式上传的本地图片大小上限
+	Apply         bool   // 将alt文字写入文章
+}
+
+// AltTextOptionsFromConfig 配置的图片alt文字生成选项
+func AltTextOptionsFromConfig(site *config.SiteConfig, cfg *config.AltTextConfig) *AltTextOptions {
+	return &AltTextOptions{
+		StaticDir:     site.StaticDir,
+		Vision:        cfg.Vision,
+		ContextChars:  cfg.ContextChars,
+		MaxImageBytes: int64(cfg.MaxImageKB) * 1024,
+	}
+}
+
+// GenerateAltTexts 为blogRoot下文章中alt文字缺失或为空的图片生成alt文字，已生成过的(按文章路径+图片地址)直接使用记录，
+// 不再请求AI；Apply时将alt文字写入正文对应的图片，正文其余部分保持不变
+func (app *BlogSummaryApp) GenerateAltTexts(ctx context.Context, blogRoot string, opts *AltTextOptions) ([]*entity.AltTextResult, error) {
+	blogRoot = filepath.Clean(blogRoot)
+	mds, err := scanArticleMDs(ctx, blogRoot, app.scanRules, true)
+	if err != nil {
+		return nil, err
+	}
+
+	var checker *entity.LinkChecker
+	if opts.Vision {
+		site := config.GetSiteConfig()
+		contentDir := filepath.Clean(siteContentDir(site, blogRoot))
+		staticDir := opts.StaticDir
+		if staticDir == "" {
+			staticDir = filepath.Join(filepath.Dir(contentDir), "static")
+		}
+		index := entity.NewLinkIndex(site.BaseURL, site.Permalink, contentDir, linkArticles(mds))
+		checker = entity.NewLinkChecker(index, staticDir, nil)
+	}
+
+	var results []*entity.AltTextResult
+	for _, md := range mds {
+		if ctx.Err() != nil {
+			return results, ctx.Err()
+		}
+		images := entity.FindMissingAltImages(md.MDContent, opts.ContextChars)
+		if len(images) == 0 {
+			continue
+		}
+		offset, err := contentLineOffset(md)
+		if err != nil {
+			return results, err
+		}
+
+		alts := make(map[string]string)
+		records := make(map[string]*entity.ImageAltText)
+		var mdResults []*entity.AltTextResult
+		for _, img := range images {
+			result := &entity.AltTextResult{Path: md.Filepath, Line: img.Line + offset, Target: img.Target}
+			if record, ok := records[img.Target]; ok {
+				result.AltText, result.Mode, result.Cached = record.AltText, record.Mode, true
+				mdResults = append(mdResults, result)
+				continue
+			}
+
+			record, err := app.altTextRecord(ctx, md, img, checker, opts)
+			if err != nil {
+				log.Errorf("app generate alt text for image[%s] in md[%s] got err: %s", img.Target, md.Filepath, err)
+				continue
+			}
+			if record == nil {
+				continue
+			}
+			result.AltText, result.Mode, result.Cached = record.AltText, record.Mode, record.ID > 0
+			records[img.Target], alts[img.Target] = record, record.AltText
+			mdResults = append(mdResults, result)
+			if record.ID == 0 {
+				if err = app.sqliteInfra.ReplaceImageAltText(ctx, record); err != nil {
+					return results, errors.Wrapf(err, "app replace alt text of image[%s] got err", img.Target)
+				}
+			}
+		}
+
+		if opts.Apply && len(alts) > 0 {
+			content, n := entity.ApplyAltTexts(md.MDContent, alts)
+			if err = md.SafeReplaceContent(content); err != nil {
+				return results, errors.Wrapf(err, "app replace md[%s] content got err", md.Filepath)
+			}
+			log.Infof("app write %d alt texts into md[%s]", n, md.Filepath)
+			for _, record := range records {
+				record.Status = entity.AltTextStatusApplied
+				if err = app.sqliteInfra.ReplaceImageAltText(ctx, record); err != nil {
+					log.Warnf("app mark alt text of image[%s] applied got err: %s", record.Target, err)
+				}
+			}
+			for _, result := range mdResults {
+				result.Applied = true
+			}
+		}
+		results = append(results, mdResults...)
+	}
+	return results, nil
+}
+
+// altTextRecord 图片已有的alt文字记录，没有时请求AI生成(未保存，ID为0)，AI未返回alt文字时返回nil
+func (app *BlogSummaryApp) altTextRecord(ctx context.Context, md *entity.BlogMD, img *entity.AltTextImage,
+	checker *entity.LinkChecker, opts *AltTextOptions) (*entity.ImageAltText, error) {
+	record, err := app.sqliteInfra.SelImageAltText(ctx, md.Filepath, img.Target)
+	if err != nil {
+		return nil, errors.Wrap(err, "sel image alt text got err")
+	}
+	if record != nil && record.AltText != "" {
+		return record, nil
+	}
+
+	mode, imageURL := entity.AltTextModeContext, ""
+	if checker != nil {
+		if imageURL = altTextImageURL(checker, md.Filepath, img.Target, opts.MaxImageBytes); imageURL != "" {
+			mode = entity.AltTextModeVision
+		}
+	}
+	alt, err := app.aiSrv.GenerateAltText(ctx, md, img, imageURL)
+	if err != nil {
+		return nil, err
+	}
+	if alt == "" {
+		log.Warnf("aiSrv generate alt text for image[%s] got empty alt", img.Target)
+		return nil, nil
+	}
+	return &entity.ImageAltText{
+		Path:    md.Filepath,
+		Target:  img.Target,
+		AltText: alt,
+		Mode:    mode,
+		Status:  entity.AltTextStatusPending,
+	}, nil
+}
+
+// altTextImageURL vision模式交给AI的图片：站外图片使用原地址，本地图片转为data uri，
+// svg、无法解析或超过大小上限的图片返回空(按上下文生成)
+func altTextImageURL(checker *entity.LinkChecker, source, target string, maxBytes int64) string {
+	ext := strings.ToLower(filepath.Ext(strings.SplitN(target, "?", 2)[0]))
+	if ext == ".svg" {
+		return ""
+	}
+	switch checker.Classify(target) {
+	case entity.LinkTargetExternal:
+		return target
+	case entity.LinkTargetInternal:
+	default:
+		return ""
+	}
+
+	file := checker.Resolve(source, &entity.MDLinkRef{Target: target, Image: true})
+	if file == "" {
+		return ""
+	}
+	info, err := os.Stat(file)
+	if err != nil || info.IsDir() || (maxBytes > 0 && info.Size() > maxBytes) {
+		return ""
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		log.Warnf("app read image[%s] got err: %s", file, err)
+		return ""
+	}
+	mimeType := mime.TypeByExtension(strings.ToLower(filepath.Ext(file)))
+	if mimeType == "" {
+		mimeType = "image/png"
+	}
+	return fmt.Sprintf("data:%s;base64,%s", mimeType, base64.StdEncoding.EncodeToString(data))
+}
@@ -0,0 +1,63 @@
+package application
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/lupguo/copilot_develop/app/domain/entity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestBlogSummaryApp_GenerateAltTexts(t *testing.T) {
+	ctx := context.Background()
+	root := t.TempDir()
+	contentDir := filepath.Join(root, "content")
+	writeFile := newTestFileWriter(t, root)
+	body := "## GMP\n\nGo调度器由G、M、P组成。\n\n![](/img/gmp.png)\n\n" +
+		"```\n![](/img/code.png)\n```\n<img src=\"https://example.com/sched.svg\"> ![ok](/img/ok.png)\n"
+	post := writeFile("content/posts/go.md", "---\ntitle: Go调度\ndate: 2023-01-01\n---\n"+body)
+	writeFile("static/img/gmp.png", "png")
+
+	app, infra, aiSrv := newTestApp(t)
+	aiSrv.On("GenerateAltText", mock.Anything, mock.Anything, mock.MatchedBy(func(img *entity.AltTextImage) bool {
+		return img.Target == "/img/gmp.png"
+	}), mock.MatchedBy(func(u string) bool { return strings.HasPrefix(u, "data:image/png;base64,") })).Return("GMP调度模型", nil)
+	aiSrv.On("GenerateAltText", mock.Anything, mock.Anything, mock.Anything, "").Return("调度流程", nil)
+	opts := &AltTextOptions{Vision: true, ContextChars: 200, MaxImageBytes: 1024}
+
+	// 预览：生成并记录alt文字，不修改文章
+	results, err := app.GenerateAltTexts(ctx, contentDir, opts)
+	assert.NoError(t, err)
+	assert.Equal(t, []*entity.AltTextResult{
+		{Path: post, Line: 9, Target: "/img/gmp.png", AltText: "GMP调度模型", Mode: entity.AltTextModeVision},
+		{Path: post, Line: 14, Target: "https://example.com/sched.svg", AltText: "调度流程", Mode: entity.AltTextModeContext},
+	}, results)
+	raw, err := os.ReadFile(post)
+	assert.NoError(t, err)
+	assert.Equal(t, "---\ntitle: Go调度\ndate: 2023-01-01\n---\n"+body, string(raw))
+
+	// 写入：使用已记录的alt文字，不再请求AI
+	opts.Apply = true
+	results, err = app.GenerateAltTexts(ctx, contentDir, opts)
+	assert.NoError(t, err)
+	assert.Len(t, results, 2)
+	assert.True(t, results[0].Cached && results[0].Applied)
+	aiSrv.AssertNumberOfCalls(t, "GenerateAltText", 2)
+	raw, err = os.ReadFile(post)
+	assert.NoError(t, err)
+	assert.Equal(t, "---\ntitle: Go调度\ndate: 2023-01-01\n---\n"+strings.NewReplacer(
+		"![](/img/gmp.png)", "![GMP调度模型](/img/gmp.png)",
+		"<img src=", "<img alt=\"调度流程\" src=",
+	).Replace(body), string(raw))
+	record, err := infra.SelImageAltText(ctx, post, "/img/gmp.png")
+	assert.NoError(t, err)
+	assert.Equal(t, entity.AltTextStatusApplied, record.Status)
+
+	results, err = app.GenerateAltTexts(ctx, contentDir, opts)
+	assert.NoError(t, err)
+	assert.Empty(t, results)
+}
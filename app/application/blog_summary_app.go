@@ -2,22 +2,49 @@ package application
 
 import (
 	"context"
-	"path/filepath"
+	"fmt"
+	"strings"
+	"sync"
+	"unicode/utf8"
 
-	"github.com/hold7techs/go-shim/shim"
 	"github.com/lupguo/copilot_develop/app/domain/entity"
 	"github.com/lupguo/copilot_develop/app/domain/repos"
 	"github.com/lupguo/copilot_develop/app/domain/service"
+	"github.com/lupguo/copilot_develop/config"
 	"github.com/lupguo/copilot_develop/internal/intershim"
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
-	"golang.org/x/sync/errgroup"
 )
 
 // BlogSummaryApp Blog的汇总App
 type BlogSummaryApp struct {
 	aiSrv       service.IServicesSummaryAI
 	sqliteInfra repos.IReposSQLiteBlogSummary
+
+	// 并发处理时，短标记、slug、别名在写入DB前先在内存中占位(kind:value => path)，避免同一批次内冲突，
+	// 文章处理结束(写入DB或失败)后释放
+	reserveMu sync.Mutex
+	reserved  map[string]string
+
+	// 摘要任务worker并发数、新任务提交通知、进度事件
+	concurrency int
+	jobWake     chan struct{}
+	events      *jobEventHub
+
+	// 审核模式：AI生成的摘要存为待审核，审核通过后才写入文章
+	reviewMode bool
+
+	// 本地blog git仓库，推送webhook等按变更文件处理时使用
+	gitRepo repos.IReposGit
+
+	// 文章扫描规则(include/exclude、栏目规则)
+	scanRules *entity.BlogScanRules
+
+	// 栏目摘要汇总串行执行
+	sectionMu sync.Mutex
+
+	// 文章权重公式，为nil时使用默认权重
+	weightRules *entity.WeightRules
 }
 
 // NewBlogSummaryApp 初始一个BlogSummaryApp
@@ -25,87 +52,115 @@ func NewBlogSummaryApp(aiSrv service.IServicesSummaryAI, sqliteInfra repos.IRepo
 	return &BlogSummaryApp{
 		aiSrv:       aiSrv,
 		sqliteInfra: sqliteInfra,
+		reserved:    make(map[string]string),
+		concurrency: DefaultJobConcurrency,
+		jobWake:     make(chan struct{}, 1),
+		events:      newJobEventHub(),
 	}
 }
 
-// UpdateBlogHeaderYaml 并发更新Blog的汇总信息
+// UpdateBlogHeaderYaml 更新Blog的汇总信息：提交(或恢复未完成的)摘要任务并同步处理完
 func (app *BlogSummaryApp) UpdateBlogHeaderYaml(ctx context.Context, storageRoot string) error {
-	// 查询目录下所有的markdown目录 -> slice内 []*BlogMD
-	blogFilePaths, err := shim.FindFilePaths(storageRoot, "*.md")
+	job, err := app.SubmitSummaryJob(ctx, storageRoot, storageRoot)
 	if err != nil {
-		return errors.Wrapf(err, "shim find file paths root [%s] got err", storageRoot)
-	}
-
-	// 基于条件过滤掉"path为_index.md"的
-	blogFilePaths = shim.ProcessStringsSlice(blogFilePaths, func(path string) bool {
-		return filepath.Base(path) == "_index.md"
-	}, nil)
-
-	// 通过正则提取md的主题内容 - 改并发版本
-	egp := errgroup.Group{}
-	egp.SetLimit(10)
-	for _, blogFilePath := range blogFilePaths {
-		mdPath := blogFilePath
-		egp.Go(func() error {
-			if err = app.updateBlogYamlHeader(ctx, mdPath); err != nil {
-				return intershim.LogAndWrapf(err, "replace summary for md file[%s]  got err", mdPath)
-			}
-			return nil
-		})
+		return intershim.LogAndWrapf(err, "submit summary job for root[%s] got err", storageRoot)
 	}
 
-	if err := egp.Wait(); err != nil {
-		return intershim.LogAndWrapf(err, "egp got err: %s", err)
+	jobID := job.ID
+	job, err = app.RunSummaryJob(ctx, jobID)
+	if err != nil {
+		return intershim.LogAndWrapf(err, "run summary job[%d] got err", jobID)
+	}
+	if job.Status == entity.JobFailed {
+		return errors.Errorf("summary job[%d] finished with %d failed items", job.ID, job.Failed)
 	}
 
 	return nil
 }
 
-// updateBlogYamlHeader 结合DB有替换记录、ForceUpdate是否被设置成true，决策是否需要刷新HeaderYaml头部
-func (app *BlogSummaryApp) updateBlogYamlHeader(ctx context.Context, mdfile string) error {
+// updateBlogYamlHeader 结合DB有替换记录、ForceUpdate是否被设置成true，决策是否需要刷新HeaderYaml头部，
+// 无需更新时返回跳过的原因，审核模式下AI摘要存为待审核版本(未写入文章)时pending为true
+func (app *BlogSummaryApp) updateBlogYamlHeader(ctx context.Context, mdfile string) (skip string, pending bool, err error) {
 	defer func() {
-		if err := recover(); err != nil {
-			log.Errorf("app panic recover for path[%v]: %v", mdfile, err)
+		if r := recover(); r != nil {
+			log.Errorf("app panic recover for path[%v]: %v", mdfile, r)
+			err = errors.Errorf("app panic recover for path[%v]: %v", mdfile, r)
 		}
 	}()
+	// 占位的值此时已写入DB，或处理失败未写入，均不再需要占位
+	defer app.releaseReserved(mdfile)
 
 	// 基于本地文件，初始每个md
 	md, err := entity.NewBlogMD(mdfile)
 	if err != nil {
-		return errors.Wrapf(err, "app new md[%s] got err", mdfile)
+		return "", false, errors.Wrapf(err, "app new md[%s] got err", mdfile)
+	}
+
+	// 文章自行关闭了AI摘要
+	if md.MDHeader.IsAISummaryDisabled() {
+		log.Infof("md[%v] ai_summary is disabled", md.Filepath)
+		return "ai_summary disabled", false, nil
+	}
+
+	// 正文变化时，已有的翻译标记为过期
+	if err = app.sqliteInfra.MarkTranslationsStale(ctx, mdfile, md.ContentHash()); err != nil {
+		return "", false, errors.Wrapf(err, "app mark md[%s] translations stale got err", mdfile)
 	}
 
 	// DB查看是否存在mdPath已Replace过了
 	record, err := app.sqliteInfra.SelBlogMDRecord(ctx, mdfile)
 	if err != nil { // db error
-		return err
-	} else if record != nil && md.NeedUpdate(record.WordCount) == false { // 有记录和无强刷，则直接返回
+		return "", false, err
+	}
+
+	// 文章短标记，一经分配不再变化
+	markChanged, err := app.assignShortMark(ctx, md, record)
+	if err != nil {
+		return "", false, errors.Wrapf(err, "app assign md[%s] short mark got err", mdfile)
+	}
+	if record != nil && !markChanged && md.NeedUpdate(record.WordCount) == false { // 有记录和无强刷，则直接返回
+		// 小改动不重新生成摘要，仅记录正文hash，用于判断摘要是否过期
+		if err = app.sqliteInfra.UpdateBlogMDContentHash(ctx, mdfile, md.ContentHash()); err != nil {
+			return "", false, errors.Wrapf(err, "app update md[%s] content hash got err", mdfile)
+		}
 		log.Infof("md[%v] needn't update", md.Filepath)
-		return nil
+		return "needn't update", false, nil
+	}
+	if record != nil {
+		md.SummaryHash = record.SummaryHash
 	}
 
 	// 通过AIService更新md内容
 	if record == nil || md.MDHeader.ForceUpdate == entity.UpdateALL {
 		if err := app.refreshBlogSummaryAndKeywords(ctx, md); err != nil {
-			return errors.Wrapf(err, "app refreash md[%s] blog summary and keywords got err", mdfile)
+			return "", false, errors.Wrapf(err, "app refreash md[%s] blog summary and keywords got err", mdfile)
 		}
+		pending = app.reviewMode
+	}
+
+	// 缺失时补齐SEO标题、slug、短链别名
+	if err := app.fillBlogSEO(ctx, md); err != nil {
+		return "", false, errors.Wrapf(err, "app fill md[%s] blog seo got err", mdfile)
 	}
 
+	// 按配置的权重公式重算权重
+	md.MDHeader.Weight = app.articleWeight(md, record)
+
 	// 重置强制更新字段，设置为默认空值
 	md.MDHeader.ForceUpdate = ""
-	if err = md.ReplaceWithNewYamlHeader(); err != nil {
-		return errors.Wrapf(err, "app replace write into blog md[%s] got err", mdfile)
+	if err = md.SafeReplaceYamlHeader(); err != nil {
+		return "", false, errors.Wrapf(err, "app replace write into blog md[%s] got err", mdfile)
 	}
 
 	// 新增或者更改 MD Record记录
 	if err = app.sqliteInfra.ReplaceBlogMDRecord(ctx, md); err != nil {
-		return errors.Wrapf(err, "app replace md[%s] db's record got err", mdfile)
+		return "", false, errors.Wrapf(err, "app replace md[%s] db's record got err", mdfile)
 	}
 
-	return nil
+	return "", pending, nil
 }
 
-// 刷新Blog的Summary和Keywords信息
+// 刷新Blog的Summary和Keywords信息，审核模式下AI结果仅作为待审核版本存入DB，不写入文章
 func (app *BlogSummaryApp) refreshBlogSummaryAndKeywords(ctx context.Context, md *entity.BlogMD) error {
 	// 内容太少了，不做AI生成
 	if md.IsContentWordsTooSmall() {
@@ -123,11 +178,225 @@ func (app *BlogSummaryApp) refreshBlogSummaryAndKeywords(ctx context.Context, md
 	if err != nil {
 		return errors.Wrapf(err, "aiSrv summary blog content got err")
 	}
+	status := entity.SummaryApproved
+	if app.reviewMode {
+		status = entity.SummaryPending
+	}
+	if err = app.addSummaryHistory(ctx, md.Filepath, md.Lang, summary, status); err != nil {
+		return err
+	}
 
 	// 汇总、关键字、描述，将调整后的md更新回去
-	md.MDHeader.Summary = summary.Summary
-	md.MDHeader.Keywords = summary.Keywords
-	md.MDHeader.Description = summary.Description
+	if !app.reviewMode {
+		md.MDHeader.Summary = summary.Summary
+		md.MDHeader.Keywords = summary.Keywords
+		md.MDHeader.Description = summary.Description
+		md.SummaryHash = md.ContentHash()
+	}
+
+	// 多语言摘要(按提示词配置的目标语言)
+	langSummaries, err := app.aiSrv.SummaryBlogMDLangs(ctx, md)
+	if err != nil {
+		return errors.Wrapf(err, "aiSrv summary blog content in langs got err")
+	}
+	if langSummaries == nil {
+		return nil
+	}
+	for lang, langSummary := range langSummaries.Summaries {
+		if err = app.addSummaryHistory(ctx, md.Filepath, lang, langSummary, status); err != nil {
+			return err
+		}
+	}
+	if !app.reviewMode {
+		if err = md.ApplyLangSummaries(langSummaries); err != nil {
+			return errors.Wrapf(err, "apply lang summaries for md[%s] got err", md.Filepath)
+		}
+	}
+
+	return nil
+}
+
+// addSummaryHistory 记录AI生成的摘要历史版本
+func (app *BlogSummaryApp) addSummaryHistory(ctx context.Context, path, lang string, summary *entity.ArticleSummary, status entity.SummaryStatus) error {
+	err := app.sqliteInfra.AddSummaryHistory(ctx, &entity.BlogSummaryHistory{
+		Path:        path,
+		Lang:        lang,
+		Keywords:    summary.Keywords,
+		Summary:     summary.Summary,
+		Description: summary.Description,
+		Status:      status,
+	})
+	if err != nil {
+		return errors.Wrapf(err, "app add md[%s] summary history got err", path)
+	}
+	return nil
+}
+
+// assignShortMark 分配文章短标记，DB中已分配的短标记保持不变(front matter被清空或修改时恢复)，
+// 否则基于标题生成并做全站冲突检测，返回front matter中的短标记是否被改动
+func (app *BlogSummaryApp) assignShortMark(ctx context.Context, md *entity.BlogMD, record *entity.BlogArticle) (changed bool, err error) {
+	header := md.MDHeader
+	if record != nil && record.ShortMark != "" {
+		if header.ShortMark != record.ShortMark {
+			log.Warnf("md[%s] short mark[%s] is immutable, restore from db", md.Filepath, record.ShortMark)
+			header.ShortMark = record.ShortMark
+			return true, nil
+		}
+		return false, nil
+	}
+
+	app.reserveMu.Lock()
+	defer app.reserveMu.Unlock()
+
+	// front matter中已有短标记(例如手工填写)，未冲突时沿用
+	if header.ShortMark != "" {
+		taken, err := app.isValueTaken(ctx, "mark", header.ShortMark, md.Filepath, app.sqliteInfra.IsShortMarkTaken)
+		if err != nil {
+			return false, err
+		}
+		if !taken {
+			app.reserveValue("mark", header.ShortMark, md.Filepath)
+			return false, nil
+		}
+		log.Warnf("md[%s] short mark[%s] is taken by other article, regenerate", md.Filepath, header.ShortMark)
+	}
+
+	seed := header.Title
+	if seed == "" {
+		seed = md.Filepath
+	}
+	cfg := config.GetShortMarkConfig()
+	generator := entity.NewShortMarkGenerator(cfg.Length, cfg.Alphabet)
+	for attempt := 0; attempt < entity.ShortMarkMaxAttempts; attempt++ {
+		mark := generator.Generate(seed, attempt)
+		taken, err := app.isValueTaken(ctx, "mark", mark, md.Filepath, app.sqliteInfra.IsShortMarkTaken)
+		if err != nil {
+			return false, err
+		}
+		if !taken {
+			header.ShortMark = app.reserveValue("mark", mark, md.Filepath)
+			return true, nil
+		}
+	}
+
+	return false, errors.Errorf("cannot generate unique short mark after %d attempts", entity.ShortMarkMaxAttempts)
+}
+
+// ResolveShortMark 通过短标记解析文章的permalink
+func (app *BlogSummaryApp) ResolveShortMark(ctx context.Context, shortMark string) (permalink string, err error) {
+	record, err := app.sqliteInfra.SelBlogMDRecordByShortMark(ctx, shortMark)
+	if err != nil {
+		return "", errors.Wrapf(err, "app sel short mark[%s] record got err", shortMark)
+	}
+	if record == nil {
+		return "", nil
+	}
+
+	site := config.GetSiteConfig()
+	return strings.TrimSuffix(site.BaseURL, "/") + entity.Permalink(site.Permalink, site.ContentDir, record), nil
+}
+
+// fillBlogSEO 缺失时通过AI补齐SEO标题、slug，并基于ShortMark生成短链别名，写入前做全站冲突检测
+func (app *BlogSummaryApp) fillBlogSEO(ctx context.Context, md *entity.BlogMD) error {
+	header := md.MDHeader
+
+	// AI生成SEO建议(在锁外请求)
+	var seo *entity.ArticleSEO
+	if header.NeedSEO() {
+		var err error
+		if seo, err = app.aiSrv.SuggestBlogSEO(ctx, md); err != nil {
+			return errors.Wrap(err, "aiSrv suggest blog seo got err")
+		}
+	}
+
+	app.reserveMu.Lock()
+	defer app.reserveMu.Unlock()
+
+	// SEO标题冲突时追加数字后缀，避免标题一直为空而反复请求AI
+	if seo != nil && header.SEOTitle == "" {
+		for i := 1; i < 10 && header.SEOTitle == ""; i++ {
+			title := seo.Title
+			if i > 1 {
+				title = fmt.Sprintf("%s (%d)", seo.Title, i)
+			}
+			taken, err := app.isValueTaken(ctx, "title", title, md.Filepath, app.sqliteInfra.IsTitleTaken)
+			if err != nil {
+				return err
+			}
+			if !taken {
+				header.SEOTitle = app.reserveValue("title", title, md.Filepath)
+			}
+		}
+		if header.SEOTitle == "" {
+			log.Warnf("md[%s] seo title[%s] is taken by other articles, skip", md.Filepath, seo.Title)
+		}
+	}
+
+	// slug冲突时追加数字后缀
+	if seo != nil && header.Slug == "" {
+		for i := 1; i < 10 && header.Slug == ""; i++ {
+			slug := seo.Slug
+			if i > 1 {
+				slug = fmt.Sprintf("%s-%d", seo.Slug, i)
+			}
+			taken, err := app.isValueTaken(ctx, "slug", slug, md.Filepath, app.sqliteInfra.IsSlugTaken)
+			if err != nil {
+				return err
+			}
+			if !taken {
+				header.Slug = app.reserveValue("slug", slug, md.Filepath)
+			}
+		}
+	}
+
+	// 短链别名，已有短链时保持不变，前缀冲突时加长截取的位数
+	for _, alias := range header.Aliases {
+		if strings.HasPrefix(alias, entity.ShortAliasPrefix) {
+			return nil
+		}
+	}
+	for n := entity.ShortAliasMarkLength; n <= utf8.RuneCountInString(header.ShortMark); n += 2 {
+		alias := entity.ShortAlias(header.ShortMark, n)
+		taken, err := app.isValueTaken(ctx, "alias", alias, md.Filepath, app.sqliteInfra.IsAliasTaken)
+		if err != nil {
+			return err
+		}
+		if !taken {
+			header.AddAlias(app.reserveValue("alias", alias, md.Filepath))
+			break
+		}
+	}
 
 	return nil
 }
+
+// isValueTaken 检测值是否已被本批次其他文章占用或在DB中被其他文章使用
+func (app *BlogSummaryApp) isValueTaken(ctx context.Context, kind, value, path string,
+	dbTaken func(ctx context.Context, value, excludePath string) (bool, error)) (bool, error) {
+	if owner, ok := app.reserved[kind+":"+value]; ok && owner != path {
+		return true, nil
+	}
+
+	taken, err := dbTaken(ctx, value, path)
+	if err != nil {
+		return false, errors.Wrapf(err, "check %s[%s] collision got err", kind, value)
+	}
+	return taken, nil
+}
+
+// reserveValue 在本批次内占用该值
+func (app *BlogSummaryApp) reserveValue(kind, value, path string) string {
+	app.reserved[kind+":"+value] = path
+	return value
+}
+
+// releaseReserved 释放文章占用的所有值，占位数不超过并发处理中的文章数，直接遍历
+func (app *BlogSummaryApp) releaseReserved(path string) {
+	app.reserveMu.Lock()
+	defer app.reserveMu.Unlock()
+	for key, owner := range app.reserved {
+		if owner == path {
+			delete(app.reserved, key)
+		}
+	}
+}
@@ -167,14 +167,52 @@ func (m *mockAISrv) SummaryBlogMD(ctx context.Context, md *entity.BlogMD) (summa
 	return args[0].(*entity.ArticleSummary), args.Error(1)
 }
 
+func (m *mockAISrv) SummaryBlogMDLangs(ctx context.Context, md *entity.BlogMD) (summaries *entity.LangSummaries, err error) {
+	return nil, nil
+}
+
+func (m *mockAISrv) SuggestBlogSEO(ctx context.Context, md *entity.BlogMD) (seo *entity.ArticleSEO, err error) {
+	args := m.Called(ctx, md)
+	return args[0].(*entity.ArticleSEO), args.Error(1)
+}
+
+func (m *mockAISrv) SummarySection(ctx context.Context, index *entity.BlogMD, children []*entity.SectionChild) (summary *entity.ArticleSummary, err error) {
+	args := m.Called(ctx, index, children)
+	return args[0].(*entity.ArticleSummary), args.Error(1)
+}
+
+func (m *mockAISrv) SuggestLinkReplacements(ctx context.Context, md *entity.BlogMD, issues []*entity.LinkIssue,
+	candidates []*entity.LinkCandidate) (map[string]string, error) {
+	args := m.Called(ctx, md, issues, candidates)
+	return args[0].(map[string]string), args.Error(1)
+}
+
+func (m *mockAISrv) GenerateAltText(ctx context.Context, md *entity.BlogMD, img *entity.AltTextImage, imageURL string) (string, error) {
+	args := m.Called(ctx, md, img, imageURL)
+	return args.String(0), args.Error(1)
+}
+
+func (m *mockAISrv) EmbeddingModel() string {
+	args := m.Called()
+	return args.String(0)
+}
+
+func (m *mockAISrv) EmbedTexts(ctx context.Context, texts []string) ([][]float32, error) {
+	args := m.Called(ctx, texts)
+	if fn, ok := args[0].(func(context.Context, []string) [][]float32); ok {
+		return fn(ctx, texts), args.Error(1)
+	}
+	return args[0].([][]float32), args.Error(1)
+}
+
 // mock 出一个sqliteInfra
 type mockInfra struct {
 	mock.Mock
 }
 
 func (m *mockInfra) SelBlogMDRecord(ctx context.Context, path string) (*entity.BlogArticle, error) {
-	// TODO implement me
-	panic("implement me")
+	args := m.Called(ctx, path)
+	return args[0].(*entity.BlogArticle), args.Error(1)
 }
 
 func (m *mockInfra) AddBlogMDRecord(ctx context.Context, md *entity.BlogMD) error {
@@ -187,6 +225,71 @@ func (m *mockInfra) UpdateBlogMDRecord(ctx context.Context, md *entity.BlogMD) e
 	panic("implement me")
 }
 
+func (m *mockInfra) ReplaceBlogMDRecord(ctx context.Context, md *entity.BlogMD) error {
+	args := m.Called(ctx, md)
+	return args.Error(0)
+}
+
+func (m *mockInfra) SelTranslationRecord(ctx context.Context, sourcePath, lang string) (*entity.BlogTranslation, error) {
+	// TODO implement me
+	panic("implement me")
+}
+
+func (m *mockInfra) ReplaceTranslationRecord(ctx context.Context, translation *entity.BlogTranslation) error {
+	// TODO implement me
+	panic("implement me")
+}
+
+func (m *mockInfra) MarkTranslationsStale(ctx context.Context, sourcePath, sourceHash string) error {
+	args := m.Called(ctx, sourcePath, sourceHash)
+	return args.Error(0)
+}
+
+func (m *mockInfra) SelBlogMDRecordByID(ctx context.Context, id uint) (*entity.BlogArticle, error) {
+	// TODO implement me
+	panic("implement me")
+}
+
+func (m *mockInfra) SelBlogMDRecords(ctx context.Context, query *entity.ArticleQuery) (records []*entity.BlogArticle, total int64, err error) {
+	// TODO implement me
+	panic("implement me")
+}
+
+func (m *mockInfra) AddSummaryHistory(ctx context.Context, history *entity.BlogSummaryHistory) error {
+	args := m.Called(ctx, history)
+	return args.Error(0)
+}
+
+func (m *mockInfra) SelSummaryHistories(ctx context.Context, path string) ([]*entity.BlogSummaryHistory, error) {
+	// TODO implement me
+	panic("implement me")
+}
+
+func (m *mockInfra) SelBlogMDRecordByShortMark(ctx context.Context, shortMark string) (*entity.BlogArticle, error) {
+	args := m.Called(ctx, shortMark)
+	return args[0].(*entity.BlogArticle), args.Error(1)
+}
+
+func (m *mockInfra) IsShortMarkTaken(ctx context.Context, shortMark, excludePath string) (bool, error) {
+	args := m.Called(ctx, shortMark, excludePath)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *mockInfra) IsSlugTaken(ctx context.Context, slug, excludePath string) (bool, error) {
+	args := m.Called(ctx, slug, excludePath)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *mockInfra) IsTitleTaken(ctx context.Context, title, excludePath string) (bool, error) {
+	args := m.Called(ctx, title, excludePath)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *mockInfra) IsAliasTaken(ctx context.Context, alias, excludePath string) (bool, error) {
+	args := m.Called(ctx, alias, excludePath)
+	return args.Bool(0), args.Error(1)
+}
+
 func (m *mockInfra) InitBlogSummaryDB(ctx context.Context) error {
 	// TODO implement me
 	panic("implement me")
@@ -202,6 +305,136 @@ func (m *mockInfra) CleanAllBlogSummaryDB(ctx context.Context) error {
 	panic("implement me")
 }
 
+func (m *mockInfra) UpdateBlogMDContentHash(ctx context.Context, path, contentHash string) error {
+	args := m.Called(ctx, path, contentHash)
+	return args.Error(0)
+}
+
+func (m *mockInfra) UpdateBlogMDWeight(ctx context.Context, path string, weight, inboundLinks int) error {
+	// TODO implement me
+	panic("implement me")
+}
+
+func (m *mockInfra) SelArticleEmbedding(ctx context.Context, path string) (*entity.ArticleEmbedding, error) {
+	// TODO implement me
+	panic("implement me")
+}
+
+func (m *mockInfra) ReplaceArticleEmbedding(ctx context.Context, embedding *entity.ArticleEmbedding) error {
+	// TODO implement me
+	panic("implement me")
+}
+
+func (m *mockInfra) SelImageAltText(ctx context.Context, path, target string) (*entity.ImageAltText, error) {
+	// TODO implement me
+	panic("implement me")
+}
+
+func (m *mockInfra) ReplaceImageAltText(ctx context.Context, alt *entity.ImageAltText) error {
+	// TODO implement me
+	panic("implement me")
+}
+
+func (m *mockInfra) SelSummaryHistory(ctx context.Context, id uint) (*entity.BlogSummaryHistory, error) {
+	// TODO implement me
+	panic("implement me")
+}
+
+func (m *mockInfra) SelSummaryHistoriesByStatus(ctx context.Context, status entity.SummaryStatus) ([]*entity.BlogSummaryHistory, error) {
+	// TODO implement me
+	panic("implement me")
+}
+
+func (m *mockInfra) UpdateSummaryHistory(ctx context.Context, history *entity.BlogSummaryHistory) error {
+	// TODO implement me
+	panic("implement me")
+}
+
+func (m *mockInfra) AddJob(ctx context.Context, job *entity.SummaryJob, paths []string) error {
+	// TODO implement me
+	panic("implement me")
+}
+
+func (m *mockInfra) SelJob(ctx context.Context, id uint) (*entity.SummaryJob, error) {
+	// TODO implement me
+	panic("implement me")
+}
+
+func (m *mockInfra) SelJobs(ctx context.Context, limit int) ([]*entity.SummaryJob, error) {
+	// TODO implement me
+	panic("implement me")
+}
+
+func (m *mockInfra) SelUnfinishedJobByPath(ctx context.Context, path string) (*entity.SummaryJob, error) {
+	// TODO implement me
+	panic("implement me")
+}
+
+func (m *mockInfra) SelJobByRef(ctx context.Context, ref string) (*entity.SummaryJob, error) {
+	// TODO implement me
+	panic("implement me")
+}
+
+func (m *mockInfra) SelLastSucceededJob(ctx context.Context, path string) (*entity.SummaryJob, error) {
+	// TODO implement me
+	panic("implement me")
+}
+
+func (m *mockInfra) UpdateJobCommit(ctx context.Context, jobID uint, commit string) error {
+	// TODO implement me
+	panic("implement me")
+}
+
+func (m *mockInfra) SelAllBlogMDRecords(ctx context.Context) ([]*entity.BlogArticle, error) {
+	// TODO implement me
+	panic("implement me")
+}
+
+func (m *mockInfra) SelBlogMDRecordsByDir(ctx context.Context, dir string) ([]*entity.BlogArticle, error) {
+	// TODO implement me
+	panic("implement me")
+}
+
+func (m *mockInfra) SelBlogSection(ctx context.Context, path string) (*entity.BlogSection, error) {
+	// TODO implement me
+	panic("implement me")
+}
+
+func (m *mockInfra) SelBlogSectionsByDir(ctx context.Context, dir string) ([]*entity.BlogSection, error) {
+	// TODO implement me
+	panic("implement me")
+}
+
+func (m *mockInfra) ReplaceBlogSection(ctx context.Context, section *entity.BlogSection) error {
+	// TODO implement me
+	panic("implement me")
+}
+
+func (m *mockInfra) SelJobItems(ctx context.Context, jobID uint, statuses ...entity.JobItemStatus) ([]*entity.SummaryJobItem, error) {
+	// TODO implement me
+	panic("implement me")
+}
+
+func (m *mockInfra) ClaimJobItems(ctx context.Context, jobID uint, limit int) ([]*entity.SummaryJobItem, error) {
+	// TODO implement me
+	panic("implement me")
+}
+
+func (m *mockInfra) UpdateJobItem(ctx context.Context, item *entity.SummaryJobItem) error {
+	// TODO implement me
+	panic("implement me")
+}
+
+func (m *mockInfra) RefreshJobStats(ctx context.Context, jobID uint) (*entity.SummaryJob, bool, error) {
+	// TODO implement me
+	panic("implement me")
+}
+
+func (m *mockInfra) ResetRunningJobItems(ctx context.Context, jobID uint) (int64, error) {
+	// TODO implement me
+	panic("implement me")
+}
+
 func TestBlogSummaryApp_ReplaceKeywordsAndSummary(t *testing.T) {
 	// 创建并打开一个临时文件
 	tempFile := filepath.Join(os.TempDir(), "01.md")
@@ -249,6 +482,13 @@ iPhone 是与 Android 并列的世界上最大的两个智能手机平台之一
 	mockSqliteInfra.On("AddBlogMDRecord", ctx, mock.Anything, mock.Anything).Return(
 		nil,
 	)
+	mockSqliteInfra.On("MarkTranslationsStale", ctx, mock.Anything, mock.Anything).Return(nil)
+	mockSqliteInfra.On("IsShortMarkTaken", ctx, mock.Anything, mock.Anything).Return(false, nil)
+	mockSqliteInfra.On("IsTitleTaken", ctx, mock.Anything, mock.Anything).Return(false, nil)
+	mockSqliteInfra.On("IsSlugTaken", ctx, mock.Anything, mock.Anything).Return(false, nil)
+	mockSqliteInfra.On("IsAliasTaken", ctx, mock.Anything, mock.Anything).Return(false, nil)
+	mockSqliteInfra.On("ReplaceBlogMDRecord", ctx, mock.Anything).Return(nil)
+	mockAISrv.On("SuggestBlogSEO", ctx, mock.Anything).Return(&entity.ArticleSEO{Title: "苹果Wiki", Slug: "apple-wiki"}, nil)
 
 	type args struct {
 		ctx          context.Context
@@ -267,7 +507,7 @@ iPhone 是与 Android 并列的世界上最大的两个智能手机平台之一
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			app := NewBlogSummaryApp(mockAISrv, mockSqliteInfra)
-			if err := app.updateBlogYamlHeader(tt.args.ctx, tt.args.blogFilePath); (err != nil) != tt.wantErr {
+			if _, _, err := app.updateBlogYamlHeader(tt.args.ctx, tt.args.blogFilePath); (err != nil) != tt.wantErr {
 				t.Errorf("updateBlogYamlHeader() error = %v, wantErr %v", err, tt.wantErr)
 			}
 
@@ -280,3 +520,87 @@ iPhone 是与 Android 并列的世界上最大的两个智能手机平台之一
 		})
 	}
 }
+
+func TestBlogSummaryApp_fillBlogSEO(t *testing.T) {
+	ctx := context.Background()
+	mockAISrv := new(mockAISrv)
+	mockAISrv.On("SuggestBlogSEO", ctx, mock.Anything).Return(&entity.ArticleSEO{
+		Title: "Go并发编程入门",
+		Slug:  "go-concurrency",
+	}, nil)
+
+	// go-concurrency已被其他文章使用，短链前8位冲突
+	mockSqliteInfra := new(mockInfra)
+	mockSqliteInfra.On("IsTitleTaken", ctx, mock.Anything, mock.Anything).Return(false, nil)
+	mockSqliteInfra.On("IsSlugTaken", ctx, "go-concurrency", mock.Anything).Return(true, nil)
+	mockSqliteInfra.On("IsSlugTaken", ctx, mock.Anything, mock.Anything).Return(false, nil)
+	mockSqliteInfra.On("IsAliasTaken", ctx, "/s/0123abcd/", mock.Anything).Return(true, nil)
+	mockSqliteInfra.On("IsAliasTaken", ctx, mock.Anything, mock.Anything).Return(false, nil)
+
+	app := NewBlogSummaryApp(mockAISrv, mockSqliteInfra)
+	md := &entity.BlogMD{
+		Filepath: "/blog/a.md",
+		MDHeader: &entity.YamlHeader{Title: "Go并发", ShortMark: "0123abcdef98"},
+	}
+	assert.NoError(t, app.fillBlogSEO(ctx, md))
+	assert.Equal(t, "Go并发编程入门", md.MDHeader.SEOTitle)
+	assert.Equal(t, "go-concurrency-2", md.MDHeader.Slug)
+	assert.Equal(t, []string{"/s/0123abcdef/"}, md.MDHeader.Aliases)
+
+	// 同一批次的另一篇文章，不能再使用已占位的slug
+	other := &entity.BlogMD{
+		Filepath: "/blog/b.md",
+		MDHeader: &entity.YamlHeader{Title: "Go并发2", SEOTitle: "Go并发2", ShortMark: "ffff0000"},
+	}
+	assert.NoError(t, app.fillBlogSEO(ctx, other))
+	assert.Equal(t, "go-concurrency-3", other.MDHeader.Slug)
+	assert.Equal(t, []string{"/s/ffff0000/"}, other.MDHeader.Aliases)
+
+	// SEO标题已被占位时追加数字后缀
+	third := &entity.BlogMD{
+		Filepath: "/blog/c.md",
+		MDHeader: &entity.YamlHeader{Title: "Go并发3", ShortMark: "eeee0000"},
+	}
+	assert.NoError(t, app.fillBlogSEO(ctx, third))
+	assert.Equal(t, "Go并发编程入门 (2)", third.MDHeader.SEOTitle)
+	assert.Equal(t, "go-concurrency-4", third.MDHeader.Slug)
+	app.releaseReserved("/blog/c.md")
+
+	// 文章处理结束后释放占位，之后以DB为准
+	app.releaseReserved("/blog/a.md")
+	assert.Len(t, app.reserved, 2)
+	taken, err := app.isValueTaken(ctx, "slug", "go-concurrency-2", "/blog/b.md", app.sqliteInfra.IsSlugTaken)
+	assert.NoError(t, err)
+	assert.False(t, taken)
+	app.releaseReserved("/blog/b.md")
+	assert.Empty(t, app.reserved)
+}
+
+func TestBlogSummaryApp_assignShortMark(t *testing.T) {
+	ctx := context.Background()
+	generator := entity.NewShortMarkGenerator(0, "")
+	firstMark := generator.Generate("Go并发", 0)
+
+	// 首个候选冲突，使用下一次尝试生成的短标记
+	mockSqliteInfra := new(mockInfra)
+	mockSqliteInfra.On("IsShortMarkTaken", ctx, firstMark, mock.Anything).Return(true, nil)
+	mockSqliteInfra.On("IsShortMarkTaken", ctx, mock.Anything, mock.Anything).Return(false, nil)
+	app := NewBlogSummaryApp(new(mockAISrv), mockSqliteInfra)
+
+	md := &entity.BlogMD{Filepath: "/blog/a.md", MDHeader: &entity.YamlHeader{Title: "Go并发"}}
+	changed, err := app.assignShortMark(ctx, md, nil)
+	assert.NoError(t, err)
+	assert.True(t, changed)
+	assert.Equal(t, generator.Generate("Go并发", 1), md.MDHeader.ShortMark)
+
+	// DB中已分配的短标记不可变，标题修改、front matter被清空时恢复
+	md = &entity.BlogMD{Filepath: "/blog/a.md", MDHeader: &entity.YamlHeader{Title: "Go并发(修订)"}}
+	changed, err = app.assignShortMark(ctx, md, &entity.BlogArticle{ShortMark: "Ab3dE6gH"})
+	assert.NoError(t, err)
+	assert.True(t, changed)
+	assert.Equal(t, "Ab3dE6gH", md.MDHeader.ShortMark)
+
+	changed, err = app.assignShortMark(ctx, md, &entity.BlogArticle{ShortMark: "Ab3dE6gH"})
+	assert.NoError(t, err)
+	assert.False(t, changed)
+}
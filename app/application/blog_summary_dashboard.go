@@ -0,0 +1,207 @@
+package application
+
+import (
+	"context"
+	"strings"
+
+	"github.com/lupguo/copilot_develop/app/domain/entity"
+	"github.com/pkg/errors"
+)
+
+var (
+	// ErrArticleNotFound 文章不存在
+	ErrArticleNotFound = errors.New("article not found")
+
+	// ErrSummaryHistoryNotFound 摘要历史版本不存在(或不属于该文章)
+	ErrSummaryHistoryNotFound = errors.New("summary history not found")
+)
+
+// SetReviewMode 设置审核模式，开启后AI生成的摘要存为待审核，审核通过后才写入文章
+func (app *BlogSummaryApp) SetReviewMode(reviewMode bool) {
+	app.reviewMode = reviewMode
+}
+
+// ListPendingSummaries 待审核的摘要及所属文章，按生成时间正序
+func (app *BlogSummaryApp) ListPendingSummaries(ctx context.Context) ([]*entity.SummaryReview, error) {
+	histories, err := app.sqliteInfra.SelSummaryHistoriesByStatus(ctx, entity.SummaryPending)
+	if err != nil {
+		return nil, errors.Wrap(err, "app sel pending summaries got err")
+	}
+
+	reviews := make([]*entity.SummaryReview, 0, len(histories))
+	for _, history := range histories {
+		article, err := app.sqliteInfra.SelBlogMDRecord(ctx, history.Path)
+		if err != nil {
+			return nil, errors.Wrapf(err, "app sel article[%s] got err", history.Path)
+		}
+		if article == nil { // 文章已被删除
+			continue
+		}
+		reviews = append(reviews, &entity.SummaryReview{History: history, Article: article})
+	}
+	return reviews, nil
+}
+
+// ApproveSummary 审核通过摘要的某个历史版本，通过安全的front matter写入回写到文章
+func (app *BlogSummaryApp) ApproveSummary(ctx context.Context, articleID, historyID uint) (*entity.BlogArticle, error) {
+	article, history, err := app.selArticleHistory(ctx, articleID, historyID)
+	if err != nil {
+		return nil, err
+	}
+
+	article, err = app.writeArticleSummary(ctx, article, history.Lang, history.ArticleSummary())
+	if err != nil {
+		return nil, err
+	}
+	if err = app.setSummaryStatus(ctx, history, entity.SummaryApproved); err != nil {
+		return nil, err
+	}
+	return article, nil
+}
+
+// EditSummary 人工编辑摘要后回写到文章：historyID不为0时修改该(待审核)版本并审核通过，否则记录为新的历史版本
+func (app *BlogSummaryApp) EditSummary(ctx context.Context, articleID, historyID uint, lang string, summary *entity.ArticleSummary) (*entity.BlogArticle, error) {
+	summary.Keywords = strings.TrimSpace(summary.Keywords)
+	summary.Summary = strings.TrimSpace(summary.Summary)
+	summary.Description = strings.TrimSpace(summary.Description)
+	if summary.Summary == "" || summary.Keywords == "" || summary.Description == "" {
+		return nil, errors.New("summary, keywords and description are required")
+	}
+
+	if historyID > 0 {
+		article, history, err := app.selArticleHistory(ctx, articleID, historyID)
+		if err != nil {
+			return nil, err
+		}
+		history.Keywords, history.Summary, history.Description = summary.Keywords, summary.Summary, summary.Description
+		if article, err = app.writeArticleSummary(ctx, article, history.Lang, summary); err != nil {
+			return nil, err
+		}
+		if err = app.setSummaryStatus(ctx, history, entity.SummaryApproved); err != nil {
+			return nil, err
+		}
+		return article, nil
+	}
+
+	article, err := app.selArticle(ctx, articleID)
+	if err != nil {
+		return nil, err
+	}
+	if lang == "" {
+		lang = article.Lang
+	}
+	if err = app.addSummaryHistory(ctx, article.Path, lang, summary, entity.SummaryApproved); err != nil {
+		return nil, err
+	}
+	return app.writeArticleSummary(ctx, article, lang, summary)
+}
+
+// RejectSummary 审核拒绝摘要版本，不写入文章
+func (app *BlogSummaryApp) RejectSummary(ctx context.Context, articleID, historyID uint) error {
+	_, history, err := app.selArticleHistory(ctx, articleID, historyID)
+	if err != nil {
+		return err
+	}
+	return app.setSummaryStatus(ctx, history, entity.SummaryRejected)
+}
+
+// RegenerateSummary 重新请求AI生成摘要，仅记录为待审核的历史版本，审核通过后才回写到文章
+func (app *BlogSummaryApp) RegenerateSummary(ctx context.Context, articleID uint) (*entity.BlogSummaryHistory, error) {
+	article, err := app.selArticle(ctx, articleID)
+	if err != nil {
+		return nil, err
+	}
+
+	md, err := entity.NewBlogMD(article.Path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "app new md[%s] got err", article.Path)
+	}
+	if md.IsContentWordsTooSmall() {
+		return nil, errors.Errorf("content is too small, needn't request OpenAI")
+	}
+	if limitSize := entity.OpenAIMaxTokenSize; md.IsMinContentTooLong(limitSize) {
+		return nil, errors.Errorf("min content is over max token size(%d), cannot request OpenAI", limitSize)
+	}
+
+	summary, err := app.aiSrv.SummaryBlogMD(ctx, md)
+	if err != nil {
+		return nil, errors.Wrapf(err, "aiSrv summary blog content got err")
+	}
+
+	history := &entity.BlogSummaryHistory{
+		Path:        md.Filepath,
+		Lang:        md.Lang,
+		Keywords:    summary.Keywords,
+		Summary:     summary.Summary,
+		Description: summary.Description,
+		Status:      entity.SummaryPending,
+	}
+	if err = app.sqliteInfra.AddSummaryHistory(ctx, history); err != nil {
+		return nil, errors.Wrapf(err, "app add md[%s] summary history got err", md.Filepath)
+	}
+	return history, nil
+}
+
+// selArticle 查询文章，不存在时返回ErrArticleNotFound
+func (app *BlogSummaryApp) selArticle(ctx context.Context, id uint) (*entity.BlogArticle, error) {
+	article, err := app.sqliteInfra.SelBlogMDRecordByID(ctx, id)
+	if err != nil {
+		return nil, errors.Wrapf(err, "app sel article[%d] got err", id)
+	}
+	if article == nil {
+		return nil, ErrArticleNotFound
+	}
+	return article, nil
+}
+
+// selArticleHistory 查询文章及其摘要历史版本
+func (app *BlogSummaryApp) selArticleHistory(ctx context.Context, articleID, historyID uint) (*entity.BlogArticle, *entity.BlogSummaryHistory, error) {
+	article, err := app.selArticle(ctx, articleID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	history, err := app.sqliteInfra.SelSummaryHistory(ctx, historyID)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "app sel summary history[%d] got err", historyID)
+	}
+	if history == nil || history.Path != article.Path {
+		return nil, nil, ErrSummaryHistoryNotFound
+	}
+	return article, history, nil
+}
+
+// setSummaryStatus 更新摘要版本的审核状态
+func (app *BlogSummaryApp) setSummaryStatus(ctx context.Context, history *entity.BlogSummaryHistory, status entity.SummaryStatus) error {
+	history.Status = status
+	if err := app.sqliteInfra.UpdateSummaryHistory(ctx, history); err != nil {
+		return errors.Wrapf(err, "app set summary history[%d] status[%s] got err", history.ID, status)
+	}
+	return nil
+}
+
+// writeArticleSummary 将摘要写回文章front matter(源语言写入summary等字段，其他语言写入summary_<lang>等字段)，并同步DB记录
+func (app *BlogSummaryApp) writeArticleSummary(ctx context.Context, article *entity.BlogArticle, lang string, summary *entity.ArticleSummary) (*entity.BlogArticle, error) {
+	md, err := entity.NewBlogMD(article.Path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "app new md[%s] got err", article.Path)
+	}
+
+	md.SummaryHash = article.SummaryHash
+	if lang == "" || lang == md.Lang {
+		md.MDHeader.Keywords = summary.Keywords
+		md.MDHeader.Summary = summary.Summary
+		md.MDHeader.Description = summary.Description
+		md.SummaryHash = md.ContentHash()
+	} else {
+		md.MDHeader.SetLangSummary(lang, summary)
+	}
+
+	if err = md.SafeReplaceYamlHeader(); err != nil {
+		return nil, errors.Wrapf(err, "app write md[%s] summary got err", md.Filepath)
+	}
+	if err = app.sqliteInfra.ReplaceBlogMDRecord(ctx, md); err != nil {
+		return nil, errors.Wrapf(err, "app replace md[%s] db's record got err", md.Filepath)
+	}
+	return app.selArticle(ctx, article.ID)
+}
@@ -0,0 +1,140 @@
+package application
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/lupguo/copilot_develop/app/domain/entity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestBlogSummaryApp_ReviewSummary(t *testing.T) {
+	ctx := context.Background()
+	root := t.TempDir()
+	post := filepath.Join(root, "go.md")
+	body := testPostBody
+	assert.NoError(t, os.WriteFile(post, []byte("---\ntitle: Go\n---\n"+body), 0644))
+
+	app, _, aiSrv := newTestApp(t)
+	aiSrv.On("SummaryBlogMD", mock.Anything, mock.Anything).Return(&entity.ArticleSummary{
+		Keywords: "go", Summary: "v1", Description: "d1",
+	}, nil).Once()
+	aiSrv.On("SummaryBlogMD", mock.Anything, mock.Anything).Return(&entity.ArticleSummary{
+		Keywords: "go,channel", Summary: "v2", Description: "d2",
+	}, nil)
+	aiSrv.On("SuggestBlogSEO", mock.Anything, mock.Anything).Return(&entity.ArticleSEO{Title: "Go", Slug: "go"}, nil)
+	assert.NoError(t, app.UpdateBlogHeaderYaml(ctx, root))
+
+	articles, _, err := app.ListArticles(ctx, &entity.ArticleQuery{})
+	assert.NoError(t, err)
+	article := articles[0]
+	assert.Equal(t, "v1", article.Summary)
+	assert.False(t, article.IsSummaryStale())
+
+	// 重新生成只新增历史版本，不回写
+	history, err := app.RegenerateSummary(ctx, article.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "v2", history.Summary)
+	content, _ := os.ReadFile(post)
+	assert.Contains(t, string(content), "summary: v1")
+
+	// 审核通过后回写
+	article, err = app.ApproveSummary(ctx, article.ID, history.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "v2", article.Summary)
+	content, _ = os.ReadFile(post)
+	assert.Contains(t, string(content), "summary: v2")
+	assert.True(t, strings.HasSuffix(string(content), body))
+
+	// 人工编辑
+	article, err = app.EditSummary(ctx, article.ID, 0, "", &entity.ArticleSummary{Keywords: "go", Summary: " v3 ", Description: "d3"})
+	assert.NoError(t, err)
+	assert.Equal(t, "v3", article.Summary)
+	_, err = app.EditSummary(ctx, article.ID, 0, "", &entity.ArticleSummary{Summary: "v4"})
+	assert.Error(t, err)
+	_, histories, err := app.GetArticle(ctx, article.ID)
+	assert.NoError(t, err)
+	assert.Len(t, histories, 3)
+
+	_, err = app.ApproveSummary(ctx, 999, history.ID)
+	assert.ErrorIs(t, err, ErrArticleNotFound)
+	_, err = app.ApproveSummary(ctx, article.ID, 999)
+	assert.ErrorIs(t, err, ErrSummaryHistoryNotFound)
+
+	// 正文小改动不重新生成，摘要标记为过期
+	content, _ = os.ReadFile(post)
+	assert.NoError(t, os.WriteFile(post, append(content, " edited"...), 0644))
+	assert.NoError(t, app.UpdateBlogHeaderYaml(ctx, root))
+	stale, total, err := app.ListArticles(ctx, &entity.ArticleQuery{StaleSummary: true})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), total)
+	assert.True(t, stale[0].IsSummaryStale())
+
+	missing, _, err := app.ListArticles(ctx, &entity.ArticleQuery{MissingSummary: true})
+	assert.NoError(t, err)
+	assert.Empty(t, missing)
+}
+
+func TestBlogSummaryApp_ReviewMode(t *testing.T) {
+	ctx := context.Background()
+	root := t.TempDir()
+	post := filepath.Join(root, "go.md")
+	body := testPostBody
+	assert.NoError(t, os.WriteFile(post, []byte("---\ntitle: Go\n---\n"+body), 0644))
+
+	app, _, aiSrv := newTestApp(t)
+	aiSrv.On("SummaryBlogMD", mock.Anything, mock.Anything).Return(&entity.ArticleSummary{
+		Keywords: "go", Summary: "v1", Description: "d1",
+	}, nil)
+	aiSrv.On("SuggestBlogSEO", mock.Anything, mock.Anything).Return(&entity.ArticleSEO{Title: "Go", Slug: "go"}, nil)
+	app.SetReviewMode(true)
+	assert.NoError(t, app.UpdateBlogHeaderYaml(ctx, root))
+
+	// 审核模式下不写入文章，摘要待审核，进度事件为pending
+	jobs, err := app.ListJobs(ctx, 1)
+	assert.NoError(t, err)
+	_, items, err := app.GetJob(ctx, jobs[0].ID)
+	assert.NoError(t, err)
+	assert.Equal(t, entity.JobEventPending, entity.JobItemEvent(items[0]).Type)
+	content, _ := os.ReadFile(post)
+	assert.NotContains(t, string(content), "summary: v1")
+	reviews, err := app.ListPendingSummaries(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, reviews, 1)
+	assert.Equal(t, "v1", reviews[0].History.Summary)
+	pending, total, err := app.ListArticles(ctx, &entity.ArticleQuery{PendingReview: true})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), total)
+
+	// 编辑待审核版本后审核通过并写入
+	article, err := app.EditSummary(ctx, pending[0].ID, reviews[0].History.ID, "",
+		&entity.ArticleSummary{Keywords: "go", Summary: "v1 edited", Description: "d1"})
+	assert.NoError(t, err)
+	assert.Equal(t, "v1 edited", article.Summary)
+	content, _ = os.ReadFile(post)
+	assert.Contains(t, string(content), "summary: v1 edited")
+	assert.True(t, strings.HasSuffix(string(content), body))
+
+	_, histories, err := app.GetArticle(ctx, article.ID)
+	assert.NoError(t, err)
+	assert.Len(t, histories, 1)
+	assert.Equal(t, entity.SummaryApproved, histories[0].Status)
+	reviews, err = app.ListPendingSummaries(ctx)
+	assert.NoError(t, err)
+	assert.Empty(t, reviews)
+
+	// 重新生成为待审核，拒绝后不写入
+	history, err := app.RegenerateSummary(ctx, article.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, entity.SummaryPending, history.Status)
+	assert.NoError(t, app.RejectSummary(ctx, article.ID, history.ID))
+	content, _ = os.ReadFile(post)
+	assert.Contains(t, string(content), "summary: v1 edited")
+	_, total, err = app.ListArticles(ctx, &entity.ArticleQuery{PendingReview: true})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), total)
+}
@@ -0,0 +1,133 @@
+package application
+
+import (
+	"context"
+	"sync"
+
+	"github.com/lupguo/copilot_develop/app/domain/entity"
+	"github.com/pkg/errors"
+)
+
+// jobEventBufferSize 每个订阅者的事件缓冲，消费过慢时丢弃中间的进度事件
+const jobEventBufferSize = 256
+
+// jobEventHub 按任务分发进度事件
+type jobEventHub struct {
+	mu   sync.Mutex
+	subs map[uint]map[chan *entity.JobEvent]struct{}
+}
+
+func newJobEventHub() *jobEventHub {
+	return &jobEventHub{subs: make(map[uint]map[chan *entity.JobEvent]struct{})}
+}
+
+// subscribe 订阅任务的进度事件，任务结束(summary事件)后channel被关闭
+func (h *jobEventHub) subscribe(jobID uint) (ch chan *entity.JobEvent, cancel func()) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	ch = make(chan *entity.JobEvent, jobEventBufferSize)
+	if h.subs[jobID] == nil {
+		h.subs[jobID] = make(map[chan *entity.JobEvent]struct{})
+	}
+	h.subs[jobID][ch] = struct{}{}
+
+	return ch, func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		if _, ok := h.subs[jobID][ch]; ok {
+			delete(h.subs[jobID], ch)
+			close(ch)
+		}
+	}
+}
+
+// publish 分发事件，summary事件分发后关闭该任务的所有订阅
+func (h *jobEventHub) publish(event *entity.JobEvent) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for ch := range h.subs[event.JobID] {
+		select {
+		case ch <- event:
+		default:
+		}
+		if event.Type == entity.JobEventSummary {
+			close(ch)
+		}
+	}
+	if event.Type == entity.JobEventSummary {
+		delete(h.subs, event.JobID)
+	}
+}
+
+// WatchJob 订阅任务进度：先回放已处理文件的状态，再推送实时事件，最后以summary事件结束并关闭channel；
+// ctx结束时提前关闭
+func (app *BlogSummaryApp) WatchJob(ctx context.Context, jobID uint) (<-chan *entity.JobEvent, error) {
+	// 先订阅再查询，避免回放与实时事件之间有遗漏
+	live, cancel := app.events.subscribe(jobID)
+	job, items, err := app.GetJob(ctx, jobID)
+	if err != nil || job == nil {
+		cancel()
+		if err == nil {
+			err = errors.Errorf("job[%d] not found", jobID)
+		}
+		return nil, err
+	}
+
+	out := make(chan *entity.JobEvent, jobEventBufferSize)
+	go func() {
+		defer close(out)
+		defer cancel()
+
+		send := func(event *entity.JobEvent) bool {
+			select {
+			case out <- event:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+
+		for _, item := range items {
+			if !send(entity.JobItemEvent(item)) {
+				return
+			}
+		}
+		if job.IsFinished() {
+			send(app.jobSummaryEvent(job))
+			return
+		}
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case event, ok := <-live:
+				if !ok { // 任务结束，summary事件可能因缓冲已满被丢弃，从DB补齐
+					if job, err := app.sqliteInfra.SelJob(ctx, jobID); err == nil && job != nil {
+						send(app.jobSummaryEvent(job))
+					}
+					return
+				}
+				if !send(event) || event.Type == entity.JobEventSummary {
+					return
+				}
+			}
+		}
+	}()
+
+	return out, nil
+}
+
+// publishJobEvent 分发任务进度事件
+func (app *BlogSummaryApp) publishJobEvent(event *entity.JobEvent) {
+	app.events.publish(event)
+}
+
+// jobSummaryEvent 任务结束的汇总事件
+func (app *BlogSummaryApp) jobSummaryEvent(job *entity.SummaryJob) *entity.JobEvent {
+	event := entity.NewJobEvent(entity.JobEventSummary)
+	event.JobID, event.Job = job.ID, job
+	return event
+}
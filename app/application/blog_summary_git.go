@@ -0,0 +1,217 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/lupguo/copilot_develop/app/domain/entity"
+	"github.com/lupguo/copilot_develop/app/domain/repos"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+var (
+	// ErrGitRepoNotConfigured 未配置本地blog git仓库
+	ErrGitRepoNotConfigured = errors.New("git repo is not configured")
+
+	// ErrNoSucceededJob 增量模式下没有记录了git提交的成功任务
+	ErrNoSucceededJob = errors.New("no succeeded summary job with git commit recorded")
+)
+
+// SetGitRepo 设置本地blog git仓库
+func (app *BlogSummaryApp) SetGitRepo(gitRepo repos.IReposGit) {
+	app.gitRepo = gitRepo
+}
+
+// SubmitPushJob 按git推送中新增、修改的文件提交摘要任务，仅处理blogRoot下的markdown文章；
+// pull为true时先拉取本地仓库，没有需要处理的文件时返回nil
+func (app *BlogSummaryApp) SubmitPushJob(ctx context.Context, blogRoot string, push *entity.GitPush, pull bool) (*entity.SummaryJob, error) {
+	if app.gitRepo == nil {
+		return nil, ErrGitRepoNotConfigured
+	}
+	if pull {
+		if err := app.gitRepo.Pull(ctx); err != nil {
+			return nil, errors.Wrapf(err, "app pull git repo[%s] got err", app.gitRepo.Root())
+		}
+	}
+
+	files := app.repoFilesInBlogRoot(blogRoot, push.ChangedFiles())
+	if len(files) == 0 {
+		return nil, nil
+	}
+	return app.SubmitFilesJob(ctx, blogRoot, push.After, files)
+}
+
+// SubmitSinceJob 增量模式：仅处理自since以来(含工作区未提交的改动)新增、修改的文章，since为空时以blogRoot上次成功任务记录的提交为起点；
+// 没有变更时也会新增一个空任务，记录当前HEAD作为下次增量的起点
+func (app *BlogSummaryApp) SubmitSinceJob(ctx context.Context, blogRoot, since string) (*entity.SummaryJob, error) {
+	if app.gitRepo == nil {
+		return nil, ErrGitRepoNotConfigured
+	}
+	blogRoot = filepath.Clean(blogRoot)
+	if since == "" {
+		last, err := app.sqliteInfra.SelLastSucceededJob(ctx, blogRoot)
+		if err != nil {
+			return nil, errors.Wrapf(err, "app sel last succeeded job for path[%s] got err", blogRoot)
+		}
+		if last == nil {
+			return nil, ErrNoSucceededJob
+		}
+		since = last.HeadCommit
+	}
+
+	head, err := app.gitRepo.Head(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "app get git HEAD got err")
+	}
+	changed, err := app.gitRepo.ChangedFiles(ctx, since)
+	if err != nil {
+		return nil, errors.Wrapf(err, "app get files changed since[%s] got err", since)
+	}
+	mdfiles := app.filterBlogMDFiles(blogRoot, app.repoFilesInBlogRoot(blogRoot, changed))
+	log.Infof("%d md files changed since[%s] in path[%s]", len(mdfiles), since, blogRoot)
+
+	return app.addSummaryJob(ctx, &entity.SummaryJob{Path: blogRoot, BlogRoot: blogRoot, HeadCommit: head}, mdfiles)
+}
+
+// SubmitFilesJob 按指定的文件提交摘要任务，ref为触发任务的git提交(同一提交只提交一次)；
+// 仅处理blogRoot下存在的markdown文章，没有需要处理的文件时返回nil
+func (app *BlogSummaryApp) SubmitFilesJob(ctx context.Context, blogRoot, ref string, files []string) (*entity.SummaryJob, error) {
+	if ref != "" {
+		job, err := app.sqliteInfra.SelJobByRef(ctx, ref)
+		if err != nil {
+			return nil, errors.Wrapf(err, "app sel job by ref[%s] got err", ref)
+		}
+		if job != nil {
+			log.Infof("summary job[%d] for ref[%s] already submitted", job.ID, ref)
+			app.wakeJobWorkers()
+			return job, nil
+		}
+	}
+
+	blogRoot = filepath.Clean(blogRoot)
+	mdfiles := app.filterBlogMDFiles(blogRoot, files)
+	if len(mdfiles) == 0 {
+		return nil, nil
+	}
+	return app.addSummaryJob(ctx, &entity.SummaryJob{Path: blogRoot, BlogRoot: blogRoot, Ref: ref, HeadCommit: ref}, mdfiles)
+}
+
+// CommitJobChanges 提交任务中已更新(front matter被重写)的文章及任务结束后汇总的栏目首页，返回新的提交，没有改动时返回空；
+// 提交后将任务记录的提交更新为新提交，下次增量时不再处理本次重写的文件
+func (app *BlogSummaryApp) CommitJobChanges(ctx context.Context, jobID uint) (string, error) {
+	if app.gitRepo == nil {
+		return "", ErrGitRepoNotConfigured
+	}
+	items, err := app.sqliteInfra.SelJobItems(ctx, jobID, entity.JobItemDone)
+	if err != nil {
+		return "", errors.Wrapf(err, "app sel job[%d] done items got err", jobID)
+	}
+	if len(items) == 0 {
+		return "", nil
+	}
+
+	files := make([]string, 0, len(items))
+	for _, item := range items {
+		files = append(files, item.Path)
+	}
+	sections, err := app.jobUpdatedSections(ctx, jobID)
+	if err != nil {
+		return "", err
+	}
+	files = append(files, sections...)
+	commit, err := app.gitRepo.Commit(ctx, jobCommitMessage(jobID, files), files)
+	if err != nil {
+		return "", errors.Wrapf(err, "app commit job[%d] changes got err", jobID)
+	}
+	if commit == "" {
+		return "", nil
+	}
+	if err = app.sqliteInfra.UpdateJobCommit(ctx, jobID, commit); err != nil {
+		return "", errors.Wrapf(err, "app update job[%d] commit got err", jobID)
+	}
+	log.Infof("commit job[%d] %d updated files: %s", jobID, len(files), commit)
+	return commit, nil
+}
+
+// jobUpdatedSections 任务创建后更新过摘要的栏目首页(_index.md)
+func (app *BlogSummaryApp) jobUpdatedSections(ctx context.Context, jobID uint) ([]string, error) {
+	job, err := app.sqliteInfra.SelJob(ctx, jobID)
+	if err != nil || job == nil || job.BlogRoot == "" {
+		return nil, errors.Wrapf(err, "app sel job[%d] got err", jobID)
+	}
+	sections, err := app.sqliteInfra.SelBlogSectionsByDir(ctx, job.BlogRoot)
+	if err != nil {
+		return nil, errors.Wrapf(err, "app sel sections in dir[%s] got err", job.BlogRoot)
+	}
+	var files []string
+	for _, section := range sections {
+		if section.UpdatedAt >= job.CreatedAt {
+			files = append(files, section.Path)
+		}
+	}
+	return files, nil
+}
+
+// headCommit 当前blog仓库的HEAD，未配置仓库或获取失败时返回空
+func (app *BlogSummaryApp) headCommit(ctx context.Context) string {
+	if app.gitRepo == nil {
+		return ""
+	}
+	head, err := app.gitRepo.Head(ctx)
+	if err != nil {
+		log.Warnf("app get git HEAD got err: %s", err)
+		return ""
+	}
+	return head
+}
+
+// repoFilesInBlogRoot 将相对于仓库根目录的文件转换为blogRoot下的路径，不在blogRoot下的文件被忽略；
+// 仓库根目录为真实路径，blogRoot可能包含软链，按真实路径计算相对位置后再拼回blogRoot，与DB中的文章路径保持一致
+func (app *BlogSummaryApp) repoFilesInBlogRoot(blogRoot string, repoFiles []string) []string {
+	realRoot, err := filepath.EvalSymlinks(blogRoot)
+	if err != nil {
+		realRoot = blogRoot
+	}
+
+	var files []string
+	for _, file := range repoFiles {
+		rel, err := filepath.Rel(realRoot, filepath.Join(app.gitRepo.Root(), filepath.FromSlash(file)))
+		if err != nil || strings.HasPrefix(rel, "..") {
+			continue
+		}
+		files = append(files, filepath.Join(blogRoot, rel))
+	}
+	return files
+}
+
+// filterBlogMDFiles 按扫描规则过滤出blogRoot下存在的文章
+func (app *BlogSummaryApp) filterBlogMDFiles(blogRoot string, files []string) []string {
+	scanner := entity.NewBlogScanner(blogRoot, app.scanRules)
+	var mdfiles []string
+	for _, file := range files {
+		file = filepath.Clean(file)
+		if !scanner.Match(file) {
+			continue
+		}
+		if info, err := os.Stat(file); err != nil || info.IsDir() { // 本地仓库未同步或已删除
+			log.Warnf("md file[%s] not found, skip", file)
+			continue
+		}
+		mdfiles = append(mdfiles, file)
+	}
+	return mdfiles
+}
+
+// jobCommitMessage 生成提交重写文章的提交信息
+func jobCommitMessage(jobID uint, files []string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Update AI summaries for %d posts\n\nSummary job #%d rewrote the front matter of:\n\n", len(files), jobID)
+	for _, file := range files {
+		fmt.Fprintf(&b, "- %s\n", filepath.Base(file))
+	}
+	return b.String()
+}
@@ -0,0 +1,85 @@
+package application
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/lupguo/copilot_develop/app/domain/entity"
+	"github.com/lupguo/copilot_develop/app/infras/gitx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func gitCmd(t *testing.T, dir string, args ...string) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	assert.NoError(t, err, string(out))
+}
+
+func TestBlogSummaryApp_SinceJob(t *testing.T) {
+	ctx := context.Background()
+	repoDir := t.TempDir()
+	blogRoot := filepath.Join(repoDir, "content")
+	assert.NoError(t, os.MkdirAll(blogRoot, 0755))
+	body := testPostBody
+	for _, name := range []string{"go.md", "rust.md"} {
+		assert.NoError(t, os.WriteFile(filepath.Join(blogRoot, name), []byte("---\ntitle: "+name+"\n---\n"+body), 0644))
+	}
+	gitCmd(t, repoDir, "init", "-q")
+	gitCmd(t, repoDir, "config", "user.name", "tester")
+	gitCmd(t, repoDir, "config", "user.email", "tester@example.com")
+	gitCmd(t, repoDir, "add", ".")
+	gitCmd(t, repoDir, "commit", "-q", "-m", "init")
+
+	app, _, aiSrv := newTestApp(t)
+	aiSrv.On("SummaryBlogMD", mock.Anything, mock.Anything).Return(&entity.ArticleSummary{
+		Keywords: "go", Summary: "v1", Description: "d1",
+	}, nil)
+	aiSrv.On("SuggestBlogSEO", mock.Anything, mock.Anything).Return(&entity.ArticleSEO{}, nil)
+
+	// 未配置git仓库、没有成功的任务
+	_, err := app.SubmitSinceJob(ctx, blogRoot, "")
+	assert.ErrorIs(t, err, ErrGitRepoNotConfigured)
+	gitRepo, err := gitx.NewGitRepo(ctx, blogRoot)
+	assert.NoError(t, err)
+	app.SetGitRepo(gitRepo)
+	_, err = app.SubmitSinceJob(ctx, blogRoot, "")
+	assert.ErrorIs(t, err, ErrNoSucceededJob)
+
+	// 全量处理并提交重写的文章，任务记录提交后的HEAD
+	job, err := app.SubmitSummaryJob(ctx, blogRoot, blogRoot)
+	assert.NoError(t, err)
+	job, err = app.RunSummaryJob(ctx, job.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, job.Done)
+	commit, err := app.CommitJobChanges(ctx, job.ID)
+	assert.NoError(t, err)
+	head, _ := gitRepo.Head(ctx)
+	assert.Equal(t, head, commit)
+
+	// 增量：仅处理上次成功任务以来变更的文章
+	post := filepath.Join(blogRoot, "rust.md")
+	content, _ := os.ReadFile(post)
+	assert.NoError(t, os.WriteFile(post, append(content, " borrow"...), 0644))
+	job, err = app.SubmitSinceJob(ctx, blogRoot, "")
+	assert.NoError(t, err)
+	_, items, err := app.GetJob(ctx, job.ID)
+	assert.NoError(t, err)
+	assert.Len(t, items, 1)
+	assert.Equal(t, post, items[0].Path)
+	assert.Equal(t, commit, job.HeadCommit)
+	job, err = app.RunSummaryJob(ctx, job.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, entity.JobSucceeded, job.Status)
+
+	// 指定ref
+	job, err = app.SubmitSinceJob(ctx, blogRoot, "HEAD~1")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, job.Total)
+	_, err = app.SubmitSinceJob(ctx, blogRoot, "no-such-ref")
+	assert.Error(t, err)
+}
@@ -0,0 +1,42 @@
+package application
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/lupguo/copilot_develop/app/infras/dbs"
+	"github.com/stretchr/testify/require"
+)
+
+// testPostBody 测试文章的正文，字数超过摘要的最少字数要求
+var testPostBody = strings.Repeat("goroutine channel select ", 60)
+
+// newTestInfra 临时目录下初始化的sqlite库
+func newTestInfra(t *testing.T) *dbs.BlogSummarySqliteInfra {
+	t.Helper()
+	infra, err := dbs.NewBlogSummarySqliteInfra(filepath.Join(t.TempDir(), "blog_summary.db"))
+	require.NoError(t, err)
+	require.NoError(t, infra.InitBlogSummaryDB(context.Background()))
+	return infra
+}
+
+// newTestApp 基于临时sqlite库及mock AI服务的app，AI服务的返回值由各测试按需设置
+func newTestApp(t *testing.T) (*BlogSummaryApp, *dbs.BlogSummarySqliteInfra, *mockAISrv) {
+	t.Helper()
+	infra, aiSrv := newTestInfra(t), new(mockAISrv)
+	return NewBlogSummaryApp(aiSrv, infra), infra, aiSrv
+}
+
+// newTestFileWriter 返回在dir下写入测试文件的函数，自动创建上级目录，返回写入的文件路径
+func newTestFileWriter(t *testing.T, dir string) func(name, content string) string {
+	return func(name, content string) string {
+		t.Helper()
+		file := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(file), 0755))
+		require.NoError(t, os.WriteFile(file, []byte(content), 0644))
+		return file
+	}
+}
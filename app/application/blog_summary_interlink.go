@@ -0,0 +1,188 @@
+package application
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/hold7techs/go-shim/shim"
+	"github.com/lupguo/copilot_develop/app/domain/entity"
+	"github.com/lupguo/copilot_develop/config"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// InterlinkOptions 站内互链建议选项
+type InterlinkOptions struct {
+	MaxPerPost    int     // 每篇文章最多的建议数
+	MinSimilarity float64 // 配置了embedding提示词时，文章相似度低于该值不建议
+}
+
+// InterlinkOptionsFromConfig 配置的站内互链建议选项
+func InterlinkOptionsFromConfig(cfg *config.InterlinkConfig) *InterlinkOptions {
+	return &InterlinkOptions{
+		MaxPerPost:    cfg.MaxPerPost,
+		MinSimilarity: cfg.MinSimilarity,
+	}
+}
+
+// SuggestInterlinks 为blogRoot下每篇文章(不含栏目首页)查找正文中出现的其他文章标题、关键字，建议链接到尚未链接的文章；
+// 配置了embedding提示词时只建议相似度不低于MinSimilarity的文章，并按相似度排序；草稿不作为链接目标，行号为文件中的行号
+func (app *BlogSummaryApp) SuggestInterlinks(ctx context.Context, blogRoot string, opts *InterlinkOptions) ([]*entity.InterlinkSuggestion, error) {
+	blogRoot = filepath.Clean(blogRoot)
+	mds, err := scanArticleMDs(ctx, blogRoot, app.scanRules, false)
+	if err != nil {
+		return nil, err
+	}
+
+	site := config.GetSiteConfig()
+	contentDir := filepath.Clean(siteContentDir(site, blogRoot))
+	articles := linkArticles(mds)
+	index := entity.NewLinkIndex(site.BaseURL, site.Permalink, contentDir, articles)
+	var targets []*entity.InterlinkTarget
+	for i, md := range mds {
+		if md.IsDraft() {
+			continue
+		}
+		url := entity.Permalink(site.Permalink, contentDir, articles[i])
+		targets = append(targets, entity.NewInterlinkTarget(md.Filepath, md.MDHeader.Title, url, md.MDHeader.Keywords))
+	}
+
+	vectors, err := app.articleEmbeddings(ctx, mds)
+	if err != nil {
+		return nil, err
+	}
+
+	var suggestions []*entity.InterlinkSuggestion
+	for _, md := range mds {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		linked := make(map[string]bool)
+		for _, link := range entity.ExtractMDLinks(md.MDContent) {
+			if target := index.Resolve(md.Filepath, link); target != "" {
+				linked[target] = true
+			}
+		}
+
+		findOpts := &entity.InterlinkOptions{MaxPerPost: opts.MaxPerPost, MinSimilarity: opts.MinSimilarity}
+		if source := vectors[md.Filepath]; source != nil {
+			findOpts.Similarities = make(map[string]float64, len(targets))
+			for _, target := range targets {
+				if vector := vectors[target.Path]; vector != nil {
+					findOpts.Similarities[target.Path] = entity.CosineSimilarity(source, vector)
+				}
+			}
+		}
+
+		found := entity.FindInterlinks(md.Filepath, md.MDContent, targets, linked, findOpts)
+		if len(found) == 0 {
+			continue
+		}
+		offset, err := contentLineOffset(md)
+		if err != nil {
+			return nil, err
+		}
+		for _, s := range found {
+			s.Line += offset
+		}
+		suggestions = append(suggestions, found...)
+	}
+	return suggestions, nil
+}
+
+// ApplyInterlinks 按文章将互链建议插入正文中锚文字第一次出现处(跳过代码块、标题及已有链接)，返回实际插入的建议；
+// 文章正文在建议生成后被修改、锚文字已不存在时跳过
+func (app *BlogSummaryApp) ApplyInterlinks(ctx context.Context, suggestions []*entity.InterlinkSuggestion) ([]*entity.InterlinkSuggestion, error) {
+	var paths []string
+	bySource := make(map[string][]*entity.InterlinkSuggestion)
+	for _, s := range suggestions {
+		if _, ok := bySource[s.Source]; !ok {
+			paths = append(paths, s.Source)
+		}
+		bySource[s.Source] = append(bySource[s.Source], s)
+	}
+
+	var applied []*entity.InterlinkSuggestion
+	for _, path := range paths {
+		if ctx.Err() != nil {
+			return applied, ctx.Err()
+		}
+		md, err := entity.NewBlogMD(path)
+		if err != nil {
+			return applied, errors.Wrapf(err, "app new md[%s] got err", path)
+		}
+		content, inserted := entity.ApplyInterlinks(md.MDContent, bySource[path])
+		if len(inserted) == 0 {
+			continue
+		}
+		if err = md.SafeReplaceContent(content); err != nil {
+			return applied, errors.Wrapf(err, "app replace md[%s] content got err", path)
+		}
+		log.Infof("app insert %d interlinks into md[%s]", len(inserted), path)
+		applied = append(applied, inserted...)
+	}
+	return applied, nil
+}
+
+// articleEmbeddings 文章路径 => embedding向量，未配置embedding提示词时返回nil；
+// embedding文本、模型未变化时使用缓存，其余文章批量请求AI后写回缓存(失败只影响下次是否重新请求)
+func (app *BlogSummaryApp) articleEmbeddings(ctx context.Context, mds []*entity.BlogMD) (map[string][]float32, error) {
+	model := app.aiSrv.EmbeddingModel()
+	if model == "" {
+		return nil, nil
+	}
+
+	vectors := make(map[string][]float32, len(mds))
+	var missing []*entity.ArticleEmbedding
+	var texts []string
+	for _, md := range mds {
+		text := entity.InterlinkEmbeddingText(md)
+		hash := fmt.Sprintf("%x", sha256.Sum256([]byte(text)))
+		cached, err := app.sqliteInfra.SelArticleEmbedding(ctx, md.Filepath)
+		if err != nil {
+			log.Warnf("app sel article embedding[%s] got err: %s", md.Filepath, err)
+		} else if cached != nil && cached.Model == model && cached.TextHash == hash {
+			if vector := cached.Values(); vector != nil {
+				vectors[md.Filepath] = vector
+				continue
+			}
+		}
+		missing = append(missing, &entity.ArticleEmbedding{Path: md.Filepath, Model: model, TextHash: hash})
+		texts = append(texts, text)
+	}
+	if len(missing) == 0 {
+		return vectors, nil
+	}
+
+	embedded, err := app.aiSrv.EmbedTexts(ctx, texts)
+	if err != nil {
+		return nil, errors.Wrap(err, "aiSrv embed article texts got err")
+	}
+	for i, embedding := range missing {
+		if i >= len(embedded) {
+			break
+		}
+		vectors[embedding.Path] = embedded[i]
+		embedding.Vector = shim.ToJsonString(embedded[i], false)
+		if err = app.sqliteInfra.ReplaceArticleEmbedding(ctx, embedding); err != nil {
+			log.Warnf("app replace article embedding[%s] got err: %s", embedding.Path, err)
+		}
+	}
+	return vectors, nil
+}
+
+// contentLineOffset 正文之前(front matter)的行数
+func contentLineOffset(md *entity.BlogMD) (int, error) {
+	raw, err := os.ReadFile(md.Filepath)
+	if err != nil {
+		return 0, errors.Wrapf(err, "app read md[%s] got err", md.Filepath)
+	}
+	if !strings.HasSuffix(string(raw), md.MDContent) {
+		return 0, nil
+	}
+	return strings.Count(string(raw[:len(raw)-len(md.MDContent)]), "\n"), nil
+}
@@ -0,0 +1,81 @@
+package application
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestBlogSummaryApp_SuggestInterlinks(t *testing.T) {
+	ctx := context.Background()
+	root := t.TempDir()
+	contentDir := filepath.Join(root, "content")
+	body := strings.Repeat("filler words here ", 20)
+	writeFile := newTestFileWriter(t, contentDir)
+	goPost := writeFile("posts/go.md", "---\ntitle: Go并发\ndate: 2023-01-01\n---\n\n## goroutine调度\n\n"+
+		"Go的goroutine调度依赖GMP模型，channel详解见 [channel](channel.md)，内存管理参考 Rust所有权。\n"+body)
+	writeFile("posts/sched.md", "---\ntitle: GMP模型\nkeywords: goroutine调度,调度器\ndate: 2023-02-01\n---\n"+body)
+	writeFile("posts/channel.md", "---\ntitle: Channel详解\ndate: 2023-03-01\n---\n"+body)
+	writeFile("posts/rust.md", "---\ntitle: Rust所有权\ndate: 2023-04-01\n---\n"+body)
+	writeFile("posts/draft.md", "---\ntitle: GMP模型草稿\ndraft: true\n---\n"+body)
+
+	app, infra, aiSrv := newTestApp(t)
+	aiSrv.On("EmbeddingModel").Return("")
+
+	suggestions, err := app.SuggestInterlinks(ctx, contentDir, &InterlinkOptions{MaxPerPost: 5, MinSimilarity: 0.8})
+	assert.NoError(t, err)
+	var got [][3]interface{}
+	for _, s := range suggestions {
+		if s.Source == goPost {
+			got = append(got, [3]interface{}{s.Line, s.Anchor, s.Url})
+		}
+	}
+	assert.Equal(t, [][3]interface{}{{8, "GMP模型", "/posts/sched/"}, {8, "Rust所有权", "/posts/rust/"}}, got)
+
+	// 按embedding相似度筛选，向量写入缓存后不再重复请求
+	aiSrv = new(mockAISrv)
+	aiSrv.On("EmbeddingModel").Return("text-embedding-ada-002")
+	aiSrv.On("EmbedTexts", mock.Anything, mock.Anything).Return(func(ctx context.Context, texts []string) [][]float32 {
+		vectors := make([][]float32, len(texts))
+		for i, text := range texts {
+			vectors[i] = []float32{1, 0}
+			if strings.HasPrefix(text, "Rust") {
+				vectors[i] = []float32{0, 1}
+			}
+		}
+		return vectors
+	}, nil)
+	app = NewBlogSummaryApp(aiSrv, infra)
+	for i := 0; i < 2; i++ {
+		suggestions, err = app.SuggestInterlinks(ctx, contentDir, &InterlinkOptions{MaxPerPost: 5, MinSimilarity: 0.8})
+		assert.NoError(t, err)
+		got = nil
+		for _, s := range suggestions {
+			if s.Source == goPost {
+				got = append(got, [3]interface{}{s.Line, s.Anchor, s.Url})
+			}
+		}
+		assert.Equal(t, [][3]interface{}{{8, "GMP模型", "/posts/sched/"}}, got)
+	}
+	aiSrv.AssertNumberOfCalls(t, "EmbedTexts", 1)
+
+	applied, err := app.ApplyInterlinks(ctx, suggestions)
+	assert.NoError(t, err)
+	assert.Len(t, applied, len(suggestions))
+	raw, err := os.ReadFile(goPost)
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(raw), "---\ntitle: Go并发\ndate: 2023-01-01\n---\n\n## goroutine调度\n\n"+
+		"Go的goroutine调度依赖[GMP模型](/posts/sched/)"))
+
+	// 已链接的文章不再建议
+	suggestions, err = app.SuggestInterlinks(ctx, contentDir, &InterlinkOptions{MaxPerPost: 5, MinSimilarity: 0.8})
+	assert.NoError(t, err)
+	for _, s := range suggestions {
+		assert.NotEqual(t, goPost, s.Source)
+	}
+}
@@ -0,0 +1,257 @@
+package application
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lupguo/copilot_develop/app/domain/entity"
+	"github.com/lupguo/copilot_develop/config"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// DefaultJobConcurrency 摘要任务默认并发处理的文件数
+	DefaultJobConcurrency = 10
+
+	// DefaultJobListSize 任务列表默认返回条数
+	DefaultJobListSize = 20
+
+	// jobPollInterval 后台worker轮询待处理文件的间隔
+	jobPollInterval = 30 * time.Second
+)
+
+// SetScanRules 设置文章扫描规则，blog目录下的.blogsummaryignore始终生效
+func (app *BlogSummaryApp) SetScanRules(rules *entity.BlogScanRules) {
+	app.scanRules = rules
+}
+
+// ScanRulesFromConfig 配置的文章扫描规则
+func ScanRulesFromConfig(cfg *config.ScanConfig) *entity.BlogScanRules {
+	rules := &entity.BlogScanRules{
+		Include: append([]string(nil), cfg.Include...),
+		Exclude: append([]string(nil), cfg.Exclude...),
+	}
+	for _, section := range cfg.Sections {
+		rules.Sections = append(rules.Sections, &entity.BlogSectionRule{
+			Path:    section.Path,
+			Include: section.Include,
+			Exclude: section.Exclude,
+			Skip:    section.Skip,
+		})
+	}
+	return rules
+}
+
+// SetConcurrency 设置摘要任务并发数，n<=0时保持不变
+func (app *BlogSummaryApp) SetConcurrency(n int) {
+	if n > 0 {
+		app.concurrency = n
+	}
+}
+
+// SubmitSummaryJob 提交摘要任务，path需要位于blogRoot目录下，为空时处理整个blogRoot；
+// 同一路径存在未结束的任务时直接返回该任务(继续处理剩余文件)
+func (app *BlogSummaryApp) SubmitSummaryJob(ctx context.Context, blogRoot, path string) (*entity.SummaryJob, error) {
+	if path == "" {
+		path = blogRoot
+	}
+	path = filepath.Clean(path)
+	rel, err := filepath.Rel(blogRoot, path)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return nil, errors.Errorf("path[%s] is out of blog root[%s]", path, blogRoot)
+	}
+
+	job, err := app.sqliteInfra.SelUnfinishedJobByPath(ctx, path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "app sel unfinished job for path[%s] got err", path)
+	}
+	if job != nil {
+		log.Infof("resume unfinished summary job[%d] for path[%s]", job.ID, path)
+		app.wakeJobWorkers()
+		return job, nil
+	}
+
+	mdfiles, err := entity.NewBlogScanner(blogRoot, app.scanRules).Walk(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "app find md files in path[%s] got err", path)
+	}
+	return app.addSummaryJob(ctx, &entity.SummaryJob{Path: path, BlogRoot: filepath.Clean(blogRoot), HeadCommit: app.headCommit(ctx)}, mdfiles)
+}
+
+// RunSummaryJob 在当前进程内处理完指定任务，上次中断时处理中的文件会被重新处理
+func (app *BlogSummaryApp) RunSummaryJob(ctx context.Context, jobID uint) (*entity.SummaryJob, error) {
+	if _, err := app.sqliteInfra.ResetRunningJobItems(ctx, jobID); err != nil {
+		return nil, errors.Wrapf(err, "app reset job[%d] running items got err", jobID)
+	}
+	if err := app.runJobWorkers(ctx, jobID); err != nil {
+		return nil, err
+	}
+	return app.sqliteInfra.SelJob(ctx, jobID)
+}
+
+// StartJobWorkers 启动后台worker处理所有任务的待处理文件，直到ctx结束；
+// 启动时先将上次退出时处理中的文件重置为待处理，实现重启后继续
+func (app *BlogSummaryApp) StartJobWorkers(ctx context.Context) {
+	if n, err := app.sqliteInfra.ResetRunningJobItems(ctx, 0); err != nil {
+		log.Errorf("app reset running job items got err: %s", err)
+	} else if n > 0 {
+		log.Infof("app reset %d interrupted job items to pending", n)
+	}
+
+	ticker := time.NewTicker(jobPollInterval)
+	defer ticker.Stop()
+	for {
+		if err := app.runJobWorkers(ctx, 0); err != nil {
+			log.Errorf("app run job workers got err: %s", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-app.jobWake:
+		case <-ticker.C:
+		}
+	}
+}
+
+// GetJob 查询任务及文件明细，任务不存在时返回nil
+func (app *BlogSummaryApp) GetJob(ctx context.Context, id uint) (*entity.SummaryJob, []*entity.SummaryJobItem, error) {
+	job, err := app.sqliteInfra.SelJob(ctx, id)
+	if err != nil || job == nil {
+		return nil, nil, err
+	}
+
+	items, err := app.sqliteInfra.SelJobItems(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	return job, items, nil
+}
+
+// ListJobs 查询最近的任务
+func (app *BlogSummaryApp) ListJobs(ctx context.Context, limit int) ([]*entity.SummaryJob, error) {
+	if limit <= 0 {
+		limit = DefaultJobListSize
+	}
+	return app.sqliteInfra.SelJobs(ctx, limit)
+}
+
+// runJobWorkers 按并发数启动worker，逐个领取并处理待处理文件，没有待处理文件时返回；
+// 任务在最后一个文件处理完时收尾，指定的任务没有待处理文件时(例如空任务)在worker结束后收尾
+func (app *BlogSummaryApp) runJobWorkers(ctx context.Context, jobID uint) error {
+	var (
+		wg       sync.WaitGroup
+		claimMu  sync.Mutex // 串行领取，避免sqlite写锁冲突
+		errOnce  sync.Once
+		claimErr error
+	)
+
+	for i := 0; i < app.concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for ctx.Err() == nil {
+				claimMu.Lock()
+				items, err := app.sqliteInfra.ClaimJobItems(ctx, jobID, 1)
+				if err != nil || len(items) == 0 {
+					claimMu.Unlock()
+					if err != nil {
+						errOnce.Do(func() { claimErr = err })
+					}
+					return
+				}
+				claimMu.Unlock()
+				app.processJobItem(ctx, items[0])
+			}
+		}()
+	}
+	wg.Wait()
+
+	if jobID > 0 && ctx.Err() == nil {
+		if _, err := app.finishJob(ctx, jobID); err != nil {
+			log.Errorf("app finish job[%d] got err: %s", jobID, err)
+		}
+	}
+	if claimErr != nil {
+		return errors.Wrapf(claimErr, "app claim job[%d] items got err", jobID)
+	}
+	return ctx.Err()
+}
+
+// processJobItem 处理单个文件，记录处理结果、刷新任务进度并分发进度事件；ctx结束导致的中断保持running，重启后重新处理
+func (app *BlogSummaryApp) processJobItem(ctx context.Context, item *entity.SummaryJobItem) {
+	// AI服务等下游上报的事件补齐任务、文件信息
+	emitCtx := entity.WithJobEventEmitter(ctx, func(event *entity.JobEvent) {
+		event.JobID, event.Path = item.JobID, item.Path
+		app.publishJobEvent(event)
+	})
+	skip, pending, err := app.updateBlogYamlHeader(emitCtx, item.Path)
+	switch {
+	case err != nil && ctx.Err() != nil:
+		log.Warnf("job[%d] item[%s] interrupted: %s", item.JobID, item.Path, err)
+		return
+	case err != nil:
+		log.Errorf("job[%d] item[%s] failed: %s", item.JobID, item.Path, err)
+		item.Status, item.Reason = entity.JobItemFailed, err.Error()
+	case skip != "":
+		item.Status, item.Reason = entity.JobItemSkipped, skip
+	case pending:
+		item.Status, item.Reason = entity.JobItemDone, entity.JobItemReasonPendingReview
+	default:
+		item.Status, item.Reason = entity.JobItemDone, ""
+	}
+
+	if err = app.sqliteInfra.UpdateJobItem(ctx, item); err != nil {
+		log.Errorf("app update job[%d] item[%s] got err: %s", item.JobID, item.Path, err)
+		return
+	}
+	app.publishJobEvent(entity.JobItemEvent(item))
+	if _, err = app.finishJob(ctx, item.JobID); err != nil {
+		log.Errorf("app finish job[%d] got err: %s", item.JobID, err)
+	}
+}
+
+// finishJob 刷新任务进度，任务在本次刷新时结束的，汇总相关栏目的摘要并分发summary事件
+func (app *BlogSummaryApp) finishJob(ctx context.Context, jobID uint) (*entity.SummaryJob, error) {
+	job, finished, err := app.sqliteInfra.RefreshJobStats(ctx, jobID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "app refresh job[%d] stats got err", jobID)
+	}
+	if finished {
+		app.summarizeJobSections(ctx, job)
+		app.publishJobEvent(app.jobSummaryEvent(job))
+	}
+	return job, nil
+}
+
+// addSummaryJob 新增任务及待处理文件，分发queued事件并通知后台worker
+func (app *BlogSummaryApp) addSummaryJob(ctx context.Context, job *entity.SummaryJob, mdfiles []string) (*entity.SummaryJob, error) {
+	if err := app.sqliteInfra.AddJob(ctx, job, mdfiles); err != nil {
+		return nil, errors.Wrapf(err, "app add job for path[%s] got err", job.Path)
+	}
+	job, err := app.finishJob(ctx, job.ID)
+	if err != nil {
+		return nil, err
+	}
+	for _, mdfile := range mdfiles {
+		event := entity.NewJobEvent(entity.JobEventQueued)
+		event.JobID, event.Path = job.ID, mdfile
+		app.publishJobEvent(event)
+	}
+	log.Infof("submit summary job[%d] for path[%s], total %d files", job.ID, job.Path, job.Total)
+
+	app.wakeJobWorkers()
+	return job, nil
+}
+
+// wakeJobWorkers 通知后台worker有新的任务
+func (app *BlogSummaryApp) wakeJobWorkers() {
+	select {
+	case app.jobWake <- struct{}{}:
+	default:
+	}
+}
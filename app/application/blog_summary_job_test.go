@@ -0,0 +1,229 @@
+package application
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/lupguo/copilot_develop/app/domain/entity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestBlogSummaryApp_SummaryJob(t *testing.T) {
+	ctx := context.Background()
+	root := t.TempDir()
+	posts := filepath.Join(root, "posts")
+	assert.NoError(t, os.MkdirAll(posts, 0755))
+	longPost := filepath.Join(posts, "long.md")
+	shortPost := filepath.Join(posts, "short.md")
+	for path, content := range map[string]string{
+		longPost:                          "---\ntitle: Long\n---\n" + testPostBody,
+		shortPost:                         "---\ntitle: Short\n---\ntoo short",
+		filepath.Join(posts, "_index.md"): "---\ntitle: Posts\n---\n",
+	} {
+		assert.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+
+	app, infra, aiSrv := newTestApp(t)
+	aiSrv.On("SummaryBlogMD", mock.Anything, mock.Anything).Return(&entity.ArticleSummary{
+		Keywords: "go", Summary: "summary", Description: "description",
+	}, nil)
+	aiSrv.On("SuggestBlogSEO", mock.Anything, mock.Anything).Return(&entity.ArticleSEO{Title: "Long", Slug: "long"}, nil)
+	aiSrv.On("SummarySection", mock.Anything, mock.Anything, mock.Anything).Return(&entity.ArticleSummary{
+		Keywords: "go", Summary: "section summary", Description: "section description",
+	}, nil)
+	app.SetConcurrency(2)
+
+	// 路径需在blog目录内
+	_, err := app.SubmitSummaryJob(ctx, posts, root)
+	assert.Error(t, err)
+
+	job, err := app.SubmitSummaryJob(ctx, root, "")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, job.Total)
+	assert.Equal(t, entity.JobPending, job.Status)
+
+	// 未结束的任务重复提交时继续原任务
+	again, err := app.SubmitSummaryJob(ctx, root, root)
+	assert.NoError(t, err)
+	assert.Equal(t, job.ID, again.ID)
+
+	// 模拟处理中进程退出，重新执行时继续处理
+	claimed, err := infra.ClaimJobItems(ctx, job.ID, 1)
+	assert.NoError(t, err)
+	assert.Len(t, claimed, 1)
+
+	events, err := app.WatchJob(ctx, job.ID)
+	assert.NoError(t, err)
+	job, err = app.RunSummaryJob(ctx, job.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, entity.JobFailed, job.Status)
+	assert.Equal(t, 1, job.Done)
+	assert.Equal(t, 1, job.Failed)
+	assert.NotEmpty(t, job.FinishedAt)
+
+	_, items, err := app.GetJob(ctx, job.ID)
+	assert.NoError(t, err)
+	for _, item := range items {
+		switch item.Path {
+		case longPost:
+			assert.Equal(t, entity.JobItemDone, item.Status)
+		case shortPost:
+			assert.Equal(t, entity.JobItemFailed, item.Status)
+			assert.Contains(t, item.Reason, "too small")
+		}
+	}
+
+	// 有文章更新时汇总所在栏目的摘要
+	index, err := os.ReadFile(filepath.Join(posts, "_index.md"))
+	assert.NoError(t, err)
+	assert.Contains(t, string(index), "section summary")
+
+	// 多个worker并发处理时，栏目汇总、summary事件只在任务结束时执行一次
+	aiSrv.AssertNumberOfCalls(t, "SummarySection", 1)
+	summaries := 0
+	for event := range events {
+		if event.Type == entity.JobEventSummary {
+			summaries++
+		}
+	}
+	assert.Equal(t, 1, summaries)
+
+	// 再次提交，未变化的文章跳过
+	job, err = app.SubmitSummaryJob(ctx, root, root)
+	assert.NoError(t, err)
+	job, err = app.RunSummaryJob(ctx, job.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, job.Skipped)
+
+	jobs, err := app.ListJobs(ctx, 0)
+	assert.NoError(t, err)
+	assert.Len(t, jobs, 2)
+}
+
+func TestBlogSummaryApp_RunJobWorkers(t *testing.T) {
+	ctx := context.Background()
+	root := t.TempDir()
+	body := testPostBody
+	for name, content := range map[string]string{
+		"a/_index.md": "---\ntitle: A\n---\n",
+		"a/go.md":     "---\ntitle: Go\n---\n" + body,
+		"b/go.md":     "---\ntitle: Go\n---\n" + body,
+	} {
+		file := filepath.Join(root, name)
+		assert.NoError(t, os.MkdirAll(filepath.Dir(file), 0755))
+		assert.NoError(t, os.WriteFile(file, []byte(content), 0644))
+	}
+
+	app, _, aiSrv := newTestApp(t)
+	aiSrv.On("SummaryBlogMD", mock.Anything, mock.Anything).Return(&entity.ArticleSummary{
+		Keywords: "go", Summary: "summary", Description: "description",
+	}, nil)
+	aiSrv.On("SuggestBlogSEO", mock.Anything, mock.Anything).Return(&entity.ArticleSEO{}, nil)
+	aiSrv.On("SummarySection", mock.Anything, mock.Anything, mock.Anything).Return(&entity.ArticleSummary{
+		Keywords: "go", Summary: "section summary", Description: "section description",
+	}, nil)
+	app.SetConcurrency(1)
+
+	jobA, err := app.SubmitSummaryJob(ctx, root, filepath.Join(root, "a"))
+	assert.NoError(t, err)
+	_, err = app.SubmitSummaryJob(ctx, root, filepath.Join(root, "b"))
+	assert.NoError(t, err)
+	events, err := app.WatchJob(ctx, jobA.ID)
+	assert.NoError(t, err)
+
+	// 后台worker处理所有任务时，任务A在最后一个文件处理完时收尾，不等待任务B
+	assert.NoError(t, app.runJobWorkers(ctx, 0))
+	var calls []string
+	for _, call := range aiSrv.Calls {
+		if call.Method != "SuggestBlogSEO" {
+			calls = append(calls, call.Method)
+		}
+	}
+	assert.Equal(t, []string{"SummaryBlogMD", "SummarySection", "SummaryBlogMD"}, calls)
+
+	var last *entity.JobEvent
+	for event := range events {
+		last = event
+	}
+	assert.Equal(t, entity.JobEventSummary, last.Type)
+	assert.Equal(t, entity.JobSucceeded, last.Job.Status)
+}
+
+func TestBlogSummaryApp_WatchJob(t *testing.T) {
+	ctx := context.Background()
+	root := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(root, "short.md"), []byte("---\ntitle: Short\n---\ntoo short"), 0644))
+
+	app, _, _ := newTestApp(t)
+
+	job, err := app.SubmitSummaryJob(ctx, root, root)
+	assert.NoError(t, err)
+	events, err := app.WatchJob(ctx, job.ID)
+	assert.NoError(t, err)
+	_, err = app.RunSummaryJob(ctx, job.ID)
+	assert.NoError(t, err)
+
+	var types []entity.JobEventType
+	for event := range events {
+		types = append(types, event.Type)
+	}
+	assert.Equal(t, []entity.JobEventType{entity.JobEventQueued, entity.JobEventFailed, entity.JobEventSummary}, types)
+
+	// 已结束的任务回放后直接结束
+	events, err = app.WatchJob(ctx, job.ID)
+	assert.NoError(t, err)
+	var last *entity.JobEvent
+	for event := range events {
+		last = event
+	}
+	assert.Equal(t, entity.JobEventSummary, last.Type)
+	assert.Equal(t, 1, last.Job.Failed)
+
+	_, err = app.WatchJob(ctx, 999)
+	assert.Error(t, err)
+}
+
+func TestBlogSummaryApp_ScanRules(t *testing.T) {
+	ctx := context.Background()
+	root := t.TempDir()
+	body := testPostBody
+	for name, header := range map[string]string{
+		"posts/go.md":         "title: Go",
+		"posts/optout.md":     "title: OptOut\nai_summary: false",
+		"posts/drafts/wip.md": "title: WIP",
+		"about/me.md":         "title: Me",
+	} {
+		file := filepath.Join(root, name)
+		assert.NoError(t, os.MkdirAll(filepath.Dir(file), 0755))
+		assert.NoError(t, os.WriteFile(file, []byte("---\n"+header+"\n---\n"+body), 0644))
+	}
+	assert.NoError(t, os.WriteFile(filepath.Join(root, entity.BlogScanIgnoreFile), []byte("drafts/\n"), 0644))
+
+	app, _, aiSrv := newTestApp(t)
+	aiSrv.On("SummaryBlogMD", mock.Anything, mock.Anything).Return(&entity.ArticleSummary{
+		Keywords: "go", Summary: "summary", Description: "description",
+	}, nil)
+	aiSrv.On("SuggestBlogSEO", mock.Anything, mock.Anything).Return(&entity.ArticleSEO{}, nil)
+	app.SetScanRules(&entity.BlogScanRules{Sections: []*entity.BlogSectionRule{{Path: "about", Skip: true}}})
+
+	job, err := app.SubmitSummaryJob(ctx, root, "")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, job.Total)
+	job, err = app.RunSummaryJob(ctx, job.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, job.Done)
+	assert.Equal(t, 1, job.Skipped)
+
+	_, items, err := app.GetJob(ctx, job.ID)
+	assert.NoError(t, err)
+	for _, item := range items {
+		if item.Path == filepath.Join(root, "posts/optout.md") {
+			assert.Equal(t, entity.JobItemSkipped, item.Status)
+			assert.Contains(t, item.Reason, "ai_summary disabled")
+		}
+	}
+	aiSrv.AssertNumberOfCalls(t, "SummaryBlogMD", 1)
+}
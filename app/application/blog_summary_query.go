@@ -0,0 +1,60 @@
+package application
+
+import (
+	"context"
+
+	"github.com/lupguo/copilot_develop/app/domain/entity"
+	"github.com/pkg/errors"
+)
+
+const (
+	// DefaultPageSize 默认分页大小
+	DefaultPageSize = 20
+
+	// MaxPageSize 最大分页大小
+	MaxPageSize = 200
+)
+
+// ListArticles 按条件分页查询文章
+func (app *BlogSummaryApp) ListArticles(ctx context.Context, query *entity.ArticleQuery) (articles []*entity.BlogArticle, total int64, err error) {
+	if query.Page <= 0 {
+		query.Page = 1
+	}
+	if query.Size <= 0 {
+		query.Size = DefaultPageSize
+	} else if query.Size > MaxPageSize {
+		query.Size = MaxPageSize
+	}
+
+	articles, total, err = app.sqliteInfra.SelBlogMDRecords(ctx, query)
+	if err != nil {
+		return nil, 0, errors.Wrap(err, "app list articles got err")
+	}
+	return articles, total, nil
+}
+
+// GetArticle 查询文章信息及摘要历史，文章不存在时返回nil
+func (app *BlogSummaryApp) GetArticle(ctx context.Context, id uint) (article *entity.BlogArticle, histories []*entity.BlogSummaryHistory, err error) {
+	article, err = app.sqliteInfra.SelBlogMDRecordByID(ctx, id)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "app get article[%d] got err", id)
+	}
+	if article == nil {
+		return nil, nil, nil
+	}
+
+	histories, err = app.sqliteInfra.SelSummaryHistories(ctx, article.Path)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "app get article[%d] summary histories got err", id)
+	}
+	return article, histories, nil
+}
+
+// GetBlogStats 全站内容统计及健康报告，top为各列表展示的条数(<=0时不限)
+func (app *BlogSummaryApp) GetBlogStats(ctx context.Context, top int) (*entity.BlogStats, error) {
+	records, err := app.sqliteInfra.SelAllBlogMDRecords(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "app get blog stats got err")
+	}
+	return entity.NewBlogStats(records, top), nil
+}
@@ -0,0 +1,201 @@
+package application
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/lupguo/copilot_develop/app/domain/entity"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// SummarizeSections 汇总path下(含path所属的上级栏目)各栏目_index.md的摘要、关键字并写入front matter，
+// 摘要基于子文章、子栏目已生成的摘要，子摘要集合没有变化的栏目不重新生成；返回更新了的_index.md
+func (app *BlogSummaryApp) SummarizeSections(ctx context.Context, blogRoot, path string) ([]string, error) {
+	if path == "" {
+		path = blogRoot
+	}
+	blogRoot, path = filepath.Clean(blogRoot), filepath.Clean(path)
+	rel, err := filepath.Rel(blogRoot, path)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return nil, errors.Errorf("path[%s] is out of blog root[%s]", path, blogRoot)
+	}
+
+	indexes, err := entity.NewBlogScanner(blogRoot, app.scanRules).WalkSections(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "app find section indexes in path[%s] got err", path)
+	}
+	// 子项变化后上级栏目的汇总也需要刷新
+	for index := entity.NearestSectionIndex(blogRoot, path); index != ""; index = entity.NearestSectionIndex(blogRoot, filepath.Dir(index)) {
+		indexes = append(indexes, index)
+	}
+	indexes = sortSectionIndexes(indexes)
+
+	// 同一时间只执行一次汇总，避免多个任务同时结束时重复请求AI
+	app.sectionMu.Lock()
+	defer app.sectionMu.Unlock()
+
+	var updated []string
+	failed := 0
+	for _, index := range indexes {
+		skip, err := app.summarizeSection(ctx, blogRoot, index)
+		switch {
+		case err != nil && ctx.Err() != nil:
+			return updated, ctx.Err()
+		case err != nil:
+			log.Errorf("app summarize section[%s] got err: %s", index, err)
+			failed++
+		case skip != "":
+			log.Infof("section[%s] skipped: %s", index, skip)
+		default:
+			updated = append(updated, index)
+		}
+	}
+	if failed > 0 {
+		return updated, errors.Errorf("%d of %d sections failed to summarize", failed, len(indexes))
+	}
+	return updated, nil
+}
+
+// summarizeSection 汇总单个栏目的摘要，无需更新时返回跳过的原因
+func (app *BlogSummaryApp) summarizeSection(ctx context.Context, blogRoot, index string) (skip string, err error) {
+	md, err := entity.NewBlogMD(index)
+	if err != nil {
+		return "", errors.Wrapf(err, "app new section index md[%s] got err", index)
+	}
+	if md.MDHeader.IsAISummaryDisabled() {
+		return "ai_summary disabled", nil
+	}
+
+	children, err := app.sectionChildren(ctx, blogRoot, index)
+	if err != nil {
+		return "", err
+	}
+	if len(children) == 0 {
+		return "no child summaries", nil
+	}
+
+	hash := entity.SectionChildrenHash(children)
+	record, err := app.sqliteInfra.SelBlogSection(ctx, index)
+	if err != nil {
+		return "", errors.Wrapf(err, "app sel section[%s] record got err", index)
+	}
+	var summary *entity.ArticleSummary
+	if record != nil && record.ChildrenHash == hash {
+		// 子摘要没有变化，front matter中的摘要被清空时从DB恢复，手工修改过的保持不变
+		if md.MDHeader.Summary != "" {
+			return "child summaries unchanged", nil
+		}
+		summary = record.ArticleSummary()
+	} else {
+		if summary, err = app.aiSrv.SummarySection(ctx, md, children); err != nil {
+			return "", errors.Wrapf(err, "aiSrv summary section[%s] got err", index)
+		}
+	}
+
+	md.MDHeader.Summary = summary.Summary
+	md.MDHeader.Keywords = summary.Keywords
+	md.MDHeader.Description = summary.Description
+	md.MDHeader.ForceUpdate = ""
+	if err = md.SafeReplaceYamlHeader(); err != nil {
+		return "", errors.Wrapf(err, "app replace write into section index md[%s] got err", index)
+	}
+
+	err = app.sqliteInfra.ReplaceBlogSection(ctx, &entity.BlogSection{
+		Path:         index,
+		Title:        md.MDHeader.Title,
+		ChildrenHash: hash,
+		Children:     len(children),
+		Keywords:     summary.Keywords,
+		Summary:      summary.Summary,
+		Description:  summary.Description,
+	})
+	if err != nil {
+		return "", errors.Wrapf(err, "app replace section[%s] record got err", index)
+	}
+	log.Infof("section[%s] summarized from %d children", index, len(children))
+	return "", nil
+}
+
+// sectionChildren 栏目下直属(最近的_index.md为该栏目)的非手稿文章、子栏目已存储的摘要，已删除的文件忽略
+func (app *BlogSummaryApp) sectionChildren(ctx context.Context, blogRoot, index string) ([]*entity.SectionChild, error) {
+	dir := filepath.Dir(index)
+	records, err := app.sqliteInfra.SelBlogMDRecordsByDir(ctx, dir)
+	if err != nil {
+		return nil, errors.Wrapf(err, "app sel md records in dir[%s] got err", dir)
+	}
+	sections, err := app.sqliteInfra.SelBlogSectionsByDir(ctx, dir)
+	if err != nil {
+		return nil, errors.Wrapf(err, "app sel sections in dir[%s] got err", dir)
+	}
+
+	var children []*entity.SectionChild
+	for _, record := range records {
+		if record.Summary == "" || record.Draft || filepath.Base(record.Path) == entity.SectionIndexFile ||
+			entity.NearestSectionIndex(blogRoot, record.Path) != index || !fileExists(record.Path) {
+			continue
+		}
+		children = append(children, &entity.SectionChild{
+			Path:     record.Path,
+			Title:    record.Title,
+			Keywords: record.Keywords,
+			Summary:  record.Summary,
+		})
+	}
+	for _, section := range sections {
+		if section.Path == index || section.Summary == "" ||
+			entity.NearestSectionIndex(blogRoot, filepath.Dir(section.Path)) != index || !fileExists(section.Path) {
+			continue
+		}
+		children = append(children, &entity.SectionChild{
+			Path:     section.Path,
+			Title:    section.Title,
+			Keywords: section.Keywords,
+			Summary:  section.Summary,
+		})
+	}
+	return children, nil
+}
+
+// summarizeJobSections 任务结束后汇总任务路径相关的栏目摘要
+func (app *BlogSummaryApp) summarizeJobSections(ctx context.Context, job *entity.SummaryJob) {
+	if job.BlogRoot == "" || job.Done == 0 {
+		return
+	}
+	updated, err := app.SummarizeSections(ctx, job.BlogRoot, job.Path)
+	if err != nil {
+		log.Errorf("app summarize sections for job[%d] got err: %s", job.ID, err)
+	}
+	if len(updated) > 0 {
+		log.Infof("job[%d] updated %d section summaries", job.ID, len(updated))
+	}
+}
+
+// sortSectionIndexes 去重并按目录深度倒序排列，子栏目先于上级栏目汇总
+func sortSectionIndexes(indexes []string) []string {
+	seen := make(map[string]bool, len(indexes))
+	var sorted []string
+	for _, index := range indexes {
+		if !seen[index] {
+			seen[index] = true
+			sorted = append(sorted, index)
+		}
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		di, dj := strings.Count(sorted[i], string(filepath.Separator)), strings.Count(sorted[j], string(filepath.Separator))
+		if di != dj {
+			return di > dj
+		}
+		return sorted[i] < sorted[j]
+	})
+	return sorted
+}
+
+// fileExists 文件是否存在
+func fileExists(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && !info.IsDir()
+}
@@ -0,0 +1,84 @@
+package application
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/lupguo/copilot_develop/app/domain/entity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestBlogSummaryApp_SummarizeSections(t *testing.T) {
+	ctx := context.Background()
+	root := t.TempDir()
+	body := testPostBody
+	writeMD := func(name, header, content string) string {
+		file := filepath.Join(root, name)
+		assert.NoError(t, os.MkdirAll(filepath.Dir(file), 0755))
+		assert.NoError(t, os.WriteFile(file, []byte("---\n"+header+"\n---\n"+content), 0644))
+		return file
+	}
+	postsIndex := writeMD("posts/_index.md", "title: Posts\nweight: 3", "")
+	golangIndex := writeMD("posts/golang/_index.md", "title: Golang", "")
+	writeMD("posts/go.md", "title: Go", body)
+	writeMD("posts/rust.md", "title: Rust", body)
+	writeMD("posts/golang/channel.md", "title: Channel", body)
+
+	app, infra, aiSrv := newTestApp(t)
+	aiSrv.On("SummaryBlogMD", mock.Anything, mock.Anything).Return(&entity.ArticleSummary{
+		Keywords: "go", Summary: "summary", Description: "description",
+	}, nil)
+	aiSrv.On("SuggestBlogSEO", mock.Anything, mock.Anything).Return(&entity.ArticleSEO{}, nil)
+	aiSrv.On("SummarySection", mock.Anything, mock.Anything, mock.Anything).Return(&entity.ArticleSummary{
+		Keywords: "section", Summary: "section summary", Description: "section description",
+	}, nil)
+
+	// 任务结束后汇总栏目，子栏目先于上级栏目
+	job, err := app.SubmitSummaryJob(ctx, root, "")
+	assert.NoError(t, err)
+	assert.Equal(t, 3, job.Total)
+	_, err = app.RunSummaryJob(ctx, job.ID)
+	assert.NoError(t, err)
+	aiSrv.AssertNumberOfCalls(t, "SummarySection", 2)
+
+	children := aiSrv.Calls[len(aiSrv.Calls)-1].Arguments.Get(2).([]*entity.SectionChild)
+	var titles []string
+	for _, child := range children {
+		titles = append(titles, child.Title)
+	}
+	assert.ElementsMatch(t, []string{"Go", "Rust", "Golang"}, titles)
+
+	md, err := entity.NewBlogMD(postsIndex)
+	assert.NoError(t, err)
+	assert.Equal(t, "section summary", md.MDHeader.Summary)
+	assert.Equal(t, "section", md.MDHeader.Keywords)
+	assert.Equal(t, 3, md.MDHeader.Weight)
+	assert.False(t, md.MDHeader.Draft)
+
+	// 子摘要没有变化时不重新生成
+	updated, err := app.SummarizeSections(ctx, root, "")
+	assert.NoError(t, err)
+	assert.Empty(t, updated)
+	aiSrv.AssertNumberOfCalls(t, "SummarySection", 2)
+
+	// front matter的摘要被清空时从DB恢复
+	writeMD("posts/_index.md", "title: Posts", "")
+	updated, err = app.SummarizeSections(ctx, root, filepath.Join(root, "posts", "golang"))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{postsIndex}, updated)
+	aiSrv.AssertNumberOfCalls(t, "SummarySection", 2)
+
+	// 新增文章后重新汇总所属栏目，子栏目摘要未变化的上级栏目不重新生成
+	writeMD("posts/golang/select.md", "title: Select", body)
+	job, err = app.SubmitSummaryJob(ctx, root, filepath.Join(root, "posts", "golang", "select.md"))
+	assert.NoError(t, err)
+	_, err = app.RunSummaryJob(ctx, job.ID)
+	assert.NoError(t, err)
+	aiSrv.AssertNumberOfCalls(t, "SummarySection", 3)
+	section, err := infra.SelBlogSection(ctx, golangIndex)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, section.Children)
+}
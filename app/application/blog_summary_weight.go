@@ -0,0 +1,208 @@
+package application
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/hold7techs/go-shim/shim"
+	"github.com/lupguo/copilot_develop/app/domain/entity"
+	"github.com/lupguo/copilot_develop/config"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// SetWeightRules 设置文章权重公式，为nil时使用默认权重
+func (app *BlogSummaryApp) SetWeightRules(rules *entity.WeightRules) {
+	app.weightRules = rules
+}
+
+// WeightRulesFromConfig 配置的文章权重公式，未配置时返回nil
+func WeightRulesFromConfig(cfg *config.WeightConfig) *entity.WeightRules {
+	if cfg == nil {
+		return nil
+	}
+	rules := entity.DefaultWeightRules()
+	if cfg.Base != 0 {
+		rules.Base = cfg.Base
+	}
+	rules.Min, rules.Max = cfg.Min, cfg.Max
+	if rules.Min == 0 {
+		rules.Min = 1
+	}
+	rules.Pinned = cfg.Pinned
+	if cfg.Recency != nil {
+		rules.Recency = &entity.RecencyWeightRule{HalfLifeDays: cfg.Recency.HalfLifeDays, MaxAdjust: cfg.Recency.MaxAdjust}
+	}
+	for _, bucket := range cfg.WordBuckets {
+		rules.WordBuckets = append(rules.WordBuckets, &entity.WordBucketWeightRule{MinWords: bucket.MinWords, Adjust: bucket.Adjust})
+	}
+	if len(cfg.Categories) > 0 {
+		rules.CategoryBoosts = make(map[string]int, len(cfg.Categories))
+		for category, adjust := range cfg.Categories {
+			rules.CategoryBoosts[strings.ToLower(category)] += adjust
+		}
+	}
+	if cfg.InboundLinks != nil {
+		rules.InboundLinks = &entity.InboundLinkWeightRule{PerLink: cfg.InboundLinks.PerLink, Max: cfg.InboundLinks.Max}
+	}
+	return rules
+}
+
+// articleWeight 摘要任务中按配置的公式(未配置时为默认公式)重算文章权重，站内链接数取最近一次weights命令的统计，
+// 栏目首页保留原有的权重设置
+func (app *BlogSummaryApp) articleWeight(md *entity.BlogMD, record *entity.BlogArticle) int {
+	if md.IsIndexMD() {
+		return md.MDHeader.Weight
+	}
+	if app.weightRules == nil {
+		return md.CalcArticleWeight()
+	}
+	inboundLinks := 0
+	if record != nil {
+		inboundLinks = record.InboundLinks
+	}
+	return app.weightRules.Calc(md.WeightInput(inboundLinks), time.Now()).Weight
+}
+
+// weightArticle 计算权重的文章
+type weightArticle struct {
+	md           *entity.BlogMD
+	inboundLinks int
+	change       *entity.WeightChange
+}
+
+// PreviewWeights 按权重公式计算blogRoot下文章的新权重，返回按新名次排序的新旧权重、名次对比，不修改文件
+func (app *BlogSummaryApp) PreviewWeights(ctx context.Context, blogRoot string) ([]*entity.WeightChange, error) {
+	articles, err := app.calcWeights(ctx, blogRoot)
+	if err != nil {
+		return nil, err
+	}
+	changes := make([]*entity.WeightChange, 0, len(articles))
+	for _, article := range articles {
+		changes = append(changes, article.change)
+	}
+	return entity.RankWeightChanges(changes), nil
+}
+
+// ApplyWeights 将新权重写入文章front matter(仅权重有变化的)，并在DB中记录权重及站内链接数，返回所有文章的权重对比
+func (app *BlogSummaryApp) ApplyWeights(ctx context.Context, blogRoot string) ([]*entity.WeightChange, error) {
+	articles, err := app.calcWeights(ctx, blogRoot)
+	if err != nil {
+		return nil, err
+	}
+	changes := make([]*entity.WeightChange, 0, len(articles))
+	for _, article := range articles {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		md, change := article.md, article.change
+		if change.NewWeight != change.OldWeight {
+			md.MDHeader.Weight = change.NewWeight
+			if err = md.SafeReplaceYamlHeader(); err != nil {
+				return nil, errors.Wrapf(err, "app replace write weight into md[%s] got err", md.Filepath)
+			}
+		}
+		if err = app.sqliteInfra.UpdateBlogMDWeight(ctx, md.Filepath, change.NewWeight, article.inboundLinks); err != nil {
+			return nil, errors.Wrapf(err, "app update md[%s] weight got err", md.Filepath)
+		}
+		changes = append(changes, change)
+	}
+	return entity.RankWeightChanges(changes), nil
+}
+
+// calcWeights 扫描blogRoot下的文章，统计站内链接后按权重公式计算新权重
+func (app *BlogSummaryApp) calcWeights(ctx context.Context, blogRoot string) ([]*weightArticle, error) {
+	blogRoot = filepath.Clean(blogRoot)
+	mds, err := scanArticleMDs(ctx, blogRoot, app.scanRules, false)
+	if err != nil {
+		return nil, err
+	}
+
+	// 站内链接统计仅在公式中启用时才需要
+	rules := app.weightRules
+	if rules == nil {
+		rules = entity.DefaultWeightRules()
+	}
+	inboundLinks := make(map[string]int)
+	if rules.InboundLinks != nil {
+		contents := make(map[string]string, len(mds))
+		for _, md := range mds {
+			contents[md.Filepath] = md.MDContent
+		}
+		site := config.GetSiteConfig()
+		inboundLinks = entity.NewLinkIndex(site.BaseURL, site.Permalink, siteContentDir(site, blogRoot), linkArticles(mds)).
+			InboundLinks(contents)
+	}
+
+	now := time.Now()
+	articles := make([]*weightArticle, 0, len(mds))
+	for _, md := range mds {
+		links := inboundLinks[md.Filepath]
+		score := rules.Calc(md.WeightInput(links), now)
+		articles = append(articles, &weightArticle{
+			md:           md,
+			inboundLinks: links,
+			change: &entity.WeightChange{
+				Path:      md.Filepath,
+				Title:     md.MDHeader.Title,
+				Date:      md.MDHeader.Date,
+				OldWeight: md.MDHeader.Weight,
+				NewWeight: score.Weight,
+				Score:     score,
+			},
+		})
+	}
+	return articles, nil
+}
+
+// scanArticleMDs 按扫描规则读取blogRoot下的文章，withSections为false时跳过栏目首页(_index.md)，无法解析的文章仅记录日志
+func scanArticleMDs(ctx context.Context, blogRoot string, rules *entity.BlogScanRules, withSections bool) ([]*entity.BlogMD, error) {
+	mdfiles, err := entity.NewBlogScanner(blogRoot, rules).Walk(blogRoot)
+	if err != nil {
+		return nil, errors.Wrapf(err, "app find md files in path[%s] got err", blogRoot)
+	}
+
+	var mds []*entity.BlogMD
+	for _, mdfile := range mdfiles {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		if !withSections && filepath.Base(mdfile) == entity.SectionIndexFile {
+			continue
+		}
+		md, err := entity.NewBlogMD(mdfile)
+		if err != nil {
+			log.Warnf("app new md[%s] got err: %s", mdfile, err)
+			continue
+		}
+		mds = append(mds, md)
+	}
+	return mds, nil
+}
+
+// linkArticles 构建站内链接索引的文章(不含栏目首页)
+func linkArticles(mds []*entity.BlogMD) []*entity.BlogArticle {
+	articles := make([]*entity.BlogArticle, 0, len(mds))
+	for _, md := range mds {
+		if filepath.Base(md.Filepath) == entity.SectionIndexFile {
+			continue
+		}
+		articles = append(articles, &entity.BlogArticle{
+			Path:    md.Filepath,
+			Date:    md.MDHeader.Date,
+			Slug:    md.MDHeader.Slug,
+			Aliases: shim.ToJsonString(md.MDHeader.Aliases, false),
+		})
+	}
+	return articles
+}
+
+// siteContentDir Hugo content目录，未配置时为blogRoot
+func siteContentDir(site *config.SiteConfig, blogRoot string) string {
+	if site.ContentDir != "" {
+		return site.ContentDir
+	}
+	return blogRoot
+}
@@ -0,0 +1,74 @@
+package application
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/lupguo/copilot_develop/app/domain/entity"
+	"github.com/lupguo/copilot_develop/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBlogSummaryApp_Weights(t *testing.T) {
+	ctx := context.Background()
+	root := t.TempDir()
+	body := testPostBody
+	writeMD := func(name, header, content string) string {
+		file := filepath.Join(root, name)
+		assert.NoError(t, os.MkdirAll(filepath.Dir(file), 0755))
+		assert.NoError(t, os.WriteFile(file, []byte("---\n"+header+"\n---\n"+content), 0644))
+		return file
+	}
+	goPost := writeMD("posts/go.md", "title: Go\ndate: 2023-01-01\nweight: 100", body)
+	rustPost := writeMD("posts/rust.md", "title: Rust\ndate: 2023-06-01\nweight: 100\ncategories: [Rust]", body+" [go](go.md)")
+	pinnedPost := writeMD("posts/about.md", "title: About\ndate: 2020-01-01\nweight: 100\npinned: true", body+" [go](/posts/go/)")
+	writeMD("posts/_index.md", "title: Posts\nweight: 3", "")
+
+	app, infra, _ := newTestApp(t)
+	for _, file := range []string{goPost, rustPost, pinnedPost} {
+		md, err := entity.NewBlogMD(file)
+		assert.NoError(t, err)
+		assert.NoError(t, infra.AddBlogMDRecord(ctx, md))
+	}
+	app.SetWeightRules(WeightRulesFromConfig(&config.WeightConfig{
+		Pinned:       -80,
+		Categories:   map[string]int{"RUST": -5},
+		InboundLinks: &config.WeightInboundLinksConfig{PerLink: -10},
+	}))
+
+	// 预览不修改文章
+	changes, err := app.PreviewWeights(ctx, root)
+	assert.NoError(t, err)
+	var got [][4]interface{}
+	for _, c := range changes {
+		got = append(got, [4]interface{}{c.Title, c.NewWeight, c.OldRank, c.NewRank})
+	}
+	assert.Equal(t, [][4]interface{}{{"About", 20, 3, 1}, {"Go", 80, 2, 2}, {"Rust", 95, 1, 3}}, got)
+	md, err := entity.NewBlogMD(goPost)
+	assert.NoError(t, err)
+	assert.Equal(t, 100, md.MDHeader.Weight)
+
+	// 写入文章及DB
+	_, err = app.ApplyWeights(ctx, root)
+	assert.NoError(t, err)
+	md, err = entity.NewBlogMD(goPost)
+	assert.NoError(t, err)
+	assert.Equal(t, 80, md.MDHeader.Weight)
+	record, err := infra.SelBlogMDRecord(ctx, goPost)
+	assert.NoError(t, err)
+	assert.Equal(t, 80, record.Weight)
+	assert.Equal(t, 2, record.InboundLinks)
+
+	// 栏目_index.md不参与计算
+	index, err := entity.NewBlogMD(filepath.Join(root, "posts", "_index.md"))
+	assert.NoError(t, err)
+	assert.Equal(t, 3, index.MDHeader.Weight)
+
+	changes, err = app.PreviewWeights(ctx, root)
+	assert.NoError(t, err)
+	for _, c := range changes {
+		assert.Equal(t, c.OldWeight, c.NewWeight)
+	}
+}
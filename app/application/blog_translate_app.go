@@ -0,0 +1,74 @@
+package application
+
+import (
+	"context"
+	"os"
+
+	"github.com/lupguo/copilot_develop/app/domain/entity"
+	"github.com/lupguo/copilot_develop/app/domain/repos"
+	"github.com/lupguo/copilot_develop/app/domain/service"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// BlogTranslateApp Blog的翻译App
+type BlogTranslateApp struct {
+	aiSrv       service.IServicesTranslateAI
+	sqliteInfra repos.IReposSQLiteBlogSummary
+}
+
+// NewBlogTranslateApp 初始一个BlogTranslateApp
+func NewBlogTranslateApp(aiSrv service.IServicesTranslateAI, sqliteInfra repos.IReposSQLiteBlogSummary) *BlogTranslateApp {
+	return &BlogTranslateApp{
+		aiSrv:       aiSrv,
+		sqliteInfra: sqliteInfra,
+	}
+}
+
+// TranslateBlog 将文章翻译到Hugo多语言兄弟文件(name.<lang>.md)，译文存在且未过期时跳过，force为true时强制重新翻译
+func (app *BlogTranslateApp) TranslateBlog(ctx context.Context, mdfile string, lang string, force bool) (targetPath string, err error) {
+	md, err := entity.NewBlogMD(mdfile)
+	if err != nil {
+		return "", errors.Wrapf(err, "app new md[%s] got err", mdfile)
+	}
+	if md.Lang == lang {
+		return "", errors.Errorf("md[%s] source lang is already %s", mdfile, lang)
+	}
+
+	// 检查已有的翻译是否仍然有效
+	sourceHash := md.ContentHash()
+	record, err := app.sqliteInfra.SelTranslationRecord(ctx, mdfile, lang)
+	if err != nil {
+		return "", err
+	}
+	if record != nil && !force && !record.Stale && record.SourceHash == sourceHash {
+		if _, err := os.Stat(record.TargetPath); err == nil {
+			log.Infof("md[%s] translation[%s] is up to date", mdfile, record.TargetPath)
+			return record.TargetPath, nil
+		}
+	}
+
+	// AI翻译并写入兄弟文件
+	translated, err := app.aiSrv.TranslateBlogMD(ctx, md, lang)
+	if err != nil {
+		return "", errors.Wrapf(err, "aiSrv translate md[%s] into %s got err", mdfile, lang)
+	}
+	translated.MDHeader.ForceUpdate = ""
+	if err = translated.ReplaceWithNewYamlHeader(); err != nil {
+		return "", errors.Wrapf(err, "app write translation md[%s] got err", translated.Filepath)
+	}
+
+	// 记录翻译
+	err = app.sqliteInfra.ReplaceTranslationRecord(ctx, &entity.BlogTranslation{
+		SourcePath: mdfile,
+		TargetPath: translated.Filepath,
+		Lang:       lang,
+		SourceHash: sourceHash,
+		Stale:      false,
+	})
+	if err != nil {
+		return "", errors.Wrapf(err, "app replace md[%s] translation record got err", mdfile)
+	}
+
+	return translated.Filepath, nil
+}
@@ -0,0 +1,235 @@
+package entity
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// AltTextMode alt文字的生成方式
+type AltTextMode string
+
+const (
+	AltTextModeContext AltTextMode = "context" // 基于图片所在段落的上下文生成
+	AltTextModeVision  AltTextMode = "vision"  // 由支持图片输入的模型看图生成
+)
+
+// AltTextStatus alt文字的处理状态
+type AltTextStatus string
+
+const (
+	AltTextStatusPending AltTextStatus = "pending" // 已生成，尚未写入文章
+	AltTextStatusApplied AltTextStatus = "applied" // 已写入文章
+)
+
+var (
+	// htmlImgTagRegex 正文中的html图片标签
+	htmlImgTagRegex = regexp.MustCompile(`(?i)<img\s[^>]*>`)
+
+	// htmlAltAttrRegex html图片标签的alt属性
+	htmlAltAttrRegex = regexp.MustCompile(`(?i)\salt\s*=\s*(?:"([^"]*)"|'([^']*)')`)
+
+	// htmlTagRegex 上下文中去除的html标签
+	htmlTagRegex = regexp.MustCompile(`<[^>]+>`)
+)
+
+// AltTextImage 正文中alt文字缺失或为空的图片
+type AltTextImage struct {
+	Target  string // 图片地址
+	Line    int    // 所在行号(正文中，从1开始)
+	Context string // 所在段落、前后段落及所属标题的文字
+	html    bool   // html图片标签
+	noAttr  bool   // html图片标签缺少alt属性，需要插入
+	start   int    // alt文字的替换起点
+	end     int    // alt文字的替换终点
+}
+
+// textBlock 正文中代码块以外、以空行分隔的段落
+type textBlock struct {
+	startLine, endLine int
+	text               string
+	heading            bool
+}
+
+// FindMissingAltImages 查找正文中alt文字缺失或为空的markdown图片及html图片，跳过代码块及行内代码，
+// 上下文取所在段落及前后段落(去除图片、html标签)，前面有标题时附带最近的标题，最多contextRunes个字符
+func FindMissingAltImages(content string, contextRunes int) []*AltTextImage {
+	var images []*AltTextImage
+	var blocks []*textBlock
+	var block *textBlock
+	fence := ""
+	offset := 0
+	for i, line := range strings.SplitAfter(content, "\n") {
+		lineStart := offset
+		offset += len(line)
+		text := strings.TrimRight(line, "\r\n")
+		trimmed := strings.TrimSpace(text)
+		if fence != "" {
+			if strings.HasPrefix(trimmed, fence) {
+				fence = ""
+			}
+			continue
+		}
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			fence, block = trimmed[:3], nil
+			continue
+		}
+		if trimmed == "" {
+			block = nil
+			continue
+		}
+
+		lineNo := i + 1
+		heading := strings.HasPrefix(trimmed, "#")
+		if block == nil || heading || block.heading {
+			block = &textBlock{startLine: lineNo, heading: heading}
+			blocks = append(blocks, block)
+		}
+		block.endLine = lineNo
+		block.text += " " + trimmed
+
+		// 行内代码替换为等长的空白，保持偏移不变
+		masked := mdInlineCodeRegex.ReplaceAllStringFunc(text, func(s string) string { return strings.Repeat(" ", len(s)) })
+		for _, m := range mdImageRefRegex.FindAllStringSubmatchIndex(masked, -1) {
+			if strings.TrimSpace(text[m[2]:m[3]]) != "" {
+				continue
+			}
+			images = append(images, &AltTextImage{Target: text[m[4]:m[5]], Line: lineNo, start: lineStart + m[2], end: lineStart + m[3]})
+		}
+		for _, m := range htmlImgTagRegex.FindAllStringIndex(masked, -1) {
+			tag := text[m[0]:m[1]]
+			src := htmlImgRegex.FindStringSubmatch(tag)
+			if src == nil {
+				continue
+			}
+			img := &AltTextImage{Target: src[1], Line: lineNo, html: true}
+			if alt := htmlAltAttrRegex.FindStringSubmatchIndex(tag); alt == nil {
+				img.noAttr = true
+				img.start = lineStart + m[0] + len("<img")
+				img.end = img.start
+			} else if value := altAttrValue(tag, alt); strings.TrimSpace(tag[value[0]:value[1]]) == "" {
+				img.start, img.end = lineStart+m[0]+value[0], lineStart+m[0]+value[1]
+			} else {
+				continue
+			}
+			images = append(images, img)
+		}
+	}
+
+	sort.SliceStable(images, func(i, j int) bool { return images[i].start < images[j].start })
+	for _, img := range images {
+		img.Context = altTextContext(blocks, img.Line, contextRunes)
+	}
+	return images
+}
+
+// altAttrValue alt属性值(双引号或单引号内)在标签中的位置
+func altAttrValue(tag string, m []int) [2]int {
+	if m[2] >= 0 {
+		return [2]int{m[2], m[3]}
+	}
+	return [2]int{m[4], m[5]}
+}
+
+// altTextContext 图片所在段落及前后段落的文字，附带最近的标题
+func altTextContext(blocks []*textBlock, line, contextRunes int) string {
+	idx := -1
+	for i, b := range blocks {
+		if b.startLine <= line && line <= b.endLine {
+			idx = i
+			break
+		}
+	}
+	if idx < 0 {
+		return ""
+	}
+
+	var parts []string
+	for i := idx - 1; i >= 0; i-- {
+		if blocks[i].heading {
+			parts = append(parts, blockText(blocks[i]))
+			break
+		}
+	}
+	for i := idx - 1; i <= idx+1; i++ {
+		if i >= 0 && i < len(blocks) && !blocks[i].heading {
+			if text := blockText(blocks[i]); text != "" {
+				parts = append(parts, text)
+			}
+		}
+	}
+
+	context := strings.Join(parts, "\n")
+	if runes := []rune(context); contextRunes > 0 && len(runes) > contextRunes {
+		context = string(runes[:contextRunes])
+	}
+	return context
+}
+
+// blockText 段落文字，图片替换为alt文字并去除html标签
+func blockText(b *textBlock) string {
+	text := mdImageRefRegex.ReplaceAllString(b.text, "$1")
+	text = htmlTagRegex.ReplaceAllString(text, "")
+	return strings.Join(strings.Fields(strings.TrimLeft(strings.TrimSpace(text), "# ")), " ")
+}
+
+// ApplyAltTexts 将图片地址 => alt文字写入正文中alt缺失或为空的图片，正文其余部分保持不变，返回新正文及写入的图片数
+func ApplyAltTexts(content string, alts map[string]string) (string, int) {
+	images := FindMissingAltImages(content, 0)
+	count := 0
+	// 从后向前替换，保证前面图片的偏移不变
+	for i := len(images) - 1; i >= 0; i-- {
+		img := images[i]
+		alt := strings.Join(strings.Fields(alts[img.Target]), " ")
+		if alt == "" {
+			continue
+		}
+		var replacement string
+		switch {
+		case !img.html:
+			replacement = strings.NewReplacer("[", "", "]", "").Replace(alt)
+		case img.noAttr:
+			replacement = ` alt="` + strings.ReplaceAll(alt, `"`, "&quot;") + `"`
+		case content[img.start-1] == '\'':
+			replacement = strings.ReplaceAll(alt, "'", "&#39;")
+		default:
+			replacement = strings.ReplaceAll(alt, `"`, "&quot;")
+		}
+		content = content[:img.start] + replacement + content[img.end:]
+		count++
+	}
+	return content, count
+}
+
+// NormalizeAltText 整理AI生成的alt文字：去除首尾引号、换行及多余空白
+func NormalizeAltText(alt string) string {
+	alt = strings.Join(strings.Fields(alt), " ")
+	return strings.Trim(alt, "\"'“”「」 ")
+}
+
+// ImageAltText 文章图片的alt文字生成记录，按文章路径+图片地址唯一，已生成的不再请求AI
+type ImageAltText struct {
+	ID        uint          `gorm:"id"`
+	CreatedAt string        `gorm:"created_at"`
+	UpdatedAt string        `gorm:"updated_at"`
+	Path      string        `gorm:"path"`     // 文章路径
+	Target    string        `gorm:"target"`   // 图片地址
+	AltText   string        `gorm:"alt_text"` // 生成的alt文字
+	Mode      AltTextMode   `gorm:"mode"`     // 生成方式
+	Status    AltTextStatus `gorm:"status"`   // 是否已写入文章
+}
+
+func (t ImageAltText) TableName() string {
+	return "image_alt_texts"
+}
+
+// AltTextResult alt-text命令的处理结果
+type AltTextResult struct {
+	Path    string      `json:"path"`
+	Line    int         `json:"line"`
+	Target  string      `json:"target"`
+	AltText string      `json:"alt_text"`
+	Mode    AltTextMode `json:"mode"`
+	Cached  bool        `json:"cached"`  // 使用了之前生成的alt文字
+	Applied bool        `json:"applied"` // 已写入文章
+}
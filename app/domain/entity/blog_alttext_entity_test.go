@@ -0,0 +1,42 @@
+package entity
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindMissingAltImages(t *testing.T) {
+	content := "## 调度模型\n\n" +
+		"GMP模型由G、M、P组成。\n\n" +
+		"![](/img/gmp.png) 调度流程如下\n" +
+		"![GMP](/img/ok.png) `go run`\n\n" +
+		"```\n![](/img/fence.png)\n```\n" +
+		"<img src=\"/img/a.jpg\" width=\"100\"> <img alt=\"\" src='/img/b.jpg'> <img alt=\"B\" src=\"/img/c.jpg\">\n\n" +
+		"P负责本地队列。\n"
+
+	images := FindMissingAltImages(content, 0)
+	var got [][2]interface{}
+	for _, img := range images {
+		got = append(got, [2]interface{}{img.Line, img.Target})
+	}
+	assert.Equal(t, [][2]interface{}{{5, "/img/gmp.png"}, {11, "/img/a.jpg"}, {11, "/img/b.jpg"}}, got)
+	assert.Equal(t, "调度模型\nGMP模型由G、M、P组成。\n调度流程如下 GMP `go run`", images[0].Context)
+	assert.Equal(t, "调度模型\n调度流程如下 GMP `go run`\nP负责本地队列。", images[1].Context)
+	assert.Equal(t, "调度模型", FindMissingAltImages(content, 4)[0].Context)
+}
+
+func TestApplyAltTexts(t *testing.T) {
+	content := "![](/img/gmp.png \"t\") text `![](/img/gmp.png)`\n" +
+		"<img src=\"/img/a.jpg\"> <img alt='' src=\"/img/b.jpg\"> ![ ](/img/none.png)\n"
+	got, n := ApplyAltTexts(content, map[string]string{
+		"/img/gmp.png": "GMP [调度] 模型",
+		"/img/a.jpg":   "say \"hi\"",
+		"/img/b.jpg":   "it's b",
+	})
+	assert.Equal(t, 3, n)
+	assert.Equal(t, "![GMP 调度 模型](/img/gmp.png \"t\") text `![](/img/gmp.png)`\n"+
+		"<img alt=\"say &quot;hi&quot;\" src=\"/img/a.jpg\"> <img alt='it&#39;s b' src=\"/img/b.jpg\"> ![ ](/img/none.png)\n", got)
+
+	assert.Equal(t, "Go调度器示意图", NormalizeAltText("\"Go调度器示意图\"\n"))
+}
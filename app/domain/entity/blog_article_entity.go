@@ -9,25 +9,87 @@ type ArticleSummary struct {
 
 // BlogArticle DB更新记录
 type BlogArticle struct {
-	ID          uint   `gorm:"id"`
-	CreatedAt   string `gorm:"created_at"`
-	UpdatedAt   string `gorm:"updated_at"`
-	DeletedAt   string `gorm:"deleted_at"`
-	Date        string `gorm:date`          // 文章编写时间
-	Path        string `gorm:"path"`        // 文章本地存储路径(目前作为唯一的标识)
-	ShortMark   string `gorm:"short_mark"`  // 文章短标记，文章创建后自动生成，基于文章标题做短hash，支持后续软链接快速检索到文章
-	Title       string `gorm:"title"`       // 文章标题
-	Categories  string `gorm:"categories"`  // 文章类型
-	Tags        string `gorm:"tags"`        // 文章标签
-	Draft       bool   `gorm:"draft"`       // 是否手稿
-	Weight      int    `gorm:"weight"`      // 文章权重
-	WordCount   int    `gorm:"word_count"`  // 文章内容长度，可以用于指导hugo文章的基本情况(新增和更新时候都会用到)
-	Keywords    string `gorm:"keywords"`    // 文章关键字
-	Summary     string `gorm:"summary"`     // 文章摘要
-	Description string `gorm:"description"` // 文章描述
-	Aliases     string `gorm:"aliases"`     // 软连
+	ID           uint   `gorm:"id"`
+	CreatedAt    string `gorm:"created_at"`
+	UpdatedAt    string `gorm:"updated_at"`
+	DeletedAt    string `gorm:"deleted_at"`
+	Date         string `gorm:"date"`          // 文章编写时间
+	Path         string `gorm:"path"`          // 文章本地存储路径(目前作为唯一的标识)
+	ShortMark    string `gorm:"short_mark"`    // 文章短标记，文章创建后自动生成，基于文章标题做短hash，支持后续软链接快速检索到文章
+	Title        string `gorm:"title"`         // 文章标题
+	Categories   string `gorm:"categories"`    // 文章类型
+	Tags         string `gorm:"tags"`          // 文章标签
+	Draft        bool   `gorm:"draft"`         // 是否手稿
+	Weight       int    `gorm:"weight"`        // 文章权重
+	WordCount    int    `gorm:"word_count"`    // 文章内容长度，可以用于指导hugo文章的基本情况(新增和更新时候都会用到)
+	Keywords     string `gorm:"keywords"`      // 文章关键字
+	Summary      string `gorm:"summary"`       // 文章摘要
+	Description  string `gorm:"description"`   // 文章描述
+	Aliases      string `gorm:"aliases"`       // 软连
+	Lang         string `gorm:"lang"`          // 文章源语言(检测得到)
+	SEOTitle     string `gorm:"seo_title"`     // SEO标题
+	Slug         string `gorm:"slug"`          // 文章slug，全站唯一
+	ContentHash  string `gorm:"content_hash"`  // 最近一次同步时的正文hash
+	SummaryHash  string `gorm:"summary_hash"`  // 生成(或审核通过)当前摘要时的正文hash
+	InboundLinks int    `gorm:"inbound_links"` // 被站内其他文章链接的次数(weights命令统计)
 }
 
 func (t BlogArticle) TableName() string {
 	return "blog_articles"
 }
+
+// IsSummaryStale 摘要生成后正文又有修改
+func (t *BlogArticle) IsSummaryStale() bool {
+	return t.Summary != "" && t.SummaryHash != "" && t.SummaryHash != t.ContentHash
+}
+
+// SummaryStatus 摘要的审核状态
+type SummaryStatus string
+
+const (
+	SummaryPending  SummaryStatus = "pending"  // 待审核，未写入文章
+	SummaryApproved SummaryStatus = "approved" // 审核通过(或非审核模式下直接写入)
+	SummaryRejected SummaryStatus = "rejected" // 审核拒绝
+)
+
+// BlogSummaryHistory AI生成摘要的历史版本
+type BlogSummaryHistory struct {
+	ID          uint          `gorm:"id"`
+	CreatedAt   string        `gorm:"created_at"`
+	UpdatedAt   string        `gorm:"updated_at"`
+	Path        string        `gorm:"path"`        // 文章路径
+	Lang        string        `gorm:"lang"`        // 摘要语言
+	Keywords    string        `gorm:"keywords"`    // 文章关键字
+	Summary     string        `gorm:"summary"`     // 文章摘要
+	Description string        `gorm:"description"` // 文章描述
+	Status      SummaryStatus `gorm:"status"`      // 审核状态，为空的历史记录视为approved
+}
+
+func (t BlogSummaryHistory) TableName() string {
+	return "blog_summary_histories"
+}
+
+// ArticleSummary 摘要内容
+func (t *BlogSummaryHistory) ArticleSummary() *ArticleSummary {
+	return &ArticleSummary{Keywords: t.Keywords, Summary: t.Summary, Description: t.Description}
+}
+
+// SummaryReview 待审核的摘要及所属文章
+type SummaryReview struct {
+	History *BlogSummaryHistory
+	Article *BlogArticle
+}
+
+// ArticleQuery 文章列表查询条件
+type ArticleQuery struct {
+	Keyword  string // 搜索标题、关键字、摘要、描述
+	Category string // 分类
+	Tag      string // 标签
+	Draft    *bool  // 是否手稿，nil表示不过滤
+	Page     int    // 页码，从1开始
+	Size     int    // 每页数量
+
+	MissingSummary bool // 仅查询缺少摘要的文章
+	StaleSummary   bool // 仅查询摘要已过期(正文有修改)的文章
+	PendingReview  bool // 仅查询有待审核摘要的文章
+}
@@ -0,0 +1,107 @@
+package entity
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ClipSourceKey 摘录笔记front matter中记录原文地址的字段
+const ClipSourceKey = "source"
+
+// WebPage 抓取并去除导航、侧栏等噪音后的网页正文
+type WebPage struct {
+	Url      string // 规范化后的网页地址(重定向后的最终地址)
+	Title    string // 网页标题
+	SiteName string // 站点名称
+	Content  string // 正文，Markdown格式
+}
+
+// WebClip 网页摘录记录，按url去重避免重复摘录
+type WebClip struct {
+	ID          uint   `gorm:"id"`
+	CreatedAt   string `gorm:"created_at"`
+	UpdatedAt   string `gorm:"updated_at"`
+	Url         string `gorm:"url"`          // 网页地址
+	Title       string `gorm:"title"`        // 网页标题
+	Filepath    string `gorm:"filepath"`     // 摘录笔记的本地路径
+	Keywords    string `gorm:"keywords"`     // 关键字
+	Summary     string `gorm:"summary"`      // 摘要
+	Description string `gorm:"description"`  // 描述
+	ContentHash string `gorm:"content_hash"` // 摘录时网页正文hash
+}
+
+func (t WebClip) TableName() string {
+	return "web_clips"
+}
+
+// NewWebPageMD 基于网页正文构建(不落盘的)BlogMD，复用文章摘要的AI请求流程
+func NewWebPageMD(page *WebPage) *BlogMD {
+	md := &BlogMD{
+		Filepath:  page.Url,
+		MDHeader:  &YamlHeader{Title: page.Title},
+		MDContent: page.Content,
+	}
+	md.MDHeader.WordCounts = wordsCount(md.MDContent)
+	md.MiniData = md.GenerateMiniData()
+	md.Lang = DetectLang(md.MiniData.MiniContent)
+	return md
+}
+
+// ClipNoteFilename 摘录笔记文件名：抓取日期-标题slug.md，标题无法生成slug时使用url的hash
+func ClipNoteFilename(page *WebPage, fetched time.Time) string {
+	slug := NormalizeSlug(page.Title)
+	if slug == "" {
+		slug = "clip-" + ClipURLHash(page.Url)
+	}
+	return fmt.Sprintf("%s-%s.md", fetched.Format("2006-01-02"), slug)
+}
+
+// ClipURLHash url的短hash
+func ClipURLHash(url string) string {
+	return fmt.Sprintf("%x", sha256.Sum256([]byte(url)))[:8]
+}
+
+// UniqueClipPath 目录下不存在的笔记路径，重名时追加url的hash
+func UniqueClipPath(dir, filename, url string) string {
+	path := filepath.Join(dir, filename)
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return path
+	}
+	ext := filepath.Ext(filename)
+	return filepath.Join(dir, strings.TrimSuffix(filename, ext)+"-"+ClipURLHash(url)+ext)
+}
+
+// NewClipNote 生成Hugo摘录笔记：front matter记录标题、原文地址、抓取时间、摘要及关键字，正文为原文链接、摘要及笔记占位；
+// 笔记关闭AI摘要，避免摘要任务重复请求
+func NewClipNote(path string, page *WebPage, summary *ArticleSummary, fetched time.Time) *BlogMD {
+	disabled := false
+	header := &YamlHeader{
+		Title:       page.Title,
+		Date:        fetched.Format(time.RFC3339),
+		Keywords:    summary.Keywords,
+		Summary:     summary.Summary,
+		Description: summary.Description,
+		AISummary:   &disabled,
+		Extra:       map[string]interface{}{ClipSourceKey: page.Url},
+	}
+
+	source := page.Title
+	if page.SiteName != "" {
+		source = fmt.Sprintf("%s - %s", page.Title, page.SiteName)
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "> 原文：[%s](%s)\n\n", escapeMDLinkText(source), page.Url)
+	fmt.Fprintf(&b, "## 摘要\n\n%s\n\n", summary.Summary)
+	b.WriteString("## 笔记\n\n")
+
+	return &BlogMD{Filepath: path, MDHeader: header, MDContent: b.String()}
+}
+
+// escapeMDLinkText 转义链接文本中的方括号
+func escapeMDLinkText(s string) string {
+	return strings.NewReplacer("[", `\[`, "]", `\]`).Replace(s)
+}
@@ -0,0 +1,42 @@
+package entity
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewClipNote(t *testing.T) {
+	dir := t.TempDir()
+	fetched := time.Date(2024, 3, 5, 10, 0, 0, 0, time.UTC)
+	page := &WebPage{Url: "https://example.com/go", Title: "Go [Channels]", SiteName: "Example", Content: "body"}
+
+	filename := ClipNoteFilename(page, fetched)
+	assert.Equal(t, "2024-03-05-go-channels.md", filename)
+	assert.Equal(t, "2024-03-05-clip-"+ClipURLHash("https://example.com/中文")+".md",
+		ClipNoteFilename(&WebPage{Url: "https://example.com/中文", Title: "中文标题"}, fetched))
+
+	path := UniqueClipPath(dir, filename, page.Url)
+	assert.Equal(t, filepath.Join(dir, filename), path)
+	note := NewClipNote(path, page, &ArticleSummary{Keywords: "go", Summary: "about channels", Description: "desc"}, fetched)
+	assert.NoError(t, note.ReplaceWithNewYamlHeader())
+	assert.Equal(t, filepath.Join(dir, "2024-03-05-go-channels-"+ClipURLHash(page.Url)+".md"), UniqueClipPath(dir, filename, page.Url))
+
+	md, err := NewBlogMD(path)
+	assert.NoError(t, err)
+	assert.Equal(t, "Go [Channels]", md.MDHeader.Title)
+	assert.Equal(t, "2024-03-05T10:00:00Z", md.MDHeader.Date)
+	assert.Equal(t, "about channels", md.MDHeader.Summary)
+	assert.Equal(t, "go", md.MDHeader.Keywords)
+	assert.Equal(t, page.Url, md.MDHeader.Extra[ClipSourceKey])
+	assert.True(t, md.MDHeader.IsAISummaryDisabled())
+	assert.True(t, strings.HasPrefix(md.MDContent, `> 原文：[Go \[Channels\] - Example](https://example.com/go)`))
+
+	raw, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Contains(t, string(raw), "source: https://example.com/go")
+}
@@ -0,0 +1,155 @@
+package entity
+
+import (
+	"bytes"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/pkg/errors"
+)
+
+// DefaultCoverPromptTemplate 默认的封面图提示词模板
+const DefaultCoverPromptTemplate = `A clean, modern flat illustration as the cover image of a technical blog post titled "{{.Title}}".
+{{- if .Summary}} The post is about: {{.Summary}}{{end}}
+{{- if .Keywords}} Key concepts: {{.Keywords}}.{{end}} No text, no letters, no watermark.`
+
+// ImageGenRequest 文生图请求
+type ImageGenRequest struct {
+	Prompt         string // 提示词
+	NegativePrompt string // 反向提示词，仅Stable Diffusion支持
+	Model          string // 模型，为空时使用服务的默认模型
+	Size           string // 尺寸，例如 1792x1024
+	Seed           int64  // 随机种子，<=0时随机
+}
+
+// GeneratedImage 文生图结果
+type GeneratedImage struct {
+	Data          []byte // 图片内容
+	Ext           string // 图片扩展名，例如 .png
+	Model         string // 实际使用的模型
+	Seed          int64  // 实际使用的随机种子，服务不支持时为0
+	RevisedPrompt string // 服务改写后的提示词
+}
+
+// SizeWH 解析 1792x1024 形式的尺寸
+func (r *ImageGenRequest) SizeWH() (int, int, error) {
+	parts := strings.SplitN(strings.ToLower(r.Size), "x", 2)
+	if len(parts) == 2 {
+		w, errW := strconv.Atoi(parts[0])
+		h, errH := strconv.Atoi(parts[1])
+		if errW == nil && errH == nil && w > 0 && h > 0 {
+			return w, h, nil
+		}
+	}
+	return 0, 0, errors.Errorf("invalid image size[%s]", r.Size)
+}
+
+// coverPromptData 封面图提示词模板的数据
+type coverPromptData struct {
+	Title       string
+	Summary     string
+	Description string
+	Keywords    string
+	Tags        string
+}
+
+// CoverPrompt 按模板基于文章标题、摘要、关键字生成封面图提示词，模板为空时使用默认模板
+func CoverPrompt(tmpl string, md *BlogMD) (string, error) {
+	if tmpl == "" {
+		tmpl = DefaultCoverPromptTemplate
+	}
+	t, err := template.New("cover").Parse(tmpl)
+	if err != nil {
+		return "", errors.Wrap(err, "parse cover prompt template got err")
+	}
+
+	summary := md.MDHeader.Summary
+	if summary == "" {
+		summary = md.MDHeader.Description
+	}
+	data := &coverPromptData{
+		Title:       md.MDHeader.Title,
+		Summary:     summary,
+		Description: md.MDHeader.Description,
+		Keywords:    md.MDHeader.Keywords,
+		Tags:        strings.Join(md.MDHeader.Tags, ", "),
+	}
+	var b bytes.Buffer
+	if err = t.Execute(&b, data); err != nil {
+		return "", errors.Wrap(err, "execute cover prompt template got err")
+	}
+	return strings.TrimSpace(b.String()), nil
+}
+
+// IsPageBundle 文章是否为Hugo page bundle(index.md)，封面图可与文章放在同一目录
+func IsPageBundle(mdPath string) bool {
+	base := filepath.Base(mdPath)
+	return base == "index.md" || strings.HasPrefix(base, "index.") && strings.HasSuffix(base, ".md")
+}
+
+// CoverLocation 封面图的保存路径及front matter中的引用：page bundle保存为同目录的cover图片，
+// 其余文章保存到static目录下的coverDir，以文件名(不含扩展名、语言后缀)命名
+func CoverLocation(mdPath, staticDir, coverDir, ext string) (file, ref string) {
+	if IsPageBundle(mdPath) {
+		return filepath.Join(filepath.Dir(mdPath), "cover"+ext), "cover" + ext
+	}
+	name := linkFileName(mdPath) + ext
+	coverDir = strings.Trim(filepath.ToSlash(coverDir), "/")
+	return filepath.Join(staticDir, filepath.FromSlash(coverDir), name), "/" + path.Join(coverDir, name)
+}
+
+// HasCover front matter中是否已设置封面图
+func (h *YamlHeader) HasCover(key string) bool {
+	v, ok := h.Extra[key]
+	return ok && v != nil && v != ""
+}
+
+// SetCover 设置front matter的封面图(或社交分享卡片)字段，并加入Hugo OpenGraph等模板使用的images列表，
+// 返回front matter是否有变化
+func (h *YamlHeader) SetCover(key, ref string) bool {
+	if h.Extra == nil {
+		h.Extra = make(map[string]interface{})
+	}
+	changed := h.Extra[key] != ref
+	h.Extra[key] = ref
+	if key == "images" {
+		return changed
+	}
+
+	var images []interface{}
+	switch v := h.Extra["images"].(type) {
+	case []interface{}:
+		images = v
+	case string:
+		images = []interface{}{v}
+	}
+	for _, image := range images {
+		if image == ref {
+			return changed
+		}
+	}
+	h.Extra["images"] = append([]interface{}{ref}, images...)
+	return true
+}
+
+// CoverImage 文章封面图的生成记录(提示词、模型、随机种子)，按文章路径唯一
+type CoverImage struct {
+	ID            uint   `gorm:"id" json:"-"`
+	CreatedAt     string `gorm:"created_at" json:"created_at"`
+	UpdatedAt     string `gorm:"updated_at" json:"-"`
+	Path          string `gorm:"path" json:"path"`                     // 文章路径
+	Provider      string `gorm:"provider" json:"provider"`             // 文生图服务: openai、sd
+	Model         string `gorm:"model" json:"model"`                   // 模型
+	Prompt        string `gorm:"prompt" json:"prompt"`                 // 提示词
+	RevisedPrompt string `gorm:"revised_prompt" json:"revised_prompt"` // 服务改写后的提示词
+	Seed          int64  `gorm:"seed" json:"seed"`                     // 随机种子
+	File          string `gorm:"file" json:"file"`                     // 封面图保存路径
+	Ref           string `gorm:"ref" json:"ref"`                       // front matter中的引用
+}
+
+func (t CoverImage) TableName() string {
+	return "cover_images"
+}
@@ -0,0 +1,51 @@
+package entity
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCoverPrompt(t *testing.T) {
+	md := &BlogMD{MDHeader: &YamlHeader{Title: "Go调度器", Description: "GMP模型", Keywords: "go,gmp", Tags: []string{"go", "runtime"}}}
+	prompt, err := CoverPrompt("", md)
+	assert.NoError(t, err)
+	assert.Equal(t, `A clean, modern flat illustration as the cover image of a technical blog post titled "Go调度器". `+
+		`The post is about: GMP模型 Key concepts: go,gmp. No text, no letters, no watermark.`, prompt)
+
+	prompt, err = CoverPrompt("{{.Title}} | {{.Tags}}", md)
+	assert.NoError(t, err)
+	assert.Equal(t, "Go调度器 | go, runtime", prompt)
+
+	_, err = CoverPrompt("{{.Title", md)
+	assert.Error(t, err)
+}
+
+func TestCoverLocation(t *testing.T) {
+	file, ref := CoverLocation("/blog/content/posts/bundle/index.md", "/blog/static", "images/covers", ".png")
+	assert.Equal(t, filepath.FromSlash("/blog/content/posts/bundle/cover.png"), file)
+	assert.Equal(t, "cover.png", ref)
+
+	file, ref = CoverLocation("/blog/content/posts/go.en.md", "/blog/static", "/images/covers/", ".png")
+	assert.Equal(t, filepath.FromSlash("/blog/static/images/covers/go.png"), file)
+	assert.Equal(t, "/images/covers/go.png", ref)
+}
+
+func TestYamlHeader_SetCover(t *testing.T) {
+	h := &YamlHeader{}
+	assert.False(t, h.HasCover("cover"))
+	h.SetCover("cover", "/images/covers/go.png")
+	assert.True(t, h.HasCover("cover"))
+	assert.Equal(t, []interface{}{"/images/covers/go.png"}, h.Extra["images"])
+
+	h.Extra["images"] = "/img/a.png"
+	h.SetCover("cover", "/images/covers/go.png")
+	h.SetCover("cover", "/images/covers/go.png")
+	assert.Equal(t, []interface{}{"/images/covers/go.png", "/img/a.png"}, h.Extra["images"])
+
+	sizeReq := &ImageGenRequest{Size: "1792X1024"}
+	w, hgt, err := sizeReq.SizeWH()
+	assert.NoError(t, err)
+	assert.Equal(t, [2]int{1792, 1024}, [2]int{w, hgt})
+}
@@ -0,0 +1,152 @@
+package entity
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// FeedOutput 订阅源新文章的输出方式
+type FeedOutput string
+
+const (
+	FeedOutputDigest FeedOutput = "digest" // 每天汇总为一篇摘要文章
+	FeedOutputNotes  FeedOutput = "notes"  // 每篇文章生成一篇摘录笔记
+)
+
+// FeedEntryStatus 订阅文章的处理状态
+type FeedEntryStatus string
+
+const (
+	FeedEntrySummarized FeedEntryStatus = "summarized" // 已AI摘要并写入
+	FeedEntrySkipped    FeedEntryStatus = "skipped"    // 超过单次处理数量或内容过少，仅记录不摘要
+)
+
+// FeedExcerptWords 订阅文章内容少于该字数时视为摘录，需抓取原文全文
+const FeedExcerptWords = 300
+
+// WebFeed 解析后的RSS/Atom订阅
+type WebFeed struct {
+	Url          string         // 订阅地址
+	Title        string         // 订阅源标题
+	SiteUrl      string         // 订阅源站点地址
+	Etag         string         // 响应的ETag
+	LastModified string         // 响应的Last-Modified
+	NotModified  bool           // 条件请求返回304，Items为空
+	Items        []*WebFeedItem // 按发布时间从新到旧
+}
+
+// WebFeedItem 订阅中的一篇文章
+type WebFeedItem struct {
+	Guid      string    // 唯一标识(guid、id，没有时为链接)
+	Url       string    // 文章链接(规范化)
+	Title     string    // 标题
+	Published time.Time // 发布(或更新)时间，未知时为零值
+	Content   string    // 正文(或摘录)，Markdown格式
+}
+
+// IsExcerpt 内容过少，订阅只提供了摘录
+func (item *WebFeedItem) IsExcerpt() bool {
+	return wordsCount(item.Content) < FeedExcerptWords
+}
+
+// SortFeedItems 按发布时间从新到旧排序，时间未知的保持原顺序排在最后
+func SortFeedItems(items []*WebFeedItem) {
+	sort.SliceStable(items, func(i, j int) bool {
+		if items[j].Published.IsZero() {
+			return !items[i].Published.IsZero()
+		}
+		return items[i].Published.After(items[j].Published)
+	})
+}
+
+// Feed 订阅源，记录条件请求的ETag、Last-Modified
+type Feed struct {
+	ID           uint   `gorm:"id"`
+	CreatedAt    string `gorm:"created_at"`
+	UpdatedAt    string `gorm:"updated_at"`
+	Url          string `gorm:"url"`           // 订阅地址
+	Title        string `gorm:"title"`         // 订阅源标题
+	Etag         string `gorm:"etag"`          // 上次成功处理时响应的ETag
+	LastModified string `gorm:"last_modified"` // 上次成功处理时响应的Last-Modified
+	CheckedAt    string `gorm:"checked_at"`    // 最近一次检查时间
+}
+
+func (t Feed) TableName() string {
+	return "feeds"
+}
+
+// FeedEntry 已处理的订阅文章，按订阅地址+guid识别新文章
+type FeedEntry struct {
+	ID          uint            `gorm:"id"`
+	CreatedAt   string          `gorm:"created_at"`
+	UpdatedAt   string          `gorm:"updated_at"`
+	FeedUrl     string          `gorm:"feed_url"`    // 订阅地址
+	FeedTitle   string          `gorm:"feed_title"`  // 订阅源标题
+	Guid        string          `gorm:"guid"`        // 文章唯一标识
+	Url         string          `gorm:"url"`         // 文章链接
+	Title       string          `gorm:"title"`       // 文章标题
+	Published   string          `gorm:"published"`   // 发布时间，未知时为空
+	Status      FeedEntryStatus `gorm:"status"`      // 处理状态
+	Keywords    string          `gorm:"keywords"`    // 关键字
+	Summary     string          `gorm:"summary"`     // 摘要
+	Description string          `gorm:"description"` // 描述
+	Filepath    string          `gorm:"filepath"`    // 写入的摘要文章或摘录笔记路径
+}
+
+func (t FeedEntry) TableName() string {
+	return "feed_entries"
+}
+
+// FeedDigestFilename 每日订阅摘要文章文件名
+func FeedDigestFilename(day time.Time) string {
+	return day.Format("2006-01-02") + "-feed-digest.md"
+}
+
+// NewFeedDigest 生成每日订阅摘要文章：每篇新文章一节，包含原文链接、来源、摘要及关键字；
+// front matter的关键字取各文章关键字的并集，文章关闭AI摘要
+func NewFeedDigest(path string, day time.Time, entries []*FeedEntry) *BlogMD {
+	disabled := false
+	var titles, keywords []string
+	seen := make(map[string]bool)
+	var b strings.Builder
+	for _, entry := range entries {
+		titles = append(titles, entry.Title)
+		for _, kw := range strings.FieldsFunc(entry.Keywords, func(r rune) bool { return r == ',' || r == '，' }) {
+			if kw = strings.TrimSpace(kw); kw != "" && !seen[strings.ToLower(kw)] {
+				seen[strings.ToLower(kw)] = true
+				keywords = append(keywords, kw)
+			}
+		}
+
+		fmt.Fprintf(&b, "## [%s](%s)\n\n", escapeMDLinkText(entry.Title), entry.Url)
+		source := entry.FeedTitle
+		if published := entry.Published; published != "" {
+			if len(published) > len("2006-01-02") {
+				published = published[:len("2006-01-02")]
+			}
+			source = strings.TrimPrefix(source+" · "+published, " · ")
+		}
+		if source != "" {
+			fmt.Fprintf(&b, "> 来源：%s\n\n", source)
+		}
+		if entry.Summary != "" {
+			fmt.Fprintf(&b, "%s\n\n", entry.Summary)
+		}
+		if entry.Keywords != "" {
+			fmt.Fprintf(&b, "关键字：%s\n\n", entry.Keywords)
+		}
+	}
+
+	summary := fmt.Sprintf("%s 订阅更新 %d 篇：%s", day.Format("2006-01-02"), len(entries), strings.Join(titles, "；"))
+	header := &YamlHeader{
+		Title:       "订阅摘要 " + day.Format("2006-01-02"),
+		Date:        day.Format(time.RFC3339),
+		Keywords:    strings.Join(keywords, ","),
+		Summary:     summary,
+		Description: summary,
+		AISummary:   &disabled,
+	}
+	return &BlogMD{Filepath: path, MDHeader: header, MDContent: b.String()}
+}
@@ -0,0 +1,48 @@
+package entity
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSortFeedItems(t *testing.T) {
+	day := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	items := []*WebFeedItem{
+		{Guid: "unknown1"},
+		{Guid: "old", Published: day},
+		{Guid: "unknown2"},
+		{Guid: "new", Published: day.Add(time.Hour)},
+	}
+	SortFeedItems(items)
+	var guids []string
+	for _, item := range items {
+		guids = append(guids, item.Guid)
+	}
+	assert.Equal(t, []string{"new", "old", "unknown1", "unknown2"}, guids)
+}
+
+func TestWebFeedItem_IsExcerpt(t *testing.T) {
+	assert.True(t, (&WebFeedItem{Content: "Go 1.22 发布了，更多内容请阅读原文…"}).IsExcerpt())
+	assert.False(t, (&WebFeedItem{Content: strings.Repeat("word ", FeedExcerptWords)}).IsExcerpt())
+}
+
+func TestNewFeedDigest(t *testing.T) {
+	day := time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC)
+	md := NewFeedDigest("/blog/feeds/2024-05-01-feed-digest.md", day, []*FeedEntry{
+		{FeedTitle: "Go Blog", Url: "https://go.dev/blog/a", Title: "Range [over] func", Published: "2024-04-30 10:00:00",
+			Summary: "摘要A", Keywords: "Go,iterator"},
+		{Url: "https://example.com/b", Title: "B", Summary: "摘要B", Keywords: "go，Rust"},
+	})
+
+	assert.Equal(t, "订阅摘要 2024-05-01", md.MDHeader.Title)
+	assert.Equal(t, "2024-05-01T08:00:00Z", md.MDHeader.Date)
+	assert.Equal(t, "Go,iterator,Rust", md.MDHeader.Keywords)
+	assert.Equal(t, "2024-05-01 订阅更新 2 篇：Range [over] func；B", md.MDHeader.Summary)
+	assert.True(t, md.MDHeader.IsAISummaryDisabled())
+	assert.Equal(t, "## [Range \\[over\\] func](https://go.dev/blog/a)\n\n> 来源：Go Blog · 2024-04-30\n\n摘要A\n\n关键字：Go,iterator\n\n"+
+		"## [B](https://example.com/b)\n\n摘要B\n\n关键字：go，Rust\n\n", md.MDContent)
+	assert.Equal(t, "2024-05-01-feed-digest.md", FeedDigestFilename(day))
+}
@@ -0,0 +1,347 @@
+package entity
+
+import (
+	"encoding/json"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// InterlinkMatch 站内互链建议匹配到的目标文章信息
+type InterlinkMatch string
+
+const (
+	InterlinkMatchTitle   InterlinkMatch = "title"   // 正文中出现了目标文章标题
+	InterlinkMatchKeyword InterlinkMatch = "keyword" // 正文中出现了目标文章的关键字
+)
+
+var (
+	// interlinkProtectRegex 行内不能插入链接的片段：行内代码、链接、图片、引用式链接、html标签、短代码及url
+	interlinkProtectRegex = regexp.MustCompile("`[^`]*`" + `|!?\[[^\]]*\](?:\([^)]*\)|\[[^\]]*\])?|<[^>]+>|\{\{[<%].*?[>%]\}\}|https?://\S+`)
+
+	// setextUnderlineRegex Setext标题的下划线 ===、---
+	setextUnderlineRegex = regexp.MustCompile(`^\s{0,3}(=+|-+)\s*$`)
+)
+
+// InterlinkTarget 可被链接的站内文章，短语为标题及关键字
+type InterlinkTarget struct {
+	Path    string
+	Title   string
+	Url     string // 站内链接(permalink)
+	phrases []*interlinkPhrase
+}
+
+// interlinkPhrase 目标文章的标题或关键字，编译为不区分大小写的正则
+type interlinkPhrase struct {
+	text  string
+	match InterlinkMatch
+	re    *regexp.Regexp
+}
+
+// NewInterlinkTarget 构建可被链接的文章，过短的标题、关键字(英文少于3个字母、中文少于2个字)不参与匹配
+func NewInterlinkTarget(path, title, url, keywords string) *InterlinkTarget {
+	t := &InterlinkTarget{Path: path, Title: title, Url: url}
+	seen := make(map[string]bool)
+	add := func(text string, match InterlinkMatch) {
+		text = strings.TrimSpace(text)
+		key := strings.ToLower(text)
+		if seen[key] || !isInterlinkPhrase(text) {
+			return
+		}
+		seen[key] = true
+		t.phrases = append(t.phrases, &interlinkPhrase{
+			text:  text,
+			match: match,
+			re:    regexp.MustCompile(`(?i)` + regexp.QuoteMeta(text)),
+		})
+	}
+	add(title, InterlinkMatchTitle)
+	for _, kw := range strings.FieldsFunc(keywords, func(r rune) bool { return r == ',' || r == '，' }) {
+		add(kw, InterlinkMatchKeyword)
+	}
+	return t
+}
+
+// isInterlinkPhrase 短语足够长，避免 Go、AI 之类过于宽泛的词
+func isInterlinkPhrase(text string) bool {
+	count := utf8.RuneCountInString(text)
+	if count == len(text) {
+		return count >= 3
+	}
+	return count >= 2
+}
+
+// InterlinkSuggestion 站内互链建议：在source正文中anchor第一次出现处插入指向target的链接
+type InterlinkSuggestion struct {
+	Source     string         `json:"source"`               // 文章路径
+	Line       int            `json:"line"`                 // anchor所在行号
+	Anchor     string         `json:"anchor"`               // 正文中的锚文字
+	Target     string         `json:"target"`               // 目标文章路径
+	Url        string         `json:"url"`                  // 插入的站内链接
+	Match      InterlinkMatch `json:"match"`                // 匹配的是目标文章标题还是关键字
+	Similarity float64        `json:"similarity,omitempty"` // 两篇文章embedding的余弦相似度，未启用时为0
+}
+
+// InterlinkOptions 互链建议的筛选条件
+type InterlinkOptions struct {
+	MaxPerPost    int                // 每篇文章最多的建议数，<=0时不限制
+	MinSimilarity float64            // Similarities不为空时，相似度低于该值的目标文章不建议
+	Similarities  map[string]float64 // 目标文章路径 => 与source的相似度，为空时不按相似度筛选、排序
+}
+
+// FindInterlinks 在source正文(不含front matter)中查找其他文章的标题、关键字，为尚未链接(linked)的文章生成互链建议：
+// 只匹配代码块、标题、已有链接以外的文字；每个目标文章、每处文字只建议一次，按相似度、短语长度(更具体)、标题优先排序
+func FindInterlinks(source, content string, targets []*InterlinkTarget, linked map[string]bool, opts *InterlinkOptions) []*InterlinkSuggestion {
+	segments := linkableSegments(content)
+	type candidate struct {
+		suggestion *InterlinkSuggestion
+		start, end int
+	}
+	var candidates []*candidate
+	for _, target := range targets {
+		if target.Path == source || linked[target.Path] {
+			continue
+		}
+		similarity, hasSimilarity := 0.0, false
+		if opts.Similarities != nil {
+			similarity, hasSimilarity = opts.Similarities[target.Path]
+			if !hasSimilarity || similarity < opts.MinSimilarity {
+				continue
+			}
+		}
+
+		// 标题优先，其次取最长的关键字
+		var best *candidate
+		for _, phrase := range target.phrases {
+			start, end, ok := findLinkablePhrase(content, segments, phrase.re)
+			if !ok {
+				continue
+			}
+			if best != nil && (best.suggestion.Match == InterlinkMatchTitle ||
+				utf8.RuneCountInString(phrase.text) <= utf8.RuneCountInString(best.suggestion.Anchor)) {
+				continue
+			}
+			best = &candidate{
+				suggestion: &InterlinkSuggestion{
+					Source:     source,
+					Line:       strings.Count(content[:start], "\n") + 1,
+					Anchor:     content[start:end],
+					Target:     target.Path,
+					Url:        target.Url,
+					Match:      phrase.match,
+					Similarity: similarity,
+				},
+				start: start,
+				end:   end,
+			}
+		}
+		if best != nil {
+			candidates = append(candidates, best)
+		}
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		a, b := candidates[i].suggestion, candidates[j].suggestion
+		if a.Similarity != b.Similarity {
+			return a.Similarity > b.Similarity
+		}
+		if la, lb := utf8.RuneCountInString(a.Anchor), utf8.RuneCountInString(b.Anchor); la != lb {
+			return la > lb
+		}
+		return a.Match == InterlinkMatchTitle && b.Match != InterlinkMatchTitle
+	})
+
+	var accepted []*candidate
+	anchors := make(map[string]bool)
+	for _, c := range candidates {
+		if opts.MaxPerPost > 0 && len(accepted) >= opts.MaxPerPost {
+			break
+		}
+		if anchors[strings.ToLower(c.suggestion.Anchor)] {
+			continue
+		}
+		overlap := false
+		for _, a := range accepted {
+			if c.start < a.end && a.start < c.end {
+				overlap = true
+				break
+			}
+		}
+		if overlap {
+			continue
+		}
+		accepted = append(accepted, c)
+		anchors[strings.ToLower(c.suggestion.Anchor)] = true
+	}
+
+	// 按在正文中出现的顺序返回
+	sort.Slice(accepted, func(i, j int) bool { return accepted[i].start < accepted[j].start })
+	suggestions := make([]*InterlinkSuggestion, 0, len(accepted))
+	for _, c := range accepted {
+		suggestions = append(suggestions, c.suggestion)
+	}
+	return suggestions
+}
+
+// ApplyInterlinks 依次在正文中anchor第一次出现(代码块、标题、已有链接以外)处插入Markdown链接，
+// 返回新正文及实际插入的建议，正文中找不到anchor的建议跳过
+func ApplyInterlinks(content string, suggestions []*InterlinkSuggestion) (string, []*InterlinkSuggestion) {
+	var applied []*InterlinkSuggestion
+	for _, s := range suggestions {
+		if s.Anchor == "" || s.Url == "" {
+			continue
+		}
+		re := regexp.MustCompile(regexp.QuoteMeta(s.Anchor))
+		start, end, ok := findLinkablePhrase(content, linkableSegments(content), re)
+		if !ok {
+			continue
+		}
+		content = content[:start] + "[" + content[start:end] + "](" + s.Url + ")" + content[end:]
+		applied = append(applied, s)
+	}
+	return content, applied
+}
+
+// linkableSegment 正文中可以插入链接的片段[start, end)
+type linkableSegment struct {
+	start, end int
+}
+
+// linkableSegments 正文中可以插入链接的片段：跳过代码块(含缩进代码)、ATX及Setext标题，行内跳过interlinkProtectRegex的片段
+func linkableSegments(content string) []linkableSegment {
+	var segments []linkableSegment
+	lines := strings.SplitAfter(content, "\n")
+	offset := 0
+	fence := ""
+	prevParagraph := false // 缩进代码不能打断段落
+	for i, line := range lines {
+		lineStart := offset
+		offset += len(line)
+		text := strings.TrimRight(line, "\r\n")
+		trimmed := strings.TrimSpace(text)
+
+		if fence != "" {
+			if strings.HasPrefix(trimmed, fence) {
+				fence = ""
+			}
+			continue
+		}
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			fence, prevParagraph = trimmed[:3], false
+			continue
+		}
+		isCode := (strings.HasPrefix(text, "    ") || strings.HasPrefix(text, "\t")) && !prevParagraph
+		heading := strings.HasPrefix(trimmed, "#") || setextUnderlineRegex.MatchString(text)
+		if trimmed != "" && !heading && i+1 < len(lines) && setextUnderlineRegex.MatchString(strings.TrimRight(lines[i+1], "\r\n")) {
+			heading = true
+		}
+		prevParagraph = trimmed != "" && !isCode && !heading
+		if !prevParagraph {
+			continue
+		}
+
+		pos := 0
+		for _, loc := range interlinkProtectRegex.FindAllStringIndex(text, -1) {
+			if loc[0] > pos {
+				segments = append(segments, linkableSegment{lineStart + pos, lineStart + loc[0]})
+			}
+			pos = loc[1]
+		}
+		if pos < len(text) {
+			segments = append(segments, linkableSegment{lineStart + pos, lineStart + len(text)})
+		}
+	}
+	return segments
+}
+
+// findLinkablePhrase 在可插入链接的片段中查找短语第一次出现的位置，英文短语要求单词边界
+func findLinkablePhrase(content string, segments []linkableSegment, re *regexp.Regexp) (int, int, bool) {
+	for _, seg := range segments {
+		text := content[seg.start:seg.end]
+		for _, loc := range re.FindAllStringIndex(text, -1) {
+			if isWordBoundary(text, loc[0], loc[1]) {
+				return seg.start + loc[0], seg.start + loc[1], true
+			}
+		}
+	}
+	return 0, 0, false
+}
+
+// isWordBoundary 匹配的首尾为英文字母、数字时，前后不能紧接英文字母、数字
+func isWordBoundary(text string, start, end int) bool {
+	first, _ := utf8.DecodeRuneInString(text[start:])
+	last, _ := utf8.DecodeLastRuneInString(text[:end])
+	if isASCIIWord(first) && start > 0 {
+		if prev, _ := utf8.DecodeLastRuneInString(text[:start]); isASCIIWord(prev) {
+			return false
+		}
+	}
+	if isASCIIWord(last) && end < len(text) {
+		if next, _ := utf8.DecodeRuneInString(text[end:]); isASCIIWord(next) {
+			return false
+		}
+	}
+	return true
+}
+
+func isASCIIWord(r rune) bool {
+	return r == '_' || r >= '0' && r <= '9' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z'
+}
+
+// ArticleEmbedding 文章的embedding缓存，embedding文本或模型变化时重新生成
+type ArticleEmbedding struct {
+	ID        uint   `gorm:"id"`
+	CreatedAt string `gorm:"created_at"`
+	UpdatedAt string `gorm:"updated_at"`
+	Path      string `gorm:"path"`      // 文章路径
+	Model     string `gorm:"model"`     // embedding模型
+	TextHash  string `gorm:"text_hash"` // embedding文本的sha256
+	Vector    string `gorm:"vector"`    // json数组格式的向量
+}
+
+func (t ArticleEmbedding) TableName() string {
+	return "article_embeddings"
+}
+
+// Values 解析json格式的向量，格式错误时返回nil
+func (t *ArticleEmbedding) Values() []float32 {
+	var values []float32
+	if err := json.Unmarshal([]byte(t.Vector), &values); err != nil {
+		return nil
+	}
+	return values
+}
+
+// InterlinkEmbeddingText 用于生成文章embedding的文本：标题、关键字及摘要(没有摘要时取精简正文的前2000个字符)
+func InterlinkEmbeddingText(md *BlogMD) string {
+	body := md.MDHeader.Summary
+	if body == "" {
+		body = md.MDHeader.Description
+	}
+	if body == "" {
+		body = MinimiseContent(md.MDContent)
+		if runes := []rune(body); len(runes) > 2000 {
+			body = string(runes[:2000])
+		}
+	}
+	return strings.Join(strings.Fields(md.MDHeader.Title+"\n"+md.MDHeader.Keywords+"\n"+body), " ")
+}
+
+// CosineSimilarity 两个向量的余弦相似度，长度不同或为零向量时返回0
+func CosineSimilarity(a, b []float32) float64 {
+	if len(a) != len(b) || len(a) == 0 {
+		return 0
+	}
+	var dot, na, nb float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		na += float64(a[i]) * float64(a[i])
+		nb += float64(b[i]) * float64(b[i])
+	}
+	if na == 0 || nb == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(na) * math.Sqrt(nb))
+}
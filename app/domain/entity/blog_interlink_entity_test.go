@@ -0,0 +1,70 @@
+package entity
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindInterlinks(t *testing.T) {
+	content := "# Go channel详解\n\n" +
+		"Title\n=====\n\n" +
+		"本文介绍 `go channel` 以及 [Go Channel](/posts/channel/) 的用法。\n" +
+		"```go\n// goroutine调度\n```\n" +
+		"    goroutine调度 in code\n\n" +
+		"select语句配合goroutine调度使用，详见 goroutines 和 Goroutine调度，以及 rust入门。\n" +
+		"MySQL索引优化 https://example.com/MySQL索引优化\n"
+	targets := []*InterlinkTarget{
+		NewInterlinkTarget("posts/go.md", "Go channel详解", "/posts/go/", "go,channel"),
+		NewInterlinkTarget("posts/sched.md", "GMP模型", "/posts/sched/", "goroutine调度,调度"),
+		NewInterlinkTarget("posts/goroutine.md", "Goroutine", "/posts/goroutine/", "Go"),
+		NewInterlinkTarget("posts/rust.md", "Rust入门", "/posts/rust/", ""),
+		NewInterlinkTarget("posts/mysql.md", "MySQL索引优化", "/posts/mysql/", ""),
+		NewInterlinkTarget("posts/linked.md", "select语句", "/posts/linked/", ""),
+	}
+	linked := map[string]bool{"posts/linked.md": true}
+
+	got := FindInterlinks("posts/go.md", content, targets, linked, &InterlinkOptions{})
+	assert.Equal(t, []*InterlinkSuggestion{
+		{Source: "posts/go.md", Line: 12, Anchor: "goroutine调度", Target: "posts/sched.md", Url: "/posts/sched/", Match: InterlinkMatchKeyword},
+		{Source: "posts/go.md", Line: 12, Anchor: "rust入门", Target: "posts/rust.md", Url: "/posts/rust/", Match: InterlinkMatchTitle},
+		{Source: "posts/go.md", Line: 13, Anchor: "MySQL索引优化", Target: "posts/mysql.md", Url: "/posts/mysql/", Match: InterlinkMatchTitle},
+	}, got)
+
+	// 按相似度筛选、排序后截取
+	got = FindInterlinks("posts/go.md", content, targets, linked, &InterlinkOptions{
+		MaxPerPost:    1,
+		MinSimilarity: 0.8,
+		Similarities:  map[string]float64{"posts/sched.md": 0.85, "posts/rust.md": 0.5, "posts/mysql.md": 0.9},
+	})
+	assert.Len(t, got, 1)
+	assert.Equal(t, "posts/mysql.md", got[0].Target)
+	assert.Equal(t, 0.9, got[0].Similarity)
+}
+
+func TestApplyInterlinks(t *testing.T) {
+	content := "## goroutine调度\n\n```\ngoroutine调度\n```\n见 `goroutine调度` 与 goroutine调度，再次 goroutine调度。\nRust入门\n"
+	suggestions := []*InterlinkSuggestion{
+		{Anchor: "goroutine调度", Url: "/posts/sched/"},
+		{Anchor: "不存在", Url: "/posts/none/"},
+		{Anchor: "Rust入门", Url: "/posts/rust/"},
+	}
+	got, applied := ApplyInterlinks(content, suggestions)
+	assert.Equal(t, "## goroutine调度\n\n```\ngoroutine调度\n```\n见 `goroutine调度` 与 [goroutine调度](/posts/sched/)，再次 goroutine调度。\n[Rust入门](/posts/rust/)\n", got)
+	assert.Equal(t, []*InterlinkSuggestion{suggestions[0], suggestions[2]}, applied)
+
+	// 已插入的链接不会被重复插入
+	again, applied := ApplyInterlinks(got, suggestions[:1])
+	assert.Equal(t, "## goroutine调度\n\n```\ngoroutine调度\n```\n见 `goroutine调度` 与 [goroutine调度](/posts/sched/)，再次 [goroutine调度](/posts/sched/)。\n[Rust入门](/posts/rust/)\n", again)
+	assert.Len(t, applied, 1)
+}
+
+func TestCosineSimilarity(t *testing.T) {
+	assert.InDelta(t, 1.0, CosineSimilarity([]float32{1, 2}, []float32{2, 4}), 1e-9)
+	assert.InDelta(t, 0.0, CosineSimilarity([]float32{1, 0}, []float32{0, 1}), 1e-9)
+	assert.Equal(t, 0.0, CosineSimilarity([]float32{1}, []float32{1, 2}))
+	assert.Equal(t, 0.0, CosineSimilarity([]float32{0, 0}, []float32{1, 2}))
+
+	e := &ArticleEmbedding{Vector: "[0.5,-1]"}
+	assert.Equal(t, []float32{0.5, -1}, e.Values())
+}
@@ -0,0 +1,67 @@
+package entity
+
+// JobStatus 摘要任务状态
+type JobStatus string
+
+const (
+	JobPending   JobStatus = "pending"   // 等待执行
+	JobRunning   JobStatus = "running"   // 执行中
+	JobSucceeded JobStatus = "succeeded" // 全部文件处理完成
+	JobFailed    JobStatus = "failed"    // 处理完成，但有文件失败
+)
+
+// JobItemStatus 任务内单个文件的处理状态
+type JobItemStatus string
+
+const (
+	JobItemPending JobItemStatus = "pending" // 等待处理(包括重启前未处理完的)
+	JobItemRunning JobItemStatus = "running" // 处理中
+	JobItemDone    JobItemStatus = "done"    // 已更新
+	JobItemSkipped JobItemStatus = "skipped" // 无需更新
+	JobItemFailed  JobItemStatus = "failed"  // 处理失败
+)
+
+// JobItemReasonPendingReview 审核模式下已处理完的文件的Reason：AI摘要存为待审核版本，未写入文章
+const JobItemReasonPendingReview = "summary pending review"
+
+// SummaryJob 摘要任务，一次对指定路径(文件或目录)，或一次git推送变更文件的摘要生成
+type SummaryJob struct {
+	ID         uint      `gorm:"id"`
+	CreatedAt  string    `gorm:"created_at"`
+	UpdatedAt  string    `gorm:"updated_at"`
+	FinishedAt string    `gorm:"finished_at"`
+	Path       string    `gorm:"path"`        // 任务路径
+	BlogRoot   string    `gorm:"blog_root"`   // 任务所属的blog目录，任务结束后按此汇总栏目摘要
+	Ref        string    `gorm:"ref"`         // 触发任务的git提交(推送webhook等)，按路径扫描的任务为空
+	HeadCommit string    `gorm:"head_commit"` // 提交任务时blog仓库的HEAD，增量模式以上次成功任务的提交为起点
+	Status     JobStatus `gorm:"status"`      // 任务状态
+	Total      int       `gorm:"total"`       // 文件总数
+	Done       int       `gorm:"done"`        // 已更新数
+	Skipped    int       `gorm:"skipped"`     // 跳过数
+	Failed     int       `gorm:"failed"`      // 失败数
+}
+
+func (t SummaryJob) TableName() string {
+	return "jobs"
+}
+
+// IsFinished 任务是否已结束
+func (t *SummaryJob) IsFinished() bool {
+	return t.Status == JobSucceeded || t.Status == JobFailed
+}
+
+// SummaryJobItem 任务内的单个文件
+type SummaryJobItem struct {
+	ID        uint          `gorm:"id"`
+	CreatedAt string        `gorm:"created_at"`
+	UpdatedAt string        `gorm:"updated_at"`
+	JobID     uint          `gorm:"job_id"`
+	Path      string        `gorm:"path"`     // 文件路径
+	Status    JobItemStatus `gorm:"status"`   // 处理状态
+	Reason    string        `gorm:"reason"`   // 跳过原因或失败错误
+	Attempts  int           `gorm:"attempts"` // 处理次数
+}
+
+func (t SummaryJobItem) TableName() string {
+	return "job_items"
+}
@@ -0,0 +1,73 @@
+package entity
+
+import (
+	"context"
+	"time"
+
+	"github.com/hold7techs/go-shim/shim"
+)
+
+// JobEventType 摘要任务进度事件类型
+type JobEventType string
+
+const (
+	JobEventQueued    JobEventType = "queued"     // 文件等待处理
+	JobEventAIRequest JobEventType = "ai_request" // 开始请求AI
+	JobEventTokens    JobEventType = "tokens"     // AI请求消耗的token
+	JobEventWritten   JobEventType = "written"    // 已写回文件
+	JobEventPending   JobEventType = "pending"    // 审核模式：AI摘要已存为待审核版本，未写入文章
+	JobEventSkipped   JobEventType = "skipped"    // 跳过，Reason为原因
+	JobEventFailed    JobEventType = "failed"     // 失败，Error为错误信息
+	JobEventSummary   JobEventType = "summary"    // 任务结束汇总，事件流的最后一个事件
+)
+
+// JobEvent 摘要任务进度事件
+type JobEvent struct {
+	JobID  uint
+	Type   JobEventType
+	Path   string
+	Model  string      // ai_request: 请求的模型
+	Tokens int         // tokens: 本次请求消耗的token
+	Reason string      // skipped: 跳过原因
+	Error  string      // failed: 错误信息
+	Job    *SummaryJob // summary: 任务结束时的统计
+	Time   string
+}
+
+// NewJobEvent 初始一个进度事件
+func NewJobEvent(typ JobEventType) *JobEvent {
+	return &JobEvent{Type: typ, Time: time.Now().Format(shim.StdDateTimeLayout)}
+}
+
+// JobItemEvent 按文件的处理状态转换成事件，用于订阅时回放已有进度
+func JobItemEvent(item *SummaryJobItem) *JobEvent {
+	event := &JobEvent{JobID: item.JobID, Path: item.Path, Time: item.UpdatedAt}
+	switch item.Status {
+	case JobItemDone:
+		event.Type = JobEventWritten
+		if item.Reason == JobItemReasonPendingReview {
+			event.Type = JobEventPending
+		}
+	case JobItemSkipped:
+		event.Type, event.Reason = JobEventSkipped, item.Reason
+	case JobItemFailed:
+		event.Type, event.Error = JobEventFailed, item.Reason
+	default:
+		event.Type = JobEventQueued
+	}
+	return event
+}
+
+type jobEventEmitterKey struct{}
+
+// WithJobEventEmitter 在ctx中设置事件回调，下游(例如AI服务)通过EmitJobEvent上报进度
+func WithJobEventEmitter(ctx context.Context, emit func(event *JobEvent)) context.Context {
+	return context.WithValue(ctx, jobEventEmitterKey{}, emit)
+}
+
+// EmitJobEvent 上报进度事件，ctx中未设置回调时忽略
+func EmitJobEvent(ctx context.Context, event *JobEvent) {
+	if emit, ok := ctx.Value(jobEventEmitterKey{}).(func(event *JobEvent)); ok {
+		emit(event)
+	}
+}
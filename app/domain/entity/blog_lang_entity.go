@@ -0,0 +1,156 @@
+package entity
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	LangZH = "zh" // 中文
+	LangEN = "en" // 英文
+	LangJA = "ja" // 日文
+	LangKO = "ko" // 韩文
+)
+
+// LangOutputType 多语言摘要的输出方式
+type LangOutputType string
+
+const (
+	LangOutputKeys    LangOutputType = "keys"    // 写入当前文件的 summary_en、description_en、keywords_en 字段
+	LangOutputSibling LangOutputType = "sibling" // 写入Hugo多语言兄弟文件，例如 post.en.md
+)
+
+// LangSummaries 多语言摘要结果
+type LangSummaries struct {
+	Output    LangOutputType             `json:"output"`
+	Summaries map[string]*ArticleSummary `json:"summaries"` // lang => 摘要
+}
+
+// DetectLang 基于字符分布检测内容的主要语言，汉字占比较高认为是中文，其他默认英文
+func DetectLang(content string) string {
+	var han, kana, hangul, latin int
+	for _, r := range content {
+		switch {
+		case unicode.Is(unicode.Hiragana, r), unicode.Is(unicode.Katakana, r):
+			kana++
+		case unicode.Is(unicode.Hangul, r):
+			hangul++
+		case unicode.Is(unicode.Han, r):
+			han++
+		case unicode.Is(unicode.Latin, r):
+			latin++
+		}
+	}
+
+	switch {
+	case kana > 0 && kana*5 >= han: // 日文夹杂大量汉字，假名占比达到一定程度即认为是日文
+		return LangJA
+	case hangul > han && hangul*10 >= latin:
+		return LangKO
+	case han > 0 && han*10 >= latin: // 英文按字母计数，大约折算成单词后再比较
+		return LangZH
+	default:
+		return LangEN
+	}
+}
+
+// Hugo多语言文件后缀，例如 .en、.zh-cn
+var langSuffixRegex = regexp.MustCompile(`^\.[a-z]{2}(-[a-zA-Z]{2,4})?$`)
+
+// LangKey 多语言字段名，例如 summary + en => summary_en
+func LangKey(key, lang string) string {
+	return fmt.Sprintf("%s_%s", key, lang)
+}
+
+// LangSiblingPath Hugo多语言兄弟文件路径，例如 post.md => post.en.md，index.zh.md => index.en.md
+func LangSiblingPath(path, lang string) string {
+	dir, base := filepath.Split(path)
+	name := strings.TrimSuffix(base, filepath.Ext(base))
+	if ext := filepath.Ext(name); langSuffixRegex.MatchString(ext) { // 已带语言后缀
+		name = strings.TrimSuffix(name, ext)
+	}
+	return filepath.Join(dir, fmt.Sprintf("%s.%s.md", name, lang))
+}
+
+// SetLangSummary 将指定语言的摘要写入YamlHeader的多语言字段
+func (y *YamlHeader) SetLangSummary(lang string, summary *ArticleSummary) {
+	if y.Extra == nil {
+		y.Extra = make(map[string]interface{})
+	}
+	y.Extra[LangKey("summary", lang)] = summary.Summary
+	y.Extra[LangKey("description", lang)] = summary.Description
+	y.Extra[LangKey("keywords", lang)] = summary.Keywords
+}
+
+// GetLangSummary 读取YamlHeader中指定语言的摘要，不存在时返回nil
+func (y *YamlHeader) GetLangSummary(lang string) *ArticleSummary {
+	summary := &ArticleSummary{
+		Summary:     y.extraString(LangKey("summary", lang)),
+		Description: y.extraString(LangKey("description", lang)),
+		Keywords:    y.extraString(LangKey("keywords", lang)),
+	}
+	if summary.Summary == "" && summary.Description == "" && summary.Keywords == "" {
+		return nil
+	}
+	return summary
+}
+
+// langSummaryKeyRegex 多语言摘要字段名，例如 summary_en、keywords_zh-cn
+var langSummaryKeyRegex = regexp.MustCompile(`^(summary|description|keywords)_[a-z]{2}(-[a-zA-Z]{2,4})?$`)
+
+// ClearLangSummaries 清除YamlHeader中所有语言的摘要字段，用于译文副本
+func (y *YamlHeader) ClearLangSummaries() {
+	for key := range y.Extra {
+		if langSummaryKeyRegex.MatchString(key) {
+			delete(y.Extra, key)
+		}
+	}
+}
+
+func (y *YamlHeader) extraString(key string) string {
+	if v, ok := y.Extra[key].(string); ok {
+		return v
+	}
+	return ""
+}
+
+// ApplyLangSummaries 按输出方式写入多语言摘要，sibling模式下仅更新已存在的兄弟文件，
+// 兄弟文件不存在时回退为写入原文front matter的多语言字段
+func (md *BlogMD) ApplyLangSummaries(ls *LangSummaries) error {
+	if ls == nil {
+		return nil
+	}
+
+	for lang, summary := range ls.Summaries {
+		switch ls.Output {
+		case LangOutputSibling:
+			siblingPath := LangSiblingPath(md.Filepath, lang)
+			if _, err := os.Stat(siblingPath); err != nil {
+				// 兄弟文件不存在时不做创建，交由翻译流程生成，摘要先写入原文避免丢失
+				log.Warnf("lang sibling md[%s] not exist, write %s summary into md[%s] front matter", siblingPath, lang, md.Filepath)
+				md.MDHeader.SetLangSummary(lang, summary)
+				continue
+			}
+			sibling, err := NewBlogMD(siblingPath)
+			if err != nil {
+				return err
+			}
+			sibling.MDHeader.Summary = summary.Summary
+			sibling.MDHeader.Description = summary.Description
+			sibling.MDHeader.Keywords = summary.Keywords
+			if err := sibling.ReplaceWithNewYamlHeader(); err != nil {
+				return err
+			}
+		default:
+			md.MDHeader.SetLangSummary(lang, summary)
+		}
+	}
+
+	return nil
+}
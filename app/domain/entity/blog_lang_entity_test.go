@@ -0,0 +1,92 @@
+package entity
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDetectLang(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{"zh", "这是一篇关于Go语言并发编程的文章，介绍了goroutine和channel的使用", LangZH},
+		{"en", "This article introduces goroutines and channels in Go, 并发.", LangEN},
+		{"ja", "これはGo言語の並行処理についての記事です", LangJA},
+		{"ko", "이것은 Go 언어 동시성에 관한 글입니다", LangKO},
+		{"empty", "", LangEN},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, DetectLang(tt.content))
+		})
+	}
+}
+
+func TestLangSiblingPath(t *testing.T) {
+	tests := []struct {
+		path string
+		lang string
+		want string
+	}{
+		{"/blog/posts/go.md", "en", "/blog/posts/go.en.md"},
+		{"/blog/posts/bundle/index.zh.md", "en", "/blog/posts/bundle/index.en.md"},
+		{"/blog/posts/go.zh-cn.md", "en", "/blog/posts/go.en.md"},
+		{"/blog/posts/go.1.21.md", "ja", "/blog/posts/go.1.21.ja.md"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			assert.Equal(t, tt.want, LangSiblingPath(tt.path, tt.lang))
+		})
+	}
+}
+
+func TestBlogMD_ApplyLangSummaries(t *testing.T) {
+	dir := t.TempDir()
+	mdFile := filepath.Join(dir, "post.md")
+	err := os.WriteFile(mdFile, []byte("---\ntitle: 测试\ncover: a.png\n---\n\n正文内容"), 0644)
+	assert.NoError(t, err)
+
+	md, err := NewBlogMD(mdFile)
+	assert.NoError(t, err)
+	assert.Equal(t, LangZH, md.Lang)
+
+	// keys模式，写入summary_en等字段，同时保留未声明的cover字段
+	err = md.ApplyLangSummaries(&LangSummaries{
+		Output:    LangOutputKeys,
+		Summaries: map[string]*ArticleSummary{"en": {Summary: "S", Description: "D", Keywords: "K"}},
+	})
+	assert.NoError(t, err)
+	assert.NoError(t, md.ReplaceWithNewYamlHeader())
+
+	content, err := os.ReadFile(mdFile)
+	assert.NoError(t, err)
+	assert.True(t, strings.Contains(string(content), "summary_en: S"))
+	assert.True(t, strings.Contains(string(content), "cover: a.png"))
+
+	reload, err := NewBlogMD(mdFile)
+	assert.NoError(t, err)
+	assert.Equal(t, &ArticleSummary{Summary: "S", Description: "D", Keywords: "K"}, reload.MDHeader.GetLangSummary("en"))
+
+	// sibling模式，兄弟文件不存在时回退写入原文，存在时更新
+	ls := &LangSummaries{
+		Output:    LangOutputSibling,
+		Summaries: map[string]*ArticleSummary{"en": {Summary: "ES", Description: "ED", Keywords: "EK"}},
+	}
+	assert.NoError(t, md.ApplyLangSummaries(ls))
+	_, err = os.Stat(filepath.Join(dir, "post.en.md"))
+	assert.True(t, os.IsNotExist(err))
+	assert.Equal(t, &ArticleSummary{Summary: "ES", Description: "ED", Keywords: "EK"}, md.MDHeader.GetLangSummary("en"))
+
+	err = os.WriteFile(filepath.Join(dir, "post.en.md"), []byte("---\ntitle: Test\n---\n\nbody"), 0644)
+	assert.NoError(t, err)
+	assert.NoError(t, md.ApplyLangSummaries(ls))
+	sibling, err := NewBlogMD(filepath.Join(dir, "post.en.md"))
+	assert.NoError(t, err)
+	assert.Equal(t, "ES", sibling.MDHeader.Summary)
+}
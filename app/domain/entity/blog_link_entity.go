@@ -0,0 +1,154 @@
+package entity
+
+import (
+	"net/url"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+var (
+	// mdLinkRegex markdown链接 [text](target "title")，!开头的为图片
+	mdLinkRegex = regexp.MustCompile(`(!?)\[[^\]]*\]\(\s*<?([^)\s>]+)>?(?:\s+"[^"]*")?\s*\)`)
+
+	// hugoRefRegex Hugo的 {{< ref "path" >}}、{{% relref "path" %}} 短代码
+	hugoRefRegex = regexp.MustCompile(`\{\{[<%]\s*(?:rel)?ref\s+"([^"]+)"\s*[>%]\}\}`)
+)
+
+// ExtractMDLinks 提取正文(代码块外)中的链接目标，不含图片
+func ExtractMDLinks(content string) []string {
+	content = MinimiseContent(content)
+	var links []string
+	for _, match := range mdLinkRegex.FindAllStringSubmatch(content, -1) {
+		if match[1] == "" {
+			links = append(links, match[2])
+		}
+	}
+	for _, match := range hugoRefRegex.FindAllStringSubmatch(content, -1) {
+		links = append(links, match[1])
+	}
+	return links
+}
+
+// LinkIndex 站内链接索引，将文章中的链接解析为目标文章的路径
+type LinkIndex struct {
+	host        string
+	contentDir  string
+	byPath      map[string]string // 文章路径
+	byPermalink map[string]string // permalink、别名 => 文章路径
+	byName      map[string]string // 文件名、slug => 文章路径，重名时为空
+}
+
+// NewLinkIndex 基于站点地址、permalink模板和Hugo content目录构建文章的链接索引
+func NewLinkIndex(baseURL, permalink, contentDir string, articles []*BlogArticle) *LinkIndex {
+	idx := &LinkIndex{
+		contentDir:  filepath.Clean(contentDir),
+		byPath:      make(map[string]string),
+		byPermalink: make(map[string]string),
+		byName:      make(map[string]string),
+	}
+	if u, err := url.Parse(baseURL); err == nil {
+		idx.host = u.Host
+	}
+
+	addName := func(name, path string) {
+		if name == "" {
+			return
+		}
+		if owner, ok := idx.byName[name]; ok && owner != path {
+			idx.byName[name] = ""
+			return
+		}
+		idx.byName[name] = path
+	}
+	for _, article := range articles {
+		idx.byPath[article.Path] = article.Path
+		idx.byPermalink[normalizeLinkPath(Permalink(permalink, contentDir, article))] = article.Path
+		for _, alias := range jsonStringSlice(article.Aliases) {
+			idx.byPermalink[normalizeLinkPath(alias)] = article.Path
+		}
+		addName(linkFileName(article.Path), article.Path)
+		addName(article.Slug, article.Path)
+	}
+	return idx
+}
+
+// Resolve 解析source文章中的链接target，返回目标文章路径，站外链接或无法解析时返回空
+func (idx *LinkIndex) Resolve(source, target string) string {
+	target = strings.TrimSpace(target)
+	if i := strings.IndexAny(target, "#?"); i >= 0 {
+		target = target[:i]
+	}
+	if target == "" {
+		return ""
+	}
+
+	u, err := url.Parse(target)
+	if err != nil {
+		return ""
+	}
+	if u.Scheme != "" || u.Host != "" {
+		if (u.Scheme != "http" && u.Scheme != "https" && u.Scheme != "") || u.Host != idx.host || idx.host == "" {
+			return ""
+		}
+		target = u.Path
+	}
+
+	// 相对或content目录下的md文件(含ref短代码)
+	if strings.HasSuffix(target, ".md") {
+		candidates := []string{filepath.Join(idx.contentDir, filepath.FromSlash(target))}
+		if !strings.HasPrefix(target, "/") {
+			candidates = append([]string{filepath.Join(filepath.Dir(source), filepath.FromSlash(target))}, candidates...)
+		}
+		for _, candidate := range candidates {
+			if p, ok := idx.byPath[candidate]; ok {
+				return p
+			}
+		}
+		return idx.byName[linkFileName(target)]
+	}
+
+	// permalink、别名，最后按路径最后一段匹配文件名或slug
+	if p, ok := idx.byPermalink[normalizeLinkPath(target)]; ok {
+		return p
+	}
+	return idx.byName[path.Base(strings.TrimSuffix(target, "/"))]
+}
+
+// InboundLinks 统计每篇文章被其他文章链接的次数(同一来源只计一次)，contents为文章路径 => 正文
+func (idx *LinkIndex) InboundLinks(contents map[string]string) map[string]int {
+	counts := make(map[string]int)
+	for source, content := range contents {
+		linked := make(map[string]bool)
+		for _, link := range ExtractMDLinks(content) {
+			if target := idx.Resolve(source, link); target != "" && target != source && !linked[target] {
+				linked[target] = true
+				counts[target]++
+			}
+		}
+	}
+	return counts
+}
+
+// normalizeLinkPath 统一为 /a/b/ 形式
+func normalizeLinkPath(p string) string {
+	p = "/" + strings.Trim(p, "/") + "/"
+	if p == "//" {
+		return "/"
+	}
+	return p
+}
+
+// linkFileName 文章的文件名(不含扩展名、语言后缀)，page bundle取目录名
+func linkFileName(p string) string {
+	p = filepath.ToSlash(p)
+	name := strings.TrimSuffix(path.Base(p), path.Ext(p))
+	if ext := path.Ext(name); langSuffixRegex.MatchString(ext) {
+		name = strings.TrimSuffix(name, ext)
+	}
+	if name == "index" || name == "_index" {
+		name = path.Base(path.Dir(p))
+	}
+	return name
+}
@@ -0,0 +1,36 @@
+package entity
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExtractMDLinks(t *testing.T) {
+	content := "see [rust](rust.md \"Rust\") and ![img](cover.png)\n" +
+		"```go\n// [code](skip.md)\n```\n" +
+		`{{< relref "posts/go.md" >}} [site](https://tkstorm.com/r/#top)`
+	assert.Equal(t, []string{"rust.md", "https://tkstorm.com/r/#top", "posts/go.md"}, ExtractMDLinks(content))
+}
+
+func TestLinkIndex_InboundLinks(t *testing.T) {
+	idx := NewLinkIndex("https://tkstorm.com", "/:sections/:slug/", "/c", []*BlogArticle{
+		{Path: "/c/posts/go.md", Slug: "golang-intro"},
+		{Path: "/c/posts/rust.md", Aliases: `["/r/"]`},
+		{Path: "/c/posts/bundle/index.md"},
+	})
+
+	assert.Equal(t, "/c/posts/rust.md", idx.Resolve("/c/posts/go.md", "rust.md"))
+	assert.Equal(t, "/c/posts/go.md", idx.Resolve("/c/posts/rust.md", "posts/go.md"))
+	assert.Equal(t, "/c/posts/go.md", idx.Resolve("/c/posts/rust.md", "/posts/golang-intro/?from=rss"))
+	assert.Equal(t, "/c/posts/bundle/index.md", idx.Resolve("/c/posts/go.md", "/posts/bundle/"))
+	assert.Equal(t, "", idx.Resolve("/c/posts/go.md", "https://example.com/posts/bundle/"))
+	assert.Equal(t, "", idx.Resolve("/c/posts/go.md", "#top"))
+
+	counts := idx.InboundLinks(map[string]string{
+		"/c/posts/go.md":           "[rust](rust.md) [again](https://tkstorm.com/r/) [ext](https://example.com/posts/bundle/)",
+		"/c/posts/rust.md":         `{{< ref "posts/go.md" >}} [b](/posts/bundle/) [self](/r/)`,
+		"/c/posts/bundle/index.md": "[go](/posts/golang-intro/#top) [rust](../rust.md)",
+	})
+	assert.Equal(t, map[string]int{"/c/posts/go.md": 2, "/c/posts/rust.md": 2, "/c/posts/bundle/index.md": 1}, counts)
+}
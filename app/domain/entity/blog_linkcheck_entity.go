@@ -0,0 +1,407 @@
+package entity
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hold7techs/go-shim/shim"
+)
+
+// LinkIssueKind 链接检查发现的问题类型
+type LinkIssueKind string
+
+const (
+	LinkIssueBroken       LinkIssueKind = "broken"        // 站内链接无法解析到文章、栏目或文件
+	LinkIssueMissingImage LinkIssueKind = "missing_image" // 引用的图片文件不存在
+	LinkIssueExternal     LinkIssueKind = "external"      // 站外链接请求失败
+	LinkIssueOrphanImage  LinkIssueKind = "orphan_image"  // 没有被任何文章引用的图片
+)
+
+var (
+	// mdImageRefRegex markdown图片 ![alt](src "title")
+	mdImageRefRegex = regexp.MustCompile(`!\[([^\]]*)\]\(\s*<?([^)\s>]+)>?(?:\s+"[^"]*")?\s*\)`)
+
+	// mdLinkRefRegex markdown链接 [text](target "title")，图片已替换为alt文字
+	mdLinkRefRegex = regexp.MustCompile(`\[([^\]]*)\]\(\s*<?([^)\s>]+)>?(?:\s+"[^"]*")?\s*\)`)
+
+	// mdLinkDefRegex 引用式链接的定义 [id]: target "title"
+	mdLinkDefRegex = regexp.MustCompile(`^\s{0,3}\[([^\]]+)\]:\s*<?([^\s>]+)>?`)
+
+	// htmlImgRegex 正文中的html图片 <img src="...">
+	htmlImgRegex = regexp.MustCompile(`(?i)<img\s[^>]*?src\s*=\s*["']([^"']+)["']`)
+
+	// mdInlineCodeRegex 行内代码
+	mdInlineCodeRegex = regexp.MustCompile("`[^`\n]*`")
+)
+
+// imageExts 视为图片的文件扩展名
+var imageExts = map[string]bool{
+	".png": true, ".jpg": true, ".jpeg": true, ".gif": true, ".webp": true, ".svg": true, ".avif": true, ".bmp": true,
+}
+
+// IsImageFile 按扩展名判断是否为图片
+func IsImageFile(p string) bool {
+	if i := strings.IndexAny(p, "?#"); i >= 0 {
+		p = p[:i]
+	}
+	return imageExts[strings.ToLower(path.Ext(p))]
+}
+
+// MDLinkRef 文章中的一处链接或图片引用
+type MDLinkRef struct {
+	Target string // 链接目标
+	Text   string // 链接文字或图片alt
+	Line   int    // 所在行号(从1开始)，front matter中的引用为0
+	Image  bool   // 图片引用
+}
+
+// ExtractMDLinkRefs 按行提取文件内容中的markdown链接、图片、引用式链接定义、html图片及Hugo的ref/relref短代码，
+// 跳过开头的front matter、代码块及行内代码，行号按整个文件计算
+func ExtractMDLinkRefs(content string) []*MDLinkRef {
+	lines := strings.Split(content, "\n")
+	start := 0
+	if len(lines) > 0 && strings.TrimSpace(lines[0]) == "---" {
+		for i := 1; i < len(lines); i++ {
+			if strings.TrimSpace(lines[i]) == "---" {
+				start = i + 1
+				break
+			}
+		}
+	}
+
+	var refs []*MDLinkRef
+	fence := ""
+	for i := start; i < len(lines); i++ {
+		line := lines[i]
+		trimmed := strings.TrimSpace(line)
+		if fence != "" {
+			if strings.HasPrefix(trimmed, fence) {
+				fence = ""
+			}
+			continue
+		}
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			fence = trimmed[:3]
+			continue
+		}
+
+		lineNo := i + 1
+		line = mdInlineCodeRegex.ReplaceAllString(line, "")
+		if match := mdLinkDefRegex.FindStringSubmatch(line); match != nil {
+			refs = append(refs, &MDLinkRef{Target: match[2], Text: match[1], Line: lineNo, Image: IsImageFile(match[2])})
+			continue
+		}
+
+		// 先提取图片并替换为alt文字，避免 [![alt](src)](target) 被当作链接到图片
+		for _, match := range mdImageRefRegex.FindAllStringSubmatch(line, -1) {
+			refs = append(refs, &MDLinkRef{Target: match[2], Text: match[1], Line: lineNo, Image: true})
+		}
+		line = mdImageRefRegex.ReplaceAllString(line, "$1")
+		for _, match := range mdLinkRefRegex.FindAllStringSubmatch(line, -1) {
+			refs = append(refs, &MDLinkRef{Target: match[2], Text: match[1], Line: lineNo})
+		}
+		for _, match := range htmlImgRegex.FindAllStringSubmatch(line, -1) {
+			refs = append(refs, &MDLinkRef{Target: match[1], Line: lineNo, Image: true})
+		}
+		for _, match := range hugoRefRegex.FindAllStringSubmatch(line, -1) {
+			refs = append(refs, &MDLinkRef{Target: match[1], Line: lineNo})
+		}
+	}
+	return refs
+}
+
+// HeaderImageRefs front matter中未声明字段(cover、images等)里的图片引用
+func HeaderImageRefs(header *YamlHeader) []*MDLinkRef {
+	if header == nil {
+		return nil
+	}
+	var refs []*MDLinkRef
+	var walk func(v interface{})
+	walk = func(v interface{}) {
+		switch val := v.(type) {
+		case string:
+			if IsImageFile(val) {
+				refs = append(refs, &MDLinkRef{Target: val, Image: true})
+			}
+		case []interface{}:
+			for _, item := range val {
+				walk(item)
+			}
+		case map[string]interface{}:
+			for _, item := range val {
+				walk(item)
+			}
+		}
+	}
+	keys := make([]string, 0, len(header.Extra))
+	for key := range header.Extra {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		walk(header.Extra[key])
+	}
+	return refs
+}
+
+// LinkTargetType 链接目标的类型
+type LinkTargetType int
+
+const (
+	LinkTargetSkip     LinkTargetType = iota // 页内锚点、mailto等非页面链接及忽略的链接，不检查
+	LinkTargetInternal                       // 站内链接
+	LinkTargetExternal                       // 站外http(s)链接
+)
+
+// LinkChecker 站内链接检查：文章链接按LinkIndex解析，其余按content目录(栏目、page bundle资源)及static目录下的文件解析
+type LinkChecker struct {
+	index     *LinkIndex
+	staticDir string
+	ignore    []string
+}
+
+// NewLinkChecker 基于文章链接索引、Hugo static目录构建链接检查，ignore为忽略的链接前缀或glob(例如 /tags/)
+func NewLinkChecker(index *LinkIndex, staticDir string, ignore []string) *LinkChecker {
+	if staticDir != "" {
+		staticDir = filepath.Clean(staticDir)
+	}
+	return &LinkChecker{index: index, staticDir: staticDir, ignore: ignore}
+}
+
+// Classify 链接目标的类型，匹配忽略规则的返回LinkTargetSkip
+func (c *LinkChecker) Classify(target string) LinkTargetType {
+	target = strings.TrimSpace(target)
+	if target == "" || strings.HasPrefix(target, "#") {
+		return LinkTargetSkip
+	}
+	u, err := url.Parse(target)
+	if err != nil {
+		return LinkTargetInternal
+	}
+
+	switch {
+	case u.Scheme != "" && u.Scheme != "http" && u.Scheme != "https":
+		return LinkTargetSkip
+	case u.Host != "" && u.Host != c.index.host:
+		if c.Ignored(target) {
+			return LinkTargetSkip
+		}
+		return LinkTargetExternal
+	}
+	if c.Ignored(linkTargetPath(target)) {
+		return LinkTargetSkip
+	}
+	return LinkTargetInternal
+}
+
+// Ignored 链接(站内为路径，站外为url)匹配忽略规则
+func (c *LinkChecker) Ignored(target string) bool {
+	for _, pattern := range c.ignore {
+		if strings.HasPrefix(target, pattern) {
+			return true
+		}
+		// 不含/的glob(例如 *.pdf)匹配最后一段
+		name := target
+		if !strings.Contains(pattern, "/") {
+			name = path.Base(strings.TrimSuffix(target, "/"))
+		}
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Resolve 解析source文章中的站内链接，返回链接到的文章、栏目目录或文件路径，无法解析时返回空：
+// 图片以外的链接先按文章链接索引解析，其余以/开头的在static、content目录下查找，相对链接在文章所在目录下查找
+func (c *LinkChecker) Resolve(source string, ref *MDLinkRef) string {
+	if !ref.Image {
+		if p := c.index.Resolve(source, ref.Target); p != "" {
+			return p
+		}
+	}
+
+	p := linkTargetPath(ref.Target)
+	if p == "" {
+		return ""
+	}
+	var candidates []string
+	if strings.HasPrefix(p, "/") {
+		if c.staticDir != "" {
+			candidates = append(candidates, filepath.Join(c.staticDir, filepath.FromSlash(p)))
+		}
+		if c.index.contentDir != "" && c.index.contentDir != "." {
+			candidates = append(candidates, filepath.Join(c.index.contentDir, filepath.FromSlash(p)))
+		}
+	} else {
+		candidates = append(candidates, filepath.Join(filepath.Dir(source), filepath.FromSlash(p)))
+	}
+	for _, candidate := range candidates {
+		if _, err := os.Stat(candidate); err == nil {
+			return candidate
+		}
+	}
+	return ""
+}
+
+// linkTargetPath 站内链接的路径：去除锚点、参数及站点地址，并解码%转义
+func linkTargetPath(target string) string {
+	target = strings.TrimSpace(target)
+	if i := strings.IndexAny(target, "#?"); i >= 0 {
+		target = target[:i]
+	}
+	u, err := url.Parse(target)
+	if err != nil {
+		return target
+	}
+	if u.Host != "" {
+		if u.Path == "" {
+			return "/"
+		}
+		return u.Path
+	}
+	if p, err := url.PathUnescape(target); err == nil {
+		return p
+	}
+	return target
+}
+
+// LinkIssue 链接检查发现的问题
+type LinkIssue struct {
+	Path       string        `json:"path"`                 // 文章路径，孤立图片时为图片路径
+	Line       int           `json:"line,omitempty"`       // 链接所在行号
+	Kind       LinkIssueKind `json:"kind"`                 // 问题类型
+	Target     string        `json:"target,omitempty"`     // 链接目标
+	Text       string        `json:"text,omitempty"`       // 链接文字
+	Reason     string        `json:"reason,omitempty"`     // 站外链接的状态码或错误
+	Suggestion string        `json:"suggestion,omitempty"` // AI建议的替换站内链接
+}
+
+// LinkReport 链接检查报告
+type LinkReport struct {
+	Files    int          `json:"files"`    // 检查的文章数
+	Links    int          `json:"links"`    // 链接数
+	Images   int          `json:"images"`   // 图片引用数
+	External int          `json:"external"` // 检查的站外url数(去重)
+	Issues   []*LinkIssue `json:"issues"`   // 按文件、行号排序
+}
+
+// SortLinkIssues 按文件、行号排序
+func SortLinkIssues(issues []*LinkIssue) {
+	sort.SliceStable(issues, func(i, j int) bool {
+		if issues[i].Path != issues[j].Path {
+			return issues[i].Path < issues[j].Path
+		}
+		return issues[i].Line < issues[j].Line
+	})
+}
+
+// CountLinkIssues 各类型问题的数量
+func (r *LinkReport) CountLinkIssues() map[LinkIssueKind]int {
+	counts := make(map[LinkIssueKind]int)
+	for _, issue := range r.Issues {
+		counts[issue.Kind]++
+	}
+	return counts
+}
+
+// LinkCheck 站外链接的检查结果，在缓存有效期内不再重复请求
+type LinkCheck struct {
+	ID         uint   `gorm:"id"`
+	CreatedAt  string `gorm:"created_at"`
+	UpdatedAt  string `gorm:"updated_at"`
+	Url        string `gorm:"url"`         // 站外url(不含锚点)
+	StatusCode int    `gorm:"status_code"` // 最终响应的状态码，请求失败时为0
+	Error      string `gorm:"error"`       // 请求失败的错误
+	CheckedAt  string `gorm:"checked_at"`  // 检查时间
+}
+
+func (t LinkCheck) TableName() string {
+	return "link_checks"
+}
+
+// IsBroken 请求失败或返回4xx、5xx；401、403、429多为拒绝爬虫或限频，不视为失效
+func (t *LinkCheck) IsBroken() bool {
+	if t.Error != "" {
+		return true
+	}
+	switch t.StatusCode {
+	case 401, 403, 429:
+		return false
+	}
+	return t.StatusCode >= 400
+}
+
+// Reason 失效原因
+func (t *LinkCheck) Reason() string {
+	if t.Error != "" {
+		return t.Error
+	}
+	return fmt.Sprintf("status %d", t.StatusCode)
+}
+
+// LinkCandidate 替换失效链接的站内文章候选
+type LinkCandidate struct {
+	Path     string `json:"path"`
+	Title    string `json:"title"`
+	Keywords string `json:"keywords"`
+	Url      string `json:"url"` // 站内链接(permalink)
+}
+
+// RankLinkCandidates 按与链接文字、目标路径的词重合数排序候选文章，返回前n个(没有重合的不返回)
+func RankLinkCandidates(issue *LinkIssue, candidates []*LinkCandidate, n int) []*LinkCandidate {
+	query := linkTerms(issue.Text + " " + strings.NewReplacer("-", " ", "_", " ", "/", " ").Replace(issue.Target))
+	type scored struct {
+		candidate *LinkCandidate
+		score     int
+	}
+	var ranked []scored
+	for _, candidate := range candidates {
+		terms := linkTerms(candidate.Title + " " + candidate.Keywords + " " +
+			strings.NewReplacer("-", " ", "_", " ", "/", " ").Replace(candidate.Url))
+		score := 0
+		for term := range query {
+			if terms[term] {
+				score++
+			}
+		}
+		if score > 0 {
+			ranked = append(ranked, scored{candidate, score})
+		}
+	}
+	sort.SliceStable(ranked, func(i, j int) bool { return ranked[i].score > ranked[j].score })
+
+	var top []*LinkCandidate
+	for i := 0; i < len(ranked) && (n <= 0 || i < n); i++ {
+		top = append(top, ranked[i].candidate)
+	}
+	return top
+}
+
+// linkTerms 文本中的词(小写)及汉字
+func linkTerms(s string) map[string]bool {
+	terms := make(map[string]bool)
+	for _, w := range wordsRegex.FindAllString(strings.ToLower(s), -1) {
+		if w == "http" || w == "https" || w == "www" || w == "com" || w == "md" {
+			continue
+		}
+		terms[w] = true
+	}
+	return terms
+}
+
+// IsLinkCheckFresh 检查结果在ttl内，ttl<=0时不使用缓存
+func IsLinkCheckFresh(check *LinkCheck, ttl time.Duration, now time.Time) bool {
+	if check == nil || ttl <= 0 {
+		return false
+	}
+	checked, err := time.ParseInLocation(shim.StdDateTimeLayout, check.CheckedAt, time.Local)
+	return err == nil && now.Sub(checked) < ttl
+}
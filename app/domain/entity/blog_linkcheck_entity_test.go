@@ -0,0 +1,86 @@
+package entity
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExtractMDLinkRefs(t *testing.T) {
+	content := "---\ntitle: t\ncover: /img/cover.png\n---\n\n" +
+		"see [rust](rust.md \"Rust\") and ![img](cover.png) `[code](inline.md)`\n" +
+		"```go\n// [code](skip.md)\n```\n" +
+		"[![logo](logo.svg)](https://example.com/)\n" +
+		`{{< relref "posts/go.md" >}} <img alt="x" src="/img/a.jpg">` + "\n" +
+		"[ref]: https://example.com/ref \"Ref\""
+
+	refs := ExtractMDLinkRefs(content)
+	assert.Equal(t, []*MDLinkRef{
+		{Target: "cover.png", Text: "img", Line: 6, Image: true},
+		{Target: "rust.md", Text: "rust", Line: 6},
+		{Target: "logo.svg", Text: "logo", Line: 10, Image: true},
+		{Target: "https://example.com/", Text: "logo", Line: 10},
+		{Target: "/img/a.jpg", Line: 11, Image: true},
+		{Target: "posts/go.md", Line: 11},
+		{Target: "https://example.com/ref", Text: "ref", Line: 12},
+	}, refs)
+
+	header := &YamlHeader{Extra: map[string]interface{}{"cover": "/img/cover.png", "images": []interface{}{"a.webp", "note"}}}
+	assert.Equal(t, []*MDLinkRef{{Target: "/img/cover.png", Image: true}, {Target: "a.webp", Image: true}}, HeaderImageRefs(header))
+}
+
+func TestLinkChecker_Resolve(t *testing.T) {
+	root := t.TempDir()
+	contentDir, staticDir := filepath.Join(root, "content"), filepath.Join(root, "static")
+	for _, file := range []string{"content/posts/go.md", "content/posts/bundle/index.md", "content/posts/bundle/a.png", "static/img/logo.png"} {
+		p := filepath.Join(root, file)
+		assert.NoError(t, os.MkdirAll(filepath.Dir(p), 0755))
+		assert.NoError(t, os.WriteFile(p, nil, 0644))
+	}
+	idx := NewLinkIndex("https://tkstorm.com", "/:sections/:slug/", contentDir, []*BlogArticle{
+		{Path: filepath.Join(contentDir, "posts/go.md")},
+		{Path: filepath.Join(contentDir, "posts/bundle/index.md")},
+	})
+	checker := NewLinkChecker(idx, staticDir, []string{"/tags/", "*.pdf"})
+	source := filepath.Join(contentDir, "posts/bundle/index.md")
+
+	assert.Equal(t, LinkTargetSkip, checker.Classify("#top"))
+	assert.Equal(t, LinkTargetSkip, checker.Classify("mailto:a@b.c"))
+	assert.Equal(t, LinkTargetSkip, checker.Classify("/tags/go/"))
+	assert.Equal(t, LinkTargetSkip, checker.Classify("https://example.com/a.pdf"))
+	assert.Equal(t, LinkTargetExternal, checker.Classify("https://example.com/x"))
+	assert.Equal(t, LinkTargetInternal, checker.Classify("https://tkstorm.com/posts/go/"))
+	assert.Equal(t, LinkTargetInternal, checker.Classify("../go.md"))
+
+	resolve := func(target string, image bool) string {
+		return checker.Resolve(source, &MDLinkRef{Target: target, Image: image})
+	}
+	assert.Equal(t, filepath.Join(contentDir, "posts/go.md"), resolve("/posts/go/#intro", false))
+	assert.Equal(t, filepath.Join(contentDir, "posts/bundle/a.png"), resolve("a.png", true))
+	assert.Equal(t, filepath.Join(staticDir, "img/logo.png"), resolve("https://tkstorm.com/img/logo.png", true))
+	assert.Equal(t, filepath.Join(contentDir, "posts"), resolve("/posts/", false))
+	assert.Equal(t, "", resolve("/posts/missing/", false))
+	assert.Equal(t, "", resolve("b.png", true))
+}
+
+func TestRankLinkCandidates(t *testing.T) {
+	candidates := []*LinkCandidate{
+		{Title: "Rust所有权", Url: "/posts/rust-ownership/"},
+		{Title: "Go并发编程", Keywords: "goroutine,channel", Url: "/posts/go-concurrency/"},
+		{Title: "Go channel详解", Url: "/posts/go-channel/"},
+	}
+	issue := &LinkIssue{Text: "go channel详解", Target: "/posts/go-channels-old/"}
+	top := RankLinkCandidates(issue, candidates, 2)
+	assert.Len(t, top, 2)
+	assert.Equal(t, "/posts/go-channel/", top[0].Url)
+	assert.Equal(t, "/posts/go-concurrency/", top[1].Url)
+}
+
+func TestLinkCheck_IsBroken(t *testing.T) {
+	assert.True(t, (&LinkCheck{StatusCode: 404}).IsBroken())
+	assert.True(t, (&LinkCheck{Error: "dial tcp: timeout"}).IsBroken())
+	assert.False(t, (&LinkCheck{StatusCode: 403}).IsBroken())
+	assert.False(t, (&LinkCheck{StatusCode: 200}).IsBroken())
+}
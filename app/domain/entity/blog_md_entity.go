@@ -1,7 +1,6 @@
 package entity
 
 import (
-	"crypto/md5"
 	"encoding/json"
 	"fmt"
 	"math"
@@ -9,6 +8,7 @@ import (
 	"path/filepath"
 	"regexp"
 	"strings"
+	"time"
 
 	"github.com/hold7techs/go-shim/shim"
 	"github.com/pkg/errors"
@@ -50,6 +50,10 @@ type BlogMD struct {
 	MDHeader  *YamlHeader `json:"yaml_header,omitempty"`
 	MDContent string      `json:"md_content,omitempty"`
 	MiniData  *MiniData   `json:"mini_data"` // 精简内容
+	Lang      string      `json:"lang"`      // 文章源语言，基于内容检测
+
+	// SummaryHash 当前摘要对应的正文hash，与正文hash不一致时摘要已过期
+	SummaryHash string `json:"summary_hash,omitempty"`
 }
 
 // MiniData 精简后的内容, 参考: https://platform.openai.com/tokenizer
@@ -74,7 +78,18 @@ type YamlHeader struct {
 	WordCounts  int             `yaml:"words_counts,omitempty"` // 文件字数统计
 	ShortMark   string          `yaml:"short_mark,omitempty"`   // 文章短标记
 	Aliases     []string        `yaml:"aliases,omitempty"`
-	ForceUpdate ForceUpdateType `yaml:"force_update"` // 强制更新YamlHeader的内容
+	SEOTitle    string          `yaml:"seo_title,omitempty"`  // AI生成的SEO标题
+	Slug        string          `yaml:"slug,omitempty"`       // 英文kebab-case的slug
+	ForceUpdate ForceUpdateType `yaml:"force_update"`         // 强制更新YamlHeader的内容
+	AISummary   *bool           `yaml:"ai_summary,omitempty"` // 为false时不生成AI摘要，不改写文章
+
+	// Extra 未显式声明的字段(例如 summary_en 多语言字段、cover等)，原样保留避免重写时丢失
+	Extra map[string]interface{} `yaml:",inline"`
+}
+
+// IsAISummaryDisabled front matter中设置了 ai_summary: false
+func (y *YamlHeader) IsAISummaryDisabled() bool {
+	return y.AISummary != nil && !*y.AISummary
 }
 
 func (y *YamlHeader) String() string {
@@ -117,16 +132,17 @@ func NewBlogMD(path string) (*BlogMD, error) {
 		MDContent: match[2],
 	}
 
-	// MD Yaml信息更新
+	// MD Yaml信息更新，权重保留front matter中的设置，由摘要任务、weights命令按配置的公式重算
 	header.WordCounts = wordsCount(md.MDContent)
-	header.Draft = md.IsDraft()                                                           // 是否手稿
-	header.Weight = md.CalcArticleWeight()                                                // 文章权重
-	header.ShortMark = md.ShortMark()                                                     // 文章签名
+	if !md.IsIndexMD() { // 栏目首页正文通常很短，保留原有的手稿设置
+		header.Draft = md.IsDraft() // 是否手稿
+	}
 	header.Categories = shim.ProcessStringsSlice(header.Categories, nil, strings.ToLower) // 文章分类统一转小写
 	header.Tags = shim.ProcessStringsSlice(header.Tags, nil, strings.ToLower)             // 文章标签统一转小写
 
 	// MD Content Mini信息，后续用于OpenAI请求
 	md.MiniData = md.GenerateMiniData()
+	md.Lang = DetectLang(md.MiniData.MiniContent)
 
 	return md, nil
 }
@@ -177,16 +193,6 @@ func (md *BlogMD) NeedUpdate(oldWordsCount int) bool {
 	return false
 }
 
-// ShortMark 获取MD的shortMark短标记
-func (md *BlogMD) ShortMark() string {
-	header := md.MDHeader
-	if header.ShortMark == "" && header.Title != "" {
-		header.ShortMark = fmt.Sprintf("%x", md5.Sum([]byte(header.Title)))
-	}
-
-	return header.ShortMark
-}
-
 func (md *BlogMD) GenerateMiniData() *MiniData {
 	// 精简token size
 	minContent, minLevel := minimiseContent(md.MDHeader.WordCounts, md.MDContent)
@@ -198,8 +204,8 @@ func (md *BlogMD) GenerateMiniData() *MiniData {
 	return miniData
 }
 
-// mdCodeRegex markdown中的代码正则
-var mdCodeRegex = regexp.MustCompile("(?ms)```.*```")
+// mdCodeRegex markdown中的代码正则(非贪婪，避免多个代码块之间的正文被一并剔除)
+var mdCodeRegex = regexp.MustCompile("(?ms)```.*?```")
 var mdListRightRegex = regexp.MustCompile(`[:：].+`)
 var mdListAllRegex = regexp.MustCompile(`[-1-9].+`)
 var mdReturnRegex = regexp.MustCompile(`\n+`)
@@ -211,21 +217,26 @@ func minimiseContent(wordsCount int, content string) (miniContent string, miniLe
 	// 基于MD的原始内容长度判断
 	switch {
 	case wordsCount < OpenAIMinTokenSize: // 小于1000，移除code代码
-		contRemoveCode := mdCodeRegex.ReplaceAllString(content, "")
+		contRemoveCode := MinimiseContent(content)
 		return contRemoveCode, 0
 	case wordsCount < OpenAIMediumTokenSize: // 小于5000, 移除code、list右侧内容
-		contRemoveCode := mdCodeRegex.ReplaceAllString(content, "")
+		contRemoveCode := MinimiseContent(content)
 		contRemoveListRight := mdListRightRegex.ReplaceAllString(contRemoveCode, "")
 		contRemoveReturn := mdReturnRegex.ReplaceAllString(contRemoveListRight, "\n")
 		return contRemoveReturn, 1
 	default: // 移除code+list全部内容
-		contRemoveCode := mdCodeRegex.ReplaceAllString(content, "")
+		contRemoveCode := MinimiseContent(content)
 		contRemoveCodeAndList := mdListAllRegex.ReplaceAllString(contRemoveCode, "")
 		contRemoveReturn := mdReturnRegex.ReplaceAllString(contRemoveCodeAndList, "\n")
 		return contRemoveReturn, 2
 	}
 }
 
+// MinimiseContent 剔除内容中的```代码块```
+func MinimiseContent(content string) string {
+	return mdCodeRegex.ReplaceAllString(content, "")
+}
+
 // 使用正则表达式匹配单词
 var wordsRegex = regexp.MustCompile(`(\p{Han}|\b\w+\b)`)
 
@@ -235,18 +246,15 @@ func wordsCount(content string) int {
 	return len(matches)
 }
 
-// CalcArticleWeight 计算新的文章权重
-// 文章权重 = 默认文章权重(100) +/- 时间权重 +/- 字数权重(没100长度权重）
+// CalcArticleWeight 按默认公式计算新的文章权重，可配置的公式(时间、字数、置顶、分类、站内链接权重)参考 WeightRules
 func (md *BlogMD) CalcArticleWeight() int {
-	// 内容长度
-	if md.MDHeader.WordCounts < ArticleDraftMinLength {
-		return WeightLow
-	}
-
-	return WeightDefault
+	return DefaultWeightRules().Calc(md.WeightInput(0), time.Now()).Weight
 }
 
-// ReplaceWithNewYamlHeader 更新成新的MD信息
+// ErrMDContentChanged 读取文章后，磁盘上的正文又被修改了
+var ErrMDContentChanged = errors.New("md content changed since read")
+
+// ReplaceWithNewYamlHeader 更新成新的MD信息，先写临时文件再rename，避免写入中断导致文章损坏
 func (md *BlogMD) ReplaceWithNewYamlHeader() error {
 	// 虚拟化处理
 	headerStr, err := yaml.Marshal(md.MDHeader)
@@ -255,16 +263,74 @@ func (md *BlogMD) ReplaceWithNewYamlHeader() error {
 	}
 	// log.Debugf("newMDHeaderStr: %s", headerStr)
 
-	// 清理+重写如新的文件内容
-	mdFile, err := os.OpenFile(md.Filepath, os.O_TRUNC|os.O_RDWR, 0644)
+	content := fmt.Sprintf("---\n%s---\n\n%s", headerStr, md.MDContent)
+	if err = writeFileAtomic(md.Filepath, []byte(content)); err != nil {
+		return errors.Wrapf(err, "write into blog file[%s] with new yaml header got err", md.Filepath)
+	}
+	return nil
+}
+
+// SafeReplaceYamlHeader 安全回写front matter：写入前校验磁盘上的正文与读取时一致，仅替换YamlHeader，
+// 正文被修改过时返回ErrMDContentChanged，避免覆盖作者的编辑
+func (md *BlogMD) SafeReplaceYamlHeader() error {
+	fileContent, err := os.ReadFile(md.Filepath)
 	if err != nil {
-		return errors.Wrapf(err, "open blog file[%s] got err", md.Filepath)
+		return errors.Wrapf(err, "read md file[%s] got err", md.Filepath)
+	}
+	match := blogMdRegex.FindStringSubmatch(string(fileContent))
+	if len(match) != 3 || match[2] != md.MDContent {
+		return errors.Wrapf(ErrMDContentChanged, "md file[%s]", md.Filepath)
 	}
-	defer mdFile.Close()
 
-	// 重写如file
-	if _, err = fmt.Fprintf(mdFile, "---\n%s---\n\n%s", headerStr, md.MDContent); err != nil {
-		return errors.Wrapf(err, "write into blog file[%s] with new yaml header got err", md.Filepath)
+	return md.ReplaceWithNewYamlHeader()
+}
+
+// SafeReplaceContent 用新正文替换md文件的正文，保留文件中原有的yaml头；
+// 文件正文自解析后被修改过时返回ErrMDContentChanged，不覆盖用户的修改
+func (md *BlogMD) SafeReplaceContent(content string) error {
+	fileContent, err := os.ReadFile(md.Filepath)
+	if err != nil {
+		return errors.Wrapf(err, "read md file[%s] got err", md.Filepath)
 	}
+	match := blogMdRegex.FindStringSubmatch(string(fileContent))
+	if len(match) != 3 || match[2] != md.MDContent {
+		return errors.Wrapf(ErrMDContentChanged, "md file[%s]", md.Filepath)
+	}
+
+	header := fileContent[:len(fileContent)-len(match[2])]
+	if err = writeFileAtomic(md.Filepath, append(header, content...)); err != nil {
+		return errors.Wrapf(err, "write md file[%s] got err", md.Filepath)
+	}
+	md.MDContent = content
 	return nil
 }
+
+// writeFileAtomic 在同目录写临时文件后rename替换，保留原文件权限
+func writeFileAtomic(path string, data []byte) error {
+	mode := os.FileMode(0644)
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err = tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err = tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+	if err = os.Chmod(tmp.Name(), mode); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
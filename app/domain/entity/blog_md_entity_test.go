@@ -1,9 +1,13 @@
 package entity
 
 import (
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/hold7techs/go-shim/shim"
+	"github.com/stretchr/testify/assert"
 )
 
 func TestMinimiseContent(t *testing.T) {
@@ -85,3 +89,28 @@ func TestNewBlogMD(t *testing.T) {
 
 	t.Logf("md=%v", shim.ToJsonString(md, true))
 }
+
+func TestBlogMD_SafeReplaceYamlHeader(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "post.md")
+	assert.NoError(t, os.WriteFile(path, []byte("---\ntitle: Go\n---\n\nbody\n"), 0600))
+
+	md, err := NewBlogMD(path)
+	assert.NoError(t, err)
+	md.MDHeader.Summary = "summary"
+	assert.NoError(t, md.SafeReplaceYamlHeader())
+
+	content, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Contains(t, string(content), "summary: summary\n")
+	assert.True(t, strings.HasSuffix(string(content), "---\n\nbody\n"))
+	info, err := os.Stat(path)
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	// 读取后正文被修改，拒绝覆盖
+	assert.NoError(t, os.WriteFile(path, []byte("---\ntitle: Go\n---\n\nbody edited\n"), 0600))
+	md.MDHeader.Summary = "new summary"
+	assert.ErrorIs(t, md.SafeReplaceYamlHeader(), ErrMDContentChanged)
+	content, _ = os.ReadFile(path)
+	assert.Contains(t, string(content), "body edited")
+}
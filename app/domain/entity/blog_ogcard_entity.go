@@ -0,0 +1,63 @@
+package entity
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// OGCard 文章社交分享卡片(Open Graph图片)上绘制的内容
+type OGCard struct {
+	Title       string   // 文章标题
+	Description string   // 文章描述，为空时使用摘要
+	Tags        []string // 文章标签
+	SiteName    string   // 站点名称
+}
+
+// NewOGCard 基于文章front matter的社交分享卡片内容
+func NewOGCard(md *BlogMD, siteName string) *OGCard {
+	description := md.MDHeader.Description
+	if description == "" {
+		description = md.MDHeader.Summary
+	}
+	return &OGCard{
+		Title:       strings.TrimSpace(md.MDHeader.Title),
+		Description: strings.Join(strings.Fields(description), " "),
+		Tags:        md.MDHeader.Tags,
+		SiteName:    siteName,
+	}
+}
+
+// Hash 标题及描述的hash，只有两者变化时才重新生成卡片
+func (c *OGCard) Hash() string {
+	return fmt.Sprintf("%x", sha256.Sum256([]byte(c.Title+"\x00"+c.Description)))
+}
+
+// OGCardLocation 卡片的保存路径及front matter中的引用：page bundle保存为同目录的og.png(多语言文章为og.<lang>.png)，
+// 其余文章保存到static目录下的 <cardDir>/<文件名>/og.png
+func OGCardLocation(mdPath, staticDir, cardDir string) (file, ref string) {
+	base := strings.TrimSuffix(filepath.Base(mdPath), ".md")
+	if IsPageBundle(mdPath) {
+		name := "og" + strings.TrimPrefix(base, "index") + ".png"
+		return filepath.Join(filepath.Dir(mdPath), name), name
+	}
+	cardDir = strings.Trim(filepath.ToSlash(cardDir), "/")
+	return filepath.Join(staticDir, filepath.FromSlash(cardDir), base, "og.png"), "/" + path.Join(cardDir, base, "og.png")
+}
+
+// OGCardImage 文章社交分享卡片的生成记录，按文章路径唯一，标题、描述的hash未变化时不重新生成
+type OGCardImage struct {
+	ID        uint   `gorm:"id" json:"-"`
+	CreatedAt string `gorm:"created_at" json:"created_at"`
+	UpdatedAt string `gorm:"updated_at" json:"-"`
+	Path      string `gorm:"path" json:"path"` // 文章路径
+	Hash      string `gorm:"hash" json:"-"`    // 生成时标题、描述的hash
+	File      string `gorm:"file" json:"file"` // 卡片保存路径
+	Ref       string `gorm:"ref" json:"ref"`   // front matter中的引用
+}
+
+func (t OGCardImage) TableName() string {
+	return "og_cards"
+}
@@ -0,0 +1,35 @@
+package entity
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOGCardLocation(t *testing.T) {
+	file, ref := OGCardLocation("/blog/content/posts/bundle/index.md", "/blog/static", "images/og")
+	assert.Equal(t, filepath.FromSlash("/blog/content/posts/bundle/og.png"), file)
+	assert.Equal(t, "og.png", ref)
+
+	file, ref = OGCardLocation("/blog/content/posts/bundle/index.en.md", "/blog/static", "images/og")
+	assert.Equal(t, filepath.FromSlash("/blog/content/posts/bundle/og.en.png"), file)
+	assert.Equal(t, "og.en.png", ref)
+
+	file, ref = OGCardLocation("/blog/content/posts/go.en.md", "/blog/static", "/images/og/")
+	assert.Equal(t, filepath.FromSlash("/blog/static/images/og/go.en/og.png"), file)
+	assert.Equal(t, "/images/og/go.en/og.png", ref)
+}
+
+func TestNewOGCard(t *testing.T) {
+	md := &BlogMD{MDHeader: &YamlHeader{Title: " Go调度器 ", Summary: "GMP\n模型", Tags: []string{"go"}}}
+	card := NewOGCard(md, "tkstorm.com")
+	assert.Equal(t, &OGCard{Title: "Go调度器", Description: "GMP 模型", Tags: []string{"go"}, SiteName: "tkstorm.com"}, card)
+
+	// 只有标题、描述影响hash
+	hash := card.Hash()
+	md.MDHeader.Tags = []string{"go", "runtime"}
+	assert.Equal(t, hash, NewOGCard(md, "").Hash())
+	md.MDHeader.Description = "GMP模型"
+	assert.NotEqual(t, hash, NewOGCard(md, "").Hash())
+}
@@ -0,0 +1,295 @@
+package entity
+
+import (
+	"bufio"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// BlogScanIgnoreFile 忽略文件，gitignore语法，可放在blog目录及其任意子目录下，作用于所在目录
+const BlogScanIgnoreFile = ".blogsummaryignore"
+
+// DefaultBlogScanInclude 未配置include时扫描的文件
+var DefaultBlogScanInclude = []string{"*.md"}
+
+// BlogSectionRule 栏目(blog目录下的子目录)的扫描规则，include、exclude相对于栏目目录
+type BlogSectionRule struct {
+	Path    string   // 栏目目录，相对于blog目录，例如 posts
+	Include []string // 为空时不额外限制
+	Exclude []string
+	Skip    bool // 跳过整个栏目
+}
+
+// BlogScanRules blog文章扫描规则，glob相对于blog目录，使用/分隔，**匹配任意层目录，不含/的glob匹配文件名
+type BlogScanRules struct {
+	Include  []string // 为空时为DefaultBlogScanInclude
+	Exclude  []string
+	Sections []*BlogSectionRule // 文件按最长匹配的栏目规则过滤
+}
+
+// BlogScanner 按扫描规则、.blogsummaryignore查找需要生成摘要的文章(_index.md始终排除)
+type BlogScanner struct {
+	root  string
+	rules *BlogScanRules
+
+	mu      sync.Mutex
+	ignores map[string][]*ignorePattern // 目录(相对于root) => 该目录下忽略文件的规则
+}
+
+// NewBlogScanner 初始化root目录的扫描器，rules为nil时使用默认规则
+func NewBlogScanner(root string, rules *BlogScanRules) *BlogScanner {
+	if rules == nil {
+		rules = &BlogScanRules{}
+	}
+	return &BlogScanner{
+		root:    filepath.Clean(root),
+		rules:   rules,
+		ignores: make(map[string][]*ignorePattern),
+	}
+}
+
+// Walk 查找path(文件或root下的目录)下所有需要生成摘要的文章
+func (s *BlogScanner) Walk(path string) ([]string, error) {
+	return s.walk(path, s.Match)
+}
+
+// WalkSections 查找path目录下未被忽略、跳过的栏目首页(_index.md)
+func (s *BlogScanner) WalkSections(path string) ([]string, error) {
+	return s.walk(path, func(file string) bool {
+		return filepath.Base(file) == SectionIndexFile
+	})
+}
+
+// walk 遍历path，跳过被忽略的目录、跳过的栏目，返回match的文件
+func (s *BlogScanner) walk(path string, match func(file string) bool) ([]string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		if match(path) {
+			return []string{path}, nil
+		}
+		return nil, nil
+	}
+
+	var files []string
+	err = filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, ok := s.rel(p)
+		if !ok {
+			return nil
+		}
+		if d.IsDir() {
+			if rel != "." && (s.isIgnored(rel, true) || s.isSectionSkipped(rel)) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if match(p) {
+			files = append(files, p)
+		}
+		return nil
+	})
+	return files, err
+}
+
+// Match 文件是否需要生成摘要
+func (s *BlogScanner) Match(file string) bool {
+	rel, ok := s.rel(file)
+	if !ok || rel == "." || path.Base(rel) == "_index.md" {
+		return false
+	}
+
+	// 上级目录或文件本身被忽略
+	dirs := strings.Split(rel, "/")
+	for i := 1; i < len(dirs); i++ {
+		if s.isIgnored(strings.Join(dirs[:i], "/"), true) {
+			return false
+		}
+	}
+	if s.isIgnored(rel, false) {
+		return false
+	}
+
+	include := s.rules.Include
+	if len(include) == 0 {
+		include = DefaultBlogScanInclude
+	}
+	if !matchAnyGlob(include, rel) || matchAnyGlob(s.rules.Exclude, rel) {
+		return false
+	}
+
+	section := s.sectionRule(rel)
+	if section == nil {
+		return true
+	}
+	if section.Skip {
+		return false
+	}
+	sectionRel := strings.TrimPrefix(rel, strings.Trim(section.Path, "/")+"/")
+	if len(section.Include) > 0 && !matchAnyGlob(section.Include, sectionRel) {
+		return false
+	}
+	return !matchAnyGlob(section.Exclude, sectionRel)
+}
+
+// rel 相对于root的路径(/分隔)，不在root下时返回false
+func (s *BlogScanner) rel(file string) (string, bool) {
+	rel, err := filepath.Rel(s.root, filepath.Clean(file))
+	if err != nil || rel == ".." || strings.HasPrefix(rel, "../") {
+		return "", false
+	}
+	return filepath.ToSlash(rel), true
+}
+
+// sectionRule 最长匹配的栏目规则
+func (s *BlogScanner) sectionRule(rel string) *BlogSectionRule {
+	var matched *BlogSectionRule
+	for _, rule := range s.rules.Sections {
+		section := strings.Trim(rule.Path, "/")
+		if section == "" || (rel != section && !strings.HasPrefix(rel, section+"/")) {
+			continue
+		}
+		if matched == nil || len(section) > len(strings.Trim(matched.Path, "/")) {
+			matched = rule
+		}
+	}
+	return matched
+}
+
+// isSectionSkipped 目录是否属于跳过的栏目
+func (s *BlogScanner) isSectionSkipped(dir string) bool {
+	rule := s.sectionRule(dir)
+	return rule != nil && rule.Skip
+}
+
+// isIgnored 按root到所在目录的忽略文件判断，越深的忽略文件优先级越高，同一文件内后面的规则优先
+func (s *BlogScanner) isIgnored(rel string, isDir bool) bool {
+	ignored := false
+	dir := "."
+	parts := strings.Split(rel, "/")
+	for i := 0; i < len(parts); i++ {
+		target := strings.Join(parts[i:], "/")
+		for _, p := range s.loadIgnores(dir) {
+			if p.match(target, isDir) {
+				ignored = !p.negate
+			}
+		}
+		dir = path.Join(dir, parts[i])
+	}
+	return ignored
+}
+
+// loadIgnores 读取目录下的忽略文件(带缓存)，不存在时为空
+func (s *BlogScanner) loadIgnores(dir string) []*ignorePattern {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if patterns, ok := s.ignores[dir]; ok {
+		return patterns
+	}
+
+	var patterns []*ignorePattern
+	if f, err := os.Open(filepath.Join(s.root, filepath.FromSlash(dir), BlogScanIgnoreFile)); err == nil {
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			if p := parseIgnorePattern(scanner.Text()); p != nil {
+				patterns = append(patterns, p)
+			}
+		}
+		f.Close()
+	}
+	s.ignores[dir] = patterns
+	return patterns
+}
+
+// ignorePattern gitignore的单条规则
+type ignorePattern struct {
+	glob    string
+	negate  bool // !开头，重新包含
+	dirOnly bool // /结尾，仅匹配目录
+}
+
+// parseIgnorePattern 解析gitignore的一行，空行、注释返回nil
+func parseIgnorePattern(line string) *ignorePattern {
+	line = strings.TrimRight(line, " \t\r")
+	if line == "" || strings.HasPrefix(line, "#") {
+		return nil
+	}
+
+	p := &ignorePattern{}
+	if strings.HasPrefix(line, "!") {
+		p.negate, line = true, line[1:]
+	} else if strings.HasPrefix(line, `\`) { // 转义开头的 # 或 !
+		line = line[1:]
+	}
+	if strings.HasSuffix(line, "/") {
+		p.dirOnly, line = true, strings.TrimRight(line, "/")
+	}
+	if line == "" {
+		return nil
+	}
+
+	// 不含/的规则匹配任意层级，含/的规则相对于忽略文件所在目录
+	if strings.Contains(line, "/") {
+		p.glob = strings.TrimPrefix(line, "/")
+	} else {
+		p.glob = "**/" + line
+	}
+	return p
+}
+
+func (p *ignorePattern) match(rel string, isDir bool) bool {
+	if p.dirOnly && !isDir {
+		return false
+	}
+	return matchGlobSegments(strings.Split(p.glob, "/"), strings.Split(rel, "/"))
+}
+
+// matchAnyGlob rel是否匹配任一glob，不含/的glob匹配文件名
+func matchAnyGlob(globs []string, rel string) bool {
+	for _, glob := range globs {
+		if !strings.Contains(glob, "/") {
+			if ok, _ := path.Match(glob, path.Base(rel)); ok {
+				return true
+			}
+			continue
+		}
+		if matchGlobSegments(strings.Split(strings.TrimPrefix(glob, "/"), "/"), strings.Split(rel, "/")) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchGlobSegments 按路径段匹配，**匹配零或多个路径段
+func matchGlobSegments(globs, parts []string) bool {
+	for len(globs) > 0 {
+		if globs[0] == "**" {
+			globs = globs[1:]
+			if len(globs) == 0 {
+				return true
+			}
+			for i := 0; i <= len(parts); i++ {
+				if matchGlobSegments(globs, parts[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(parts) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(globs[0], parts[0]); !ok {
+			return false
+		}
+		globs, parts = globs[1:], parts[1:]
+	}
+	return len(parts) == 0
+}
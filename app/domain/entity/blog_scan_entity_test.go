@@ -0,0 +1,77 @@
+package entity
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBlogScanner_Walk(t *testing.T) {
+	root := t.TempDir()
+	for _, file := range []string{
+		"posts/go.md",
+		"posts/_index.md",
+		"posts/drafts/wip.md",
+		"posts/golang/channel.md",
+		"posts/golang/notes.txt",
+		"posts/golang/tmp/scratch.md",
+		"posts/rust/README.md",
+		"posts/rust/ownership.md",
+		"about/me.md",
+		"notes/todo.md",
+		"notes/keep.md",
+	} {
+		file = filepath.Join(root, file)
+		assert.NoError(t, os.MkdirAll(filepath.Dir(file), 0755))
+		assert.NoError(t, os.WriteFile(file, []byte("x"), 0644))
+	}
+	assert.NoError(t, os.WriteFile(filepath.Join(root, BlogScanIgnoreFile), []byte("# 草稿\ndrafts/\nnotes/*\n!notes/keep.md\n"), 0644))
+	assert.NoError(t, os.WriteFile(filepath.Join(root, "posts/golang", BlogScanIgnoreFile), []byte("/tmp\n"), 0644))
+
+	scanner := NewBlogScanner(root, &BlogScanRules{
+		Exclude: []string{"README.md"},
+		Sections: []*BlogSectionRule{
+			{Path: "about", Skip: true},
+			{Path: "posts/rust", Include: []string{"own*.md"}},
+		},
+	})
+	files, err := scanner.Walk(root)
+	assert.NoError(t, err)
+	for i := range files {
+		files[i], _ = filepath.Rel(root, files[i])
+	}
+	sort.Strings(files)
+	assert.Equal(t, []string{"notes/keep.md", "posts/go.md", "posts/golang/channel.md", "posts/rust/ownership.md"}, files)
+
+	assert.True(t, scanner.Match(filepath.Join(root, "posts/go.md")))
+	assert.False(t, scanner.Match(filepath.Join(root, "posts/drafts/wip.md")))
+	assert.False(t, scanner.Match(filepath.Join(root, "about/me.md")))
+	assert.False(t, scanner.Match(filepath.Join(filepath.Dir(root), "other.md")))
+
+	// 仅扫描posts栏目
+	only := NewBlogScanner(root, &BlogScanRules{Include: []string{"posts/**/*.md"}})
+	files, err = only.Walk(filepath.Join(root, "posts", "golang"))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(root, "posts/golang/channel.md")}, files)
+	assert.False(t, only.Match(filepath.Join(root, "notes/keep.md")))
+}
+
+func TestMatchGlobSegments(t *testing.T) {
+	tests := []struct {
+		glob string
+		rel  string
+		want bool
+	}{
+		{"posts/**", "posts/a/b.md", true},
+		{"posts/**/*.md", "posts/b.md", true},
+		{"posts/**/*.md", "about/b.md", false},
+		{"**/tmp", "a/b/tmp", true},
+		{"a/*.md", "a/b/c.md", false},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, matchAnyGlob([]string{tt.glob}, tt.rel), tt.glob+" "+tt.rel)
+	}
+}
@@ -0,0 +1,74 @@
+package entity
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// SectionIndexFile Hugo栏目首页文件
+const SectionIndexFile = "_index.md"
+
+// BlogSection 栏目(_index.md)的摘要记录，摘要由子文章、子栏目已生成的摘要汇总得到
+type BlogSection struct {
+	ID           uint   `gorm:"id"`
+	CreatedAt    string `gorm:"created_at"`
+	UpdatedAt    string `gorm:"updated_at"`
+	Path         string `gorm:"path"`          // _index.md路径
+	Title        string `gorm:"title"`         // 栏目标题
+	ChildrenHash string `gorm:"children_hash"` // 生成摘要时子摘要集合的hash，变化时才重新生成
+	Children     int    `gorm:"children"`      // 参与汇总的子文章、子栏目数
+	Keywords     string `gorm:"keywords"`      // 栏目关键字
+	Summary      string `gorm:"summary"`       // 栏目摘要
+	Description  string `gorm:"description"`   // 栏目描述
+}
+
+func (t BlogSection) TableName() string {
+	return "blog_sections"
+}
+
+// ArticleSummary 栏目摘要内容
+func (t *BlogSection) ArticleSummary() *ArticleSummary {
+	return &ArticleSummary{Keywords: t.Keywords, Summary: t.Summary, Description: t.Description}
+}
+
+// SectionChild 参与栏目汇总的子文章或子栏目
+type SectionChild struct {
+	Path     string
+	Title    string
+	Keywords string
+	Summary  string
+}
+
+// SectionChildrenHash 子摘要集合的hash，与子项顺序无关
+func SectionChildrenHash(children []*SectionChild) string {
+	lines := make([]string, 0, len(children))
+	for _, child := range children {
+		lines = append(lines, strings.Join([]string{child.Path, child.Title, child.Keywords, child.Summary}, "\x00"))
+	}
+	sort.Strings(lines)
+	return fmt.Sprintf("%x", sha256.Sum256([]byte(strings.Join(lines, "\n"))))
+}
+
+// NearestSectionIndex 文件(或目录)所属栏目的_index.md：从上级目录向上查找到root为止，没有时返回空
+func NearestSectionIndex(root, path string) string {
+	root = filepath.Clean(root)
+	dir := filepath.Dir(filepath.Clean(path))
+	for {
+		rel, err := filepath.Rel(root, dir)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return ""
+		}
+		index := filepath.Join(dir, SectionIndexFile)
+		if info, err := os.Stat(index); err == nil && !info.IsDir() {
+			return index
+		}
+		if rel == "." {
+			return ""
+		}
+		dir = filepath.Dir(dir)
+	}
+}
@@ -0,0 +1,62 @@
+package entity
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/hold7techs/go-shim/shim"
+)
+
+const (
+	// SlugMaxLength slug最大长度
+	SlugMaxLength = 60
+
+	// ShortAliasMarkLength 短链别名默认截取ShortMark的长度
+	ShortAliasMarkLength = 8
+
+	// ShortAliasPrefix 短链别名路径前缀
+	ShortAliasPrefix = "/s/"
+)
+
+// ArticleSEO AI生成的SEO信息
+type ArticleSEO struct {
+	Title string `json:"title"` // 简洁的SEO标题
+	Slug  string `json:"slug"`  // 英文kebab-case的slug
+}
+
+var slugInvalidRegex = regexp.MustCompile(`[^a-z0-9]+`)
+
+// NormalizeSlug 规范化成英文kebab-case，超长时在单词边界截断
+func NormalizeSlug(s string) string {
+	slug := strings.Trim(slugInvalidRegex.ReplaceAllString(strings.ToLower(s), "-"), "-")
+	if len(slug) > SlugMaxLength {
+		slug = slug[:SlugMaxLength]
+		if i := strings.LastIndex(slug, "-"); i > 0 {
+			slug = slug[:i]
+		}
+	}
+	return slug
+}
+
+// ShortAlias 基于ShortMark前n位生成的短链别名，例如 /s/1a2b3c4d/
+func ShortAlias(shortMark string, n int) string {
+	mark := []rune(shortMark)
+	if n > len(mark) {
+		n = len(mark)
+	}
+	return fmt.Sprintf("%s%s/", ShortAliasPrefix, string(mark[:n]))
+}
+
+// NeedSEO 是否缺少SEO标题或slug
+func (y *YamlHeader) NeedSEO() bool {
+	return y.SEOTitle == "" || y.Slug == ""
+}
+
+// AddAlias 追加别名(已存在时忽略)
+func (y *YamlHeader) AddAlias(alias string) {
+	if alias == "" || shim.InElems(alias, y.Aliases) {
+		return
+	}
+	y.Aliases = append(y.Aliases, alias)
+}
@@ -0,0 +1,31 @@
+package entity
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeSlug(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"Go Concurrency Basics", "go-concurrency-basics"},
+		{"  --How_to deal with: Tech Debt!! ", "how-to-deal-with-tech-debt"},
+		{"中文slug", "slug"},
+		{strings.Repeat("word-", 20), "word-word-word-word-word-word-word-word-word-word-word-word"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			assert.Equal(t, tt.want, NormalizeSlug(tt.in))
+		})
+	}
+}
+
+func TestShortAlias(t *testing.T) {
+	assert.Equal(t, "/s/0123abcd/", ShortAlias("0123abcdef", ShortAliasMarkLength))
+	assert.Equal(t, "/s/0123/", ShortAlias("0123", ShortAliasMarkLength))
+	assert.Equal(t, "/s/短标记测试一二三/", ShortAlias("短标记测试一二三四五", ShortAliasMarkLength))
+}
@@ -0,0 +1,113 @@
+package entity
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"math/big"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	// ShortMarkDefaultLength 短标记默认长度
+	ShortMarkDefaultLength = 8
+
+	// ShortMarkBase62 默认的base62字符集
+	ShortMarkBase62 = "0123456789abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ"
+
+	// ShortMarkMaxAttempts 冲突时最多尝试的次数
+	ShortMarkMaxAttempts = 32
+)
+
+// ShortMarkGenerator 短标记生成器，相同的种子+尝试次数总是得到相同的结果
+type ShortMarkGenerator struct {
+	Length   int
+	Alphabet string
+}
+
+// NewShortMarkGenerator 初始短标记生成器，长度或字符集为空时使用默认值(8位base62)，字符集按字符(rune)计算
+func NewShortMarkGenerator(length int, alphabet string) *ShortMarkGenerator {
+	if length <= 0 {
+		length = ShortMarkDefaultLength
+	}
+	if utf8.RuneCountInString(alphabet) < 2 {
+		alphabet = ShortMarkBase62
+	}
+	return &ShortMarkGenerator{Length: length, Alphabet: alphabet}
+}
+
+// Generate 基于种子(一般为文章标题)生成短标记，attempt用于冲突后重新生成
+func (g *ShortMarkGenerator) Generate(seed string, attempt int) string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s#%d", seed, attempt)))
+	num := new(big.Int).SetBytes(sum[:])
+	alphabet := []rune(g.Alphabet)
+	base := big.NewInt(int64(len(alphabet)))
+	mod := new(big.Int)
+
+	var sb strings.Builder
+	for i := 0; i < g.Length; i++ {
+		num.DivMod(num, base, mod)
+		sb.WriteRune(alphabet[mod.Int64()])
+	}
+	return sb.String()
+}
+
+// Permalink 按Hugo风格的permalink模板生成文章链接，支持 :sections、:section、:filename、:slug、:year、:month、:day
+//   - contentDir 为Hugo的content目录，用于计算文章的相对路径
+//   - page bundle(index.md)的filename取目录名
+func Permalink(pattern, contentDir string, article *BlogArticle) string {
+	rel, err := filepath.Rel(contentDir, article.Path)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		rel = filepath.Base(article.Path)
+	}
+	rel = filepath.ToSlash(rel)
+
+	dir, file := filepath.Split(rel)
+	dir = strings.Trim(dir, "/")
+	filename := strings.TrimSuffix(file, filepath.Ext(file))
+	if ext := filepath.Ext(filename); langSuffixRegex.MatchString(ext) {
+		filename = strings.TrimSuffix(filename, ext)
+	}
+	if filename == "index" && dir != "" { // page bundle
+		filename = filepath.Base(dir)
+		dir = filepath.Dir(dir)
+		if dir == "." {
+			dir = ""
+		}
+	}
+
+	section := dir
+	if i := strings.Index(dir, "/"); i >= 0 {
+		section = dir[:i]
+	}
+	slug := article.Slug
+	if slug == "" {
+		slug = filename
+	}
+	date, _ := time.Parse("2006-01-02", firstN(article.Date, 10))
+
+	link := strings.NewReplacer(
+		":sections", dir,
+		":section", section,
+		":filename", filename,
+		":slug", slug,
+		":year", date.Format("2006"),
+		":month", date.Format("01"),
+		":day", date.Format("02"),
+	).Replace(pattern)
+
+	// 清理空的路径段
+	for strings.Contains(link, "//") {
+		link = strings.ReplaceAll(link, "//", "/")
+	}
+	return link
+}
+
+func firstN(s string, n int) string {
+	if len(s) > n {
+		return s[:n]
+	}
+	return s
+}
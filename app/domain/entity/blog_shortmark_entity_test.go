@@ -0,0 +1,53 @@
+package entity
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShortMarkGenerator_Generate(t *testing.T) {
+	g := NewShortMarkGenerator(0, "")
+	mark := g.Generate("如何处理技术债务", 0)
+	assert.Equal(t, ShortMarkDefaultLength, len(mark))
+	assert.Equal(t, mark, g.Generate("如何处理技术债务", 0))
+	assert.NotEqual(t, mark, g.Generate("如何处理技术债务", 1))
+	for _, c := range mark {
+		assert.True(t, strings.ContainsRune(ShortMarkBase62, c))
+	}
+
+	hex := NewShortMarkGenerator(12, "0123456789abcdef").Generate("title", 0)
+	assert.Equal(t, 12, len(hex))
+	assert.Equal(t, "", strings.Trim(hex, "0123456789abcdef"))
+
+	// 非ASCII字符集按字符取值，结果为合法的UTF-8
+	cjk := NewShortMarkGenerator(6, "甲乙丙丁戊己庚辛").Generate("title", 0)
+	assert.True(t, utf8.ValidString(cjk))
+	assert.Equal(t, 6, utf8.RuneCountInString(cjk))
+	assert.Equal(t, "", strings.Trim(cjk, "甲乙丙丁戊己庚辛"))
+
+	// 只有一个字符(多字节)时使用默认字符集
+	assert.Equal(t, ShortMarkBase62, NewShortMarkGenerator(0, "甲").Alphabet)
+}
+
+func TestPermalink(t *testing.T) {
+	tests := []struct {
+		name    string
+		pattern string
+		article *BlogArticle
+		want    string
+	}{
+		{"default", "/:sections/:slug/", &BlogArticle{Path: "/content/posts/go/chan.md"}, "/posts/go/chan/"},
+		{"slug", "/:sections/:slug/", &BlogArticle{Path: "/content/posts/go/chan.md", Slug: "go-channel"}, "/posts/go/go-channel/"},
+		{"bundle", "/:section/:filename/", &BlogArticle{Path: "/content/posts/go/chan/index.md"}, "/posts/chan/"},
+		{"date", "/:year/:month/:slug/", &BlogArticle{Path: "/content/posts/chan.en.md", Date: "2023-08-17T10:00:00+08:00"}, "/2023/08/chan/"},
+		{"root", "/:sections/:slug/", &BlogArticle{Path: "/content/about.md"}, "/about/"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Permalink(tt.pattern, "/content", tt.article))
+		})
+	}
+}
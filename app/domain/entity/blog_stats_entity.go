@@ -0,0 +1,342 @@
+package entity
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/pkg/errors"
+)
+
+// StatsFormat 统计报告的输出格式
+type StatsFormat string
+
+const (
+	StatsFormatTable    StatsFormat = "table"
+	StatsFormatJSON     StatsFormat = "json"
+	StatsFormatMarkdown StatsFormat = "markdown"
+)
+
+// DefaultStatsTop 统计报告中各列表默认展示的条数
+const DefaultStatsTop = 20
+
+// statsUnknownDate 日期缺失或无法解析时的归类
+const statsUnknownDate = "unknown"
+
+// ParseStatsFormat 解析输出格式，为空时为table
+func ParseStatsFormat(format string) (StatsFormat, error) {
+	switch f := StatsFormat(strings.ToLower(format)); f {
+	case "":
+		return StatsFormatTable, nil
+	case StatsFormatTable, StatsFormatJSON, StatsFormatMarkdown:
+		return f, nil
+	case "md":
+		return StatsFormatMarkdown, nil
+	default:
+		return "", errors.Errorf("unknown stats format[%s], expect table, json or markdown", format)
+	}
+}
+
+// StatCount 按维度(年、月、标签)统计的文章数
+type StatCount struct {
+	Key   string `json:"key"`
+	Count int    `json:"count"`
+}
+
+// CategoryWords 分类的文章数、字数
+type CategoryWords struct {
+	Category string `json:"category"`
+	Posts    int    `json:"posts"`
+	Words    int    `json:"words"`
+}
+
+// StatPost 统计报告中列出的文章
+type StatPost struct {
+	ID        uint   `json:"id"`
+	Path      string `json:"path"`
+	Title     string `json:"title"`
+	Date      string `json:"date"`
+	WordCount int    `json:"word_count"`
+}
+
+// StatPostList 符合条件的文章总数，及前top篇
+type StatPostList struct {
+	Total int         `json:"total"`
+	Posts []*StatPost `json:"posts"`
+}
+
+// BlogStats 全站内容统计及健康报告，年、月、分类、标签统计仅包含非手稿文章
+type BlogStats struct {
+	Posts  int `json:"posts"`  // 文章总数(含手稿)
+	Drafts int `json:"drafts"` // 手稿数
+	Words  int `json:"words"`  // 非手稿文章总字数
+
+	PostsByYear     []*StatCount     `json:"posts_by_year"`
+	PostsByMonth    []*StatCount     `json:"posts_by_month"`
+	WordsByCategory []*CategoryWords `json:"words_by_category"`
+	TagCount        int              `json:"tag_count"` // 标签总数
+	Tags            []*StatCount     `json:"tags"`      // 按使用次数倒序的前top个标签
+
+	DraftPosts         *StatPostList `json:"draft_posts"`
+	MissingSummary     *StatPostList `json:"missing_summary"`
+	MissingDescription *StatPostList `json:"missing_description"`
+	MissingKeywords    *StatPostList `json:"missing_keywords"`
+	StaleSummaries     *StatPostList `json:"stale_summaries"` // 摘要生成后正文hash有变化
+	OversizedPosts     *StatPostList `json:"oversized_posts"` // 字数超过AI请求限制，按字数倒序
+}
+
+// statsDateRegex 提取日期中的年、月，兼容 2006-01-02、2006-01-02T15:04:05+08:00 等格式
+var statsDateRegex = regexp.MustCompile(`^(\d{4})-(\d{1,2})`)
+
+// NewBlogStats 基于文章记录统计，top<=0时列表不限条数
+func NewBlogStats(records []*BlogArticle, top int) *BlogStats {
+	stats := &BlogStats{}
+	years := make(map[string]int)
+	months := make(map[string]int)
+	categories := make(map[string]*CategoryWords)
+	tags := make(map[string]int)
+	var drafts, missingSummary, missingDescription, missingKeywords, stale, oversized []*StatPost
+
+	for _, record := range records {
+		stats.Posts++
+		post := &StatPost{ID: record.ID, Path: record.Path, Title: record.Title, Date: record.Date, WordCount: record.WordCount}
+		if record.Draft {
+			stats.Drafts++
+			drafts = append(drafts, post)
+			continue
+		}
+		stats.Words += record.WordCount
+
+		year, month := statsYearMonth(record.Date)
+		years[year]++
+		months[month]++
+		for _, category := range jsonStringSlice(record.Categories) {
+			if categories[category] == nil {
+				categories[category] = &CategoryWords{Category: category}
+			}
+			categories[category].Posts++
+			categories[category].Words += record.WordCount
+		}
+		for _, tag := range jsonStringSlice(record.Tags) {
+			tags[tag]++
+		}
+
+		if record.Summary == "" {
+			missingSummary = append(missingSummary, post)
+		}
+		if record.Description == "" {
+			missingDescription = append(missingDescription, post)
+		}
+		if record.Keywords == "" {
+			missingKeywords = append(missingKeywords, post)
+		}
+		if record.IsSummaryStale() {
+			stale = append(stale, post)
+		}
+		if record.WordCount > OpenAIMaxTokenSize {
+			oversized = append(oversized, post)
+		}
+	}
+
+	stats.PostsByYear = sortedCounts(years, false)
+	stats.PostsByMonth = sortedCounts(months, false)
+	stats.TagCount = len(tags)
+	stats.Tags = limitSlice(sortedCounts(tags, true), top)
+	for _, category := range categories {
+		stats.WordsByCategory = append(stats.WordsByCategory, category)
+	}
+	sort.Slice(stats.WordsByCategory, func(i, j int) bool {
+		a, b := stats.WordsByCategory[i], stats.WordsByCategory[j]
+		if a.Words != b.Words {
+			return a.Words > b.Words
+		}
+		return a.Category < b.Category
+	})
+	sort.SliceStable(oversized, func(i, j int) bool { return oversized[i].WordCount > oversized[j].WordCount })
+
+	stats.DraftPosts = newStatPostList(drafts, top)
+	stats.MissingSummary = newStatPostList(missingSummary, top)
+	stats.MissingDescription = newStatPostList(missingDescription, top)
+	stats.MissingKeywords = newStatPostList(missingKeywords, top)
+	stats.StaleSummaries = newStatPostList(stale, top)
+	stats.OversizedPosts = newStatPostList(oversized, top)
+	return stats
+}
+
+// Write 按格式输出统计报告
+func (s *BlogStats) Write(w io.Writer, format StatsFormat) error {
+	switch format {
+	case StatsFormatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(s)
+	case StatsFormatMarkdown:
+		return s.writeMarkdown(w)
+	default:
+		return s.writeTable(w)
+	}
+}
+
+// statsTable 报告中的一个小节
+type statsTable struct {
+	title  string
+	header []string
+	rows   [][]string
+	more   int // 未展示的条数
+}
+
+// tables 报告的各小节
+func (s *BlogStats) tables() []*statsTable {
+	overview := &statsTable{
+		title:  "概览",
+		header: []string{"指标", "数量"},
+		rows: [][]string{
+			{"文章", strconv.Itoa(s.Posts)},
+			{"手稿", strconv.Itoa(s.Drafts)},
+			{"字数", strconv.Itoa(s.Words)},
+			{"标签", strconv.Itoa(s.TagCount)},
+			{"缺少摘要", strconv.Itoa(s.MissingSummary.Total)},
+			{"缺少描述", strconv.Itoa(s.MissingDescription.Total)},
+			{"缺少关键字", strconv.Itoa(s.MissingKeywords.Total)},
+			{"摘要过期", strconv.Itoa(s.StaleSummaries.Total)},
+			{"超过AI限制", strconv.Itoa(s.OversizedPosts.Total)},
+		},
+	}
+	tables := []*statsTable{
+		overview,
+		countsTable("按年", "年份", s.PostsByYear, 0),
+		countsTable("按月", "月份", s.PostsByMonth, 0),
+	}
+
+	categories := &statsTable{title: "分类字数", header: []string{"分类", "文章", "字数"}}
+	for _, c := range s.WordsByCategory {
+		categories.rows = append(categories.rows, []string{c.Category, strconv.Itoa(c.Posts), strconv.Itoa(c.Words)})
+	}
+	tables = append(tables,
+		categories,
+		countsTable("标签", "标签", s.Tags, s.TagCount-len(s.Tags)),
+		postsTable("手稿", s.DraftPosts),
+		postsTable("缺少摘要", s.MissingSummary),
+		postsTable("缺少描述", s.MissingDescription),
+		postsTable("缺少关键字", s.MissingKeywords),
+		postsTable("摘要过期(正文有修改)", s.StaleSummaries),
+		postsTable(fmt.Sprintf("超过AI限制(%d字)", OpenAIMaxTokenSize), s.OversizedPosts),
+	)
+	return tables
+}
+
+func (s *BlogStats) writeTable(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	for i, t := range s.tables() {
+		if i > 0 {
+			fmt.Fprintln(tw)
+		}
+		fmt.Fprintf(tw, "== %s ==\n", t.title)
+		if len(t.rows) == 0 {
+			fmt.Fprintln(tw, "(无)")
+			continue
+		}
+		fmt.Fprintln(tw, strings.ToUpper(strings.Join(t.header, "\t")))
+		for _, row := range t.rows {
+			fmt.Fprintln(tw, strings.Join(row, "\t"))
+		}
+		if t.more > 0 {
+			fmt.Fprintf(tw, "... %d more\n", t.more)
+		}
+	}
+	return tw.Flush()
+}
+
+func (s *BlogStats) writeMarkdown(w io.Writer) error {
+	var b strings.Builder
+	b.WriteString("# 内容统计\n")
+	for _, t := range s.tables() {
+		fmt.Fprintf(&b, "\n## %s\n\n", t.title)
+		if len(t.rows) == 0 {
+			b.WriteString("无\n")
+			continue
+		}
+		fmt.Fprintf(&b, "| %s |\n|%s\n", strings.Join(t.header, " | "), strings.Repeat(" --- |", len(t.header)))
+		for _, row := range t.rows {
+			cells := make([]string, len(row))
+			for i, cell := range row {
+				cells[i] = strings.ReplaceAll(cell, "|", `\|`)
+			}
+			fmt.Fprintf(&b, "| %s |\n", strings.Join(cells, " | "))
+		}
+		if t.more > 0 {
+			fmt.Fprintf(&b, "\n还有 %d 条未列出\n", t.more)
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func countsTable(title, key string, counts []*StatCount, more int) *statsTable {
+	t := &statsTable{title: title, header: []string{key, "文章"}, more: more}
+	for _, c := range counts {
+		t.rows = append(t.rows, []string{c.Key, strconv.Itoa(c.Count)})
+	}
+	return t
+}
+
+func postsTable(title string, list *StatPostList) *statsTable {
+	t := &statsTable{title: title, header: []string{"ID", "标题", "日期", "字数", "路径"}, more: list.Total - len(list.Posts)}
+	for _, p := range list.Posts {
+		t.rows = append(t.rows, []string{strconv.Itoa(int(p.ID)), p.Title, p.Date, strconv.Itoa(p.WordCount), p.Path})
+	}
+	return t
+}
+
+// statsYearMonth 日期的年份、年月，无法解析时为unknown
+func statsYearMonth(date string) (year, month string) {
+	match := statsDateRegex.FindStringSubmatch(strings.TrimSpace(date))
+	if match == nil {
+		return statsUnknownDate, statsUnknownDate
+	}
+	m, _ := strconv.Atoi(match[2])
+	return match[1], fmt.Sprintf("%s-%02d", match[1], m)
+}
+
+// sortedCounts byCount为true时按数量倒序，否则按key正序
+func sortedCounts(counts map[string]int, byCount bool) []*StatCount {
+	list := make([]*StatCount, 0, len(counts))
+	for key, count := range counts {
+		list = append(list, &StatCount{Key: key, Count: count})
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if byCount && list[i].Count != list[j].Count {
+			return list[i].Count > list[j].Count
+		}
+		return list[i].Key < list[j].Key
+	})
+	return list
+}
+
+func newStatPostList(posts []*StatPost, top int) *StatPostList {
+	return &StatPostList{Total: len(posts), Posts: limitSlice(posts, top)}
+}
+
+func limitSlice[T any](list []T, top int) []T {
+	if top > 0 && len(list) > top {
+		return list[:top]
+	}
+	if list == nil {
+		return []T{}
+	}
+	return list
+}
+
+// jsonStringSlice 解析DB中以json数组存储的分类、标签
+func jsonStringSlice(s string) []string {
+	var list []string
+	if s == "" || json.Unmarshal([]byte(s), &list) != nil {
+		return nil
+	}
+	return list
+}
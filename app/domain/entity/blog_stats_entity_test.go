@@ -0,0 +1,65 @@
+package entity
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewBlogStats(t *testing.T) {
+	records := []*BlogArticle{
+		{ID: 1, Path: "/posts/go.md", Title: "Go", Date: "2023-08-01T10:00:00+08:00", WordCount: 3000,
+			Categories: `["golang"]`, Tags: `["go","concurrency"]`, Keywords: "go", Summary: "s", Description: "d",
+			ContentHash: "h1", SummaryHash: "h1"},
+		{ID: 2, Path: "/posts/rust.md", Title: "Rust", Date: "2023-9-15", WordCount: 15000,
+			Categories: `["rust"]`, Tags: `["rust","concurrency"]`, Summary: "s", ContentHash: "h2", SummaryHash: "h0"},
+		{ID: 3, Path: "/posts/old.md", Title: "Old|Post", Date: "2021-01-01", WordCount: 800, Categories: `["golang"]`},
+		{ID: 4, Path: "/posts/wip.md", Title: "WIP", WordCount: 20, Draft: true},
+		{ID: 5, Path: "/posts/nodate.md", Title: "NoDate", WordCount: 100, Summary: "s", Keywords: "k", Description: "d"},
+	}
+
+	stats := NewBlogStats(records, 1)
+	assert.Equal(t, 5, stats.Posts)
+	assert.Equal(t, 1, stats.Drafts)
+	assert.Equal(t, 18900, stats.Words)
+	assert.Equal(t, []*StatCount{{"2021", 1}, {"2023", 2}, {"unknown", 1}}, stats.PostsByYear)
+	assert.Equal(t, []*StatCount{{"2021-01", 1}, {"2023-08", 1}, {"2023-09", 1}, {"unknown", 1}}, stats.PostsByMonth)
+	assert.Equal(t, []*CategoryWords{{"rust", 1, 15000}, {"golang", 2, 3800}}, stats.WordsByCategory)
+	assert.Equal(t, 3, stats.TagCount)
+	assert.Equal(t, []*StatCount{{"concurrency", 2}}, stats.Tags)
+
+	assert.Equal(t, 1, stats.DraftPosts.Total)
+	assert.Equal(t, 1, stats.MissingSummary.Total)
+	assert.Equal(t, 2, stats.MissingDescription.Total)
+	assert.Len(t, stats.MissingDescription.Posts, 1)
+	assert.Equal(t, 2, stats.MissingKeywords.Total)
+	assert.Equal(t, "Rust", stats.StaleSummaries.Posts[0].Title)
+	assert.Equal(t, 1, stats.OversizedPosts.Total)
+	assert.Equal(t, 15000, stats.OversizedPosts.Posts[0].WordCount)
+
+	// 输出格式
+	var buf bytes.Buffer
+	assert.NoError(t, stats.Write(&buf, StatsFormatJSON))
+	got := &BlogStats{}
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), got))
+	assert.Equal(t, stats, got)
+
+	buf.Reset()
+	assert.NoError(t, NewBlogStats(records, 0).Write(&buf, StatsFormatMarkdown))
+	assert.Contains(t, buf.String(), "## 缺少摘要\n\n| ID | 标题 | 日期 | 字数 | 路径 |\n| --- | --- | --- | --- | --- |\n| 3 | Old\\|Post | 2021-01-01 | 800 | /posts/old.md |\n")
+
+	buf.Reset()
+	assert.NoError(t, stats.Write(&buf, StatsFormatTable))
+	assert.Contains(t, buf.String(), "== 标签 ==\n")
+	assert.Contains(t, buf.String(), "... 2 more\n")
+	assert.True(t, strings.HasPrefix(buf.String(), "== 概览 ==\n"))
+
+	format, err := ParseStatsFormat("MD")
+	assert.NoError(t, err)
+	assert.Equal(t, StatsFormatMarkdown, format)
+	_, err = ParseStatsFormat("xml")
+	assert.Error(t, err)
+}
@@ -0,0 +1,168 @@
+package entity
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// TranslateChunkMaxWords 单次翻译请求的最大字数，超过时按段落继续拆分
+const TranslateChunkMaxWords = 1500
+
+// BlogTranslation 文章翻译记录
+type BlogTranslation struct {
+	ID         uint   `gorm:"id"`
+	CreatedAt  string `gorm:"created_at"`
+	UpdatedAt  string `gorm:"updated_at"`
+	SourcePath string `gorm:"source_path"` // 原文路径
+	TargetPath string `gorm:"target_path"` // 译文路径，例如 post.en.md
+	Lang       string `gorm:"lang"`        // 目标语言
+	SourceHash string `gorm:"source_hash"` // 翻译时原文内容hash
+	Stale      bool   `gorm:"stale"`       // 原文变更后标记为过期，需要重新翻译
+}
+
+func (t BlogTranslation) TableName() string {
+	return "blog_translations"
+}
+
+// TranslationCache 翻译片段的AI结果缓存，按请求(模型+提示词+原文+目标语言)的hash唯一，
+// 重复翻译未变化的章节时不再请求AI
+type TranslationCache struct {
+	ID        uint   `gorm:"id"`
+	CreatedAt string `gorm:"created_at"`
+	UpdatedAt string `gorm:"updated_at"`
+	CacheKey  string `gorm:"cache_key"` // 请求的sha256
+	Lang      string `gorm:"lang"`      // 目标语言
+	Model     string `gorm:"model"`
+	Content   string `gorm:"content"` // AI返回的译文(含占位符)
+}
+
+func (t TranslationCache) TableName() string {
+	return "translation_caches"
+}
+
+// ContentHash MD正文内容hash，用于判断正文是否变更
+func (md *BlogMD) ContentHash() string {
+	return fmt.Sprintf("%x", sha256.Sum256([]byte(md.MDContent)))
+}
+
+var (
+	mdHeadingRegex = regexp.MustCompile(`^#{1,6}\s`)
+	mdFenceRegex   = regexp.MustCompile("^\\s*(```|~~~)")
+)
+
+// SplitSections 按标题(代码块外的#)将正文拆分成章节，超过maxWords的章节再按空行段落拆分
+func SplitSections(content string, maxWords int) []string {
+	var sections []string
+	for _, section := range splitBlocks(content, mdHeadingRegex.MatchString, false) {
+		if wordsCount(section) <= maxWords {
+			sections = append(sections, section)
+			continue
+		}
+
+		// 章节过长，按段落打包
+		var chunk []string
+		chunkWords := 0
+		for _, para := range splitBlocks(section, func(line string) bool { return strings.TrimSpace(line) == "" }, true) {
+			words := wordsCount(para)
+			if len(chunk) > 0 && chunkWords+words > maxWords {
+				sections = append(sections, strings.Join(chunk, "\n\n"))
+				chunk, chunkWords = nil, 0
+			}
+			chunk = append(chunk, para)
+			chunkWords += words
+		}
+		if len(chunk) > 0 {
+			sections = append(sections, strings.Join(chunk, "\n\n"))
+		}
+	}
+
+	return sections
+}
+
+// splitBlocks 在代码块外遇到isBoundary的行时切分，dropBoundary表示分界行本身不保留
+func splitBlocks(content string, isBoundary func(line string) bool, dropBoundary bool) []string {
+	var blocks []string
+	var current []string
+	inFence := false
+	flush := func() {
+		if block := strings.Trim(strings.Join(current, "\n"), "\n"); block != "" {
+			blocks = append(blocks, block)
+		}
+		current = nil
+	}
+
+	for _, line := range strings.Split(content, "\n") {
+		if mdFenceRegex.MatchString(line) {
+			inFence = !inFence
+		} else if !inFence && isBoundary(line) {
+			flush()
+			if dropBoundary {
+				continue
+			}
+		}
+		current = append(current, line)
+	}
+	flush()
+
+	return blocks
+}
+
+// 翻译时需要原样保留的内容，按顺序替换为占位符
+var protectRegexes = []*regexp.Regexp{
+	regexp.MustCompile("(?s)```.*?```|~~~.*?~~~"),                                                // 代码块
+	regexp.MustCompile(`(?s)\{\{[<%]\s*highlight.*?[>%]\}\}.*?\{\{[<%]\s*/highlight\s*[>%]\}\}`), // 高亮shortcode整体保留
+	regexp.MustCompile(`(?s)\{\{<.*?>\}\}|\{\{%.*?%\}\}`),                                        // shortcode
+	regexp.MustCompile("`[^`\n]+`"),                                                              // 行内代码
+	regexp.MustCompile(`\]\([^)\n]*\)`),                                                          // 链接、图片地址
+	regexp.MustCompile(`https?://[^\s)>\]]+`),                                                    // 裸URL
+}
+
+// ProtectedText 替换了占位符的待翻译内容
+type ProtectedText struct {
+	Text         string   // 替换后的内容
+	Placeholders []string // 占位符对应的原始内容
+}
+
+var placeholderRegex = regexp.MustCompile(`⟦P\d+⟧`)
+
+func placeholder(i int) string {
+	return fmt.Sprintf("⟦P%d⟧", i)
+}
+
+// NeedTranslate 去掉占位符后是否还有需要翻译的文本
+func (pt *ProtectedText) NeedTranslate() bool {
+	return strings.TrimSpace(placeholderRegex.ReplaceAllString(pt.Text, "")) != ""
+}
+
+// ProtectContent 将代码块、行内代码、URL、shortcode替换为占位符，避免被翻译
+func ProtectContent(content string) *ProtectedText {
+	pt := &ProtectedText{Text: content}
+	for _, re := range protectRegexes {
+		pt.Text = re.ReplaceAllStringFunc(pt.Text, func(match string) string {
+			prefix := ""
+			if strings.HasPrefix(match, "](") { // 链接文字需要翻译，仅保留地址部分
+				prefix, match = "]", match[1:]
+			}
+			pt.Placeholders = append(pt.Placeholders, match)
+			return prefix + placeholder(len(pt.Placeholders)-1)
+		})
+	}
+	return pt
+}
+
+// Restore 将译文中的占位符还原为原始内容，占位符缺失时报错
+func (pt *ProtectedText) Restore(translated string) (string, error) {
+	// 倒序还原，后生成的占位符内容里可能包含先生成的占位符(例如highlight内的代码块)
+	for i := len(pt.Placeholders) - 1; i >= 0; i-- {
+		ph := placeholder(i)
+		if !strings.Contains(translated, ph) {
+			return "", errors.Errorf("translated content lost placeholder %s", ph)
+		}
+		translated = strings.ReplaceAll(translated, ph, pt.Placeholders[i])
+	}
+	return translated, nil
+}
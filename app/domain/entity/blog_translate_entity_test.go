@@ -0,0 +1,45 @@
+package entity
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitSections(t *testing.T) {
+	content := "intro\n\n## A\ntext a\n```\n# not heading\n```\n\n## B\ntext b"
+	sections := SplitSections(content, 100)
+	assert.Equal(t, []string{"intro", "## A\ntext a\n```\n# not heading\n```", "## B\ntext b"}, sections)
+
+	// 超长章节按段落拆分，代码块内的空行不拆分
+	long := "## Long\n" + strings.Repeat("word ", 8) + "\n\n```\ncode\n\ncode\n```\n\n" + strings.Repeat("word ", 8)
+	sections = SplitSections(long, 9)
+	assert.Equal(t, 3, len(sections))
+	assert.Equal(t, "```\ncode\n\ncode\n```", sections[1])
+}
+
+func TestProtectContent(t *testing.T) {
+	content := "看 `go run` 和 [文档](https://go.dev/doc) 以及 https://tkstorm.com\n" +
+		"```go\nfmt.Println(\"你好\")\n```\n{{< figure src=\"a.png\" >}}\n" +
+		"{{< highlight go >}}\n// 注释\n{{< /highlight >}}"
+	pt := ProtectContent(content)
+	assert.True(t, pt.NeedTranslate())
+	for _, keep := range []string{"go run", "https://", "fmt.Println", "figure", "注释"} {
+		assert.False(t, strings.Contains(pt.Text, keep), keep)
+	}
+	assert.True(t, strings.Contains(pt.Text, "[文档]"))
+
+	// 模拟翻译后还原
+	translated := strings.NewReplacer("看", "See", "和", "and", "文档", "docs", "以及", "also").Replace(pt.Text)
+	restored, err := pt.Restore(translated)
+	assert.NoError(t, err)
+	assert.Equal(t, strings.NewReplacer("看", "See", "和", "and", "[文档]", "[docs]", "以及", "also").Replace(content), restored)
+
+	// 占位符丢失
+	_, err = pt.Restore("lost")
+	assert.Error(t, err)
+
+	// 只有代码时无需翻译
+	assert.False(t, ProtectContent("```\ncode\n```").NeedTranslate())
+}
@@ -0,0 +1,190 @@
+package entity
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+// weightDateLayouts front matter中date支持的格式
+var weightDateLayouts = []string{time.RFC3339, "2006-01-02T15:04:05", "2006-01-02 15:04:05", "2006-01-02"}
+
+// WeightPinnedKeys front matter中为true时视为置顶/精选的字段
+var WeightPinnedKeys = []string{"pinned", "featured"}
+
+// RecencyWeightRule 时间权重：按文章日期指数衰减，发布当天调整MaxAdjust，每过HalfLifeDays减半
+type RecencyWeightRule struct {
+	HalfLifeDays float64
+	MaxAdjust    int
+}
+
+// WordBucketWeightRule 字数权重：字数>=MinWords的最大档位生效
+type WordBucketWeightRule struct {
+	MinWords int
+	Adjust   int
+}
+
+// InboundLinkWeightRule 被站内其他文章链接的权重：每个链接调整PerLink，合计不超过Max(同号)
+type InboundLinkWeightRule struct {
+	PerLink int
+	Max     int
+}
+
+// WeightRules 文章权重公式，Hugo按权重从小到大排序，调整值为负时排序靠前：
+// 权重 = Base + 时间权重 + 字数权重 + 置顶/精选 + 分类权重 + 站内链接权重，结果限制在[Min, Max]；
+// 字数少于MinWords的手稿权重固定为DraftWeight
+type WeightRules struct {
+	Base           int
+	MinWords       int
+	DraftWeight    int
+	Recency        *RecencyWeightRule
+	WordBuckets    []*WordBucketWeightRule
+	Pinned         int
+	CategoryBoosts map[string]int // 分类 => 调整值，多个分类累加
+	InboundLinks   *InboundLinkWeightRule
+	Min            int
+	Max            int
+}
+
+// DefaultWeightRules 默认权重：字数过少的为WeightLow，否则为WeightDefault
+func DefaultWeightRules() *WeightRules {
+	return &WeightRules{
+		Base:        WeightDefault,
+		MinWords:    ArticleDraftMinLength,
+		DraftWeight: WeightLow,
+	}
+}
+
+// WeightInput 计算权重的文章信息
+type WeightInput struct {
+	Words        int
+	Date         string
+	Pinned       bool
+	Categories   []string
+	InboundLinks int
+}
+
+// WeightScore 权重及各部分的调整值
+type WeightScore struct {
+	Weight   int  `json:"weight"`
+	Base     int  `json:"base"`
+	Recency  int  `json:"recency"`
+	Words    int  `json:"words"`
+	Pinned   int  `json:"pinned"`
+	Category int  `json:"category"`
+	Links    int  `json:"links"`
+	Draft    bool `json:"draft"` // 字数过少，使用DraftWeight
+}
+
+// Calc 按公式计算权重，now用于计算时间权重
+func (r *WeightRules) Calc(in *WeightInput, now time.Time) *WeightScore {
+	if in.Words < r.MinWords {
+		return &WeightScore{Weight: r.DraftWeight, Draft: true}
+	}
+
+	score := &WeightScore{Base: r.Base}
+	if r.Recency != nil && r.Recency.HalfLifeDays > 0 {
+		if date, ok := parseWeightDate(in.Date); ok {
+			days := math.Max(now.Sub(date).Hours()/24, 0)
+			score.Recency = int(math.Round(float64(r.Recency.MaxAdjust) * math.Pow(0.5, days/r.Recency.HalfLifeDays)))
+		}
+	}
+	matched := -1
+	for _, bucket := range r.WordBuckets {
+		if in.Words >= bucket.MinWords && bucket.MinWords > matched {
+			matched, score.Words = bucket.MinWords, bucket.Adjust
+		}
+	}
+	if in.Pinned {
+		score.Pinned = r.Pinned
+	}
+	for _, category := range in.Categories {
+		score.Category += r.CategoryBoosts[strings.ToLower(category)]
+	}
+	if r.InboundLinks != nil {
+		score.Links = r.InboundLinks.PerLink * in.InboundLinks
+		if max := r.InboundLinks.Max; max != 0 && math.Abs(float64(score.Links)) > math.Abs(float64(max)) {
+			score.Links = max
+		}
+	}
+
+	score.Weight = score.Base + score.Recency + score.Words + score.Pinned + score.Category + score.Links
+	if r.Min != 0 && score.Weight < r.Min {
+		score.Weight = r.Min
+	}
+	if r.Max != 0 && score.Weight > r.Max {
+		score.Weight = r.Max
+	}
+	return score
+}
+
+// WeightInput 文章计算权重的信息，inboundLinks为被站内其他文章链接的次数
+func (md *BlogMD) WeightInput(inboundLinks int) *WeightInput {
+	return &WeightInput{
+		Words:        md.MDHeader.WordCounts,
+		Date:         md.MDHeader.Date,
+		Pinned:       md.MDHeader.IsPinned(),
+		Categories:   md.MDHeader.Categories,
+		InboundLinks: inboundLinks,
+	}
+}
+
+// IsPinned front matter中设置了 pinned: true 或 featured: true
+func (y *YamlHeader) IsPinned() bool {
+	for _, key := range WeightPinnedKeys {
+		if v, ok := y.Extra[key].(bool); ok && v {
+			return true
+		}
+	}
+	return false
+}
+
+// WeightChange 权重预览：文章新旧权重及按Hugo排序的名次
+type WeightChange struct {
+	Path      string       `json:"path"`
+	Title     string       `json:"title"`
+	Date      string       `json:"date"`
+	OldWeight int          `json:"old_weight"`
+	NewWeight int          `json:"new_weight"`
+	OldRank   int          `json:"old_rank"`
+	NewRank   int          `json:"new_rank"`
+	Score     *WeightScore `json:"score"`
+}
+
+// RankWeightChanges 按Hugo的默认排序(权重升序、日期倒序、标题)分别计算新旧名次，返回按新名次排序的结果
+func RankWeightChanges(changes []*WeightChange) []*WeightChange {
+	rank := func(weight func(c *WeightChange) int, set func(c *WeightChange, rank int)) {
+		sorted := append([]*WeightChange(nil), changes...)
+		sort.SliceStable(sorted, func(i, j int) bool {
+			a, b := sorted[i], sorted[j]
+			if weight(a) != weight(b) {
+				return weight(a) < weight(b)
+			}
+			if a.Date != b.Date {
+				return a.Date > b.Date
+			}
+			return a.Title < b.Title
+		})
+		for i, c := range sorted {
+			set(c, i+1)
+		}
+	}
+	rank(func(c *WeightChange) int { return c.OldWeight }, func(c *WeightChange, r int) { c.OldRank = r })
+	rank(func(c *WeightChange) int { return c.NewWeight }, func(c *WeightChange, r int) { c.NewRank = r })
+
+	sorted := append([]*WeightChange(nil), changes...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].NewRank < sorted[j].NewRank })
+	return sorted
+}
+
+// parseWeightDate 解析front matter中的日期
+func parseWeightDate(date string) (time.Time, bool) {
+	date = strings.TrimSpace(date)
+	for _, layout := range weightDateLayouts {
+		if t, err := time.Parse(layout, date); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,62 @@
+package entity
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWeightRules_Calc(t *testing.T) {
+	now := time.Date(2024, 1, 11, 0, 0, 0, 0, time.UTC)
+
+	// 默认公式与原有权重一致
+	assert.Equal(t, WeightDefault, DefaultWeightRules().Calc(&WeightInput{Words: 100}, now).Weight)
+	assert.Equal(t, WeightLow, DefaultWeightRules().Calc(&WeightInput{Words: 10}, now).Weight)
+
+	rules := &WeightRules{
+		Base:           100,
+		MinWords:       50,
+		DraftWeight:    200,
+		Recency:        &RecencyWeightRule{HalfLifeDays: 10, MaxAdjust: -40},
+		WordBuckets:    []*WordBucketWeightRule{{MinWords: 0, Adjust: 20}, {MinWords: 5000, Adjust: -10}, {MinWords: 1000, Adjust: 0}},
+		Pinned:         -80,
+		CategoryBoosts: map[string]int{"golang": -10},
+		InboundLinks:   &InboundLinkWeightRule{PerLink: -2, Max: -5},
+		Min:            1,
+	}
+	score := rules.Calc(&WeightInput{Words: 1200, Date: "2024-01-01", Categories: []string{"Golang"}, InboundLinks: 4}, now)
+	assert.Equal(t, &WeightScore{Weight: 65, Base: 100, Recency: -20, Words: 0, Category: -10, Links: -5}, score)
+
+	// 无法解析日期时不调整，短文章落在最低档
+	score = rules.Calc(&WeightInput{Words: 300, Date: "unknown"}, now)
+	assert.Equal(t, 120, score.Weight)
+
+	// 置顶长文结果限制在Min
+	score = rules.Calc(&WeightInput{Words: 6000, Date: "2024-01-11T00:00:00Z", Pinned: true}, now)
+	assert.Equal(t, -10, score.Words)
+	assert.Equal(t, -80, score.Pinned)
+	assert.Equal(t, 1, score.Weight)
+
+	score = rules.Calc(&WeightInput{Words: 10, Pinned: true}, now)
+	assert.Equal(t, &WeightScore{Weight: 200, Draft: true}, score)
+}
+
+func TestYamlHeader_IsPinned(t *testing.T) {
+	assert.False(t, (&YamlHeader{}).IsPinned())
+	assert.True(t, (&YamlHeader{Extra: map[string]interface{}{"featured": true}}).IsPinned())
+	assert.False(t, (&YamlHeader{Extra: map[string]interface{}{"pinned": "yes"}}).IsPinned())
+}
+
+func TestRankWeightChanges(t *testing.T) {
+	changes := RankWeightChanges([]*WeightChange{
+		{Title: "C", Date: "2022-01-01", OldWeight: 200, NewWeight: 100},
+		{Title: "B", Date: "2024-01-01", OldWeight: 100, NewWeight: 100},
+		{Title: "A", Date: "2023-01-01", OldWeight: 100, NewWeight: 50},
+	})
+	var got [][3]interface{}
+	for _, c := range changes {
+		got = append(got, [3]interface{}{c.Title, c.OldRank, c.NewRank})
+	}
+	assert.Equal(t, [][3]interface{}{{"A", 2, 1}, {"B", 1, 2}, {"C", 3, 3}}, got)
+}
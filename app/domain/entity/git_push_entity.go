@@ -0,0 +1,58 @@
+package entity
+
+import (
+	"sort"
+	"strings"
+)
+
+// GitPushCommit 推送中的单个提交，文件路径相对于仓库根目录
+type GitPushCommit struct {
+	ID       string
+	Added    []string
+	Modified []string
+	Removed  []string
+}
+
+// GitPush git推送事件(GitHub/Gitea/GitLab的push webhook)
+type GitPush struct {
+	Provider string           // github、gitea、gitlab
+	Ref      string           // 推送的分支，例如 refs/heads/main
+	Before   string           // 推送前的提交
+	After    string           // 推送后的提交
+	Commits  []*GitPushCommit // 按提交顺序
+}
+
+// Branch 推送的分支名，非分支推送(例如tag)时返回空
+func (p *GitPush) Branch() string {
+	if !strings.HasPrefix(p.Ref, "refs/heads/") {
+		return ""
+	}
+	return strings.TrimPrefix(p.Ref, "refs/heads/")
+}
+
+// IsDeleted 是否为删除分支的推送
+func (p *GitPush) IsDeleted() bool {
+	return strings.Trim(p.After, "0") == ""
+}
+
+// ChangedFiles 按提交顺序合并新增、修改的文件，之后被删除的文件不包含在内，按路径排序
+func (p *GitPush) ChangedFiles() []string {
+	changed := make(map[string]bool)
+	for _, commit := range p.Commits {
+		for _, files := range [][]string{commit.Added, commit.Modified} {
+			for _, file := range files {
+				changed[file] = true
+			}
+		}
+		for _, file := range commit.Removed {
+			delete(changed, file)
+		}
+	}
+
+	files := make([]string, 0, len(changed))
+	for file := range changed {
+		files = append(files, file)
+	}
+	sort.Strings(files)
+	return files
+}
@@ -0,0 +1,115 @@
+package entity
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+	"net/http"
+	"path"
+	"strings"
+)
+
+// 图床支持上传的图片类型 => 扩展名，svg可能包含脚本，不支持
+var hostedImageExts = map[string]string{
+	"image/png":  ".png",
+	"image/jpeg": ".jpg",
+	"image/gif":  ".gif",
+	"image/webp": ".webp",
+}
+
+// DetectImageType 按内容识别上传图片的类型及扩展名，不支持的类型返回空
+func DetectImageType(data []byte) (mimeType, ext string) {
+	mimeType = http.DetectContentType(data)
+	if ext = hostedImageExts[mimeType]; ext == "" {
+		return "", ""
+	}
+	return mimeType, ext
+}
+
+// ImageHash 图片内容的sha256，作为图床中图片的唯一标识
+func ImageHash(data []byte) string {
+	return fmt.Sprintf("%x", sha256.Sum256(data))
+}
+
+// ImageStorageKey 按内容寻址的存储路径：<hash前2位>/<hash 3-4位>/<hash><suffix><ext>，suffix区分缩放尺寸
+func ImageStorageKey(hash, suffix, ext string) string {
+	return path.Join(hash[:2], hash[2:4], hash+suffix+ext)
+}
+
+// MarkdownImage Markdown图片引用，alt中的方括号被去除
+func MarkdownImage(alt, url string) string {
+	alt = strings.NewReplacer("[", "", "]", "", "\n", " ").Replace(alt)
+	return fmt.Sprintf("![%s](%s)", alt, url)
+}
+
+// ImageVariant 图片的缩放、WebP版本
+type ImageVariant struct {
+	Name     string `json:"name"` // 例如 w960、webp、w960.webp
+	Key      string `json:"key"`  // 存储路径
+	MimeType string `json:"mime_type"`
+	Width    int    `json:"width"`
+	Height   int    `json:"height"`
+	Size     int    `json:"size"`
+}
+
+// HostedImage 图床中的图片，按内容hash唯一，相同内容重复上传时直接返回
+type HostedImage struct {
+	ID         uint   `gorm:"id"`
+	CreatedAt  string `gorm:"created_at"`
+	UpdatedAt  string `gorm:"updated_at"`
+	Hash       string `gorm:"hash"`        // 内容的sha256
+	Name       string `gorm:"name"`        // 首次上传时的文件名
+	MimeType   string `gorm:"mime_type"`   // 图片类型
+	StorageKey string `gorm:"storage_key"` // 原图的存储路径
+	Size       int    `gorm:"size"`        // 原图字节数
+	Width      int    `gorm:"width"`
+	Height     int    `gorm:"height"`
+	Variants   string `gorm:"variants"` // json数组格式的缩放、WebP版本
+}
+
+func (t HostedImage) TableName() string {
+	return "images"
+}
+
+// ResizeImage 按宽度等比缩小图片(面积平均，缩小时不产生锯齿)，宽度不小于原图时返回原图
+func ResizeImage(src image.Image, width int) image.Image {
+	b := src.Bounds()
+	if width <= 0 || width >= b.Dx() {
+		return src
+	}
+	height := int(math.Max(1, math.Round(float64(b.Dy())*float64(width)/float64(b.Dx()))))
+	dst := image.NewNRGBA(image.Rect(0, 0, width, height))
+	sx, sy := float64(b.Dx())/float64(width), float64(b.Dy())/float64(height)
+
+	for y := 0; y < height; y++ {
+		y0, y1 := float64(y)*sy, float64(y+1)*sy
+		for x := 0; x < width; x++ {
+			x0, x1 := float64(x)*sx, float64(x+1)*sx
+			// 按覆盖面积加权平均原图像素(预乘alpha)
+			var r, g, bl, a, total float64
+			for py := int(y0); float64(py) < y1; py++ {
+				wy := math.Min(y1, float64(py+1)) - math.Max(y0, float64(py))
+				for px := int(x0); float64(px) < x1; px++ {
+					wx := math.Min(x1, float64(px+1)) - math.Max(x0, float64(px))
+					w := wx * wy
+					cr, cg, cb, ca := src.At(b.Min.X+px, b.Min.Y+py).RGBA()
+					r, g, bl, a = r+w*float64(cr), g+w*float64(cg), bl+w*float64(cb), a+w*float64(ca)
+					total += w
+				}
+			}
+			if a == 0 {
+				continue
+			}
+			// 转换回非预乘alpha
+			dst.SetNRGBA(x, y, color.NRGBA{
+				R: uint8(r/a*255 + 0.5),
+				G: uint8(g/a*255 + 0.5),
+				B: uint8(bl/a*255 + 0.5),
+				A: uint8(a/total/257 + 0.5),
+			})
+		}
+	}
+	return dst
+}
@@ -0,0 +1,70 @@
+package entity
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDetectImageType(t *testing.T) {
+	var b bytes.Buffer
+	assert.NoError(t, png.Encode(&b, image.NewGray(image.Rect(0, 0, 2, 2))))
+	mimeType, ext := DetectImageType(b.Bytes())
+	assert.Equal(t, "image/png", mimeType)
+	assert.Equal(t, ".png", ext)
+
+	mimeType, ext = DetectImageType([]byte("\xff\xd8\xff\xe0jpeg"))
+	assert.Equal(t, "image/jpeg", mimeType)
+	assert.Equal(t, ".jpg", ext)
+
+	for _, data := range []string{`<svg xmlns="http://www.w3.org/2000/svg"></svg>`, "plain text", ""} {
+		mimeType, ext = DetectImageType([]byte(data))
+		assert.Empty(t, mimeType, data)
+		assert.Empty(t, ext, data)
+	}
+}
+
+func TestImageStorageKey(t *testing.T) {
+	hash := ImageHash([]byte("png"))
+	assert.Len(t, hash, 64)
+	assert.Equal(t, hash[:2]+"/"+hash[2:4]+"/"+hash+".png", ImageStorageKey(hash, "", ".png"))
+	assert.Equal(t, hash[:2]+"/"+hash[2:4]+"/"+hash+"_w960.webp", ImageStorageKey(hash, "_w960", ".webp"))
+}
+
+func TestMarkdownImage(t *testing.T) {
+	assert.Equal(t, "![Go调度](/i/ab/cd/x.png)", MarkdownImage("Go调度", "/i/ab/cd/x.png"))
+	assert.Equal(t, "![a b link](/i/x.png)", MarkdownImage("a\nb [link]", "/i/x.png"))
+}
+
+func TestResizeImage(t *testing.T) {
+	// 左半黑、右半白，缩小后保持比例及平均颜色
+	src := image.NewNRGBA(image.Rect(0, 0, 400, 200))
+	for y := 0; y < 200; y++ {
+		for x := 0; x < 400; x++ {
+			c := color.NRGBA{A: 255}
+			if x >= 200 {
+				c = color.NRGBA{R: 255, G: 255, B: 255, A: 255}
+			}
+			src.SetNRGBA(x, y, c)
+		}
+	}
+	dst := ResizeImage(src, 100)
+	assert.Equal(t, image.Pt(100, 50), dst.Bounds().Size())
+	assert.Equal(t, color.NRGBA{A: 255}, color.NRGBAModel.Convert(dst.At(10, 10)))
+	assert.Equal(t, color.NRGBA{R: 255, G: 255, B: 255, A: 255}, color.NRGBAModel.Convert(dst.At(90, 40)))
+
+	// 非整数倍缩放时边界像素为两侧颜色的平均
+	dst = ResizeImage(src, 3)
+	assert.Equal(t, image.Pt(3, 2), dst.Bounds().Size())
+	r, _, _, a := dst.At(1, 0).RGBA()
+	assert.Equal(t, uint32(0xffff), a)
+	assert.InDelta(t, 0x7fff, r, 0x200)
+
+	// 宽度不小于原图时返回原图
+	assert.Equal(t, image.Image(src), ResizeImage(src, 400))
+	assert.Equal(t, image.Image(src), ResizeImage(src, 0))
+}
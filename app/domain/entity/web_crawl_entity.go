@@ -0,0 +1,29 @@
+package entity
+
+import "strings"
+
+// CrawlVisit 爬虫访问过的url，用于断点续爬及基于ETag/Last-Modified的增量爬取
+type CrawlVisit struct {
+	ID           uint   `gorm:"id"`
+	CreatedAt    string `gorm:"created_at"`
+	UpdatedAt    string `gorm:"updated_at"`
+	Url          string `gorm:"url"`           // 规范化后的请求地址
+	StatusCode   int    `gorm:"status_code"`   // 最近一次响应的状态码(200或304)
+	Etag         string `gorm:"etag"`          // 响应的ETag，下次请求时作为If-None-Match
+	LastModified string `gorm:"last_modified"` // 响应的Last-Modified，下次请求时作为If-Modified-Since
+	ContentHash  string `gorm:"content_hash"`  // 页面内容hash，服务端不支持条件请求时据此判断是否变化
+	Links        string `gorm:"links"`         // 页面内同host的url，换行分隔，页面未变化时据此继续BFS
+	VisitedAt    string `gorm:"visited_at"`    // 最近一次访问时间
+}
+
+func (t CrawlVisit) TableName() string {
+	return "crawl_visits"
+}
+
+// LinkList 页面内同host的url列表
+func (t *CrawlVisit) LinkList() []string {
+	if t.Links == "" {
+		return nil
+	}
+	return strings.Split(t.Links, "\n")
+}
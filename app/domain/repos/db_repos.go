@@ -7,6 +7,7 @@ import (
 )
 
 type IReposSQLiteBlogSummary interface {
+	IReposSQLiteJob
 
 	// InitBlogSummaryDB 初始化blog_summary.db sqlite数据库
 	InitBlogSummaryDB(ctx context.Context) error
@@ -14,6 +15,18 @@ type IReposSQLiteBlogSummary interface {
 	// SelBlogMDRecord 查询是否有Path处理的记录
 	SelBlogMDRecord(ctx context.Context, path string) (*entity.BlogArticle, error)
 
+	// SelBlogMDRecordByID 通过ID查询文章记录，不存在时返回nil
+	SelBlogMDRecordByID(ctx context.Context, id uint) (*entity.BlogArticle, error)
+
+	// SelBlogMDRecords 按条件分页查询文章记录，返回当前页记录和总数
+	SelBlogMDRecords(ctx context.Context, query *entity.ArticleQuery) (records []*entity.BlogArticle, total int64, err error)
+
+	// AddSummaryHistory 新增一条AI生成的摘要历史
+	AddSummaryHistory(ctx context.Context, history *entity.BlogSummaryHistory) error
+
+	// SelSummaryHistories 查询文章的摘要历史，按时间倒序
+	SelSummaryHistories(ctx context.Context, path string) ([]*entity.BlogSummaryHistory, error)
+
 	// CleanAllBlogSummaryDB 清理整个数据库记录
 	CleanAllBlogSummaryDB(ctx context.Context) error
 
@@ -25,4 +38,176 @@ type IReposSQLiteBlogSummary interface {
 
 	// ReplaceBlogMDRecord 当文档不存在时候新增，存在时候更新md内容
 	ReplaceBlogMDRecord(ctx context.Context, md *entity.BlogMD) error
+
+	// SelTranslationRecord 查询原文指定语言的翻译记录，不存在时返回nil
+	SelTranslationRecord(ctx context.Context, sourcePath, lang string) (*entity.BlogTranslation, error)
+
+	// ReplaceTranslationRecord 新增或更新翻译记录(按原文路径+语言)
+	ReplaceTranslationRecord(ctx context.Context, translation *entity.BlogTranslation) error
+
+	// MarkTranslationsStale 原文内容hash变化时，将该原文的翻译记录标记为过期
+	MarkTranslationsStale(ctx context.Context, sourcePath, sourceHash string) error
+
+	// SelBlogMDRecordByShortMark 通过短标记查询文章记录，不存在时返回nil
+	SelBlogMDRecordByShortMark(ctx context.Context, shortMark string) (*entity.BlogArticle, error)
+
+	// IsShortMarkTaken 短标记是否已被其他文章(path不同)使用
+	IsShortMarkTaken(ctx context.Context, shortMark, excludePath string) (bool, error)
+
+	// IsSlugTaken slug是否已被其他文章(path不同)使用
+	IsSlugTaken(ctx context.Context, slug, excludePath string) (bool, error)
+
+	// IsTitleTaken 标题是否与其他文章的标题或SEO标题重复
+	IsTitleTaken(ctx context.Context, title, excludePath string) (bool, error)
+
+	// UpdateBlogMDContentHash 更新文章最近一次同步时的正文hash(用于判断摘要是否过期)
+	UpdateBlogMDContentHash(ctx context.Context, path, contentHash string) error
+
+	// UpdateBlogMDWeight 更新文章权重及被站内链接的次数
+	UpdateBlogMDWeight(ctx context.Context, path string, weight, inboundLinks int) error
+
+	// SelSummaryHistory 通过ID查询摘要历史版本，不存在时返回nil
+	SelSummaryHistory(ctx context.Context, id uint) (*entity.BlogSummaryHistory, error)
+
+	// SelSummaryHistoriesByStatus 按审核状态查询摘要历史版本，按ID正序
+	SelSummaryHistoriesByStatus(ctx context.Context, status entity.SummaryStatus) ([]*entity.BlogSummaryHistory, error)
+
+	// UpdateSummaryHistory 更新摘要历史版本的内容及审核状态
+	UpdateSummaryHistory(ctx context.Context, history *entity.BlogSummaryHistory) error
+
+	// IsAliasTaken 别名是否已被其他文章使用
+	IsAliasTaken(ctx context.Context, alias, excludePath string) (bool, error)
+
+	// SelAllBlogMDRecords 查询所有文章记录，用于全站统计
+	SelAllBlogMDRecords(ctx context.Context) ([]*entity.BlogArticle, error)
+
+	// SelBlogMDRecordsByDir 查询目录下(含子目录)的文章记录
+	SelBlogMDRecordsByDir(ctx context.Context, dir string) ([]*entity.BlogArticle, error)
+
+	// SelBlogSection 查询栏目摘要记录，不存在时返回nil
+	SelBlogSection(ctx context.Context, path string) (*entity.BlogSection, error)
+
+	// SelBlogSectionsByDir 查询目录下(含子目录)的栏目摘要记录
+	SelBlogSectionsByDir(ctx context.Context, dir string) ([]*entity.BlogSection, error)
+
+	// ReplaceBlogSection 新增或更新栏目摘要记录(按路径)
+	ReplaceBlogSection(ctx context.Context, section *entity.BlogSection) error
+
+	// SelArticleEmbedding 按文章路径查询文章的embedding缓存，不存在时返回nil
+	SelArticleEmbedding(ctx context.Context, path string) (*entity.ArticleEmbedding, error)
+
+	// ReplaceArticleEmbedding 新增或更新文章的embedding缓存(按文章路径)
+	ReplaceArticleEmbedding(ctx context.Context, embedding *entity.ArticleEmbedding) error
+
+	// SelImageAltText 按文章路径、图片地址查询图片的alt文字生成记录，不存在时返回nil
+	SelImageAltText(ctx context.Context, path, target string) (*entity.ImageAltText, error)
+
+	// ReplaceImageAltText 新增或更新图片的alt文字生成记录(按文章路径、图片地址)
+	ReplaceImageAltText(ctx context.Context, alt *entity.ImageAltText) error
+}
+
+// IReposSQLiteJob 摘要任务(jobs、job_items)的存储接口
+type IReposSQLiteJob interface {
+	// AddJob 新增任务，并为每个文件路径新增一条待处理的job item
+	AddJob(ctx context.Context, job *entity.SummaryJob, paths []string) error
+
+	// SelJob 查询任务，不存在时返回nil
+	SelJob(ctx context.Context, id uint) (*entity.SummaryJob, error)
+
+	// SelJobs 查询最近的任务，按ID倒序
+	SelJobs(ctx context.Context, limit int) ([]*entity.SummaryJob, error)
+
+	// SelUnfinishedJobByPath 查询指定路径未结束的(按路径扫描)任务，不存在时返回nil
+	SelUnfinishedJobByPath(ctx context.Context, path string) (*entity.SummaryJob, error)
+
+	// SelJobByRef 查询git提交触发的最近一次任务，不存在时返回nil
+	SelJobByRef(ctx context.Context, ref string) (*entity.SummaryJob, error)
+
+	// SelLastSucceededJob 查询指定路径最近一次成功且记录了git提交的任务，不存在时返回nil
+	SelLastSucceededJob(ctx context.Context, path string) (*entity.SummaryJob, error)
+
+	// UpdateJobCommit 更新任务记录的git提交
+	UpdateJobCommit(ctx context.Context, jobID uint, commit string) error
+
+	// SelJobItems 查询任务的文件明细，statuses为空时查询全部
+	SelJobItems(ctx context.Context, jobID uint, statuses ...entity.JobItemStatus) ([]*entity.SummaryJobItem, error)
+
+	// ClaimJobItems 领取待处理的文件(标记为running)，jobID为0时不限任务
+	ClaimJobItems(ctx context.Context, jobID uint, limit int) ([]*entity.SummaryJobItem, error)
+
+	// UpdateJobItem 更新文件处理状态
+	UpdateJobItem(ctx context.Context, item *entity.SummaryJobItem) error
+
+	// RefreshJobStats 按文件明细重新统计任务进度，全部处理完时更新任务的结束状态，
+	// finished为本次调用将任务标记为结束，同一任务只会返回一次true
+	RefreshJobStats(ctx context.Context, jobID uint) (job *entity.SummaryJob, finished bool, err error)
+
+	// ResetRunningJobItems 将中断(running)的文件重置为待处理，jobID为0时不限任务
+	ResetRunningJobItems(ctx context.Context, jobID uint) (int64, error)
+}
+
+// IReposSQLiteWebClip 网页摘录(web_clips)的存储接口
+type IReposSQLiteWebClip interface {
+	// SelWebClip 按网页地址查询摘录记录，不存在时返回nil
+	SelWebClip(ctx context.Context, url string) (*entity.WebClip, error)
+
+	// ReplaceWebClip 新增或更新网页摘录记录(按url)
+	ReplaceWebClip(ctx context.Context, clip *entity.WebClip) error
+}
+
+// IReposSQLiteFeed 订阅源(feeds、feed_entries)的存储接口
+type IReposSQLiteFeed interface {
+	// SelFeed 按订阅地址查询订阅源，不存在时返回nil
+	SelFeed(ctx context.Context, url string) (*entity.Feed, error)
+
+	// ReplaceFeed 新增或更新订阅源(按url)
+	ReplaceFeed(ctx context.Context, feed *entity.Feed) error
+
+	// SelFeedEntry 按订阅地址及guid查询订阅文章，不存在时返回nil
+	SelFeedEntry(ctx context.Context, feedUrl, guid string) (*entity.FeedEntry, error)
+
+	// SelFeedEntriesByFilepath 查询写入同一文件(每日摘要文章)的订阅文章，按记录顺序
+	SelFeedEntriesByFilepath(ctx context.Context, path string) ([]*entity.FeedEntry, error)
+
+	// AddFeedEntry 新增订阅文章记录
+	AddFeedEntry(ctx context.Context, entry *entity.FeedEntry) error
+}
+
+// IReposSQLiteLinkCheck 站外链接检查结果(link_checks)的存储接口
+type IReposSQLiteLinkCheck interface {
+	// SelLinkCheck 按url查询站外链接的检查结果，不存在时返回nil
+	SelLinkCheck(ctx context.Context, url string) (*entity.LinkCheck, error)
+
+	// ReplaceLinkCheck 新增或更新站外链接的检查结果(按url)
+	ReplaceLinkCheck(ctx context.Context, check *entity.LinkCheck) error
+}
+
+// IReposSQLiteCoverImage 文章封面图生成记录(cover_images)的存储接口
+type IReposSQLiteCoverImage interface {
+	// SelCoverImage 按文章路径查询文章封面图的生成记录，不存在时返回nil
+	SelCoverImage(ctx context.Context, path string) (*entity.CoverImage, error)
+
+	// ReplaceCoverImage 新增或更新文章封面图的生成记录(按文章路径)
+	ReplaceCoverImage(ctx context.Context, cover *entity.CoverImage) error
+}
+
+// IReposSQLiteOGCard 社交分享卡片生成记录(og_cards)的存储接口
+type IReposSQLiteOGCard interface {
+	// SelOGCard 按文章路径查询文章社交分享卡片的生成记录，不存在时返回nil
+	SelOGCard(ctx context.Context, path string) (*entity.OGCardImage, error)
+
+	// ReplaceOGCard 新增或更新文章社交分享卡片的生成记录(按文章路径)
+	ReplaceOGCard(ctx context.Context, card *entity.OGCardImage) error
+}
+
+// IReposSQLiteHostedImage 图床图片(hosted_images)的存储接口
+type IReposSQLiteHostedImage interface {
+	// SelHostedImage 按内容hash查询图床中的图片，不存在时返回nil
+	SelHostedImage(ctx context.Context, hash string) (*entity.HostedImage, error)
+
+	// ReplaceHostedImage 新增或更新图床中的图片(按内容hash)
+	ReplaceHostedImage(ctx context.Context, img *entity.HostedImage) error
+
+	// ListHostedImages 最近上传的图片，按上传时间倒序
+	ListHostedImages(ctx context.Context, limit int) ([]*entity.HostedImage, error)
 }
@@ -0,0 +1,23 @@
+package repos
+
+import (
+	"context"
+)
+
+// IReposGit 负责和本地blog git仓库交互的接口
+type IReposGit interface {
+	// Root 仓库根目录(绝对路径)
+	Root() string
+
+	// Pull 拉取远端更新(仅fast-forward)
+	Pull(ctx context.Context) error
+
+	// Head 当前HEAD的提交
+	Head(ctx context.Context) (string, error)
+
+	// ChangedFiles 自since以来(含工作区未提交、未跟踪的)新增、修改的文件，路径相对于仓库根目录
+	ChangedFiles(ctx context.Context, since string) ([]string, error)
+
+	// Commit 提交指定文件的改动，没有改动时返回空提交
+	Commit(ctx context.Context, message string, files []string) (string, error)
+}
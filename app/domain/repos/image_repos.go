@@ -0,0 +1,41 @@
+package repos
+
+import (
+	"context"
+	"io"
+
+	"github.com/lupguo/copilot_develop/app/domain/entity"
+)
+
+// IReposImageGenerator 文生图服务的接口(OpenAI Images、本地Stable Diffusion等)
+type IReposImageGenerator interface {
+	// GenerateImage 按提示词生成一张图片
+	GenerateImage(ctx context.Context, req *entity.ImageGenRequest) (*entity.GeneratedImage, error)
+}
+
+// IReposCardRenderer 社交分享卡片(Open Graph图片)渲染的接口
+type IReposCardRenderer interface {
+	// RenderCard 将卡片内容渲染为PNG图片
+	RenderCard(ctx context.Context, card *entity.OGCard) ([]byte, error)
+}
+
+// IReposImageStorage 图床的存储后端(本地目录，之后可扩展S3兼容存储)，按存储路径(key)读写
+type IReposImageStorage interface {
+	// Put 保存图片，已存在时覆盖
+	Put(ctx context.Context, key string, data []byte, mimeType string) error
+
+	// Open 读取图片，不存在时返回的错误满足 errors.Is(err, fs.ErrNotExist)
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+
+	// Exists 图片是否存在
+	Exists(ctx context.Context, key string) (bool, error)
+
+	// URL 图片的访问地址
+	URL(key string) string
+}
+
+// IReposWebPEncoder WebP编码(标准库只能解码常见格式，WebP编码由cwebp等外部工具实现)
+type IReposWebPEncoder interface {
+	// EncodeWebP 将PNG、JPEG图片编码为WebP，quality为0-100
+	EncodeWebP(ctx context.Context, data []byte, quality int) ([]byte, error)
+}
@@ -3,6 +3,7 @@ package repos
 import (
 	"context"
 
+	"github.com/lupguo/copilot_develop/app/domain/entity"
 	"github.com/sashabaranov/go-openai"
 )
 
@@ -16,4 +17,16 @@ type IReposOpenAI interface {
 
 	// DoAIChatCompletionRequest 通用的AI ChatCompletion代理请求
 	DoAIChatCompletionRequest(ctx context.Context, req *openai.ChatCompletionRequest) (response *openai.ChatCompletionResponse, err error)
+
+	// DoAIEmbeddingRequest 通用的AI Embedding代理请求
+	DoAIEmbeddingRequest(ctx context.Context, req *openai.EmbeddingRequest) (response *openai.EmbeddingResponse, err error)
+}
+
+// IReposTranslationCache 翻译结果缓存的持久化存储
+type IReposTranslationCache interface {
+	// SelTranslationCache 按请求hash查询缓存，不存在时返回nil
+	SelTranslationCache(ctx context.Context, cacheKey string) (*entity.TranslationCache, error)
+
+	// ReplaceTranslationCache 新增或更新缓存
+	ReplaceTranslationCache(ctx context.Context, cache *entity.TranslationCache) error
 }
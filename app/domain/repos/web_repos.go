@@ -0,0 +1,31 @@
+package repos
+
+import (
+	"context"
+
+	"github.com/lupguo/copilot_develop/app/domain/entity"
+)
+
+// IReposWebCrawler 抓取外部网页的接口
+type IReposWebCrawler interface {
+	// FetchReadable 抓取网页并提取正文(去除导航、侧栏等噪音)
+	FetchReadable(ctx context.Context, url string) (*entity.WebPage, error)
+
+	// FoundURLsFromUrlBFS 从url开始BFS，返回maxDepth内发现的同host url(含起始url)
+	FoundURLsFromUrlBFS(ctx context.Context, url string, maxDepth int) (urls []string, err error)
+
+	// FetchFeed 请求RSS/Atom订阅，etag、lastModified不为空时条件请求，未变化时返回NotModified
+	FetchFeed(ctx context.Context, url string, etag, lastModified string) (*entity.WebFeed, error)
+
+	// CheckLink 检查站外链接是否可访问，请求失败记录在结果的Error中
+	CheckLink(ctx context.Context, url string) (*entity.LinkCheck, error)
+}
+
+// IReposCrawlVisit 爬虫已访问url的持久化存储
+type IReposCrawlVisit interface {
+	// SelCrawlVisit 按url查询访问记录，不存在时返回nil
+	SelCrawlVisit(ctx context.Context, url string) (*entity.CrawlVisit, error)
+
+	// ReplaceCrawlVisit 新增或更新访问记录
+	ReplaceCrawlVisit(ctx context.Context, visit *entity.CrawlVisit) error
+}
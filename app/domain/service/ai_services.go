@@ -3,6 +3,8 @@ package service
 import (
 	"context"
 	"encoding/json"
+	"fmt"
+	"strings"
 
 	"github.com/lupguo/copilot_develop/app/domain/entity"
 	"github.com/lupguo/copilot_develop/app/domain/repos"
@@ -12,19 +14,51 @@ import (
 )
 
 const (
-	PromptKeySummaryBlog = "summary-blog"
+	PromptKeySummaryBlog    = "summary-blog"
+	PromptKeySEOBlog        = "seo-blog"
+	PromptKeySummarySection = "summary-section"
+	PromptKeySuggestLinks   = "suggest-links"
+	PromptKeyEmbedding      = "embedding"
+	PromptKeyAltText        = "alt-text"
+	PromptKeyAltTextVision  = "alt-text-vision"
 )
 
+// embeddingBatchSize 单次Embedding请求的文本数
+const embeddingBatchSize = 16
+
 // IServicesSummaryAI AI汇总服务接口
 type IServicesSummaryAI interface {
 	// SummaryBlogMD 摘要总结+关键字
 	SummaryBlogMD(ctx context.Context, md *entity.BlogMD) (summary *entity.ArticleSummary, err error)
+
+	// SummaryBlogMDLangs 按提示词配置的目标语言生成多语言摘要，未配置目标语言时返回nil
+	SummaryBlogMDLangs(ctx context.Context, md *entity.BlogMD) (summaries *entity.LangSummaries, err error)
+
+	// SuggestBlogSEO 生成SEO标题和slug建议，未配置seo-blog提示词时返回nil
+	SuggestBlogSEO(ctx context.Context, md *entity.BlogMD) (seo *entity.ArticleSEO, err error)
+
+	// SummarySection 基于子文章、子栏目的摘要汇总栏目(_index.md)的摘要+关键字
+	SummarySection(ctx context.Context, index *entity.BlogMD, children []*entity.SectionChild) (summary *entity.ArticleSummary, err error)
+
+	// SuggestLinkReplacements 从候选站内文章中为失效链接挑选替换链接，返回失效链接 => 候选url，未配置suggest-links提示词时返回nil
+	SuggestLinkReplacements(ctx context.Context, md *entity.BlogMD, issues []*entity.LinkIssue, candidates []*entity.LinkCandidate) (map[string]string, error)
+
+	// GenerateAltText 为文章中的图片生成alt文字，imageURL不为空时由alt-text-vision提示词的模型看图生成，
+	// 否则基于图片上下文生成，未配置alt-text提示词时返回空
+	GenerateAltText(ctx context.Context, md *entity.BlogMD, img *entity.AltTextImage, imageURL string) (string, error)
+
+	// EmbeddingModel embedding提示词配置的模型，未配置embedding提示词时返回空
+	EmbeddingModel() string
+
+	// EmbedTexts 批量生成文本的embedding向量，顺序与texts一致，未配置embedding提示词时返回nil
+	EmbedTexts(ctx context.Context, texts []string) ([][]float32, error)
 }
 
 // AIService AI汇总服务
 type AIService struct {
 	infra     repos.IReposOpenAI
 	promptCfg map[string]*openaix.Prompt
+	cache     *chatCache
 }
 
 // NewAIService 底层的SummaryAI服务
@@ -39,30 +73,93 @@ func NewAIService(infra repos.IReposOpenAI, promptFile string) (*AIService, erro
 	return &AIService{
 		infra:     infra,
 		promptCfg: promptCfg,
+		cache:     &chatCache{},
 	}, nil
 }
 
 // SummaryBlogMD 内容摘要+关键字总结
 func (srv *AIService) SummaryBlogMD(ctx context.Context, md *entity.BlogMD) (summary *entity.ArticleSummary, err error) {
 	// 获取指定key的提示词
-	prompt, err := openaix.GetPrompt("summary-blog")
+	prompt, err := openaix.GetPrompt(PromptKeySummaryBlog)
 	if err != nil {
 		return nil, errors.Wrap(err, "summary blog cannot found ai prompt key")
 	}
 
+	return srv.summaryBlogMD(ctx, prompt, md, nil)
+}
+
+// SummaryBlogMDLangs 按提示词配置的目标语言(跳过与源语言一致的)逐个生成摘要
+func (srv *AIService) SummaryBlogMDLangs(ctx context.Context, md *entity.BlogMD) (summaries *entity.LangSummaries, err error) {
+	prompt, err := openaix.GetPrompt(PromptKeySummaryBlog)
+	if err != nil {
+		return nil, errors.Wrap(err, "summary blog langs cannot found ai prompt key")
+	}
+	if len(prompt.Languages) == 0 {
+		return nil, nil
+	}
+
+	summaries = &entity.LangSummaries{
+		Output:    entity.LangOutputType(prompt.LangOutput),
+		Summaries: make(map[string]*entity.ArticleSummary),
+	}
+	if summaries.Output == "" {
+		summaries.Output = entity.LangOutputKeys
+	}
+
+	for _, lang := range prompt.Languages {
+		if lang == md.Lang {
+			continue
+		}
+
+		// 追加语言要求，json的key保持不变
+		langMsg := []openai.ChatCompletionMessage{{
+			Role:    openai.ChatMessageRoleSystem,
+			Content: fmt.Sprintf("json中的summary、description、keywords的值请使用%s输出，json的key保持不变", langName(lang)),
+		}}
+		summary, err := srv.summaryBlogMD(ctx, prompt, md, langMsg)
+		if err != nil {
+			return nil, errors.Wrapf(err, "summary blog in lang[%s] got err", lang)
+		}
+		summaries.Summaries[lang] = summary
+	}
+
+	return summaries, nil
+}
+
+// doChatCompletion 请求AI，并上报请求开始、token消耗的进度事件
+func (srv *AIService) doChatCompletion(ctx context.Context, req *openai.ChatCompletionRequest) (*openai.ChatCompletionResponse, error) {
+	event := entity.NewJobEvent(entity.JobEventAIRequest)
+	event.Model = req.Model
+	entity.EmitJobEvent(ctx, event)
+
+	resp, err := srv.infra.DoAIChatCompletionRequest(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	event = entity.NewJobEvent(entity.JobEventTokens)
+	event.Model, event.Tokens = req.Model, resp.Usage.TotalTokens
+	entity.EmitJobEvent(ctx, event)
+	return resp, nil
+}
+
+// summaryBlogMD 基于提示词请求AI生成摘要，extraMsgs会追加在预定义提示之后
+func (srv *AIService) summaryBlogMD(ctx context.Context, prompt *openaix.Prompt, md *entity.BlogMD, extraMsgs []openai.ChatCompletionMessage) (summary *entity.ArticleSummary, err error) {
 	// 组装请求内容消息
-	userMsg := []openai.ChatCompletionMessage{{
+	msgs := append([]openai.ChatCompletionMessage{}, prompt.PredefinedPrompts...)
+	msgs = append(msgs, extraMsgs...)
+	msgs = append(msgs, openai.ChatCompletionMessage{
 		Role:    openai.ChatMessageRoleUser,
 		Content: md.MiniData.MiniContent,
-	}}
+	})
 	req := &openai.ChatCompletionRequest{
 		Model:     prompt.AIMode,
 		MaxTokens: prompt.MaxTokens,
-		Messages:  append(prompt.PredefinedPrompts, userMsg...),
+		Messages:  msgs,
 	}
 
 	// 请求OpenAI获取响应
-	resp, err := srv.infra.DoAIChatCompletionRequest(ctx, req)
+	resp, err := srv.doChatCompletion(ctx, req)
 	if err != nil {
 		return nil, errors.Wrap(err, "infra do ai chat completion request got err")
 	}
@@ -75,9 +172,243 @@ func (srv *AIService) SummaryBlogMD(ctx context.Context, md *entity.BlogMD) (sum
 
 	// 检测summary结果
 	if summary.Summary == "" || summary.Keywords == "" || summary.Description == "" {
-		return nil, errors.Wrapf(err, "blog summary empty values, summary: %s\n keywords: %s\n, description: %s\n",
+		return nil, errors.Errorf("blog summary empty values, summary: %s\n keywords: %s\n, description: %s\n",
 			summary.Summary, summary.Keywords, summary.Description)
 	}
 
 	return summary, nil
 }
+
+// SuggestBlogSEO 基于标题和摘要生成SEO标题、slug建议
+func (srv *AIService) SuggestBlogSEO(ctx context.Context, md *entity.BlogMD) (seo *entity.ArticleSEO, err error) {
+	prompt, err := openaix.GetPrompt(PromptKeySEOBlog)
+	if err != nil {
+		return nil, nil
+	}
+
+	// 标题+摘要足以表达文章主题，摘要为空时使用精简内容
+	content := md.MDHeader.Summary
+	if content == "" {
+		content = md.MiniData.MiniContent
+	}
+	msgs := append([]openai.ChatCompletionMessage{}, prompt.PredefinedPrompts...)
+	msgs = append(msgs, openai.ChatCompletionMessage{
+		Role:    openai.ChatMessageRoleUser,
+		Content: fmt.Sprintf("标题: %s\n内容: %s", md.MDHeader.Title, content),
+	})
+	req := &openai.ChatCompletionRequest{
+		Model:     prompt.AIMode,
+		MaxTokens: prompt.MaxTokens,
+		Messages:  msgs,
+	}
+
+	resp, err := srv.doChatCompletion(ctx, req)
+	if err != nil {
+		return nil, errors.Wrap(err, "infra do ai chat completion request got err")
+	}
+
+	seo = &entity.ArticleSEO{}
+	if err := json.Unmarshal([]byte(resp.Choices[0].Message.Content), seo); err != nil {
+		return nil, errors.Wrap(err, "the blog seo received response from AI proxy, attempted to unmarshal resp content but got an error")
+	}
+	seo.Slug = entity.NormalizeSlug(seo.Slug)
+	if seo.Title == "" || seo.Slug == "" {
+		return nil, errors.Errorf("blog seo empty values, title: %s, slug: %s", seo.Title, seo.Slug)
+	}
+
+	return seo, nil
+}
+
+// SummarySection 栏目摘要，未配置summary-section提示词时沿用summary-blog提示词，并说明输入为子文章摘要列表
+func (srv *AIService) SummarySection(ctx context.Context, index *entity.BlogMD, children []*entity.SectionChild) (summary *entity.ArticleSummary, err error) {
+	var extraMsgs []openai.ChatCompletionMessage
+	prompt, err := openaix.GetPrompt(PromptKeySummarySection)
+	if err != nil {
+		if prompt, err = openaix.GetPrompt(PromptKeySummaryBlog); err != nil {
+			return nil, errors.Wrap(err, "summary section cannot found ai prompt key")
+		}
+		extraMsgs = append(extraMsgs, openai.ChatCompletionMessage{
+			Role:    openai.ChatMessageRoleSystem,
+			Content: "输入是博客一个栏目下各篇文章(或子栏目)的标题、关键词和摘要，请汇总出整个栏目的主题，json格式保持不变",
+		})
+	}
+
+	// 栏目标题+子项摘要列表作为内容
+	var b strings.Builder
+	fmt.Fprintf(&b, "栏目: %s\n", index.MDHeader.Title)
+	for _, child := range children {
+		fmt.Fprintf(&b, "\n- %s\n  关键词: %s\n  摘要: %s\n", child.Title, child.Keywords, child.Summary)
+	}
+	md := &entity.BlogMD{
+		Filepath: index.Filepath,
+		MDHeader: index.MDHeader,
+		MiniData: &entity.MiniData{MiniContent: b.String()},
+	}
+	return srv.summaryBlogMD(ctx, prompt, md, extraMsgs)
+}
+
+// SuggestLinkReplacements 将文章标题、失效链接(含链接文字)及候选文章列表交给AI，返回失效链接 => 替换的候选url，
+// 不在候选列表中的建议会被丢弃
+func (srv *AIService) SuggestLinkReplacements(ctx context.Context, md *entity.BlogMD, issues []*entity.LinkIssue,
+	candidates []*entity.LinkCandidate) (map[string]string, error) {
+	prompt, err := openaix.GetPrompt(PromptKeySuggestLinks)
+	if err != nil || len(issues) == 0 || len(candidates) == 0 {
+		return nil, nil
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "文章: %s\n\n失效链接:\n", md.MDHeader.Title)
+	for _, issue := range issues {
+		fmt.Fprintf(&b, "- [%s](%s)\n", issue.Text, issue.Target)
+	}
+	b.WriteString("\n候选文章:\n")
+	urls := make(map[string]bool, len(candidates))
+	for _, candidate := range candidates {
+		urls[candidate.Url] = true
+		fmt.Fprintf(&b, "- %s | %s | %s\n", candidate.Url, candidate.Title, candidate.Keywords)
+	}
+	msgs := append([]openai.ChatCompletionMessage{}, prompt.PredefinedPrompts...)
+	msgs = append(msgs, openai.ChatCompletionMessage{
+		Role:    openai.ChatMessageRoleUser,
+		Content: b.String(),
+	})
+	req := &openai.ChatCompletionRequest{
+		Model:     prompt.AIMode,
+		MaxTokens: prompt.MaxTokens,
+		Messages:  msgs,
+	}
+
+	resp, err := srv.doChatCompletion(ctx, req)
+	if err != nil {
+		return nil, errors.Wrap(err, "infra do ai chat completion request got err")
+	}
+
+	var suggestions map[string]string
+	if err := json.Unmarshal([]byte(resp.Choices[0].Message.Content), &suggestions); err != nil {
+		return nil, errors.Wrap(err, "the link suggestions received response from AI proxy, attempted to unmarshal resp content but got an error")
+	}
+	for target, url := range suggestions {
+		if !urls[url] {
+			delete(suggestions, target)
+		}
+	}
+	return suggestions, nil
+}
+
+// GenerateAltText 将文章标题、图片地址及所在段落的上下文交给AI生成alt文字，
+// imageURL(http地址或data uri)不为空时附带图片，使用alt-text-vision提示词
+func (srv *AIService) GenerateAltText(ctx context.Context, md *entity.BlogMD, img *entity.AltTextImage, imageURL string) (string, error) {
+	key := PromptKeyAltText
+	if imageURL != "" {
+		key = PromptKeyAltTextVision
+	}
+	prompt, err := openaix.GetPrompt(key)
+	if err != nil {
+		if imageURL != "" {
+			return "", errors.Wrap(err, "vision alt text needs prompt")
+		}
+		return "", nil
+	}
+
+	text := fmt.Sprintf("文章: %s\n图片: %s\n上下文:\n%s", md.MDHeader.Title, img.Target, img.Context)
+	msg := openai.ChatCompletionMessage{Role: openai.ChatMessageRoleUser, Content: text}
+	if imageURL != "" {
+		msg.Content = ""
+		msg.MultiContent = []openai.ChatMessagePart{
+			{Type: openai.ChatMessagePartTypeText, Text: text},
+			{Type: openai.ChatMessagePartTypeImageURL, ImageURL: &openai.ChatMessageImageURL{URL: imageURL, Detail: openai.ImageURLDetailLow}},
+		}
+	}
+	msgs := append([]openai.ChatCompletionMessage{}, prompt.PredefinedPrompts...)
+	req := &openai.ChatCompletionRequest{
+		Model:     prompt.AIMode,
+		MaxTokens: prompt.MaxTokens,
+		Messages:  append(msgs, msg),
+	}
+
+	resp, err := srv.doChatCompletion(ctx, req)
+	if err != nil {
+		return "", errors.Wrap(err, "infra do ai chat completion request got err")
+	}
+	if len(resp.Choices) == 0 {
+		return "", errors.New("ai alt text got empty choices")
+	}
+	return entity.NormalizeAltText(resp.Choices[0].Message.Content), nil
+}
+
+// EmbeddingModel embedding提示词配置的模型
+func (srv *AIService) EmbeddingModel() string {
+	prompt, err := openaix.GetPrompt(PromptKeyEmbedding)
+	if err != nil {
+		return ""
+	}
+	return prompt.AIMode
+}
+
+// EmbedTexts 按embeddingBatchSize分批请求AI生成embedding向量，换行替换为空格
+func (srv *AIService) EmbedTexts(ctx context.Context, texts []string) ([][]float32, error) {
+	model := srv.EmbeddingModel()
+	if model == "" || len(texts) == 0 {
+		return nil, nil
+	}
+
+	var embeddingModel openai.EmbeddingModel
+	if err := embeddingModel.UnmarshalText([]byte(model)); err != nil || embeddingModel == openai.Unknown {
+		return nil, errors.Errorf("unsupported embedding model[%s]", model)
+	}
+
+	vectors := make([][]float32, 0, len(texts))
+	for start := 0; start < len(texts); start += embeddingBatchSize {
+		end := start + embeddingBatchSize
+		if end > len(texts) {
+			end = len(texts)
+		}
+		inputs := make([]string, 0, end-start)
+		for _, text := range texts[start:end] {
+			inputs = append(inputs, strings.ReplaceAll(text, "\n", " "))
+		}
+
+		event := entity.NewJobEvent(entity.JobEventAIRequest)
+		event.Model = model
+		entity.EmitJobEvent(ctx, event)
+		resp, err := srv.infra.DoAIEmbeddingRequest(ctx, &openai.EmbeddingRequest{
+			Input: inputs,
+			Model: embeddingModel,
+		})
+		if err != nil {
+			return nil, errors.Wrap(err, "infra do ai embedding request got err")
+		}
+		event = entity.NewJobEvent(entity.JobEventTokens)
+		event.Model, event.Tokens = model, resp.Usage.TotalTokens
+		entity.EmitJobEvent(ctx, event)
+
+		if len(resp.Data) != len(inputs) {
+			return nil, errors.Errorf("ai embedding got %d vectors for %d texts", len(resp.Data), len(inputs))
+		}
+		batch := make([][]float32, len(inputs))
+		for i, data := range resp.Data {
+			if data.Index >= 0 && data.Index < len(batch) {
+				batch[data.Index] = data.Embedding
+			} else {
+				batch[i] = data.Embedding
+			}
+		}
+		vectors = append(vectors, batch...)
+	}
+	return vectors, nil
+}
+
+// 语言代码对应的提示词名称
+var langNames = map[string]string{
+	entity.LangZH: "简体中文",
+	entity.LangEN: "英文(English)",
+	entity.LangJA: "日文(日本語)",
+	entity.LangKO: "韩文(한국어)",
+}
+
+func langName(lang string) string {
+	if name, ok := langNames[lang]; ok {
+		return name
+	}
+	return lang
+}
@@ -0,0 +1,181 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/lupguo/copilot_develop/app/domain/entity"
+	"github.com/lupguo/copilot_develop/app/domain/repos"
+	"github.com/lupguo/copilot_develop/app/infras/openaix"
+	"github.com/pkg/errors"
+	"github.com/sashabaranov/go-openai"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	PromptKeyTranslateBlog = "translate-blog"
+)
+
+// IServicesTranslateAI AI翻译服务接口
+type IServicesTranslateAI interface {
+	// TranslateBlogMD 将文章翻译成指定语言，返回的译文路径为Hugo多语言兄弟文件(name.<lang>.md)
+	TranslateBlogMD(ctx context.Context, md *entity.BlogMD, lang string) (translated *entity.BlogMD, err error)
+}
+
+// chatCache AI翻译结果缓存，相同请求(模型+消息)直接返回之前的结果；设置了store时持久化，
+// CLI多次执行时未变化的章节不再消耗token
+type chatCache struct {
+	m     sync.Map
+	store repos.IReposTranslationCache
+}
+
+func (c *chatCache) key(req *openai.ChatCompletionRequest) string {
+	h := sha256.New()
+	h.Write([]byte(req.Model))
+	for _, msg := range req.Messages {
+		fmt.Fprintf(h, "\x00%s\x00%s", msg.Role, msg.Content)
+	}
+	return fmt.Sprintf("%x", h.Sum(nil))
+}
+
+// load 先查内存，再查持久化存储，存储出错时只记录日志
+func (c *chatCache) load(ctx context.Context, key string) (string, bool) {
+	if v, ok := c.m.Load(key); ok {
+		return v.(string), true
+	}
+	if c.store == nil {
+		return "", false
+	}
+	record, err := c.store.SelTranslationCache(ctx, key)
+	if err != nil {
+		log.Warnf("sel translation cache[%s] got err: %s", key, err)
+		return "", false
+	}
+	if record == nil {
+		return "", false
+	}
+	c.m.Store(key, record.Content)
+	return record.Content, true
+}
+
+// save 写入内存及持久化存储
+func (c *chatCache) save(ctx context.Context, key, lang string, req *openai.ChatCompletionRequest, content string) {
+	c.m.Store(key, content)
+	if c.store == nil {
+		return
+	}
+	err := c.store.ReplaceTranslationCache(ctx, &entity.TranslationCache{CacheKey: key, Lang: lang, Model: req.Model, Content: content})
+	if err != nil {
+		log.Warnf("replace translation cache[%s] got err: %s", key, err)
+	}
+}
+
+// SetTranslationCache 设置翻译结果缓存的持久化存储
+func (srv *AIService) SetTranslationCache(store repos.IReposTranslationCache) {
+	srv.cache.store = store
+}
+
+// cachedChatCompletion 带缓存的AI请求，返回首个choice的内容，useCache为false时跳过缓存重新请求并覆盖缓存
+func (srv *AIService) cachedChatCompletion(ctx context.Context, req *openai.ChatCompletionRequest, lang string, useCache bool) (string, error) {
+	key := srv.cache.key(req)
+	if useCache {
+		if content, ok := srv.cache.load(ctx, key); ok {
+			return content, nil
+		}
+	}
+
+	resp, err := srv.doChatCompletion(ctx, req)
+	if err != nil {
+		return "", errors.Wrap(err, "infra do ai chat completion request got err")
+	}
+	if len(resp.Choices) == 0 {
+		return "", errors.New("ai chat completion response without choices")
+	}
+
+	content := resp.Choices[0].Message.Content
+	srv.cache.save(ctx, key, lang, req, content)
+	return content, nil
+}
+
+// TranslateBlogMD 按章节翻译正文，标题、摘要等文本字段一并翻译，front matter的key保持不变
+func (srv *AIService) TranslateBlogMD(ctx context.Context, md *entity.BlogMD, lang string) (translated *entity.BlogMD, err error) {
+	prompt, err := openaix.GetPrompt(PromptKeyTranslateBlog)
+	if err != nil {
+		return nil, errors.Wrap(err, "translate blog cannot found ai prompt key")
+	}
+
+	// 复制YamlHeader，避免修改原文
+	header := *md.MDHeader
+	header.Extra = make(map[string]interface{}, len(md.MDHeader.Extra))
+	for k, v := range md.MDHeader.Extra {
+		header.Extra[k] = v
+	}
+	// 译文作为独立文章，重新分配短标记、别名、slug与SEO标题，原文的多语言摘要也不再沿用
+	header.ShortMark, header.Slug, header.SEOTitle, header.Aliases = "", "", "", nil
+	header.ClearLangSummaries()
+	for _, field := range []*string{&header.Title, &header.Summary, &header.Description, &header.Keywords} {
+		if *field, err = srv.translateText(ctx, prompt, *field, lang); err != nil {
+			return nil, errors.Wrap(err, "translate blog header got err")
+		}
+	}
+
+	// 正文按章节翻译
+	sections := entity.SplitSections(md.MDContent, entity.TranslateChunkMaxWords)
+	for i, section := range sections {
+		log.Infof("translate md[%s] section %d/%d into %s", md.Filepath, i+1, len(sections), lang)
+		if sections[i], err = srv.translateText(ctx, prompt, section, lang); err != nil {
+			return nil, errors.Wrapf(err, "translate blog section %d got err", i+1)
+		}
+	}
+
+	return &entity.BlogMD{
+		Filepath:  entity.LangSiblingPath(md.Filepath, lang),
+		MDHeader:  &header,
+		MDContent: strings.Join(sections, "\n\n") + "\n",
+		Lang:      lang,
+	}, nil
+}
+
+// translateText 翻译一段文本，代码、URL、shortcode等替换为占位符后再请求AI，占位符丢失时不走缓存重试一次
+func (srv *AIService) translateText(ctx context.Context, prompt *openaix.Prompt, text, lang string) (string, error) {
+	pt := entity.ProtectContent(text)
+	if !pt.NeedTranslate() {
+		return text, nil
+	}
+
+	msgs := append([]openai.ChatCompletionMessage{}, prompt.PredefinedPrompts...)
+	msgs = append(msgs,
+		openai.ChatCompletionMessage{
+			Role:    openai.ChatMessageRoleSystem,
+			Content: fmt.Sprintf("请翻译成%s", langName(lang)),
+		},
+		openai.ChatCompletionMessage{
+			Role:    openai.ChatMessageRoleUser,
+			Content: pt.Text,
+		},
+	)
+	req := &openai.ChatCompletionRequest{
+		Model:     prompt.AIMode,
+		MaxTokens: prompt.MaxTokens,
+		Messages:  msgs,
+	}
+
+	var lastErr error
+	for retry := 0; retry < 2; retry++ {
+		content, err := srv.cachedChatCompletion(ctx, req, lang, retry == 0)
+		if err != nil {
+			return "", err
+		}
+		restored, err := pt.Restore(strings.TrimSpace(content))
+		if err == nil {
+			return restored, nil
+		}
+		lastErr = err
+		log.Warnf("translate text restore placeholders got err, retry: %v", err)
+	}
+
+	return "", errors.Wrap(lastErr, "translate text got err")
+}
@@ -0,0 +1,139 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/lupguo/copilot_develop/app/domain/entity"
+	"github.com/sashabaranov/go-openai"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeTranslateInfra 模拟AI翻译，将用户内容里的中文替换成英文
+type fakeTranslateInfra struct {
+	calls int
+}
+
+func (f *fakeTranslateInfra) DoAIChatCompletionRequest(ctx context.Context, req *openai.ChatCompletionRequest) (*openai.ChatCompletionResponse, error) {
+	f.calls++
+	content := req.Messages[len(req.Messages)-1].Content
+	content = strings.NewReplacer("标题", "Title", "正文", "Body", "小节", "Section").Replace(content)
+	return &openai.ChatCompletionResponse{
+		Choices: []openai.ChatCompletionChoice{{Message: openai.ChatCompletionMessage{Content: content}}},
+		Usage:   openai.Usage{TotalTokens: 42},
+	}, nil
+}
+
+func (f *fakeTranslateInfra) DoAIEmbeddingRequest(ctx context.Context, req *openai.EmbeddingRequest) (*openai.EmbeddingResponse, error) {
+	// TODO implement me
+	panic("implement me")
+}
+
+func TestAIService_TranslateBlogMD(t *testing.T) {
+	infra := &fakeTranslateInfra{}
+	srv, err := NewAIService(infra, "../../infras/openaix/prompt.example.yaml")
+	assert.NoError(t, err)
+
+	md := &entity.BlogMD{
+		Filepath: "/blog/posts/go.md",
+		MDHeader: &entity.YamlHeader{
+			Title: "标题", Tags: []string{"go"}, ShortMark: "ab12", Slug: "go-intro", SEOTitle: "Go入门",
+			Aliases: []string{"/s/ab12/"},
+			Extra:   map[string]interface{}{"summary_en": "Go intro", "keywords_zh-cn": "go", "series": "go"},
+		},
+		MDContent: "正文 `code`\n\n## 小节\n```go\n// 正文\n```\n",
+		Lang:      entity.LangZH,
+	}
+	translated, err := srv.TranslateBlogMD(context.Background(), md, entity.LangEN)
+	assert.NoError(t, err)
+	assert.Equal(t, "/blog/posts/go.en.md", translated.Filepath)
+	assert.Equal(t, "Title", translated.MDHeader.Title)
+	assert.Equal(t, "标题", md.MDHeader.Title)
+	assert.Equal(t, []string{"go"}, translated.MDHeader.Tags)
+	assert.Empty(t, translated.MDHeader.ShortMark)
+	assert.Empty(t, translated.MDHeader.Slug)
+	assert.Empty(t, translated.MDHeader.SEOTitle)
+	assert.Empty(t, translated.MDHeader.Aliases)
+	assert.Equal(t, map[string]interface{}{"series": "go"}, translated.MDHeader.Extra)
+	assert.Equal(t, []string{"/s/ab12/"}, md.MDHeader.Aliases)
+	assert.Len(t, md.MDHeader.Extra, 3)
+	assert.Equal(t, "Body `code`\n\n## Section\n```go\n// 正文\n```\n", translated.MDContent)
+
+	// 相同内容命中缓存
+	calls := infra.calls
+	_, err = srv.TranslateBlogMD(context.Background(), md, entity.LangEN)
+	assert.NoError(t, err)
+	assert.Equal(t, calls, infra.calls)
+}
+
+// memTranslationCache 内存中的翻译缓存存储
+type memTranslationCache struct {
+	caches map[string]*entity.TranslationCache
+}
+
+func (m *memTranslationCache) SelTranslationCache(ctx context.Context, cacheKey string) (*entity.TranslationCache, error) {
+	return m.caches[cacheKey], nil
+}
+
+func (m *memTranslationCache) ReplaceTranslationCache(ctx context.Context, cache *entity.TranslationCache) error {
+	m.caches[cache.CacheKey] = cache
+	return nil
+}
+
+func TestAIService_TranslateBlogMDPersistentCache(t *testing.T) {
+	store := &memTranslationCache{caches: map[string]*entity.TranslationCache{}}
+	md := &entity.BlogMD{
+		Filepath:  "/blog/posts/go.md",
+		MDHeader:  &entity.YamlHeader{Title: "标题"},
+		MDContent: "正文\n\n## 小节\n",
+		Lang:      entity.LangZH,
+	}
+
+	infra := &fakeTranslateInfra{}
+	srv, err := NewAIService(infra, "../../infras/openaix/prompt.example.yaml")
+	assert.NoError(t, err)
+	srv.SetTranslationCache(store)
+	_, err = srv.TranslateBlogMD(context.Background(), md, entity.LangEN)
+	assert.NoError(t, err)
+	assert.NotZero(t, infra.calls)
+	for _, cache := range store.caches {
+		assert.Equal(t, entity.LangEN, cache.Lang)
+	}
+
+	// 新进程(新的服务实例)从持久化存储读取，不再请求AI
+	infra2 := &fakeTranslateInfra{}
+	srv2, err := NewAIService(infra2, "../../infras/openaix/prompt.example.yaml")
+	assert.NoError(t, err)
+	srv2.SetTranslationCache(store)
+	translated, err := srv2.TranslateBlogMD(context.Background(), md, entity.LangEN)
+	assert.NoError(t, err)
+	assert.Zero(t, infra2.calls)
+	assert.Equal(t, "Title", translated.MDHeader.Title)
+
+	// 其他语言不命中
+	_, err = srv2.TranslateBlogMD(context.Background(), md, entity.LangJA)
+	assert.NoError(t, err)
+	assert.NotZero(t, infra2.calls)
+}
+
+func TestAIService_doChatCompletionEvents(t *testing.T) {
+	srv, err := NewAIService(&fakeTranslateInfra{}, "../../infras/openaix/prompt.example.yaml")
+	assert.NoError(t, err)
+
+	var events []*entity.JobEvent
+	ctx := entity.WithJobEventEmitter(context.Background(), func(event *entity.JobEvent) {
+		events = append(events, event)
+	})
+	_, err = srv.doChatCompletion(ctx, &openai.ChatCompletionRequest{
+		Model:    "gpt-test",
+		Messages: []openai.ChatCompletionMessage{{Role: openai.ChatMessageRoleUser, Content: "标题"}},
+	})
+	assert.NoError(t, err)
+	if assert.Len(t, events, 2) {
+		assert.Equal(t, entity.JobEventAIRequest, events[0].Type)
+		assert.Equal(t, "gpt-test", events[0].Model)
+		assert.Equal(t, entity.JobEventTokens, events[1].Type)
+		assert.Equal(t, 42, events[1].Tokens)
+	}
+}
@@ -0,0 +1,193 @@
+package crawler
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// errCrawlerStopped 调用Stop后不再发起新的请求
+var errCrawlerStopped = errors.New("crawler stopped")
+
+// bfs 从seed开始按层爬取同host的页面：深度<=maxDepth的页面回调onPage(返回false时结束爬取)，
+// 深度<maxDepth的页面中的url加入下一层；onPage为nil时只发现url，最后一层不再请求。
+// Sitemap时站点sitemap中的url与seed同为第0层，robots.txt禁止的url不加入。返回发现的url
+func (c *Crawler) bfs(ctx context.Context, seed string, maxDepth int, onPage func(page *Page) bool) ([]string, error) {
+	start, err := NormalizeURL(seed, nil)
+	if err != nil {
+		return nil, err
+	}
+	host := hostOf(start)
+	seen := map[string]bool{start: true}
+	found := []string{start}
+	frontier := []string{start}
+	add := func(u string) bool {
+		if seen[u] || hostOf(u) != host {
+			return false
+		}
+		seen[u] = true
+		if err := c.checkRobots(ctx, u); err != nil {
+			return false
+		}
+		found = append(found, u)
+		return true
+	}
+
+	if c.opts.Sitemap {
+		urls, err := c.SitemapURLs(ctx, start)
+		if err != nil {
+			log.Warnf("crawler read sitemap of [%s] got err: %s", start, err)
+		}
+		for _, u := range urls {
+			if add(u) {
+				frontier = append(frontier, u)
+			}
+		}
+	}
+	for depth := 0; len(frontier) > 0; depth++ {
+		if depth == maxDepth && onPage == nil {
+			break
+		}
+		if err = ctx.Err(); err != nil {
+			return found, err
+		}
+		if c.stopped() {
+			return found, nil
+		}
+
+		var next []string
+		for _, page := range c.fetchAll(ctx, frontier, depth) {
+			if page == nil {
+				continue
+			}
+			if onPage != nil && !onPage(page) {
+				return found, nil
+			}
+			if depth >= maxDepth {
+				continue
+			}
+			for _, u := range page.Urls {
+				if add(u) {
+					next = append(next, u)
+				}
+			}
+		}
+		frontier = next
+	}
+	return found, ctx.Err()
+}
+
+// fetchAll 并发请求同一层的页面，返回与urls顺序一致的结果，失败或停止时对应位置为nil
+func (c *Crawler) fetchAll(ctx context.Context, urls []string, depth int) []*Page {
+	pages := make([]*Page, len(urls))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < c.opts.HostConcurrency && w < len(urls); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				page, err := c.crawlPage(ctx, urls[i], c.matchRule(urls[i]))
+				switch {
+				case err == nil:
+					page.Depth = depth
+					pages[i] = page
+				case errors.Is(err, ErrDisallowed):
+					log.Debugf("crawler skip page[%s]: %s", urls[i], err)
+				case !errors.Is(err, errCrawlerStopped) && ctx.Err() == nil:
+					logPageErr(urls[i], err)
+				}
+			}
+		}()
+	}
+	for i := range urls {
+		if ctx.Err() != nil || c.stopped() {
+			break
+		}
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+	return pages
+}
+
+// hostLimiter 按host限制并发请求数，并保证两次请求的间隔不小于delay
+type hostLimiter struct {
+	concurrency int
+	delay       time.Duration
+
+	mu    sync.Mutex
+	hosts map[string]*hostSlot
+}
+
+type hostSlot struct {
+	sem   chan struct{}
+	mu    sync.Mutex
+	next  time.Time     // 下一次可以发起请求的时间
+	delay time.Duration // 站点要求的请求间隔(robots.txt的Crawl-delay)，大于delay时生效
+}
+
+func newHostLimiter(concurrency int, delay time.Duration) *hostLimiter {
+	return &hostLimiter{concurrency: concurrency, delay: delay, hosts: make(map[string]*hostSlot)}
+}
+
+// slot host的请求名额，不存在时创建
+func (l *hostLimiter) slot(host string) *hostSlot {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	slot, ok := l.hosts[host]
+	if !ok {
+		slot = &hostSlot{sem: make(chan struct{}, l.concurrency)}
+		l.hosts[host] = slot
+	}
+	return slot
+}
+
+// setDelay 设置host要求的请求间隔，小于delay时不生效
+func (l *hostLimiter) setDelay(host string, delay time.Duration) {
+	slot := l.slot(host)
+	slot.mu.Lock()
+	slot.delay = delay
+	slot.mu.Unlock()
+}
+
+// acquire 获取host的请求名额并等待礼貌延迟，ctx结束或quit关闭时返回错误
+func (l *hostLimiter) acquire(ctx context.Context, quit <-chan struct{}, host string) (release func(), err error) {
+	slot := l.slot(host)
+
+	select {
+	case slot.sem <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-quit:
+		return nil, errCrawlerStopped
+	}
+	release = func() { <-slot.sem }
+
+	// 预约请求时间，并发的请求依次间隔delay
+	slot.mu.Lock()
+	at := time.Now()
+	if slot.next.After(at) {
+		at = slot.next
+	}
+	slot.next = at.Add(max(l.delay, slot.delay))
+	slot.mu.Unlock()
+
+	if wait := time.Until(at); wait > 0 {
+		timer := time.NewTimer(wait)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			release()
+			return nil, ctx.Err()
+		case <-quit:
+			release()
+			return nil, errCrawlerStopped
+		}
+	}
+	return release, nil
+}
@@ -1,21 +1,45 @@
 package crawler
 
 import (
+	"bytes"
 	"context"
 	"io"
+	"net/http"
+	"regexp"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/lupguo/copilot_develop/app/domain/repos"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
 )
 
 // 最大深度
-var bfsMaxDepth int
+var bfsMaxDepth = 3
+
+const (
+	// defaultHostConcurrency 同一host默认并发请求数
+	defaultHostConcurrency = 2
+
+	// defaultTimeout 单个页面请求超时
+	defaultTimeout = 15 * time.Second
+
+	// defaultUserAgent 默认User-Agent
+	defaultUserAgent = "copilot_develop-crawler/1.0"
+
+	// maxPageSize 单个页面最大读取的字节数
+	maxPageSize = 10 << 20
+)
 
 // PageUrlRegex 针对pageLink页面，爬取Content和Url内容
 type PageUrlRegex struct {
-	Content string // 页面内容url
-	Url     string // 页面内的URL正则
+	Content string // 页面内容正则，有捕获组时取第一个捕获组，多处匹配时合并；为空时取整个页面的文本
+	Url     string // 页面内的URL正则，仅跟进匹配的URL；为空时跟进同host下的所有URL
 	// MaxDepth int    // 页面爬取最大深度
 }
 
-// PageUrlRegexConfig 同类型的页面url的提取规则
+// PageUrlRegexConfig 同类型的页面url的提取规则，页面url正则 => 提取规则
 type PageUrlRegexConfig map[string]PageUrlRegex
 
 // ICrawler 爬虫客户端接口
@@ -36,6 +60,254 @@ type ICrawler interface {
 	FoundURLsFromUrlBFS(ctx context.Context, url string, maxDepth int) (urls []string, err error)
 }
 
+// Page 爬取到的页面
+type Page struct {
+	Url       string   // 规范化后的页面url
+	Depth     int      // BFS深度，起始页面为0
+	Content   string   // 按PageUrlRegex提取的文本内容，页面未变化时为空
+	Urls      []string // 页面内同host、符合规则的url(规范化、去重)
+	Unchanged bool     // 与上次访问相比页面未变化(304、内容hash相同或在Revisit间隔内)
+}
+
+// Options 爬虫配置
+type Options struct {
+	Seeds           []string                              // Start时的起始页面
+	MaxDepth        int                                   // BFS最大深度，<=0时为默认值3
+	MaxPages        int                                   // 最多爬取的页面数，0不限
+	HostConcurrency int                                   // 同一host并发请求数，默认2
+	Delay           time.Duration                         // 同一host两次请求的最小间隔(礼貌延迟)
+	Timeout         time.Duration                         // 单个页面请求超时，默认15s
+	UserAgent       string                                // 请求的User-Agent，同时用于匹配robots.txt的User-agent
+	IgnoreRobots    bool                                  // 不遵守robots.txt(包括Crawl-delay)
+	Sitemap         bool                                  // BFS时将站点sitemap中的url作为起始页面
+	Visits          repos.IReposCrawlVisit                // 已访问url的存储，为空时每次全量爬取
+	Revisit         time.Duration                         // 距上次访问不足该间隔的页面不再请求，直接沿用记录的url(断点续爬)
+	Regex           PageUrlRegexConfig                    // 页面内容、URL提取规则
+	OnPage          func(ctx context.Context, page *Page) // Start时每爬取到一个变化页面的回调
+	Client          *http.Client                          // 为空时使用默认Client
+}
+
+// pageRule 编译后的页面提取规则
+type pageRule struct {
+	page    *regexp.Regexp
+	content *regexp.Regexp
+	url     *regexp.Regexp
+}
+
+// Crawler BFS站点爬虫：同host范围内按深度逐层爬取，同一host限制并发数及请求间隔，遵守robots.txt；
+// 设置Visits时记录访问过的url，再次爬取时通过ETag/Last-Modified增量请求
 type Crawler struct {
-	client ICrawler
+	opts   *Options
+	client *http.Client
+	rules  []*pageRule
+	hosts  *hostLimiter
+	sites  *robotsCache
+
+	mu       sync.Mutex
+	running  bool
+	quit     chan struct{} // Stop时关闭，不再发起新的请求
+	quitOnce sync.Once
+	done     chan struct{} // Start返回时关闭
+}
+
+var _ ICrawler = (*Crawler)(nil)
+
+// NewCrawler 初始化爬虫，PageUrlRegex中的正则无效时返回错误
+func NewCrawler(opts *Options) (*Crawler, error) {
+	if opts == nil {
+		opts = &Options{}
+	}
+	o := *opts
+	if o.MaxDepth <= 0 {
+		o.MaxDepth = bfsMaxDepth
+	}
+	if o.HostConcurrency <= 0 {
+		o.HostConcurrency = defaultHostConcurrency
+	}
+	if o.Timeout <= 0 {
+		o.Timeout = defaultTimeout
+	}
+	if o.UserAgent == "" {
+		o.UserAgent = defaultUserAgent
+	}
+	client := o.Client
+	if client == nil {
+		client = &http.Client{Timeout: o.Timeout}
+	}
+
+	rules, err := compileRules(o.Regex)
+	if err != nil {
+		return nil, err
+	}
+	return &Crawler{
+		opts:   &o,
+		client: client,
+		rules:  rules,
+		hosts:  newHostLimiter(o.HostConcurrency, o.Delay),
+		sites:  newRobotsCache(),
+		quit:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}, nil
+}
+
+// compileRules 编译提取规则，按页面url正则排序保证匹配顺序稳定
+func compileRules(cfg PageUrlRegexConfig) ([]*pageRule, error) {
+	keys := make([]string, 0, len(cfg))
+	for key := range cfg {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	compile := func(expr string) (*regexp.Regexp, error) {
+		if expr == "" {
+			return nil, nil
+		}
+		re, err := regexp.Compile(expr)
+		return re, errors.Wrapf(err, "compile crawler regex[%s] got err", expr)
+	}
+	var rules []*pageRule
+	for _, key := range keys {
+		rule := &pageRule{}
+		var err error
+		if rule.page, err = compile(key); err != nil {
+			return nil, err
+		}
+		if rule.content, err = compile(cfg[key].Content); err != nil {
+			return nil, err
+		}
+		if rule.url, err = compile(cfg[key].Url); err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// Start 从Seeds开始BFS爬取，每个变化的页面回调OnPage(未变化的页面不回调、不计入MaxPages)，爬取完成、Stop或ctx取消时返回
+func (c *Crawler) Start(ctx context.Context) error {
+	c.mu.Lock()
+	if c.running {
+		c.mu.Unlock()
+		return errors.New("crawler is already started")
+	}
+	select {
+	case <-c.quit:
+		c.mu.Unlock()
+		return errors.New("crawler is stopped")
+	default:
+	}
+	c.running = true
+	c.mu.Unlock()
+	defer close(c.done)
+
+	if len(c.opts.Seeds) == 0 {
+		return errors.New("crawler has no seed url")
+	}
+	pages := 0
+	var pagesMu sync.Mutex
+	for _, seed := range c.opts.Seeds {
+		_, err := c.bfs(ctx, seed, c.opts.MaxDepth, func(page *Page) bool {
+			if page.Unchanged {
+				return true
+			}
+			pagesMu.Lock()
+			defer pagesMu.Unlock()
+			if c.opts.MaxPages > 0 && pages >= c.opts.MaxPages {
+				return false
+			}
+			pages++
+			if c.opts.OnPage != nil {
+				c.opts.OnPage(ctx, page)
+			}
+			return true
+		})
+		if err != nil {
+			return err
+		}
+		if c.stopped() {
+			return nil
+		}
+	}
+	return nil
+}
+
+// Stop 停止爬取：不再发起新的请求，等待进行中的请求结束；爬虫停止后关闭stop(可为nil)，ctx结束时不再等待
+func (c *Crawler) Stop(ctx context.Context, stop chan struct{}) error {
+	c.quitOnce.Do(func() { close(c.quit) })
+
+	c.mu.Lock()
+	running := c.running
+	c.mu.Unlock()
+	if running {
+		select {
+		case <-c.done:
+		case <-ctx.Done():
+			return errors.Wrap(ctx.Err(), "wait crawler stop got err")
+		}
+	}
+	if stop != nil {
+		close(stop)
+	}
+	return nil
+}
+
+// stopped 是否已调用Stop
+func (c *Crawler) stopped() bool {
+	select {
+	case <-c.quit:
+		return true
+	default:
+		return false
+	}
+}
+
+// GetPageUrlDoc 请求页面，按regex提取文本内容及页面内同host的url，regex为nil时按PageUrlRegexConfig匹配规则
+func (c *Crawler) GetPageUrlDoc(ctx context.Context, pageUrl string, regex *PageUrlRegex) (doc io.Reader, urls []string, err error) {
+	pageUrl, err = NormalizeURL(pageUrl, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	rule := c.matchRule(pageUrl)
+	if regex != nil {
+		if rule, err = compileRule(regex); err != nil {
+			return nil, nil, err
+		}
+	}
+	page, err := c.fetchPage(ctx, pageUrl, rule)
+	if err != nil {
+		return nil, nil, err
+	}
+	return bytes.NewBufferString(page.Content), page.Urls, nil
+}
+
+// compileRule 编译单个提取规则
+func compileRule(regex *PageUrlRegex) (*pageRule, error) {
+	rules, err := compileRules(PageUrlRegexConfig{"": *regex})
+	if err != nil {
+		return nil, err
+	}
+	return rules[0], nil
+}
+
+// FoundURLsFromUrlBFS 从url开始BFS，返回maxDepth内发现的同host url(含起始url，按发现顺序)，maxDepth<=0时使用默认深度
+func (c *Crawler) FoundURLsFromUrlBFS(ctx context.Context, url string, maxDepth int) (urls []string, err error) {
+	if maxDepth <= 0 {
+		maxDepth = bfsMaxDepth
+	}
+	return c.bfs(ctx, url, maxDepth, nil)
+}
+
+// matchRule 页面url匹配的提取规则(页面正则为空时匹配所有页面)，没有匹配时为nil
+func (c *Crawler) matchRule(pageUrl string) *pageRule {
+	for _, rule := range c.rules {
+		if rule.page == nil || rule.page.MatchString(pageUrl) {
+			return rule
+		}
+	}
+	return nil
+}
+
+// logPageErr 单个页面失败不影响整体爬取
+func logPageErr(pageUrl string, err error) {
+	log.Warnf("crawler fetch page[%s] got err: %s", pageUrl, err)
 }
@@ -0,0 +1,233 @@
+package crawler
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// testSite 路径 => 页面html，记录每个路径的请求次数及最大并发数
+type testSite struct {
+	pages    map[string]string
+	delay    time.Duration
+	mu       sync.Mutex
+	hits     map[string]int
+	inflight int32
+	peak     int32
+}
+
+func newTestSite(t *testing.T, pages map[string]string, delay time.Duration) (*testSite, *httptest.Server) {
+	site := &testSite{pages: pages, delay: delay, hits: make(map[string]int)}
+	srv := httptest.NewServer(site)
+	t.Cleanup(srv.Close)
+	return site, srv
+}
+
+func (s *testSite) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	n := atomic.AddInt32(&s.inflight, 1)
+	defer atomic.AddInt32(&s.inflight, -1)
+	for {
+		peak := atomic.LoadInt32(&s.peak)
+		if n <= peak || atomic.CompareAndSwapInt32(&s.peak, peak, n) {
+			break
+		}
+	}
+	s.mu.Lock()
+	s.hits[r.URL.RequestURI()]++
+	s.mu.Unlock()
+	time.Sleep(s.delay)
+
+	body, ok := s.pages[r.URL.Path]
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	fmt.Fprint(w, body)
+}
+
+func (s *testSite) hit(uri string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.hits[uri]
+}
+
+func TestNormalizeURL(t *testing.T) {
+	base, _ := url.Parse("https://example.com/a/b")
+	tests := []struct {
+		raw     string
+		base    *url.URL
+		want    string
+		wantErr bool
+	}{
+		{raw: "HTTP://Example.COM:80", want: "http://example.com/"},
+		{raw: "https://example.com:443/a/./b/../c/#top", want: "https://example.com/a/c/"},
+		{raw: "https://example.com/a?y=2&x=1", want: "https://example.com/a?x=1&y=2"},
+		{raw: "../d?q=1#x", base: base, want: "https://example.com/d?q=1"},
+		{raw: "/a/b", wantErr: true},
+		{raw: "mailto:a@example.com", wantErr: true},
+		{raw: "javascript:void(0)", base: base, wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := NormalizeURL(tt.raw, tt.base)
+		if tt.wantErr {
+			assert.Error(t, err, tt.raw)
+			continue
+		}
+		assert.NoError(t, err, tt.raw)
+		assert.Equal(t, tt.want, got, tt.raw)
+	}
+}
+
+func TestCrawler_FoundURLsFromUrlBFS(t *testing.T) {
+	other := httptest.NewServer(http.NotFoundHandler())
+	defer other.Close()
+	site, srv := newTestSite(t, map[string]string{
+		"/": `<a href="/a">a</a><a href="/b#frag">b</a><a href="/a?y=2&x=1">q</a>
+			<a href="/./c/../b">dup</a><a href="` + other.URL + `/x">other</a><a href="mailto:me@example.com">mail</a>`,
+		"/a": `<a href="d">d</a>`,
+		"/b": `<a href="/">home</a>`,
+		"/d": `<a href="/e">e</a>`,
+	}, 0)
+
+	c, err := NewCrawler(nil)
+	assert.NoError(t, err)
+	urls, err := c.FoundURLsFromUrlBFS(context.Background(), srv.URL, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{srv.URL + "/", srv.URL + "/a", srv.URL + "/b", srv.URL + "/a?x=1&y=2", srv.URL + "/d"}, urls)
+
+	// 每个页面只请求一次，最后一层只发现不请求
+	assert.Equal(t, 1, site.hit("/"))
+	assert.Equal(t, 1, site.hit("/b"))
+	assert.Equal(t, 0, site.hit("/d"))
+}
+
+func TestCrawler_Start(t *testing.T) {
+	_, srv := newTestSite(t, map[string]string{
+		"/":        `<html><head><title>t</title><script>var x;</script></head><body><a href="/posts/1">1</a><a href="/about">about</a></body></html>`,
+		"/posts/1": `<nav>menu</nav><article><h1>Post 1</h1><p>hello   world</p></article><a href="/posts/2">2</a>`,
+		"/posts/2": `<article><p>second</p></article><a href="/posts/3">3</a>`,
+		"/about":   `<p>about</p>`,
+	}, 0)
+
+	var mu sync.Mutex
+	contents := make(map[string]string)
+	c, err := NewCrawler(&Options{
+		Seeds:     []string{srv.URL},
+		MaxDepth:  2,
+		UserAgent: "test-agent",
+		Regex: PageUrlRegexConfig{
+			`/posts/`: {Content: `(?s)<article>(.*?)</article>`, Url: `/posts/`},
+			`/$`:      {Url: `/posts/`},
+		},
+		OnPage: func(ctx context.Context, page *Page) {
+			mu.Lock()
+			defer mu.Unlock()
+			contents[strings.TrimPrefix(page.Url, srv.URL)] = page.Content
+		},
+	})
+	assert.NoError(t, err)
+	assert.NoError(t, c.Start(context.Background()))
+
+	assert.Equal(t, map[string]string{
+		"/":        "1about",
+		"/posts/1": "Post 1\nhello world",
+		"/posts/2": "second",
+	}, contents)
+
+	// 已结束的爬虫不能再次启动
+	assert.Error(t, c.Start(context.Background()))
+}
+
+func TestCrawler_Politeness(t *testing.T) {
+	pages := map[string]string{"/": `<a href="/1">1</a><a href="/2">2</a><a href="/3">3</a><a href="/4">4</a>`}
+	for i := 1; i <= 4; i++ {
+		pages[fmt.Sprintf("/%d", i)] = "page"
+	}
+
+	// 并发数限制
+	site, srv := newTestSite(t, pages, 30*time.Millisecond)
+	c, err := NewCrawler(&Options{Seeds: []string{srv.URL}, MaxDepth: 1, HostConcurrency: 2})
+	assert.NoError(t, err)
+	assert.NoError(t, c.Start(context.Background()))
+	assert.Equal(t, int32(2), atomic.LoadInt32(&site.peak))
+
+	// 请求间隔
+	site, srv = newTestSite(t, pages, 0)
+	c, err = NewCrawler(&Options{Seeds: []string{srv.URL}, MaxDepth: 1, HostConcurrency: 4, Delay: 40 * time.Millisecond})
+	assert.NoError(t, err)
+	start := time.Now()
+	assert.NoError(t, c.Start(context.Background()))
+	assert.GreaterOrEqual(t, time.Since(start), 4*40*time.Millisecond)
+	assert.Equal(t, 1, site.hit("/4"))
+}
+
+func TestCrawler_Stop(t *testing.T) {
+	pages := map[string]string{"/": ""}
+	var links []string
+	for i := 0; i < 20; i++ {
+		links = append(links, fmt.Sprintf(`<a href="/%d">%d</a>`, i, i))
+		pages[fmt.Sprintf("/%d", i)] = "page"
+	}
+	pages["/"] = strings.Join(links, "")
+	_, srv := newTestSite(t, pages, 20*time.Millisecond)
+
+	var fetched int32
+	first := make(chan struct{})
+	var once sync.Once
+	c, err := NewCrawler(&Options{
+		Seeds:    []string{srv.URL},
+		MaxDepth: 1,
+		Delay:    20 * time.Millisecond,
+		OnPage: func(ctx context.Context, page *Page) {
+			atomic.AddInt32(&fetched, 1)
+			once.Do(func() { close(first) })
+		},
+	})
+	assert.NoError(t, err)
+
+	started := make(chan error, 1)
+	go func() { started <- c.Start(context.Background()) }()
+	<-first
+
+	stop := make(chan struct{})
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	assert.NoError(t, c.Stop(ctx, stop))
+	<-stop
+	assert.NoError(t, <-started)
+	assert.Less(t, atomic.LoadInt32(&fetched), int32(10))
+}
+
+func TestCrawler_GetPageUrlDoc(t *testing.T) {
+	_, srv := newTestSite(t, map[string]string{
+		"/post": `<div class="content"><p>body</p></div><a href="/tags/go">go</a><a href="/post2">next</a>`,
+	}, 0)
+
+	c, err := NewCrawler(nil)
+	assert.NoError(t, err)
+	doc, urls, err := c.GetPageUrlDoc(context.Background(), srv.URL+"/post", &PageUrlRegex{
+		Content: `(?s)<div class="content">(.*?)</div>`,
+		Url:     `/tags/`,
+	})
+	assert.NoError(t, err)
+	content, _ := io.ReadAll(doc)
+	assert.Equal(t, "body", string(content))
+	assert.Equal(t, []string{srv.URL + "/tags/go"}, urls)
+
+	_, _, err = c.GetPageUrlDoc(context.Background(), srv.URL+"/missing", nil)
+	assert.Error(t, err)
+
+	_, err = NewCrawler(&Options{Regex: PageUrlRegexConfig{"(": {}}})
+	assert.Error(t, err)
+}
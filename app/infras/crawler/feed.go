@@ -0,0 +1,217 @@
+package crawler
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/lupguo/copilot_develop/app/domain/entity"
+	"github.com/pkg/errors"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/charset"
+)
+
+// feedXML RSS 2.0(channel)、RSS 1.0(RDF，item在根节点下)及Atom(feed/entry)格式的订阅
+type feedXML struct {
+	XMLName xml.Name
+	Title   feedText    `xml:"title"`
+	Links   []feedLink  `xml:"link"`
+	Channel *feedXML    `xml:"channel"`
+	Items   []feedEntry `xml:"item"`
+	Entries []feedEntry `xml:"entry"`
+}
+
+// feedEntry RSS的item及Atom的entry
+type feedEntry struct {
+	Title       feedText   `xml:"title"`
+	Links       []feedLink `xml:"link"`
+	Guid        string     `xml:"guid"`
+	ID          string     `xml:"id"`
+	PubDate     string     `xml:"pubDate"`
+	Date        string     `xml:"date"` // dc:date
+	Published   string     `xml:"published"`
+	Updated     string     `xml:"updated"`
+	Description feedText   `xml:"description"`
+	Encoded     feedText   `xml:"http://purl.org/rss/1.0/modules/content/ encoded"`
+	Summary     feedText   `xml:"summary"`
+	Content     feedText   `xml:"content"`
+}
+
+// feedText 文本元素，Atom的type为text时是纯文本，其余按html处理
+type feedText struct {
+	Type  string `xml:"type,attr"`
+	Text  string `xml:",chardata"`
+	Inner string `xml:",innerxml"`
+}
+
+// feedLink RSS的<link>文本及Atom的<link href rel>
+type feedLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr"`
+	Text string `xml:",chardata"`
+}
+
+// feedDateLayouts 订阅中常见的时间格式
+var feedDateLayouts = []string{
+	time.RFC1123Z, time.RFC1123, time.RFC3339, time.RFC3339Nano,
+	"Mon, 2 Jan 2006 15:04:05 -0700", "Mon, 2 Jan 2006 15:04:05 MST", "2 Jan 2006 15:04:05 -0700",
+	"Mon, 2 Jan 2006 15:04 -0700", "2006-01-02T15:04:05", "2006-01-02 15:04:05", "2006-01-02",
+}
+
+// FetchFeed 请求RSS/Atom订阅，etag、lastModified不为空时条件请求，未变化时返回NotModified
+func (c *Crawler) FetchFeed(ctx context.Context, feedUrl string, etag, lastModified string) (*entity.WebFeed, error) {
+	feedUrl, err := NormalizeURL(feedUrl, nil)
+	if err != nil {
+		return nil, err
+	}
+	var visit *entity.CrawlVisit
+	if etag != "" || lastModified != "" {
+		visit = &entity.CrawlVisit{Url: feedUrl, Etag: etag, LastModified: lastModified}
+	}
+	resp, err := c.fetch(ctx, feedUrl, visit)
+	if err != nil {
+		return nil, err
+	}
+	if resp.notModified {
+		return &entity.WebFeed{Url: feedUrl, Etag: etag, LastModified: lastModified, NotModified: true}, nil
+	}
+
+	feed, err := ParseFeed(resp.body, resp.final)
+	if err != nil {
+		return nil, errors.Wrapf(err, "parse feed[%s] got err", feedUrl)
+	}
+	feed.Url, feed.Etag, feed.LastModified = feedUrl, resp.etag, resp.lastModified
+	return feed, nil
+}
+
+// ParseFeed 解析RSS/Atom订阅，文章链接相对base解析；正文html转换为Markdown，文章按发布时间从新到旧排序
+func ParseFeed(body []byte, base *url.URL) (*entity.WebFeed, error) {
+	doc := &feedXML{}
+	decoder := xml.NewDecoder(bytes.NewReader(body))
+	decoder.CharsetReader = charset.NewReaderLabel
+	decoder.Strict = false
+	decoder.Entity = xml.HTMLEntity
+	if err := decoder.Decode(doc); err != nil {
+		return nil, errors.Wrap(err, "decode feed xml got err")
+	}
+
+	root := doc
+	switch strings.ToLower(doc.XMLName.Local) {
+	case "rss":
+		if doc.Channel == nil {
+			return nil, errors.New("rss has no channel")
+		}
+		root = doc.Channel
+	case "rdf", "feed":
+		if doc.Channel != nil {
+			doc.Title = doc.Channel.Title
+			doc.Links = doc.Channel.Links
+		}
+	default:
+		return nil, errors.Errorf("unsupported feed format <%s>", doc.XMLName.Local)
+	}
+
+	feed := &entity.WebFeed{Title: feedPlainText(root.Title)}
+	if link := alternateLink(root.Links); link != "" {
+		feed.SiteUrl, _ = NormalizeURL(link, base)
+	}
+	for _, e := range append(root.Items, root.Entries...) {
+		item := &entity.WebFeedItem{Title: feedPlainText(e.Title)}
+		if link := alternateLink(e.Links); link != "" {
+			item.Url, _ = NormalizeURL(link, base)
+		}
+		if item.Url == "" && e.Guid != "" {
+			// guid为永久链接时作为文章链接
+			item.Url, _ = NormalizeURL(e.Guid, base)
+		}
+		item.Guid = firstNonEmpty(strings.TrimSpace(e.Guid), strings.TrimSpace(e.ID), item.Url)
+		if item.Guid == "" {
+			continue
+		}
+		item.Published = parseFeedDate(firstNonEmpty(e.Published, e.PubDate, e.Date, e.Updated))
+
+		// 优先使用全文(content:encoded、Atom content)，没有时使用摘要
+		for _, text := range []feedText{e.Encoded, e.Content, e.Description, e.Summary} {
+			if item.Content = feedMarkdown(text); item.Content != "" {
+				break
+			}
+		}
+		if item.Title == "" {
+			item.Title = item.Url
+		}
+		feed.Items = append(feed.Items, item)
+	}
+	entity.SortFeedItems(feed.Items)
+	return feed, nil
+}
+
+// alternateLink 文章(或站点)链接：Atom取rel为alternate(或未设置)的href，RSS取<link>文本
+func alternateLink(links []feedLink) string {
+	for _, link := range links {
+		if link.Href == "" {
+			if text := strings.TrimSpace(link.Text); text != "" {
+				return text
+			}
+			continue
+		}
+		if link.Rel == "" || link.Rel == "alternate" {
+			return strings.TrimSpace(link.Href)
+		}
+	}
+	return ""
+}
+
+// feedPlainText 标题等文本元素的纯文本
+func feedPlainText(text feedText) string {
+	s := strings.TrimSpace(text.Text)
+	if text.Type == "html" || strings.Contains(s, "<") {
+		if node, err := html.Parse(strings.NewReader(s)); err == nil {
+			s = inlineText(node)
+		}
+	}
+	return collapseSpace(s)
+}
+
+// feedMarkdown 正文元素转为Markdown：Atom的xhtml取内部元素，text为纯文本，其余(含RSS转义的html)解析为html
+func feedMarkdown(text feedText) string {
+	raw := text.Text
+	switch text.Type {
+	case "text":
+		return strings.TrimSpace(raw)
+	case "xhtml":
+		raw = text.Inner
+	}
+	if strings.TrimSpace(raw) == "" {
+		return ""
+	}
+	node, err := html.Parse(strings.NewReader(raw))
+	if err != nil {
+		return strings.TrimSpace(raw)
+	}
+	var blocks []string
+	markdownBlocks(node, &blocks)
+	return strings.Join(blocks, "\n\n")
+}
+
+// parseFeedDate 解析订阅中的时间，无法解析时为零值
+func parseFeedDate(s string) time.Time {
+	s = strings.TrimSpace(s)
+	for _, layout := range feedDateLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,133 @@
+package crawler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseFeed(t *testing.T) {
+	base, _ := url.Parse("https://example.com/feed.xml")
+
+	rss := `<?xml version="1.0" encoding="UTF-8"?>
+<rss version="2.0" xmlns:content="http://purl.org/rss/1.0/modules/content/" xmlns:atom="http://www.w3.org/2005/Atom">
+<channel>
+  <title>Example &amp; Blog</title>
+  <atom:link href="https://example.com/feed.xml" rel="self" type="application/rss+xml"/>
+  <link>https://example.com/</link>
+  <item>
+    <title>Old post</title>
+    <link>/posts/old#top</link>
+    <guid isPermaLink="false">old-1</guid>
+    <pubDate>Mon, 29 Apr 2024 10:00:00 +0000</pubDate>
+    <description>&lt;p&gt;An &lt;b&gt;excerpt&lt;/b&gt;&amp;hellip;&lt;/p&gt;</description>
+  </item>
+  <item>
+    <title>New post</title>
+    <link>https://example.com/posts/new</link>
+    <pubDate>Wed, 1 May 2024 10:00:00 +0800</pubDate>
+    <description>short</description>
+    <content:encoded><![CDATA[<h2>Intro</h2><p>Full <a href="/x">content</a>.</p><ul><li>a</li><li>b</li></ul>]]></content:encoded>
+  </item>
+</channel>
+</rss>`
+	feed, err := ParseFeed([]byte(rss), base)
+	assert.NoError(t, err)
+	assert.Equal(t, "Example & Blog", feed.Title)
+	assert.Equal(t, "https://example.com/", feed.SiteUrl)
+	assert.Len(t, feed.Items, 2)
+	assert.Equal(t, "https://example.com/posts/new", feed.Items[0].Guid)
+	assert.Equal(t, "## Intro\n\nFull content.\n\n- a\n- b", feed.Items[0].Content)
+	assert.Equal(t, time.Date(2024, 5, 1, 2, 0, 0, 0, time.UTC), feed.Items[0].Published.UTC())
+	assert.Equal(t, "old-1", feed.Items[1].Guid)
+	assert.Equal(t, "https://example.com/posts/old", feed.Items[1].Url)
+	assert.Equal(t, "An excerpt…", feed.Items[1].Content)
+
+	atomFeed := `<?xml version="1.0" encoding="utf-8"?>
+<feed xmlns="http://www.w3.org/2005/Atom">
+  <title type="text">Atom Blog</title>
+  <link rel="self" href="https://example.com/feed.atom"/>
+  <link href="https://example.com/"/>
+  <entry>
+    <title type="html">Range &lt;em&gt;over&lt;/em&gt; func</title>
+    <id>tag:example.com,2024:1</id>
+    <link rel="alternate" href="https://example.com/posts/range"/>
+    <updated>2024-05-01T08:00:00Z</updated>
+    <summary type="text">plain &lt;summary&gt;</summary>
+    <content type="xhtml"><div xmlns="http://www.w3.org/1999/xhtml"><p>Hello <code>iter</code></p></div></content>
+  </entry>
+  <entry>
+    <title>Summary only</title>
+    <id>tag:example.com,2024:2</id>
+    <link href="/posts/2"/>
+    <summary type="text">plain &lt;summary&gt;</summary>
+  </entry>
+</feed>`
+	feed, err = ParseFeed([]byte(atomFeed), base)
+	assert.NoError(t, err)
+	assert.Equal(t, "Atom Blog", feed.Title)
+	assert.Equal(t, "https://example.com/", feed.SiteUrl)
+	assert.Len(t, feed.Items, 2)
+	assert.Equal(t, "Range over func", feed.Items[0].Title)
+	assert.Equal(t, "tag:example.com,2024:1", feed.Items[0].Guid)
+	assert.Equal(t, "https://example.com/posts/range", feed.Items[0].Url)
+	assert.Equal(t, "Hello iter", feed.Items[0].Content)
+	assert.Equal(t, "https://example.com/posts/2", feed.Items[1].Url)
+	assert.Equal(t, "plain <summary>", feed.Items[1].Content)
+
+	rdf := `<?xml version="1.0"?>
+<rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#" xmlns="http://purl.org/rss/1.0/" xmlns:dc="http://purl.org/dc/elements/1.1/">
+  <channel><title>RDF Blog</title><link>https://example.com/</link></channel>
+  <item><title>R1</title><link>https://example.com/r1</link><dc:date>2024-05-01T00:00:00Z</dc:date><description>d</description></item>
+</rdf:RDF>`
+	feed, err = ParseFeed([]byte(rdf), base)
+	assert.NoError(t, err)
+	assert.Equal(t, "RDF Blog", feed.Title)
+	assert.Len(t, feed.Items, 1)
+	assert.Equal(t, "https://example.com/r1", feed.Items[0].Guid)
+	assert.False(t, feed.Items[0].Published.IsZero())
+
+	_, err = ParseFeed([]byte(`<html><body>not a feed</body></html>`), base)
+	assert.Error(t, err)
+}
+
+func TestCrawler_FetchFeed(t *testing.T) {
+	requests := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/feed.xml" {
+			http.NotFound(w, r)
+			return
+		}
+		requests++
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		w.Header().Set("Last-Modified", "Wed, 01 May 2024 10:00:00 GMT")
+		w.Header().Set("Content-Type", "application/rss+xml")
+		fmt.Fprint(w, `<rss><channel><title>T</title><item><title>P</title><link>/p</link></item></channel></rss>`)
+	}))
+	defer srv.Close()
+
+	c, err := NewCrawler(nil)
+	assert.NoError(t, err)
+	feed, err := c.FetchFeed(context.Background(), srv.URL+"/feed.xml", "", "")
+	assert.NoError(t, err)
+	assert.False(t, feed.NotModified)
+	assert.Equal(t, `"v1"`, feed.Etag)
+	assert.Equal(t, "Wed, 01 May 2024 10:00:00 GMT", feed.LastModified)
+	assert.Equal(t, srv.URL+"/p", feed.Items[0].Url)
+
+	feed, err = c.FetchFeed(context.Background(), srv.URL+"/feed.xml", feed.Etag, feed.LastModified)
+	assert.NoError(t, err)
+	assert.True(t, feed.NotModified)
+	assert.Empty(t, feed.Items)
+	assert.Equal(t, 2, requests)
+}
@@ -0,0 +1,66 @@
+package crawler
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/lupguo/copilot_develop/app/domain/entity"
+	"github.com/pkg/errors"
+)
+
+// CheckLink 检查站外链接是否可访问(受host并发、礼貌延迟限制，单个链接的检查不受robots.txt限制)：
+// 先发HEAD请求，请求失败或服务端不支持HEAD(405、501等)时改用GET；请求失败记录在Error中，仅ctx取消或Stop时返回错误
+func (c *Crawler) CheckLink(ctx context.Context, linkUrl string) (*entity.LinkCheck, error) {
+	if i := strings.Index(linkUrl, "#"); i >= 0 {
+		linkUrl = linkUrl[:i]
+	}
+	check := &entity.LinkCheck{Url: linkUrl}
+	release, err := c.hosts.acquire(ctx, c.quit, hostOf(linkUrl))
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	status, err := c.requestStatus(ctx, http.MethodHead, linkUrl)
+	if err != nil || headUnsupported(status) {
+		status, err = c.requestStatus(ctx, http.MethodGet, linkUrl)
+	}
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		check.Error = err.Error()
+		return check, nil
+	}
+	check.StatusCode = status
+	return check, nil
+}
+
+// requestStatus 请求url(跟随重定向)，返回最终响应的状态码
+func (c *Crawler) requestStatus(ctx context.Context, method, linkUrl string) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, method, linkUrl, nil)
+	if err != nil {
+		return 0, errors.Wrapf(err, "new %s request for link[%s] got err", method, linkUrl)
+	}
+	req.Header.Set("User-Agent", c.opts.UserAgent)
+	req.Header.Set("Accept", "text/html,application/xhtml+xml;q=0.9,*/*;q=0.5")
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return 0, errors.Wrapf(err, "%s link[%s] got err", method, linkUrl)
+	}
+	// 仅需状态码，少量读取body以便复用连接
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 4<<10))
+	_ = resp.Body.Close()
+	return resp.StatusCode, nil
+}
+
+// headUnsupported HEAD请求的状态码不可信：不支持HEAD或对HEAD返回错误的站点
+func headUnsupported(status int) bool {
+	switch status {
+	case http.StatusMethodNotAllowed, http.StatusNotImplemented, http.StatusForbidden, http.StatusBadRequest, http.StatusNotFound:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,56 @@
+package crawler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCrawler_CheckLink(t *testing.T) {
+	var methods []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		methods = append(methods, r.Method+" "+r.URL.Path)
+		switch r.URL.Path {
+		case "/ok":
+			w.WriteHeader(http.StatusOK)
+		case "/no-head":
+			if r.Method == http.MethodHead {
+				w.WriteHeader(http.StatusMethodNotAllowed)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+		case "/moved":
+			http.Redirect(w, r, "/gone", http.StatusMovedPermanently)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	c, err := NewCrawler(nil)
+	assert.NoError(t, err)
+	ctx := context.Background()
+
+	check, err := c.CheckLink(ctx, srv.URL+"/ok#top")
+	assert.NoError(t, err)
+	assert.Equal(t, srv.URL+"/ok", check.Url)
+	assert.False(t, check.IsBroken())
+
+	check, err = c.CheckLink(ctx, srv.URL+"/no-head")
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, check.StatusCode)
+
+	check, err = c.CheckLink(ctx, srv.URL+"/moved")
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, check.StatusCode)
+	assert.True(t, check.IsBroken())
+	assert.Equal(t, []string{"HEAD /ok", "HEAD /no-head", "GET /no-head", "HEAD /moved", "HEAD /gone", "GET /moved", "GET /gone"}, methods)
+
+	check, err = c.CheckLink(ctx, "http://127.0.0.1:1/unreachable")
+	assert.NoError(t, err)
+	assert.NotEmpty(t, check.Error)
+	assert.True(t, check.IsBroken())
+}
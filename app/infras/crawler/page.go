@@ -0,0 +1,301 @@
+package crawler
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/lupguo/copilot_develop/app/domain/entity"
+	"github.com/pkg/errors"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// fetchPage 请求页面(受host并发、礼貌延迟限制)，按rule提取文本内容及同host的url
+func (c *Crawler) fetchPage(ctx context.Context, pageUrl string, rule *pageRule) (*Page, error) {
+	resp, err := c.fetch(ctx, pageUrl, nil)
+	if err != nil {
+		return nil, err
+	}
+	page, _, err := resp.page(rule)
+	return page, err
+}
+
+// fetchResponse 页面请求结果
+type fetchResponse struct {
+	url          string   // 规范化的最终地址
+	final        *url.URL // 重定向后的最终地址
+	mediaType    string
+	body         []byte
+	etag         string
+	lastModified string
+	notModified  bool // 条件请求返回304，body为空
+}
+
+func (r *fetchResponse) isHTML() bool {
+	return r.mediaType == "" || r.mediaType == "text/html" || r.mediaType == "application/xhtml+xml"
+}
+
+// page 按rule提取文本内容及url，links为页面内所有同host的url(不经rule过滤)
+func (r *fetchResponse) page(rule *pageRule) (page *Page, links []string, err error) {
+	page = &Page{Url: r.url}
+	if !r.isHTML() {
+		if strings.HasPrefix(r.mediaType, "text/") {
+			page.Content = strings.TrimSpace(string(r.body))
+		}
+		return page, nil, nil
+	}
+
+	doc, err := html.Parse(bytes.NewReader(r.body))
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "parse page[%s] html got err", r.url)
+	}
+	page.Content = extractContent(r.body, doc, rule)
+	links = extractURLs(doc, r.final)
+	page.Urls = filterURLs(links, rule)
+	return page, links, nil
+}
+
+// fetch 请求页面(遵守robots.txt，受host并发、礼貌延迟限制)，非2xx响应返回错误；
+// visit不为空时带上If-None-Match、If-Modified-Since条件请求，304时notModified
+func (c *Crawler) fetch(ctx context.Context, pageUrl string, visit *entity.CrawlVisit) (*fetchResponse, error) {
+	if err := c.checkRobots(ctx, pageUrl); err != nil {
+		return nil, err
+	}
+	release, err := c.hosts.acquire(ctx, c.quit, hostOf(pageUrl))
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, pageUrl, nil)
+	if err != nil {
+		return nil, errors.Wrapf(err, "new request for page[%s] got err", pageUrl)
+	}
+	req.Header.Set("User-Agent", c.opts.UserAgent)
+	req.Header.Set("Accept", "text/html,application/xhtml+xml;q=0.9,*/*;q=0.5")
+	if visit != nil {
+		if visit.Etag != "" {
+			req.Header.Set("If-None-Match", visit.Etag)
+		}
+		if visit.LastModified != "" {
+			req.Header.Set("If-Modified-Since", visit.LastModified)
+		}
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, errors.Wrapf(err, "request page[%s] got err", pageUrl)
+	}
+	defer resp.Body.Close()
+
+	result := &fetchResponse{
+		url:          pageUrl,
+		final:        resp.Request.URL,
+		etag:         resp.Header.Get("ETag"),
+		lastModified: resp.Header.Get("Last-Modified"),
+	}
+	if resp.StatusCode == http.StatusNotModified && visit != nil {
+		result.notModified = true
+		return result, nil
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, errors.Errorf("request page[%s] got status %d", pageUrl, resp.StatusCode)
+	}
+	if result.body, err = io.ReadAll(io.LimitReader(resp.Body, maxPageSize)); err != nil {
+		return nil, errors.Wrapf(err, "read page[%s] got err", pageUrl)
+	}
+
+	// 重定向后以最终地址为准
+	if u, err := NormalizeURL(result.final.String(), nil); err == nil {
+		result.url = u
+	}
+	result.mediaType, _, _ = mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	return result, nil
+}
+
+// extractContent 按规则的内容正则提取文本，未设置时取整个页面的文本
+func extractContent(body []byte, doc *html.Node, rule *pageRule) string {
+	if rule == nil || rule.content == nil {
+		return htmlText(doc)
+	}
+	var parts []string
+	for _, match := range rule.content.FindAllSubmatch(body, -1) {
+		fragment := match[0]
+		if len(match) > 1 {
+			fragment = match[1]
+		}
+		node, err := html.Parse(bytes.NewReader(fragment))
+		if err != nil {
+			continue
+		}
+		if text := htmlText(node); text != "" {
+			parts = append(parts, text)
+		}
+	}
+	return strings.Join(parts, "\n")
+}
+
+// extractURLs 页面中<a href>指向同host的url，规范化、去重
+func extractURLs(doc *html.Node, pageUrl *url.URL) []string {
+	base := pageUrl
+	var hrefs []string
+	walkHTML(doc, func(n *html.Node) bool {
+		switch n.DataAtom {
+		case atom.Base:
+			if href := htmlAttr(n, "href"); href != "" {
+				if u, err := pageUrl.Parse(href); err == nil {
+					base = u
+				}
+			}
+		case atom.A:
+			if href := htmlAttr(n, "href"); href != "" {
+				hrefs = append(hrefs, href)
+			}
+		}
+		return true
+	})
+
+	host := strings.ToLower(pageUrl.Host)
+	seen := make(map[string]bool)
+	var urls []string
+	for _, href := range hrefs {
+		u, err := NormalizeURL(href, base)
+		if err != nil || seen[u] || hostOf(u) != stripDefaultPort(pageUrl.Scheme, host) {
+			continue
+		}
+		seen[u] = true
+		urls = append(urls, u)
+	}
+	return urls
+}
+
+// filterURLs 按规则的url正则过滤，未设置时不过滤
+func filterURLs(urls []string, rule *pageRule) []string {
+	if rule == nil || rule.url == nil {
+		return urls
+	}
+	var matched []string
+	for _, u := range urls {
+		if rule.url.MatchString(u) {
+			matched = append(matched, u)
+		}
+	}
+	return matched
+}
+
+// NormalizeURL 规范化url用于去重：相对base解析，仅支持http(s)，scheme、host转小写，去掉默认端口、fragment，
+// 清理路径中的 . 和 ..，空路径为/，query参数按key排序
+func NormalizeURL(rawURL string, base *url.URL) (string, error) {
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil {
+		return "", errors.Wrapf(err, "parse url[%s] got err", rawURL)
+	}
+	if base != nil {
+		u = base.ResolveReference(u)
+	}
+	u.Scheme = strings.ToLower(u.Scheme)
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return "", errors.Errorf("unsupported url[%s]", rawURL)
+	}
+	if u.Host == "" {
+		return "", errors.Errorf("url[%s] has no host", rawURL)
+	}
+	u.Host = stripDefaultPort(u.Scheme, strings.ToLower(u.Host))
+	u.Fragment, u.RawFragment = "", ""
+
+	if u.Path == "" {
+		u.Path = "/"
+	} else if strings.Contains(u.Path, "/.") || strings.Contains(u.Path, "//") {
+		cleaned := path.Clean(u.Path)
+		if strings.HasSuffix(u.Path, "/") && cleaned != "/" {
+			cleaned += "/"
+		}
+		u.Path = cleaned
+	}
+	u.RawPath = ""
+	if u.RawQuery != "" {
+		u.RawQuery = u.Query().Encode()
+	}
+	return u.String(), nil
+}
+
+// hostOf 规范化url的host
+func hostOf(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	return u.Host
+}
+
+// stripDefaultPort 去掉scheme的默认端口
+func stripDefaultPort(scheme, host string) string {
+	if (scheme == "http" && strings.HasSuffix(host, ":80")) || (scheme == "https" && strings.HasSuffix(host, ":443")) {
+		return host[:strings.LastIndex(host, ":")]
+	}
+	return host
+}
+
+// blockAtoms 文本换行的块级元素
+var blockAtoms = map[atom.Atom]bool{
+	atom.P: true, atom.Div: true, atom.Br: true, atom.Li: true, atom.Tr: true, atom.Pre: true,
+	atom.H1: true, atom.H2: true, atom.H3: true, atom.H4: true, atom.H5: true, atom.H6: true,
+	atom.Section: true, atom.Article: true, atom.Blockquote: true, atom.Header: true, atom.Footer: true,
+}
+
+// skipAtoms 不提取文本的元素
+var skipAtoms = map[atom.Atom]bool{
+	atom.Script: true, atom.Style: true, atom.Noscript: true, atom.Template: true, atom.Head: true,
+}
+
+// htmlText 节点的可见文本，块级元素换行，行内空白合并
+func htmlText(n *html.Node) string {
+	var b strings.Builder
+	walkHTML(n, func(n *html.Node) bool {
+		switch n.Type {
+		case html.ElementNode:
+			if skipAtoms[n.DataAtom] {
+				return false
+			}
+			if blockAtoms[n.DataAtom] {
+				b.WriteString("\n")
+			}
+		case html.TextNode:
+			b.WriteString(n.Data)
+		}
+		return true
+	})
+
+	var lines []string
+	for _, line := range strings.Split(b.String(), "\n") {
+		if line = strings.Join(strings.Fields(line), " "); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// walkHTML 深度优先遍历节点，visit返回false时不再遍历子节点
+func walkHTML(n *html.Node, visit func(n *html.Node) bool) {
+	if !visit(n) {
+		return
+	}
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		walkHTML(child, visit)
+	}
+}
+
+// htmlAttr 元素的属性值
+func htmlAttr(n *html.Node, key string) string {
+	for _, attr := range n.Attr {
+		if attr.Key == key {
+			return strings.TrimSpace(attr.Val)
+		}
+	}
+	return ""
+}
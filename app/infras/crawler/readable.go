@@ -0,0 +1,322 @@
+package crawler
+
+import (
+	"bytes"
+	"context"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/lupguo/copilot_develop/app/domain/entity"
+	"github.com/pkg/errors"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+var (
+	// noiseAtoms 导航、表单等非正文元素
+	noiseAtoms = map[atom.Atom]bool{
+		atom.Script: true, atom.Style: true, atom.Noscript: true, atom.Template: true, atom.Nav: true,
+		atom.Header: true, atom.Footer: true, atom.Aside: true, atom.Form: true, atom.Iframe: true,
+		atom.Svg: true, atom.Button: true, atom.Select: true, atom.Input: true, atom.Textarea: true,
+	}
+
+	// noiseClassRegex class、id命中时视为噪音(同时命中正文特征的保留)
+	noiseClassRegex   = regexp.MustCompile(`(?i)comment|sidebar|footer|header|menu|nav|share|social|related|advert|\bads?\b|promo|cookie|banner|breadcrumb|popup|subscribe|newsletter`)
+	contentClassRegex = regexp.MustCompile(`(?i)article|content|post|entry|main|body|text`)
+
+	// headingLevels 标题级别
+	headingLevels = map[atom.Atom]int{atom.H1: 1, atom.H2: 2, atom.H3: 3, atom.H4: 4, atom.H5: 5, atom.H6: 6}
+
+	// paragraphAtoms 参与正文评分的段落元素
+	paragraphAtoms = map[atom.Atom]bool{atom.P: true, atom.Pre: true, atom.Td: true, atom.Blockquote: true}
+)
+
+const (
+	// minParagraphLength 参与正文评分的段落最少字符数
+	minParagraphLength = 25
+
+	// minArticleLength <article>、<main>作为正文的最少字符数
+	minArticleLength = 200
+)
+
+// FetchReadable 抓取网页并提取正文(去除导航、侧栏、评论等噪音)，正文转为Markdown
+func (c *Crawler) FetchReadable(ctx context.Context, pageUrl string) (*entity.WebPage, error) {
+	pageUrl, err := NormalizeURL(pageUrl, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.fetch(ctx, pageUrl, nil)
+	if err != nil {
+		return nil, err
+	}
+	if !resp.isHTML() {
+		return nil, errors.Errorf("page[%s] is not html: %s", pageUrl, resp.mediaType)
+	}
+	page, err := ExtractReadable(resp.body)
+	if err != nil {
+		return nil, errors.Wrapf(err, "extract page[%s] readable content got err", pageUrl)
+	}
+	page.Url = resp.url
+	return page, nil
+}
+
+// ExtractReadable 从html中提取标题、站点名及正文
+func ExtractReadable(body []byte) (*entity.WebPage, error) {
+	doc, err := html.Parse(bytes.NewReader(body))
+	if err != nil {
+		return nil, errors.Wrap(err, "parse html got err")
+	}
+
+	page := &entity.WebPage{}
+	var titleTag, firstH1 string
+	walkHTML(doc, func(n *html.Node) bool {
+		switch n.DataAtom {
+		case atom.Meta:
+			switch htmlAttr(n, "property") {
+			case "og:title":
+				page.Title = htmlAttr(n, "content")
+			case "og:site_name":
+				page.SiteName = htmlAttr(n, "content")
+			}
+		case atom.Title:
+			if titleTag == "" {
+				titleTag = inlineText(n)
+			}
+		case atom.H1:
+			if firstH1 == "" {
+				firstH1 = inlineText(n)
+			}
+		}
+		return true
+	})
+	if page.Title == "" {
+		page.Title = titleTag
+	}
+	if page.Title == "" {
+		page.Title = firstH1
+	}
+
+	removeNoise(doc)
+	if main := readableNode(doc); main != nil {
+		var blocks []string
+		markdownBlocks(main, &blocks)
+		page.Content = strings.Join(blocks, "\n\n")
+	}
+	if strings.TrimSpace(page.Content) == "" {
+		return nil, errors.New("no readable content found")
+	}
+	return page, nil
+}
+
+// removeNoise 移除导航、页眉页脚、侧栏、评论、广告等元素
+func removeNoise(n *html.Node) {
+	for child := n.FirstChild; child != nil; {
+		next := child.NextSibling
+		if child.Type == html.CommentNode || (child.Type == html.ElementNode && isNoise(child)) {
+			n.RemoveChild(child)
+		} else {
+			removeNoise(child)
+		}
+		child = next
+	}
+}
+
+func isNoise(n *html.Node) bool {
+	switch n.DataAtom {
+	case atom.Html, atom.Body, atom.Article, atom.Main:
+		return false
+	}
+	if noiseAtoms[n.DataAtom] {
+		return true
+	}
+	class := htmlAttr(n, "class") + " " + htmlAttr(n, "id")
+	return noiseClassRegex.MatchString(class) && !contentClassRegex.MatchString(class)
+}
+
+// readableNode 正文所在的节点：优先最长的<article>、<main>，否则按段落文本评分(扣除链接占比)选出最优的容器
+func readableNode(doc *html.Node) *html.Node {
+	var best *html.Node
+	bestLen := 0
+	walkHTML(doc, func(n *html.Node) bool {
+		if n.DataAtom == atom.Article || n.DataAtom == atom.Main || htmlAttr(n, "role") == "main" {
+			if l := len(htmlText(n)); l >= minArticleLength && l > bestLen {
+				best, bestLen = n, l
+			}
+		}
+		return true
+	})
+	if best != nil {
+		return best
+	}
+
+	scores := make(map[*html.Node]float64)
+	walkHTML(doc, func(n *html.Node) bool {
+		if !paragraphAtoms[n.DataAtom] || n.Parent == nil {
+			return true
+		}
+		text := inlineText(n)
+		if len(text) < minParagraphLength {
+			return true
+		}
+		score := 1 + float64(strings.Count(text, ",")+strings.Count(text, "，"))
+		score += float64(min(len(text)/100, 3))
+		scores[n.Parent] += score
+		if n.Parent.Parent != nil {
+			scores[n.Parent.Parent] += score / 2
+		}
+		return true
+	})
+	bestScore := 0.0
+	for n, score := range scores {
+		if score *= 1 - linkDensity(n); score > bestScore {
+			best, bestScore = n, score
+		}
+	}
+	if best != nil {
+		return best
+	}
+
+	var body *html.Node
+	walkHTML(doc, func(n *html.Node) bool {
+		if n.DataAtom == atom.Body {
+			body = n
+		}
+		return body == nil
+	})
+	return body
+}
+
+// linkDensity 链接文本占节点文本的比例
+func linkDensity(n *html.Node) float64 {
+	total := len(htmlText(n))
+	if total == 0 {
+		return 0
+	}
+	links := 0
+	walkHTML(n, func(n *html.Node) bool {
+		if n.DataAtom == atom.A {
+			links += len(inlineText(n))
+			return false
+		}
+		return true
+	})
+	return float64(links) / float64(total)
+}
+
+// markdownBlocks 将节点转换为Markdown块(标题、段落、列表、代码、引用、表格)，链接、图片仅保留文本
+func markdownBlocks(n *html.Node, blocks *[]string) {
+	add := func(block string) {
+		if block = strings.TrimSpace(block); block != "" {
+			*blocks = append(*blocks, block)
+		}
+	}
+	switch {
+	case n.Type == html.TextNode:
+		add(collapseSpace(n.Data))
+		return
+	case n.Type != html.ElementNode && n.Type != html.DocumentNode:
+		return
+	}
+
+	if level, ok := headingLevels[n.DataAtom]; ok {
+		if text := inlineText(n); text != "" {
+			add(strings.Repeat("#", level) + " " + text)
+		}
+		return
+	}
+	switch n.DataAtom {
+	case atom.P:
+		add(inlineText(n))
+	case atom.Pre:
+		if code := strings.Trim(rawText(n), "\n"); strings.TrimSpace(code) != "" {
+			add("```\n" + code + "\n```")
+		}
+	case atom.Ul, atom.Ol:
+		var items []string
+		i := 0
+		for li := n.FirstChild; li != nil; li = li.NextSibling {
+			if li.DataAtom != atom.Li {
+				continue
+			}
+			i++
+			marker := "-"
+			if n.DataAtom == atom.Ol {
+				marker = strconv.Itoa(i) + "."
+			}
+			if text := inlineText(li); text != "" {
+				items = append(items, marker+" "+text)
+			}
+		}
+		add(strings.Join(items, "\n"))
+	case atom.Blockquote:
+		var inner []string
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			markdownBlocks(child, &inner)
+		}
+		var lines []string
+		for _, line := range strings.Split(strings.Join(inner, "\n\n"), "\n") {
+			lines = append(lines, strings.TrimSpace("> "+line))
+		}
+		if len(inner) > 0 {
+			add(strings.Join(lines, "\n"))
+		}
+	case atom.Tr:
+		var cells []string
+		for cell := n.FirstChild; cell != nil; cell = cell.NextSibling {
+			if cell.DataAtom == atom.Td || cell.DataAtom == atom.Th {
+				cells = append(cells, inlineText(cell))
+			}
+		}
+		add(strings.Join(cells, " | "))
+	case atom.Img, atom.Figure, atom.Picture, atom.Video, atom.Audio:
+		// 图片、音视频不提取
+	default:
+		if !hasBlockChild(n) {
+			add(inlineText(n))
+			return
+		}
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			markdownBlocks(child, blocks)
+		}
+	}
+}
+
+// hasBlockChild 子节点中是否有块级元素
+func hasBlockChild(n *html.Node) bool {
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		if child.Type != html.ElementNode {
+			continue
+		}
+		if blockAtoms[child.DataAtom] || headingLevels[child.DataAtom] > 0 || paragraphAtoms[child.DataAtom] ||
+			child.DataAtom == atom.Ul || child.DataAtom == atom.Ol || child.DataAtom == atom.Table ||
+			child.DataAtom == atom.Main || child.DataAtom == atom.Figure || hasBlockChild(child) {
+			return true
+		}
+	}
+	return false
+}
+
+// inlineText 节点内的文本，空白合并为一个空格
+func inlineText(n *html.Node) string {
+	return collapseSpace(rawText(n))
+}
+
+// rawText 节点内的原始文本(保留空白，<br>为换行)
+func rawText(n *html.Node) string {
+	var b strings.Builder
+	walkHTML(n, func(n *html.Node) bool {
+		switch {
+		case n.Type == html.TextNode:
+			b.WriteString(n.Data)
+		case n.DataAtom == atom.Br:
+			b.WriteString("\n")
+		}
+		return true
+	})
+	return b.String()
+}
+
+func collapseSpace(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
@@ -0,0 +1,88 @@
+package crawler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const readableHTML = `<html><head>
+<title>Fallback Title</title>
+<meta property="og:title" content="Go Channels Explained">
+<meta property="og:site_name" content="Example Blog">
+<script>track()</script>
+</head><body>
+<header class="site-header"><a href="/">Home</a><a href="/about">About</a></header>
+<nav><ul><li><a href="/a">A</a></li><li><a href="/b">B</a></li></ul></nav>
+<div id="wrapper">
+  <div class="post-body">
+    <h1>Go Channels Explained</h1>
+    <p>Channels are the pipes that connect concurrent goroutines, you can send values into channels from one goroutine.</p>
+    <h2>Buffered channels</h2>
+    <p>By default sends and receives block until the other side is ready, which allows goroutines to synchronize.</p>
+    <pre><code>ch := make(chan int, 2)
+ch &lt;- 1</code></pre>
+    <ol><li>create</li><li>send <a href="/x">value</a></li></ol>
+    <div class="share-buttons">Share on Twitter</div>
+  </div>
+  <aside class="sidebar"><p>Popular posts, recent posts, tags and a lot of other links you do not care about.</p></aside>
+  <div class="comments"><p>Great post, thanks for writing this, very helpful and clear, keep going!</p></div>
+</div>
+<footer>Copyright</footer>
+</body></html>`
+
+func TestExtractReadable(t *testing.T) {
+	page, err := ExtractReadable([]byte(readableHTML))
+	assert.NoError(t, err)
+	assert.Equal(t, "Go Channels Explained", page.Title)
+	assert.Equal(t, "Example Blog", page.SiteName)
+	assert.Equal(t, strings.Join([]string{
+		"# Go Channels Explained",
+		"Channels are the pipes that connect concurrent goroutines, you can send values into channels from one goroutine.",
+		"## Buffered channels",
+		"By default sends and receives block until the other side is ready, which allows goroutines to synchronize.",
+		"```\nch := make(chan int, 2)\nch <- 1\n```",
+		"1. create\n2. send value",
+	}, "\n\n"), page.Content)
+
+	// 优先使用<article>，标题取<title>
+	page, err = ExtractReadable([]byte(`<title>Post</title><div><p>menu</p></div><article><p>` +
+		strings.Repeat("article body ", 20) + `</p></article>`))
+	assert.NoError(t, err)
+	assert.Equal(t, "Post", page.Title)
+	assert.Equal(t, strings.TrimSpace(strings.Repeat("article body ", 20)), page.Content)
+
+	_, err = ExtractReadable([]byte(`<html><body><nav>only nav</nav></body></html>`))
+	assert.Error(t, err)
+}
+
+func TestCrawler_FetchReadable(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/post", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/posts/go-channels/", http.StatusMovedPermanently)
+	})
+	mux.HandleFunc("/posts/go-channels/", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, readableHTML)
+	})
+	mux.HandleFunc("/feed.json", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{}`)
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	c, err := NewCrawler(nil)
+	assert.NoError(t, err)
+	page, err := c.FetchReadable(context.Background(), srv.URL+"/post#top")
+	assert.NoError(t, err)
+	assert.Equal(t, srv.URL+"/posts/go-channels/", page.Url)
+	assert.Equal(t, "Go Channels Explained", page.Title)
+
+	_, err = c.FetchReadable(context.Background(), srv.URL+"/feed.json")
+	assert.Error(t, err)
+}
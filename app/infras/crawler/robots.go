@@ -0,0 +1,270 @@
+package crawler
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// ErrDisallowed robots.txt禁止爬取的url
+var ErrDisallowed = errors.New("disallowed by robots.txt")
+
+const (
+	// robotsTTL robots.txt的缓存时间
+	robotsTTL = 24 * time.Hour
+
+	// robotsErrTTL robots.txt请求失败(5xx、网络错误)时的缓存时间，期间整个站点视为禁止爬取
+	robotsErrTTL = 10 * time.Minute
+
+	// maxRobotsSize robots.txt最大读取的字节数
+	maxRobotsSize = 500 << 10
+
+	// maxCrawlDelay Crawl-delay的上限
+	maxCrawlDelay = time.Minute
+)
+
+// robotsRule Allow/Disallow规则
+type robotsRule struct {
+	allow   bool
+	pattern string         // 原始路径规则，长度用于最长匹配
+	regex   *regexp.Regexp // 支持 * 通配及 $ 结尾
+}
+
+// robotsGroup 适用于某些User-agent的一组规则
+type robotsGroup struct {
+	agents     []string
+	rules      []*robotsRule
+	crawlDelay time.Duration
+}
+
+// robotsTxt 解析后的robots.txt
+type robotsTxt struct {
+	groups   []*robotsGroup
+	sitemaps []string
+}
+
+// parseRobots 解析robots.txt：连续的User-agent行开始一组规则，Sitemap行不属于任何组，无法识别的行忽略
+func parseRobots(body []byte) *robotsTxt {
+	robots := &robotsTxt{}
+	var group *robotsGroup
+	inAgents := false
+	scanner := bufio.NewScanner(bytes.NewReader(body))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = line[:i]
+		}
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		key, value = strings.ToLower(strings.TrimSpace(key)), strings.TrimSpace(value)
+
+		switch key {
+		case "user-agent":
+			if !inAgents {
+				group = &robotsGroup{}
+				robots.groups = append(robots.groups, group)
+				inAgents = true
+			}
+			group.agents = append(group.agents, strings.ToLower(value))
+			continue
+		case "sitemap":
+			if value != "" {
+				robots.sitemaps = append(robots.sitemaps, value)
+			}
+			continue
+		}
+		inAgents = false
+		if group == nil {
+			continue
+		}
+		switch key {
+		case "allow", "disallow":
+			// 空的Disallow表示不限制
+			if value == "" {
+				continue
+			}
+			group.rules = append(group.rules, &robotsRule{allow: key == "allow", pattern: value, regex: robotsPatternRegex(value)})
+		case "crawl-delay":
+			if seconds, err := strconv.ParseFloat(value, 64); err == nil && seconds > 0 {
+				group.crawlDelay = time.Duration(seconds * float64(time.Second))
+			}
+		}
+	}
+	return robots
+}
+
+// robotsPatternRegex 路径规则转为正则：前缀匹配，* 匹配任意字符，结尾的 $ 表示精确结尾
+func robotsPatternRegex(pattern string) *regexp.Regexp {
+	anchored := strings.HasSuffix(pattern, "$")
+	pattern = strings.TrimSuffix(pattern, "$")
+	expr := "^" + strings.ReplaceAll(regexp.QuoteMeta(pattern), `\*`, ".*")
+	if anchored {
+		expr += "$"
+	}
+	return regexp.MustCompile(expr)
+}
+
+// group 适用于userAgent的规则：合并User-agent与产品名(不区分大小写)相同的所有组，没有时使用 * 的组；都没有时为nil(不限制)
+func (r *robotsTxt) group(userAgent string) *robotsGroup {
+	product := strings.ToLower(userAgent)
+	if i := strings.IndexAny(product, "/ "); i >= 0 {
+		product = product[:i]
+	}
+
+	match := func(agent string) *robotsGroup {
+		var merged *robotsGroup
+		for _, g := range r.groups {
+			for _, a := range g.agents {
+				if a != agent {
+					continue
+				}
+				if merged == nil {
+					merged = &robotsGroup{}
+				}
+				merged.rules = append(merged.rules, g.rules...)
+				merged.crawlDelay = max(merged.crawlDelay, g.crawlDelay)
+				break
+			}
+		}
+		return merged
+	}
+	if g := match(product); g != nil {
+		return g
+	}
+	return match("*")
+}
+
+// allowed 路径(含query)是否允许爬取：最长匹配的规则生效，长度相同时Allow优先，没有匹配的规则时允许
+func (g *robotsGroup) allowed(path string) bool {
+	if g == nil || path == "/robots.txt" {
+		return true
+	}
+	allow, longest := true, -1
+	for _, rule := range g.rules {
+		if !rule.regex.MatchString(path) {
+			continue
+		}
+		if l := len(rule.pattern); l > longest || (l == longest && rule.allow) {
+			allow, longest = rule.allow, l
+		}
+	}
+	return allow
+}
+
+// robotsEntry 单个站点缓存的robots.txt
+type robotsEntry struct {
+	mu      sync.Mutex
+	expires time.Time
+	info    *robotsInfo
+}
+
+// robotsInfo 站点robots.txt中适用于本爬虫的内容，缓存更新时整体替换
+type robotsInfo struct {
+	group    *robotsGroup // 适用于本爬虫的规则，nil时不限制
+	sitemaps []string     // 声明的sitemap
+	blocked  bool         // robots.txt暂时无法访问，整个站点禁止爬取
+}
+
+// robotsCache 按 scheme://host 缓存robots.txt
+type robotsCache struct {
+	mu      sync.Mutex
+	entries map[string]*robotsEntry
+}
+
+func newRobotsCache() *robotsCache {
+	return &robotsCache{entries: make(map[string]*robotsEntry)}
+}
+
+// robots 站点的robots.txt，缓存过期时重新请求；同一站点并发调用时只请求一次
+func (c *Crawler) robots(ctx context.Context, pageUrl *url.URL) *robotsInfo {
+	site := pageUrl.Scheme + "://" + pageUrl.Host
+	c.sites.mu.Lock()
+	entry, ok := c.sites.entries[site]
+	if !ok {
+		entry = &robotsEntry{}
+		c.sites.entries[site] = entry
+	}
+	c.sites.mu.Unlock()
+
+	entry.mu.Lock()
+	defer entry.mu.Unlock()
+	if entry.info != nil && time.Now().Before(entry.expires) {
+		return entry.info
+	}
+
+	robots, err := c.fetchRobots(ctx, site+"/robots.txt")
+	if err != nil {
+		if ctx.Err() != nil {
+			return &robotsInfo{blocked: true}
+		}
+		log.Warnf("crawler fetch %s/robots.txt got err, disallow the site for %s: %s", site, robotsErrTTL, err)
+		entry.info = &robotsInfo{blocked: true}
+		entry.expires = time.Now().Add(robotsErrTTL)
+		return entry.info
+	}
+	entry.info = &robotsInfo{group: robots.group(c.opts.UserAgent), sitemaps: robots.sitemaps}
+	entry.expires = time.Now().Add(robotsTTL)
+
+	// Crawl-delay大于配置的请求间隔时以Crawl-delay为准
+	if g := entry.info.group; g != nil && g.crawlDelay > 0 {
+		c.hosts.setDelay(pageUrl.Host, min(g.crawlDelay, maxCrawlDelay))
+	}
+	return entry.info
+}
+
+// fetchRobots 请求robots.txt：4xx视为没有限制，5xx及网络错误返回错误
+func (c *Crawler) fetchRobots(ctx context.Context, robotsUrl string) (*robotsTxt, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, robotsUrl, nil)
+	if err != nil {
+		return nil, errors.Wrapf(err, "new request for [%s] got err", robotsUrl)
+	}
+	req.Header.Set("User-Agent", c.opts.UserAgent)
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, errors.Wrapf(err, "request [%s] got err", robotsUrl)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode >= 500:
+		return nil, errors.Errorf("request [%s] got status %d", robotsUrl, resp.StatusCode)
+	case resp.StatusCode >= 400:
+		return &robotsTxt{}, nil
+	case resp.StatusCode < 200 || resp.StatusCode >= 300:
+		return nil, errors.Errorf("request [%s] got status %d", robotsUrl, resp.StatusCode)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxRobotsSize))
+	if err != nil {
+		return nil, errors.Wrapf(err, "read [%s] got err", robotsUrl)
+	}
+	return parseRobots(body), nil
+}
+
+// checkRobots robots.txt是否允许爬取pageUrl，IgnoreRobots时不检查
+func (c *Crawler) checkRobots(ctx context.Context, pageUrl string) error {
+	if c.opts.IgnoreRobots {
+		return nil
+	}
+	u, err := url.Parse(pageUrl)
+	if err != nil {
+		return errors.Wrapf(err, "parse url[%s] got err", pageUrl)
+	}
+	robots := c.robots(ctx, u)
+	if robots.blocked || !robots.group.allowed(u.RequestURI()) {
+		return errors.Wrapf(ErrDisallowed, "page[%s]", pageUrl)
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+package crawler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseRobots(t *testing.T) {
+	robots := parseRobots([]byte(`
+# comment
+User-agent: Googlebot
+Disallow: /
+
+User-agent: copilot_develop-crawler
+User-agent: other
+Disallow: /private/   # inline comment
+Allow: /private/open
+Disallow: /*.pdf$
+Disallow: /search?
+Crawl-delay: 2.5
+
+User-agent: *
+Disallow: /tmp/
+Disallow:
+
+Sitemap: https://example.com/sitemap.xml
+`))
+	assert.Equal(t, []string{"https://example.com/sitemap.xml"}, robots.sitemaps)
+
+	g := robots.group("Copilot_Develop-Crawler/1.0 (+https://tkstorm.com)")
+	assert.Equal(t, 2500*time.Millisecond, g.crawlDelay)
+	tests := map[string]bool{
+		"/":                  true,
+		"/robots.txt":        true,
+		"/private/a":         false,
+		"/private/open":      true,
+		"/private/opened/x":  true,
+		"/doc.pdf":           false,
+		"/doc.pdf?download":  true,
+		"/search?q=go":       false,
+		"/searching":         true,
+		"/tmp/x":             true,
+		"/private":           true,
+		"/a/b/c/private/x/y": true,
+	}
+	for path, want := range tests {
+		assert.Equal(t, want, g.allowed(path), path)
+	}
+
+	// 未单独配置的User-agent使用 * 的规则
+	g = robots.group("unknown-bot/2.0")
+	assert.False(t, g.allowed("/tmp/x"))
+	assert.True(t, g.allowed("/private/a"))
+
+	// 没有适用的规则时不限制
+	assert.Nil(t, parseRobots([]byte("User-agent: a\nDisallow: /")).group("b"))
+	assert.True(t, (*robotsGroup)(nil).allowed("/x"))
+}
+
+func TestCrawler_Robots(t *testing.T) {
+	site, srv := newTestSite(t, map[string]string{
+		"/robots.txt": "User-agent: *\nDisallow: /private\nCrawl-delay: 0.05\n",
+		"/":           `<a href="/a">a</a><a href="/private/b">b</a><a href="/c">c</a>`,
+		"/a":          "a",
+		"/c":          "c",
+		"/private/b":  "b",
+	}, 0)
+
+	c, err := NewCrawler(&Options{Seeds: []string{srv.URL}, MaxDepth: 1, HostConcurrency: 4})
+	assert.NoError(t, err)
+	start := time.Now()
+	urls, err := c.FoundURLsFromUrlBFS(context.Background(), srv.URL, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{srv.URL + "/", srv.URL + "/a", srv.URL + "/c"}, urls)
+	assert.Equal(t, 0, site.hit("/private/b"))
+	assert.Equal(t, 1, site.hit("/robots.txt"))
+
+	// Crawl-delay大于配置的间隔，3次请求至少间隔2次
+	assert.GreaterOrEqual(t, time.Since(start), 2*50*time.Millisecond)
+
+	_, err = c.FetchReadable(context.Background(), srv.URL+"/private/b")
+	assert.ErrorIs(t, err, ErrDisallowed)
+
+	// 忽略robots.txt
+	c, err = NewCrawler(&Options{IgnoreRobots: true})
+	assert.NoError(t, err)
+	_, _, err = c.GetPageUrlDoc(context.Background(), srv.URL+"/private/b", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, site.hit("/private/b"))
+}
+
+func TestCrawler_RobotsUnavailable(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/robots.txt" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte("page"))
+	}))
+	defer srv.Close()
+
+	// robots.txt返回5xx时整个站点禁止爬取
+	c, err := NewCrawler(nil)
+	assert.NoError(t, err)
+	_, _, err = c.GetPageUrlDoc(context.Background(), srv.URL+"/a", nil)
+	assert.ErrorIs(t, err, ErrDisallowed)
+}
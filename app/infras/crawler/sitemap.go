@@ -0,0 +1,137 @@
+package crawler
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/xml"
+	"io"
+	"net/url"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// maxSitemapDepth sitemap index最多嵌套的层数
+	maxSitemapDepth = 3
+
+	// maxSitemapURLs 单个站点最多读取的url数(sitemap协议单个文件的上限)
+	maxSitemapURLs = 50000
+)
+
+// sitemapXML urlset及sitemapindex格式的sitemap
+type sitemapXML struct {
+	URLs     []sitemapLoc `xml:"url"`
+	Sitemaps []sitemapLoc `xml:"sitemap"`
+}
+
+type sitemapLoc struct {
+	Loc string `xml:"loc"`
+}
+
+// SitemapURLs 站点sitemap中与siteUrl同host的页面url(规范化、去重)：读取robots.txt中声明的sitemap，
+// 没有声明时读取 /sitemap.xml(不存在时返回空)；支持sitemap index及gzip压缩
+func (c *Crawler) SitemapURLs(ctx context.Context, siteUrl string) ([]string, error) {
+	start, err := NormalizeURL(siteUrl, nil)
+	if err != nil {
+		return nil, err
+	}
+	site, _ := url.Parse(start)
+
+	var sitemaps []string
+	declared := false
+	if !c.opts.IgnoreRobots {
+		sitemaps = c.robots(ctx, site).sitemaps
+		declared = len(sitemaps) > 0
+	}
+	if !declared {
+		sitemaps = []string{site.Scheme + "://" + site.Host + "/sitemap.xml"}
+	}
+
+	reader := &sitemapReader{crawler: c, host: site.Host, seen: make(map[string]bool)}
+	for _, sitemap := range sitemaps {
+		err := reader.read(ctx, sitemap, 0)
+		switch {
+		case err != nil && ctx.Err() != nil:
+			return reader.urls, ctx.Err()
+		case err != nil && declared:
+			log.Warnf("crawler read sitemap[%s] got err: %s", sitemap, err)
+		case err != nil:
+			log.Debugf("crawler read default sitemap[%s] got err: %s", sitemap, err)
+		}
+	}
+	return reader.urls, nil
+}
+
+// sitemapReader 递归读取sitemap，收集同host的页面url
+type sitemapReader struct {
+	crawler *Crawler
+	host    string
+	seen    map[string]bool // 已读取的sitemap及已收集的url
+	urls    []string
+}
+
+func (r *sitemapReader) read(ctx context.Context, sitemapUrl string, depth int) error {
+	sitemapUrl, err := NormalizeURL(sitemapUrl, nil)
+	if err != nil {
+		return err
+	}
+	if r.seen[sitemapUrl] || len(r.urls) >= maxSitemapURLs {
+		return nil
+	}
+	r.seen[sitemapUrl] = true
+
+	resp, err := r.crawler.fetch(ctx, sitemapUrl, nil)
+	if err != nil {
+		return err
+	}
+	sitemap, err := parseSitemap(resp.body)
+	if err != nil {
+		return errors.Wrapf(err, "parse sitemap[%s] got err", sitemapUrl)
+	}
+
+	for _, loc := range sitemap.URLs {
+		u, err := NormalizeURL(loc.Loc, nil)
+		if err != nil || r.seen[u] || hostOf(u) != r.host {
+			continue
+		}
+		if len(r.urls) >= maxSitemapURLs {
+			break
+		}
+		r.seen[u] = true
+		r.urls = append(r.urls, u)
+	}
+	if depth+1 >= maxSitemapDepth {
+		return nil
+	}
+	for _, loc := range sitemap.Sitemaps {
+		if err = r.read(ctx, loc.Loc, depth+1); err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			log.Warnf("crawler read sitemap[%s] got err: %s", loc.Loc, err)
+		}
+	}
+	return nil
+}
+
+// parseSitemap 解析sitemap，gzip压缩(按文件头判断)的先解压
+func parseSitemap(body []byte) (*sitemapXML, error) {
+	if len(body) > 2 && body[0] == 0x1f && body[1] == 0x8b {
+		zr, err := gzip.NewReader(bytes.NewReader(body))
+		if err != nil {
+			return nil, errors.Wrap(err, "open gzip sitemap got err")
+		}
+		defer zr.Close()
+		if body, err = io.ReadAll(io.LimitReader(zr, maxPageSize*5)); err != nil {
+			return nil, errors.Wrap(err, "read gzip sitemap got err")
+		}
+	}
+
+	sitemap := &sitemapXML{}
+	if err := xml.Unmarshal(body, sitemap); err != nil {
+		return nil, errors.Wrap(err, "unmarshal sitemap xml got err")
+	}
+	return sitemap, nil
+}
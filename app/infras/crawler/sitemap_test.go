@@ -0,0 +1,81 @@
+package crawler
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCrawler_SitemapURLs(t *testing.T) {
+	var srvURL string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/robots.txt":
+			fmt.Fprintf(w, "User-agent: *\nDisallow: /private\nSitemap: %s/sitemap_index.xml\n", srvURL)
+		case "/sitemap_index.xml":
+			fmt.Fprintf(w, `<?xml version="1.0" encoding="UTF-8"?>
+<sitemapindex xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+  <sitemap><loc>%[1]s/posts.xml.gz</loc></sitemap>
+  <sitemap><loc>%[1]s/pages.xml</loc></sitemap>
+  <sitemap><loc>%[1]s/missing.xml</loc></sitemap>
+</sitemapindex>`, srvURL)
+		case "/posts.xml.gz":
+			var buf bytes.Buffer
+			zw := gzip.NewWriter(&buf)
+			fmt.Fprintf(zw, `<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+  <url><loc>%[1]s/posts/1</loc><lastmod>2024-01-01</lastmod></url>
+  <url><loc>%[1]s/posts/2#top</loc></url>
+  <url><loc>https://other.example.com/x</loc></url>
+</urlset>`, srvURL)
+			zw.Close()
+			w.Header().Set("Content-Type", "application/gzip")
+			w.Write(buf.Bytes())
+		case "/pages.xml":
+			fmt.Fprintf(w, `<urlset><url><loc>%[1]s/about</loc></url><url><loc>%[1]s/posts/1</loc></url>
+<url><loc>%[1]s/private/x</loc></url></urlset>`, srvURL)
+		case "/", "/about", "/posts/1", "/posts/2", "/private/x":
+			w.Header().Set("Content-Type", "text/html")
+			fmt.Fprint(w, "page")
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+	srvURL = srv.URL
+
+	c, err := NewCrawler(nil)
+	assert.NoError(t, err)
+	urls, err := c.SitemapURLs(context.Background(), srv.URL)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{srv.URL + "/posts/1", srv.URL + "/posts/2", srv.URL + "/about", srv.URL + "/private/x"}, urls)
+
+	// sitemap中的url作为BFS第0层，robots.txt禁止的url不加入
+	c, err = NewCrawler(&Options{Sitemap: true})
+	assert.NoError(t, err)
+	urls, err = c.FoundURLsFromUrlBFS(context.Background(), srv.URL, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{srv.URL + "/", srv.URL + "/posts/1", srv.URL + "/posts/2", srv.URL + "/about"}, urls)
+}
+
+func TestCrawler_SitemapURLsDefault(t *testing.T) {
+	_, srv := newTestSite(t, map[string]string{
+		"/sitemap.xml": `<urlset><url><loc>/relative</loc></url></urlset>`,
+	}, 0)
+	c, err := NewCrawler(nil)
+	assert.NoError(t, err)
+	urls, err := c.SitemapURLs(context.Background(), srv.URL+"/blog/")
+	assert.NoError(t, err)
+	assert.Empty(t, urls)
+
+	// 没有sitemap时返回空
+	_, srv = newTestSite(t, map[string]string{}, 0)
+	urls, err = c.SitemapURLs(context.Background(), srv.URL)
+	assert.NoError(t, err)
+	assert.Empty(t, urls)
+}
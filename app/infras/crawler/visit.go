@@ -0,0 +1,79 @@
+package crawler
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/hold7techs/go-shim/shim"
+	"github.com/lupguo/copilot_develop/app/domain/entity"
+	log "github.com/sirupsen/logrus"
+)
+
+// crawlPage BFS中请求页面：设置Visits时，Revisit间隔内访问过的页面不再请求，其余页面带上ETag/Last-Modified条件请求，
+// 304或内容hash未变化时标记Unchanged，并沿用记录的url继续BFS；访问结果写回Visits
+func (c *Crawler) crawlPage(ctx context.Context, pageUrl string, rule *pageRule) (*Page, error) {
+	if c.opts.Visits == nil {
+		return c.fetchPage(ctx, pageUrl, rule)
+	}
+
+	visit, err := c.opts.Visits.SelCrawlVisit(ctx, pageUrl)
+	if err != nil {
+		log.Warnf("crawler sel visit of page[%s] got err: %s", pageUrl, err)
+		visit = nil
+	}
+	if visit != nil && c.opts.Revisit > 0 {
+		visited, err := time.ParseInLocation(shim.StdDateTimeLayout, visit.VisitedAt, time.Local)
+		if err == nil && time.Since(visited) < c.opts.Revisit {
+			// 内容未知时仍需检查robots.txt，避免断点续爬时绕过新的限制
+			if err = c.checkRobots(ctx, pageUrl); err != nil {
+				return nil, err
+			}
+			return &Page{Url: pageUrl, Urls: filterURLs(visit.LinkList(), rule), Unchanged: true}, nil
+		}
+	}
+
+	resp, err := c.fetch(ctx, pageUrl, visit)
+	if err != nil {
+		return nil, err
+	}
+	if resp.notModified {
+		page := &Page{Url: pageUrl, Urls: filterURLs(visit.LinkList(), rule), Unchanged: true}
+		visit.StatusCode = http.StatusNotModified
+		if resp.etag != "" {
+			visit.Etag = resp.etag
+		}
+		if resp.lastModified != "" {
+			visit.LastModified = resp.lastModified
+		}
+		c.saveVisit(ctx, visit)
+		return page, nil
+	}
+
+	page, links, err := resp.page(rule)
+	if err != nil {
+		return nil, err
+	}
+	hash := fmt.Sprintf("%x", sha256.Sum256(resp.body))
+	page.Unchanged = visit != nil && visit.ContentHash == hash
+	c.saveVisit(ctx, &entity.CrawlVisit{
+		Url:          pageUrl,
+		StatusCode:   http.StatusOK,
+		Etag:         resp.etag,
+		LastModified: resp.lastModified,
+		ContentHash:  hash,
+		Links:        strings.Join(links, "\n"),
+	})
+	return page, nil
+}
+
+// saveVisit 记录访问时间并写回Visits，失败只影响下次增量爬取
+func (c *Crawler) saveVisit(ctx context.Context, visit *entity.CrawlVisit) {
+	visit.VisitedAt = time.Now().Format(shim.StdDateTimeLayout)
+	if err := c.opts.Visits.ReplaceCrawlVisit(ctx, visit); err != nil {
+		log.Warnf("crawler save visit of page[%s] got err: %s", visit.Url, err)
+	}
+}
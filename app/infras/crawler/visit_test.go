@@ -0,0 +1,109 @@
+package crawler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/lupguo/copilot_develop/app/domain/entity"
+	"github.com/stretchr/testify/assert"
+)
+
+// memVisits 内存中的访问记录
+type memVisits struct {
+	mu     sync.Mutex
+	visits map[string]entity.CrawlVisit
+}
+
+func (m *memVisits) SelCrawlVisit(ctx context.Context, url string) (*entity.CrawlVisit, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	visit, ok := m.visits[url]
+	if !ok {
+		return nil, nil
+	}
+	return &visit, nil
+}
+
+func (m *memVisits) ReplaceCrawlVisit(ctx context.Context, visit *entity.CrawlVisit) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.visits[visit.Url] = *visit
+	return nil
+}
+
+func TestCrawler_IncrementalVisits(t *testing.T) {
+	var full, notModified int32
+	version := "v1"
+	var versionMu sync.Mutex
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		versionMu.Lock()
+		etag := fmt.Sprintf(`"%s%s"`, r.URL.Path, version)
+		versionMu.Unlock()
+		if r.URL.Path == "/robots.txt" {
+			http.NotFound(w, r)
+			return
+		}
+		if r.Header.Get("If-None-Match") == etag {
+			atomic.AddInt32(&notModified, 1)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		atomic.AddInt32(&full, 1)
+		w.Header().Set("ETag", etag)
+		w.Header().Set("Content-Type", "text/html")
+		switch r.URL.Path {
+		case "/":
+			fmt.Fprint(w, `<a href="/a">a</a>`)
+		case "/a":
+			fmt.Fprintf(w, `<p>%s</p><a href="/b">b</a>`, etag)
+		default:
+			fmt.Fprint(w, "leaf")
+		}
+	}))
+	defer srv.Close()
+
+	visits := &memVisits{visits: make(map[string]entity.CrawlVisit)}
+	crawl := func(revisit time.Duration) []string {
+		var changed []string
+		c, err := NewCrawler(&Options{
+			Seeds:    []string{srv.URL},
+			MaxDepth: 2,
+			Visits:   visits,
+			Revisit:  revisit,
+			OnPage: func(ctx context.Context, page *Page) {
+				changed = append(changed, page.Url)
+			},
+		})
+		assert.NoError(t, err)
+		assert.NoError(t, c.Start(context.Background()))
+		return changed
+	}
+
+	// 首次全量爬取，记录ETag及页面内的url
+	assert.Equal(t, []string{srv.URL + "/", srv.URL + "/a", srv.URL + "/b"}, crawl(0))
+	assert.Equal(t, int32(3), atomic.LoadInt32(&full))
+	visit, _ := visits.SelCrawlVisit(context.Background(), srv.URL+"/")
+	assert.Equal(t, `"/v1"`, visit.Etag)
+	assert.Equal(t, []string{srv.URL + "/a"}, visit.LinkList())
+
+	// 未变化的页面返回304，沿用记录的url继续爬取，不回调OnPage
+	assert.Empty(t, crawl(0))
+	assert.Equal(t, int32(3), atomic.LoadInt32(&notModified))
+
+	// ETag变化后重新请求，内容hash未变化的页面仍视为未变化
+	versionMu.Lock()
+	version = "v2"
+	versionMu.Unlock()
+	assert.Equal(t, []string{srv.URL + "/a"}, crawl(0))
+
+	// Revisit间隔内不再请求
+	requests := atomic.LoadInt32(&full) + atomic.LoadInt32(&notModified)
+	assert.Empty(t, crawl(time.Hour))
+	assert.Equal(t, requests, atomic.LoadInt32(&full)+atomic.LoadInt32(&notModified))
+}
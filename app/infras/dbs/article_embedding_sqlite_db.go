@@ -0,0 +1,17 @@
+package dbs
+
+import (
+	"context"
+
+	"github.com/lupguo/copilot_develop/app/domain/entity"
+)
+
+// SelArticleEmbedding 按文章路径查询文章的embedding缓存
+func (infra *BlogSummarySqliteInfra) SelArticleEmbedding(ctx context.Context, path string) (*entity.ArticleEmbedding, error) {
+	return selRecord[entity.ArticleEmbedding](infra.db, "SelArticleEmbedding", "path=?", path)
+}
+
+// ReplaceArticleEmbedding 新增或更新文章的embedding缓存
+func (infra *BlogSummarySqliteInfra) ReplaceArticleEmbedding(ctx context.Context, embedding *entity.ArticleEmbedding) error {
+	return replaceRecord(infra.db, "ReplaceArticleEmbedding", embedding, "path=?", embedding.Path)
+}
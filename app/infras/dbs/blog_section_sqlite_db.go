@@ -0,0 +1,81 @@
+package dbs
+
+import (
+	"context"
+	"path/filepath"
+	"time"
+	"unicode/utf8"
+
+	"github.com/hold7techs/go-shim/shim"
+	"github.com/lupguo/copilot_develop/app/domain/entity"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+// SelBlogMDRecordsByDir 查询目录下(含子目录)的文章记录，按前缀比较(sqlite的substr按字符计数)避免路径中的%、_被当作通配符
+func (infra *BlogSummarySqliteInfra) SelBlogMDRecordsByDir(ctx context.Context, dir string) ([]*entity.BlogArticle, error) {
+	prefix := filepath.Clean(dir) + string(filepath.Separator)
+	var records []*entity.BlogArticle
+	err := infra.db.Debug().
+		Where("substr(path, 1, ?)=?", utf8.RuneCountInString(prefix), prefix).
+		Order("path").
+		Find(&records).Error
+	if err != nil {
+		return nil, errors.Wrap(err, "db sql[SelBlogMDRecordsByDir] got err")
+	}
+
+	return records, nil
+}
+
+// SelBlogSection 查询栏目摘要记录
+func (infra *BlogSummarySqliteInfra) SelBlogSection(ctx context.Context, path string) (*entity.BlogSection, error) {
+	var section entity.BlogSection
+	err := infra.db.Debug().
+		First(&section, "path=?", path).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, errors.Wrap(err, "db sql[SelBlogSection] got err")
+	}
+
+	return &section, nil
+}
+
+// SelBlogSectionsByDir 查询目录下(含子目录)的栏目摘要记录
+func (infra *BlogSummarySqliteInfra) SelBlogSectionsByDir(ctx context.Context, dir string) ([]*entity.BlogSection, error) {
+	prefix := filepath.Clean(dir) + string(filepath.Separator)
+	var sections []*entity.BlogSection
+	err := infra.db.Debug().
+		Where("substr(path, 1, ?)=?", utf8.RuneCountInString(prefix), prefix).
+		Order("path").
+		Find(&sections).Error
+	if err != nil {
+		return nil, errors.Wrap(err, "db sql[SelBlogSectionsByDir] got err")
+	}
+
+	return sections, nil
+}
+
+// ReplaceBlogSection 新增或更新栏目摘要记录
+func (infra *BlogSummarySqliteInfra) ReplaceBlogSection(ctx context.Context, section *entity.BlogSection) error {
+	record, err := infra.SelBlogSection(ctx, section.Path)
+	if err != nil {
+		return errors.Wrap(err, "replace blog section, sel got err")
+	}
+
+	now := time.Now().Format(shim.StdDateTimeLayout)
+	section.UpdatedAt = now
+	if record == nil {
+		section.CreatedAt = now
+		err = infra.db.Debug().Create(section).Error
+	} else {
+		section.ID, section.CreatedAt = record.ID, record.CreatedAt
+		err = infra.db.Debug().Save(section).Error
+	}
+	if err != nil {
+		return errors.Wrap(err, "db sql[ReplaceBlogSection] got err")
+	}
+
+	return nil
+}
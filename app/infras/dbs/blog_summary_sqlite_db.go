@@ -2,6 +2,7 @@ package dbs
 
 import (
 	"context"
+	"strings"
 	"time"
 
 	"github.com/hold7techs/go-shim/shim"
@@ -16,7 +17,12 @@ type BlogSummarySqliteInfra struct {
 }
 
 func NewBlogSummarySqliteInfra(sqlDBFile string) (*BlogSummarySqliteInfra, error) {
-	db, err := gorm.Open(sqlite.Open(sqlDBFile), &gorm.Config{})
+	// 摘要任务的worker会并发写库：设置busy超时，事务开始即加写锁，避免先读后写的事务互相等待导致database is locked
+	dsn := sqlDBFile
+	if !strings.Contains(dsn, "?") {
+		dsn += "?_busy_timeout=5000&_txlock=immediate"
+	}
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{})
 	if err != nil {
 		return nil, errors.Wrapf(err, "sqlite.Open(%s) got err", sqlDBFile)
 	}
@@ -38,11 +44,243 @@ func (infra *BlogSummarySqliteInfra) SelBlogMDRecord(ctx context.Context, path s
 	return &record, nil
 }
 
+// SelAllBlogMDRecords 查询所有BlogMD记录
+func (infra *BlogSummarySqliteInfra) SelAllBlogMDRecords(ctx context.Context) ([]*entity.BlogArticle, error) {
+	var records []*entity.BlogArticle
+	if err := infra.db.Order("id").Find(&records).Error; err != nil {
+		return nil, errors.Wrap(err, "db sql[SelAllBlogMDRecords] got err")
+	}
+
+	return records, nil
+}
+
+// SelBlogMDRecordByID 通过ID查询BlogMD记录
+func (infra *BlogSummarySqliteInfra) SelBlogMDRecordByID(ctx context.Context, id uint) (*entity.BlogArticle, error) {
+	var record entity.BlogArticle
+	err := infra.db.Debug().
+		First(&record, "id=?", id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, errors.Wrap(err, "db sql[SelBlogMDRecordByID] got err")
+	}
+
+	return &record, nil
+}
+
+// SelBlogMDRecords 按条件分页查询BlogMD记录
+func (infra *BlogSummarySqliteInfra) SelBlogMDRecords(ctx context.Context, query *entity.ArticleQuery) (records []*entity.BlogArticle, total int64, err error) {
+	tx := infra.db.Debug().Model(&entity.BlogArticle{})
+	if query.Keyword != "" {
+		like := "%" + escapeLike(query.Keyword) + "%"
+		tx = tx.Where(`title LIKE ? ESCAPE '\' OR keywords LIKE ? ESCAPE '\' OR summary LIKE ? ESCAPE '\' OR description LIKE ? ESCAPE '\'`,
+			like, like, like, like)
+	}
+	if query.Category != "" {
+		tx = tx.Where(`categories LIKE ? ESCAPE '\'`, "%"+escapeLike(shim.ToJsonString(strings.ToLower(query.Category), false))+"%")
+	}
+	if query.Tag != "" {
+		tx = tx.Where(`tags LIKE ? ESCAPE '\'`, "%"+escapeLike(shim.ToJsonString(strings.ToLower(query.Tag), false))+"%")
+	}
+	if query.Draft != nil {
+		tx = tx.Where("draft=?", *query.Draft)
+	}
+	if query.MissingSummary {
+		tx = tx.Where("summary IS NULL OR summary=''")
+	}
+	if query.StaleSummary {
+		tx = tx.Where("summary<>'' AND summary_hash<>'' AND summary_hash<>content_hash")
+	}
+	if query.PendingReview {
+		tx = tx.Where("path IN (?)", infra.db.Model(&entity.BlogSummaryHistory{}).
+			Select("path").
+			Where("status=?", entity.SummaryPending))
+	}
+
+	if err = tx.Count(&total).Error; err != nil {
+		return nil, 0, errors.Wrap(err, "db sql[SelBlogMDRecords] count got err")
+	}
+	if query.Size > 0 {
+		tx = tx.Offset((query.Page - 1) * query.Size).Limit(query.Size)
+	}
+	if err = tx.Order("date DESC, id DESC").Find(&records).Error; err != nil {
+		return nil, 0, errors.Wrap(err, "db sql[SelBlogMDRecords] got err")
+	}
+
+	return records, total, nil
+}
+
+// AddSummaryHistory 新增摘要历史
+func (infra *BlogSummarySqliteInfra) AddSummaryHistory(ctx context.Context, history *entity.BlogSummaryHistory) error {
+	history.CreatedAt = time.Now().Format(shim.StdDateTimeLayout)
+	history.UpdatedAt = history.CreatedAt
+	if history.Status == "" {
+		history.Status = entity.SummaryApproved
+	}
+	if err := infra.db.Debug().Create(history).Error; err != nil {
+		return errors.Wrap(err, "db sql[AddSummaryHistory] got err")
+	}
+	return nil
+}
+
+// SelSummaryHistory 通过ID查询摘要历史
+func (infra *BlogSummarySqliteInfra) SelSummaryHistory(ctx context.Context, id uint) (*entity.BlogSummaryHistory, error) {
+	var history entity.BlogSummaryHistory
+	err := infra.db.Debug().
+		First(&history, "id=?", id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, errors.Wrap(err, "db sql[SelSummaryHistory] got err")
+	}
+
+	return &history, nil
+}
+
+// SelSummaryHistoriesByStatus 按审核状态查询摘要历史
+func (infra *BlogSummarySqliteInfra) SelSummaryHistoriesByStatus(ctx context.Context, status entity.SummaryStatus) ([]*entity.BlogSummaryHistory, error) {
+	var histories []*entity.BlogSummaryHistory
+	err := infra.db.Debug().
+		Where("status=?", status).
+		Order("id ASC").
+		Find(&histories).Error
+	if err != nil {
+		return nil, errors.Wrap(err, "db sql[SelSummaryHistoriesByStatus] got err")
+	}
+	return histories, nil
+}
+
+// UpdateSummaryHistory 更新摘要历史的内容及审核状态
+func (infra *BlogSummarySqliteInfra) UpdateSummaryHistory(ctx context.Context, history *entity.BlogSummaryHistory) error {
+	history.UpdatedAt = time.Now().Format(shim.StdDateTimeLayout)
+	err := infra.db.Debug().
+		Model(&entity.BlogSummaryHistory{}).
+		Where("id=?", history.ID).
+		Updates(map[string]interface{}{
+			"keywords":    history.Keywords,
+			"summary":     history.Summary,
+			"description": history.Description,
+			"status":      history.Status,
+			"updated_at":  history.UpdatedAt,
+		}).Error
+	if err != nil {
+		return errors.Wrap(err, "db sql[UpdateSummaryHistory] got err")
+	}
+	return nil
+}
+
+// SelSummaryHistories 查询文章的摘要历史
+func (infra *BlogSummarySqliteInfra) SelSummaryHistories(ctx context.Context, path string) ([]*entity.BlogSummaryHistory, error) {
+	var histories []*entity.BlogSummaryHistory
+	err := infra.db.Debug().
+		Where("path=?", path).
+		Order("id DESC").
+		Find(&histories).Error
+	if err != nil {
+		return nil, errors.Wrap(err, "db sql[SelSummaryHistories] got err")
+	}
+	return histories, nil
+}
+
+// SelBlogMDRecordByShortMark 通过短标记查询BlogMD记录
+func (infra *BlogSummarySqliteInfra) SelBlogMDRecordByShortMark(ctx context.Context, shortMark string) (*entity.BlogArticle, error) {
+	var record entity.BlogArticle
+	err := infra.db.Debug().
+		First(&record, "short_mark=?", shortMark).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, errors.Wrap(err, "db sql[SelBlogMDRecordByShortMark] got err")
+	}
+
+	return &record, nil
+}
+
+// IsShortMarkTaken 短标记是否已被其他文章使用
+func (infra *BlogSummarySqliteInfra) IsShortMarkTaken(ctx context.Context, shortMark, excludePath string) (bool, error) {
+	var count int64
+	err := infra.db.Debug().
+		Model(&entity.BlogArticle{}).
+		Where("short_mark=? AND path<>?", shortMark, excludePath).
+		Count(&count).Error
+	if err != nil {
+		return false, errors.Wrap(err, "db sql[IsShortMarkTaken] got err")
+	}
+
+	return count > 0, nil
+}
+
 // InitBlogSummaryDB 初始化
 func (infra *BlogSummarySqliteInfra) InitBlogSummaryDB(ctx context.Context) error {
-	if err := infra.db.AutoMigrate(&entity.BlogArticle{}); err != nil {
+	if err := migrateTables(infra.db,
+		&entity.BlogArticle{},
+		&entity.BlogTranslation{},
+		&entity.TranslationCache{},
+		&entity.BlogSummaryHistory{},
+		&entity.SummaryJob{},
+		&entity.SummaryJobItem{},
+		&entity.BlogSection{},
+		&entity.WebClip{},
+		&entity.CrawlVisit{},
+		&entity.Feed{},
+		&entity.FeedEntry{},
+		&entity.LinkCheck{},
+		&entity.ArticleEmbedding{},
+		&entity.ImageAltText{},
+		&entity.CoverImage{},
+		&entity.OGCardImage{},
+		&entity.HostedImage{},
+	); err != nil {
 		return errors.Wrap(err, "db sql[InitBlogSummaryDB] got err")
 	}
+
+	// 短标记用于短链解析
+	err := infra.db.Exec("CREATE INDEX IF NOT EXISTS blog_articles_short_mark_index ON blog_articles (short_mark)").Error
+	if err != nil {
+		return errors.Wrap(err, "db sql[InitBlogSummaryDB] create short mark index got err")
+	}
+
+	// worker按任务、状态领取文件
+	err = infra.db.Exec("CREATE INDEX IF NOT EXISTS job_items_job_id_status_index ON job_items (job_id, status)").Error
+	if err != nil {
+		return errors.Wrap(err, "db sql[InitBlogSummaryDB] create job items index got err")
+	}
+	return nil
+}
+
+// escapeLike 转义LIKE模式中的通配符，配合 ESCAPE '\' 使用
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
+
+// migrateTables 表不存在时创建，存在时仅补齐缺失的列
+// (手工建表的DDL带换行，gorm sqlite的AutoMigrate无法解析，因此不直接使用AutoMigrate)
+func migrateTables(db *gorm.DB, models ...interface{}) error {
+	migrator := db.Migrator()
+	for _, model := range models {
+		if !migrator.HasTable(model) {
+			if err := migrator.CreateTable(model); err != nil {
+				return errors.Wrapf(err, "create table for %T got err", model)
+			}
+			continue
+		}
+
+		stmt := &gorm.Statement{DB: db}
+		if err := stmt.Parse(model); err != nil {
+			return errors.Wrapf(err, "parse schema for %T got err", model)
+		}
+		for _, field := range stmt.Schema.Fields {
+			if field.DBName == "" || migrator.HasColumn(model, field.DBName) {
+				continue
+			}
+			if err := migrator.AddColumn(model, field.Name); err != nil {
+				return errors.Wrapf(err, "add column %s for %T got err", field.DBName, model)
+			}
+		}
+	}
 	return nil
 }
 
@@ -74,6 +312,11 @@ func (infra *BlogSummarySqliteInfra) AddBlogMDRecord(ctx context.Context, md *en
 			Summary:     header.Summary,
 			Description: header.Description,
 			Aliases:     shim.ToJsonString(header.Aliases, false),
+			Lang:        md.Lang,
+			SEOTitle:    header.SEOTitle,
+			Slug:        header.Slug,
+			ContentHash: md.ContentHash(),
+			SummaryHash: md.SummaryHash,
 		}).Error
 	if err != nil {
 		return errors.Wrap(err, "db sql[AddBlogMDRecord] got err")
@@ -102,6 +345,11 @@ func (infra *BlogSummarySqliteInfra) UpdateBlogMDRecord(ctx context.Context, md
 			Weight:      header.Weight,
 			WordCount:   header.WordCounts,
 			Aliases:     shim.ToJsonString(header.Aliases, false),
+			Lang:        md.Lang,
+			SEOTitle:    header.SEOTitle,
+			Slug:        header.Slug,
+			ContentHash: md.ContentHash(),
+			SummaryHash: md.SummaryHash,
 		}).Error
 	if err != nil {
 		return errors.Wrap(err, "db sql[AddBlogMDRecord] got err")
@@ -110,6 +358,30 @@ func (infra *BlogSummarySqliteInfra) UpdateBlogMDRecord(ctx context.Context, md
 	return nil
 }
 
+// UpdateBlogMDContentHash 更新文章最近一次同步时的正文hash
+func (infra *BlogSummarySqliteInfra) UpdateBlogMDContentHash(ctx context.Context, path, contentHash string) error {
+	err := infra.db.Debug().
+		Model(&entity.BlogArticle{}).
+		Where("path=?", path).
+		Update("content_hash", contentHash).Error
+	if err != nil {
+		return errors.Wrap(err, "db sql[UpdateBlogMDContentHash] got err")
+	}
+	return nil
+}
+
+// UpdateBlogMDWeight 更新文章权重及被站内链接的次数
+func (infra *BlogSummarySqliteInfra) UpdateBlogMDWeight(ctx context.Context, path string, weight, inboundLinks int) error {
+	err := infra.db.
+		Model(&entity.BlogArticle{}).
+		Where("path=?", path).
+		Updates(map[string]any{"weight": weight, "inbound_links": inboundLinks}).Error
+	if err != nil {
+		return errors.Wrap(err, "db sql[UpdateBlogMDWeight] got err")
+	}
+	return nil
+}
+
 // ReplaceBlogMDRecord  当文档不存在时候新增，存在时候更新md内容
 func (infra *BlogSummarySqliteInfra) ReplaceBlogMDRecord(ctx context.Context, md *entity.BlogMD) error {
 	// 查询是否存在
@@ -124,3 +396,99 @@ func (infra *BlogSummarySqliteInfra) ReplaceBlogMDRecord(ctx context.Context, md
 		return infra.UpdateBlogMDRecord(ctx, md)
 	}
 }
+
+// SelTranslationRecord 查询翻译记录
+func (infra *BlogSummarySqliteInfra) SelTranslationRecord(ctx context.Context, sourcePath, lang string) (*entity.BlogTranslation, error) {
+	var record entity.BlogTranslation
+	err := infra.db.Debug().
+		First(&record, "source_path=? AND lang=?", sourcePath, lang).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, errors.Wrap(err, "db sql[SelTranslationRecord] got err")
+	}
+
+	return &record, nil
+}
+
+// ReplaceTranslationRecord 新增或更新翻译记录
+func (infra *BlogSummarySqliteInfra) ReplaceTranslationRecord(ctx context.Context, translation *entity.BlogTranslation) error {
+	record, err := infra.SelTranslationRecord(ctx, translation.SourcePath, translation.Lang)
+	if err != nil {
+		return errors.Wrap(err, "replace translation record, sel got err")
+	}
+
+	now := time.Now().Format(shim.StdDateTimeLayout)
+	translation.UpdatedAt = now
+	if record == nil {
+		translation.CreatedAt = now
+		err = infra.db.Debug().Create(translation).Error
+	} else {
+		translation.ID, translation.CreatedAt = record.ID, record.CreatedAt
+		err = infra.db.Debug().Save(translation).Error
+	}
+	if err != nil {
+		return errors.Wrap(err, "db sql[ReplaceTranslationRecord] got err")
+	}
+
+	return nil
+}
+
+// MarkTranslationsStale 原文hash变化的翻译记录标记为过期
+func (infra *BlogSummarySqliteInfra) MarkTranslationsStale(ctx context.Context, sourcePath, sourceHash string) error {
+	err := infra.db.Debug().
+		Model(&entity.BlogTranslation{}).
+		Where("source_path=? AND source_hash<>? AND stale=?", sourcePath, sourceHash, false).
+		Updates(map[string]interface{}{
+			"stale":      true,
+			"updated_at": time.Now().Format(shim.StdDateTimeLayout),
+		}).Error
+	if err != nil {
+		return errors.Wrap(err, "db sql[MarkTranslationsStale] got err")
+	}
+
+	return nil
+}
+
+// IsSlugTaken slug是否已被其他文章使用
+func (infra *BlogSummarySqliteInfra) IsSlugTaken(ctx context.Context, slug, excludePath string) (bool, error) {
+	var count int64
+	err := infra.db.Debug().
+		Model(&entity.BlogArticle{}).
+		Where("slug=? AND path<>?", slug, excludePath).
+		Count(&count).Error
+	if err != nil {
+		return false, errors.Wrap(err, "db sql[IsSlugTaken] got err")
+	}
+
+	return count > 0, nil
+}
+
+// IsTitleTaken 标题是否与其他文章的标题或SEO标题重复
+func (infra *BlogSummarySqliteInfra) IsTitleTaken(ctx context.Context, title, excludePath string) (bool, error) {
+	var count int64
+	err := infra.db.Debug().
+		Model(&entity.BlogArticle{}).
+		Where("(title=? OR seo_title=?) AND path<>?", title, title, excludePath).
+		Count(&count).Error
+	if err != nil {
+		return false, errors.Wrap(err, "db sql[IsTitleTaken] got err")
+	}
+
+	return count > 0, nil
+}
+
+// IsAliasTaken 别名是否已被其他文章使用(aliases按json数组存储)
+func (infra *BlogSummarySqliteInfra) IsAliasTaken(ctx context.Context, alias, excludePath string) (bool, error) {
+	var count int64
+	err := infra.db.Debug().
+		Model(&entity.BlogArticle{}).
+		Where(`aliases LIKE ? ESCAPE '\' AND path<>?`, "%"+escapeLike(shim.ToJsonString(alias, false))+"%", excludePath).
+		Count(&count).Error
+	if err != nil {
+		return false, errors.Wrap(err, "db sql[IsAliasTaken] got err")
+	}
+
+	return count > 0, nil
+}
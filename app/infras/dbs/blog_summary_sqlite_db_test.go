@@ -0,0 +1,87 @@
+package dbs
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/lupguo/copilot_develop/app/domain/entity"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBlogSummarySqliteInfra_IsAliasTaken(t *testing.T) {
+	ctx := context.Background()
+	infra, err := NewBlogSummarySqliteInfra(filepath.Join(t.TempDir(), "blog_summary.db"))
+	assert.NoError(t, err)
+	assert.NoError(t, infra.InitBlogSummaryDB(ctx))
+	assert.NoError(t, infra.ReplaceBlogMDRecord(ctx, &entity.BlogMD{
+		Filepath: "/blog/a.md",
+		MDHeader: &entity.YamlHeader{Title: "a", Aliases: []string{"/s/ab_d%x/"}},
+	}))
+
+	for alias, want := range map[string]bool{
+		"/s/ab_d%x/": true,
+		"/s/abcd%x/": false, // _ 不作为通配符
+		"/s/ab_dxx/": false, // % 不作为通配符
+		"/s/ab_d%":   false,
+	} {
+		taken, err := infra.IsAliasTaken(ctx, alias, "/blog/b.md")
+		assert.NoError(t, err)
+		assert.Equal(t, want, taken, alias)
+	}
+
+	// 文章自身的别名不算冲突
+	taken, err := infra.IsAliasTaken(ctx, "/s/ab_d%x/", "/blog/a.md")
+	assert.NoError(t, err)
+	assert.False(t, taken)
+}
+
+func TestBlogSummarySqliteInfra_SelBlogMDRecords(t *testing.T) {
+	ctx := context.Background()
+	infra, err := NewBlogSummarySqliteInfra(filepath.Join(t.TempDir(), "blog_summary.db"))
+	assert.NoError(t, err)
+	assert.NoError(t, infra.InitBlogSummaryDB(ctx))
+	for path, header := range map[string]*entity.YamlHeader{
+		"/blog/a.md": {Title: "100% Go", Tags: []string{"c_go"}},
+		"/blog/b.md": {Title: "100 Go", Tags: []string{"cgo"}},
+	} {
+		assert.NoError(t, infra.ReplaceBlogMDRecord(ctx, &entity.BlogMD{Filepath: path, MDHeader: header}))
+	}
+
+	for _, query := range []*entity.ArticleQuery{
+		{Keyword: "0%"}, // % 不作为通配符
+		{Tag: "c_go"},   // _ 不作为通配符
+		{Keyword: "100%"},
+	} {
+		records, total, err := infra.SelBlogMDRecords(ctx, query)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), total, query)
+		if assert.Len(t, records, 1) {
+			assert.Equal(t, "/blog/a.md", records[0].Path)
+		}
+	}
+}
+
+func TestBlogSummarySqliteInfra_ReplaceLinkCheck(t *testing.T) {
+	ctx := context.Background()
+	infra, err := NewBlogSummarySqliteInfra(filepath.Join(t.TempDir(), "blog_summary.db"))
+	assert.NoError(t, err)
+	assert.NoError(t, infra.InitBlogSummaryDB(ctx))
+
+	record, err := infra.SelLinkCheck(ctx, "https://go.dev")
+	assert.NoError(t, err)
+	assert.Nil(t, record)
+
+	// 新增后再次写入同一url，沿用原记录的ID和创建时间
+	assert.NoError(t, infra.ReplaceLinkCheck(ctx, &entity.LinkCheck{Url: "https://go.dev", StatusCode: 500}))
+	first, err := infra.SelLinkCheck(ctx, "https://go.dev")
+	assert.NoError(t, err)
+	check := &entity.LinkCheck{Url: "https://go.dev", StatusCode: 200}
+	assert.NoError(t, infra.ReplaceLinkCheck(ctx, check))
+	assert.Equal(t, first.ID, check.ID)
+	assert.Equal(t, first.CreatedAt, check.CreatedAt)
+
+	record, err = infra.SelLinkCheck(ctx, "https://go.dev")
+	assert.NoError(t, err)
+	assert.Equal(t, 200, record.StatusCode)
+}
@@ -0,0 +1,20 @@
+package dbs
+
+import (
+	"context"
+
+	"github.com/lupguo/copilot_develop/app/domain/entity"
+	"github.com/lupguo/copilot_develop/app/domain/repos"
+)
+
+var _ repos.IReposSQLiteCoverImage = (*BlogSummarySqliteInfra)(nil)
+
+// SelCoverImage 按文章路径查询文章封面图的生成记录
+func (infra *BlogSummarySqliteInfra) SelCoverImage(ctx context.Context, path string) (*entity.CoverImage, error) {
+	return selRecord[entity.CoverImage](infra.db, "SelCoverImage", "path=?", path)
+}
+
+// ReplaceCoverImage 新增或更新文章封面图的生成记录
+func (infra *BlogSummarySqliteInfra) ReplaceCoverImage(ctx context.Context, cover *entity.CoverImage) error {
+	return replaceRecord(infra.db, "ReplaceCoverImage", cover, "path=?", cover.Path)
+}
@@ -0,0 +1,51 @@
+package dbs
+
+import (
+	"context"
+	"time"
+
+	"github.com/hold7techs/go-shim/shim"
+	"github.com/lupguo/copilot_develop/app/domain/entity"
+	"github.com/lupguo/copilot_develop/app/domain/repos"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+var _ repos.IReposCrawlVisit = (*BlogSummarySqliteInfra)(nil)
+
+// SelCrawlVisit 按url查询爬虫访问记录
+func (infra *BlogSummarySqliteInfra) SelCrawlVisit(ctx context.Context, url string) (*entity.CrawlVisit, error) {
+	var visit entity.CrawlVisit
+	err := infra.db.First(&visit, "url=?", url).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, errors.Wrap(err, "db sql[SelCrawlVisit] got err")
+	}
+
+	return &visit, nil
+}
+
+// ReplaceCrawlVisit 新增或更新爬虫访问记录
+func (infra *BlogSummarySqliteInfra) ReplaceCrawlVisit(ctx context.Context, visit *entity.CrawlVisit) error {
+	record, err := infra.SelCrawlVisit(ctx, visit.Url)
+	if err != nil {
+		return errors.Wrap(err, "replace crawl visit, sel got err")
+	}
+
+	now := time.Now().Format(shim.StdDateTimeLayout)
+	visit.UpdatedAt = now
+	if record == nil {
+		visit.CreatedAt = now
+		err = infra.db.Create(visit).Error
+	} else {
+		visit.ID, visit.CreatedAt = record.ID, record.CreatedAt
+		err = infra.db.Save(visit).Error
+	}
+	if err != nil {
+		return errors.Wrap(err, "db sql[ReplaceCrawlVisit] got err")
+	}
+
+	return nil
+}
@@ -0,0 +1,89 @@
+package dbs
+
+import (
+	"context"
+	"time"
+
+	"github.com/hold7techs/go-shim/shim"
+	"github.com/lupguo/copilot_develop/app/domain/entity"
+	"github.com/lupguo/copilot_develop/app/domain/repos"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+var _ repos.IReposSQLiteFeed = (*BlogSummarySqliteInfra)(nil)
+
+// SelFeed 按订阅地址查询订阅源
+func (infra *BlogSummarySqliteInfra) SelFeed(ctx context.Context, url string) (*entity.Feed, error) {
+	var feed entity.Feed
+	err := infra.db.First(&feed, "url=?", url).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, errors.Wrap(err, "db sql[SelFeed] got err")
+	}
+
+	return &feed, nil
+}
+
+// ReplaceFeed 新增或更新订阅源
+func (infra *BlogSummarySqliteInfra) ReplaceFeed(ctx context.Context, feed *entity.Feed) error {
+	record, err := infra.SelFeed(ctx, feed.Url)
+	if err != nil {
+		return errors.Wrap(err, "replace feed, sel got err")
+	}
+
+	now := time.Now().Format(shim.StdDateTimeLayout)
+	feed.UpdatedAt = now
+	if record == nil {
+		feed.CreatedAt = now
+		err = infra.db.Create(feed).Error
+	} else {
+		feed.ID, feed.CreatedAt = record.ID, record.CreatedAt
+		err = infra.db.Save(feed).Error
+	}
+	if err != nil {
+		return errors.Wrap(err, "db sql[ReplaceFeed] got err")
+	}
+
+	return nil
+}
+
+// SelFeedEntry 按订阅地址及guid查询订阅文章
+func (infra *BlogSummarySqliteInfra) SelFeedEntry(ctx context.Context, feedUrl, guid string) (*entity.FeedEntry, error) {
+	var entry entity.FeedEntry
+	err := infra.db.First(&entry, "feed_url=? and guid=?", feedUrl, guid).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, errors.Wrap(err, "db sql[SelFeedEntry] got err")
+	}
+
+	return &entry, nil
+}
+
+// SelFeedEntriesByFilepath 查询写入同一文件的订阅文章
+func (infra *BlogSummarySqliteInfra) SelFeedEntriesByFilepath(ctx context.Context, path string) ([]*entity.FeedEntry, error) {
+	var entries []*entity.FeedEntry
+	err := infra.db.Where("filepath=?", path).
+		Order("id").
+		Find(&entries).Error
+	if err != nil {
+		return nil, errors.Wrap(err, "db sql[SelFeedEntriesByFilepath] got err")
+	}
+
+	return entries, nil
+}
+
+// AddFeedEntry 新增订阅文章记录
+func (infra *BlogSummarySqliteInfra) AddFeedEntry(ctx context.Context, entry *entity.FeedEntry) error {
+	now := time.Now().Format(shim.StdDateTimeLayout)
+	entry.CreatedAt, entry.UpdatedAt = now, now
+	if err := infra.db.Create(entry).Error; err != nil {
+		return errors.Wrap(err, "db sql[AddFeedEntry] got err")
+	}
+
+	return nil
+}
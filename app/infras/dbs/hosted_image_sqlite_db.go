@@ -0,0 +1,30 @@
+package dbs
+
+import (
+	"context"
+
+	"github.com/lupguo/copilot_develop/app/domain/entity"
+	"github.com/lupguo/copilot_develop/app/domain/repos"
+	"github.com/pkg/errors"
+)
+
+var _ repos.IReposSQLiteHostedImage = (*BlogSummarySqliteInfra)(nil)
+
+// SelHostedImage 按内容hash查询图床中的图片
+func (infra *BlogSummarySqliteInfra) SelHostedImage(ctx context.Context, hash string) (*entity.HostedImage, error) {
+	return selRecord[entity.HostedImage](infra.db, "SelHostedImage", "hash=?", hash)
+}
+
+// ReplaceHostedImage 新增或更新图床中的图片
+func (infra *BlogSummarySqliteInfra) ReplaceHostedImage(ctx context.Context, img *entity.HostedImage) error {
+	return replaceRecord(infra.db, "ReplaceHostedImage", img, "hash=?", img.Hash)
+}
+
+// ListHostedImages 最近上传的图片，按上传时间倒序
+func (infra *BlogSummarySqliteInfra) ListHostedImages(ctx context.Context, limit int) ([]*entity.HostedImage, error) {
+	var images []*entity.HostedImage
+	if err := infra.db.Order("id DESC").Limit(limit).Find(&images).Error; err != nil {
+		return nil, errors.Wrap(err, "db sql[ListHostedImages] got err")
+	}
+	return images, nil
+}
@@ -0,0 +1,17 @@
+package dbs
+
+import (
+	"context"
+
+	"github.com/lupguo/copilot_develop/app/domain/entity"
+)
+
+// SelImageAltText 按文章路径、图片地址查询图片的alt文字生成记录
+func (infra *BlogSummarySqliteInfra) SelImageAltText(ctx context.Context, path, target string) (*entity.ImageAltText, error) {
+	return selRecord[entity.ImageAltText](infra.db, "SelImageAltText", "path=? and target=?", path, target)
+}
+
+// ReplaceImageAltText 新增或更新图片的alt文字生成记录
+func (infra *BlogSummarySqliteInfra) ReplaceImageAltText(ctx context.Context, alt *entity.ImageAltText) error {
+	return replaceRecord(infra.db, "ReplaceImageAltText", alt, "path=? and target=?", alt.Path, alt.Target)
+}
@@ -0,0 +1,20 @@
+package dbs
+
+import (
+	"context"
+
+	"github.com/lupguo/copilot_develop/app/domain/entity"
+	"github.com/lupguo/copilot_develop/app/domain/repos"
+)
+
+var _ repos.IReposSQLiteLinkCheck = (*BlogSummarySqliteInfra)(nil)
+
+// SelLinkCheck 按url查询站外链接的检查结果
+func (infra *BlogSummarySqliteInfra) SelLinkCheck(ctx context.Context, url string) (*entity.LinkCheck, error) {
+	return selRecord[entity.LinkCheck](infra.db, "SelLinkCheck", "url=?", url)
+}
+
+// ReplaceLinkCheck 新增或更新站外链接的检查结果
+func (infra *BlogSummarySqliteInfra) ReplaceLinkCheck(ctx context.Context, check *entity.LinkCheck) error {
+	return replaceRecord(infra.db, "ReplaceLinkCheck", check, "url=?", check.Url)
+}
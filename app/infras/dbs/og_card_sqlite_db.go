@@ -0,0 +1,20 @@
+package dbs
+
+import (
+	"context"
+
+	"github.com/lupguo/copilot_develop/app/domain/entity"
+	"github.com/lupguo/copilot_develop/app/domain/repos"
+)
+
+var _ repos.IReposSQLiteOGCard = (*BlogSummarySqliteInfra)(nil)
+
+// SelOGCard 按文章路径查询文章社交分享卡片的生成记录
+func (infra *BlogSummarySqliteInfra) SelOGCard(ctx context.Context, path string) (*entity.OGCardImage, error) {
+	return selRecord[entity.OGCardImage](infra.db, "SelOGCard", "path=?", path)
+}
+
+// ReplaceOGCard 新增或更新文章社交分享卡片的生成记录
+func (infra *BlogSummarySqliteInfra) ReplaceOGCard(ctx context.Context, card *entity.OGCardImage) error {
+	return replaceRecord(infra.db, "ReplaceOGCard", card, "path=?", card.Path)
+}
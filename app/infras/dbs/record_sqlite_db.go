@@ -0,0 +1,54 @@
+package dbs
+
+import (
+	"reflect"
+	"time"
+
+	"github.com/hold7techs/go-shim/shim"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+// selRecord 按唯一条件查询一条记录，不存在时返回nil，sqlName用于错误信息
+func selRecord[T any](db *gorm.DB, sqlName string, query string, args ...interface{}) (*T, error) {
+	var record T
+	err := db.Where(query, args...).First(&record).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, errors.Wrapf(err, "db sql[%s] got err", sqlName)
+	}
+
+	return &record, nil
+}
+
+// replaceRecord 按唯一条件新增或更新一条记录，更新时沿用已有记录的ID和创建时间，
+// record需包含ID、CreatedAt、UpdatedAt字段(时间为字符串格式)
+func replaceRecord[T any](db *gorm.DB, sqlName string, record *T, query string, args ...interface{}) error {
+	var existing struct {
+		ID        uint
+		CreatedAt string
+	}
+	err := db.Model(new(T)).Select("id", "created_at").Where(query, args...).Limit(1).Scan(&existing).Error
+	if err != nil {
+		return errors.Wrapf(err, "db sql[%s] sel got err", sqlName)
+	}
+
+	v := reflect.ValueOf(record).Elem()
+	now := time.Now().Format(shim.StdDateTimeLayout)
+	v.FieldByName("UpdatedAt").SetString(now)
+	if existing.ID == 0 {
+		v.FieldByName("CreatedAt").SetString(now)
+		err = db.Create(record).Error
+	} else {
+		v.FieldByName("ID").SetUint(uint64(existing.ID))
+		v.FieldByName("CreatedAt").SetString(existing.CreatedAt)
+		err = db.Save(record).Error
+	}
+	if err != nil {
+		return errors.Wrapf(err, "db sql[%s] got err", sqlName)
+	}
+
+	return nil
+}
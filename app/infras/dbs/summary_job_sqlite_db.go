@@ -0,0 +1,284 @@
+package dbs
+
+import (
+	"context"
+	"time"
+
+	"github.com/hold7techs/go-shim/shim"
+	"github.com/lupguo/copilot_develop/app/domain/entity"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+// AddJob 新增任务及文件明细
+func (infra *BlogSummarySqliteInfra) AddJob(ctx context.Context, job *entity.SummaryJob, paths []string) error {
+	now := time.Now().Format(shim.StdDateTimeLayout)
+	job.CreatedAt, job.UpdatedAt = now, now
+	job.Total = len(paths)
+	if job.Status == "" {
+		job.Status = entity.JobPending
+	}
+
+	err := infra.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(job).Error; err != nil {
+			return err
+		}
+		if len(paths) == 0 {
+			return nil
+		}
+
+		items := make([]*entity.SummaryJobItem, 0, len(paths))
+		for _, path := range paths {
+			items = append(items, &entity.SummaryJobItem{
+				CreatedAt: now,
+				UpdatedAt: now,
+				JobID:     job.ID,
+				Path:      path,
+				Status:    entity.JobItemPending,
+			})
+		}
+		return tx.CreateInBatches(items, 200).Error
+	})
+	if err != nil {
+		return errors.Wrap(err, "db sql[AddJob] got err")
+	}
+
+	return nil
+}
+
+// SelJob 查询任务
+func (infra *BlogSummarySqliteInfra) SelJob(ctx context.Context, id uint) (*entity.SummaryJob, error) {
+	var job entity.SummaryJob
+	err := infra.db.First(&job, "id=?", id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, errors.Wrap(err, "db sql[SelJob] got err")
+	}
+
+	return &job, nil
+}
+
+// SelJobs 查询最近的任务
+func (infra *BlogSummarySqliteInfra) SelJobs(ctx context.Context, limit int) ([]*entity.SummaryJob, error) {
+	var jobs []*entity.SummaryJob
+	if err := infra.db.Order("id DESC").Limit(limit).Find(&jobs).Error; err != nil {
+		return nil, errors.Wrap(err, "db sql[SelJobs] got err")
+	}
+	return jobs, nil
+}
+
+// SelUnfinishedJobByPath 查询指定路径未结束的任务(不含git提交触发的任务)
+func (infra *BlogSummarySqliteInfra) SelUnfinishedJobByPath(ctx context.Context, path string) (*entity.SummaryJob, error) {
+	var job entity.SummaryJob
+	err := infra.db.
+		Where("path=? AND (ref IS NULL OR ref='') AND status IN ?", path, []entity.JobStatus{entity.JobPending, entity.JobRunning}).
+		Order("id DESC").
+		First(&job).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, errors.Wrap(err, "db sql[SelUnfinishedJobByPath] got err")
+	}
+
+	return &job, nil
+}
+
+// SelJobByRef 查询git提交触发的最近一次任务
+func (infra *BlogSummarySqliteInfra) SelJobByRef(ctx context.Context, ref string) (*entity.SummaryJob, error) {
+	var job entity.SummaryJob
+	err := infra.db.Where("ref=?", ref).Order("id DESC").First(&job).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, errors.Wrap(err, "db sql[SelJobByRef] got err")
+	}
+
+	return &job, nil
+}
+
+// SelLastSucceededJob 查询指定路径最近一次成功且记录了git提交的任务
+func (infra *BlogSummarySqliteInfra) SelLastSucceededJob(ctx context.Context, path string) (*entity.SummaryJob, error) {
+	var job entity.SummaryJob
+	err := infra.db.
+		Where("path=? AND status=? AND head_commit<>''", path, entity.JobSucceeded).
+		Order("id DESC").
+		First(&job).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, errors.Wrap(err, "db sql[SelLastSucceededJob] got err")
+	}
+
+	return &job, nil
+}
+
+// UpdateJobCommit 更新任务记录的git提交
+func (infra *BlogSummarySqliteInfra) UpdateJobCommit(ctx context.Context, jobID uint, commit string) error {
+	err := infra.db.Model(&entity.SummaryJob{}).
+		Where("id=?", jobID).
+		Updates(map[string]interface{}{
+			"head_commit": commit,
+			"updated_at":  time.Now().Format(shim.StdDateTimeLayout),
+		}).Error
+	if err != nil {
+		return errors.Wrap(err, "db sql[UpdateJobCommit] got err")
+	}
+	return nil
+}
+
+// SelJobItems 查询任务的文件明细
+func (infra *BlogSummarySqliteInfra) SelJobItems(ctx context.Context, jobID uint, statuses ...entity.JobItemStatus) ([]*entity.SummaryJobItem, error) {
+	var items []*entity.SummaryJobItem
+	tx := infra.db.Where("job_id=?", jobID)
+	if len(statuses) > 0 {
+		tx = tx.Where("status IN ?", statuses)
+	}
+	if err := tx.Order("id ASC").Find(&items).Error; err != nil {
+		return nil, errors.Wrap(err, "db sql[SelJobItems] got err")
+	}
+	return items, nil
+}
+
+// ClaimJobItems 领取待处理的文件，在同一事务内标记为running，避免被重复领取
+func (infra *BlogSummarySqliteInfra) ClaimJobItems(ctx context.Context, jobID uint, limit int) ([]*entity.SummaryJobItem, error) {
+	var items []*entity.SummaryJobItem
+	err := infra.db.Transaction(func(tx *gorm.DB) error {
+		query := tx.Where("status=?", entity.JobItemPending)
+		if jobID > 0 {
+			query = query.Where("job_id=?", jobID)
+		}
+		if err := query.Order("id ASC").Limit(limit).Find(&items).Error; err != nil {
+			return err
+		}
+		if len(items) == 0 {
+			return nil
+		}
+
+		ids := make([]uint, 0, len(items))
+		for _, item := range items {
+			ids = append(ids, item.ID)
+			item.Status = entity.JobItemRunning
+			item.Attempts++
+		}
+		return tx.Model(&entity.SummaryJobItem{}).
+			Where("id IN ?", ids).
+			Updates(map[string]interface{}{
+				"status":     entity.JobItemRunning,
+				"attempts":   gorm.Expr("attempts + 1"),
+				"updated_at": time.Now().Format(shim.StdDateTimeLayout),
+			}).Error
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "db sql[ClaimJobItems] got err")
+	}
+
+	return items, nil
+}
+
+// UpdateJobItem 更新文件处理状态
+func (infra *BlogSummarySqliteInfra) UpdateJobItem(ctx context.Context, item *entity.SummaryJobItem) error {
+	item.UpdatedAt = time.Now().Format(shim.StdDateTimeLayout)
+	err := infra.db.Model(&entity.SummaryJobItem{}).
+		Where("id=?", item.ID).
+		Updates(map[string]interface{}{
+			"status":     item.Status,
+			"reason":     item.Reason,
+			"updated_at": item.UpdatedAt,
+		}).Error
+	if err != nil {
+		return errors.Wrap(err, "db sql[UpdateJobItem] got err")
+	}
+	return nil
+}
+
+// RefreshJobStats 按文件明细重新统计任务进度，finished为本次调用将任务标记为结束，同一任务只会返回一次true
+func (infra *BlogSummarySqliteInfra) RefreshJobStats(ctx context.Context, jobID uint) (job *entity.SummaryJob, finished bool, err error) {
+	var stats []struct {
+		Status entity.JobItemStatus
+		Count  int
+	}
+	err = infra.db.Model(&entity.SummaryJobItem{}).
+		Select("status, COUNT(*) AS count").
+		Where("job_id=?", jobID).
+		Group("status").
+		Scan(&stats).Error
+	if err != nil {
+		return nil, false, errors.Wrap(err, "db sql[RefreshJobStats] count got err")
+	}
+
+	job, err = infra.SelJob(ctx, jobID)
+	if err != nil {
+		return nil, false, err
+	} else if job == nil {
+		return nil, false, errors.Errorf("db sql[RefreshJobStats] job[%d] not found", jobID)
+	}
+
+	counts := make(map[entity.JobItemStatus]int)
+	for _, stat := range stats {
+		counts[stat.Status] = stat.Count
+	}
+	job.Done, job.Skipped, job.Failed = counts[entity.JobItemDone], counts[entity.JobItemSkipped], counts[entity.JobItemFailed]
+	job.UpdatedAt = time.Now().Format(shim.StdDateTimeLayout)
+	switch {
+	case counts[entity.JobItemPending]+counts[entity.JobItemRunning] > 0:
+		if counts[entity.JobItemRunning] > 0 || job.Done+job.Skipped+job.Failed > 0 {
+			job.Status = entity.JobRunning
+		}
+		job.FinishedAt = ""
+	case job.Failed > 0:
+		job.Status, job.FinishedAt = entity.JobFailed, job.UpdatedAt
+	default:
+		job.Status, job.FinishedAt = entity.JobSucceeded, job.UpdatedAt
+	}
+
+	// 只更新进度相关的列，避免覆盖其他worker、UpdateJobCommit同时写入的字段
+	err = infra.db.Model(&entity.SummaryJob{}).
+		Where("id=?", jobID).
+		Updates(map[string]interface{}{
+			"done":       job.Done,
+			"skipped":    job.Skipped,
+			"failed":     job.Failed,
+			"updated_at": job.UpdatedAt,
+		}).Error
+	if err != nil {
+		return nil, false, errors.Wrap(err, "db sql[RefreshJobStats] update got err")
+	}
+
+	// 状态只在任务未结束时变更，并发刷新时只有一个调用方将任务标记为结束
+	result := infra.db.Model(&entity.SummaryJob{}).
+		Where("id=? AND status IN ?", jobID, []entity.JobStatus{entity.JobPending, entity.JobRunning}).
+		Updates(map[string]interface{}{
+			"status":      job.Status,
+			"finished_at": job.FinishedAt,
+		})
+	if result.Error != nil {
+		return nil, false, errors.Wrap(result.Error, "db sql[RefreshJobStats] update status got err")
+	}
+	finished = job.IsFinished() && result.RowsAffected > 0
+
+	if job, err = infra.SelJob(ctx, jobID); err != nil {
+		return nil, false, err
+	}
+	return job, finished, nil
+}
+
+// ResetRunningJobItems 将中断的文件重置为待处理
+func (infra *BlogSummarySqliteInfra) ResetRunningJobItems(ctx context.Context, jobID uint) (int64, error) {
+	tx := infra.db.Model(&entity.SummaryJobItem{}).Where("status=?", entity.JobItemRunning)
+	if jobID > 0 {
+		tx = tx.Where("job_id=?", jobID)
+	}
+	result := tx.Updates(map[string]interface{}{
+		"status":     entity.JobItemPending,
+		"updated_at": time.Now().Format(shim.StdDateTimeLayout),
+	})
+	if result.Error != nil {
+		return 0, errors.Wrap(result.Error, "db sql[ResetRunningJobItems] got err")
+	}
+	return result.RowsAffected, nil
+}
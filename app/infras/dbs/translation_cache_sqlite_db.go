@@ -0,0 +1,20 @@
+package dbs
+
+import (
+	"context"
+
+	"github.com/lupguo/copilot_develop/app/domain/entity"
+	"github.com/lupguo/copilot_develop/app/domain/repos"
+)
+
+var _ repos.IReposTranslationCache = (*BlogSummarySqliteInfra)(nil)
+
+// SelTranslationCache 按请求hash查询翻译缓存
+func (infra *BlogSummarySqliteInfra) SelTranslationCache(ctx context.Context, cacheKey string) (*entity.TranslationCache, error) {
+	return selRecord[entity.TranslationCache](infra.db, "SelTranslationCache", "cache_key=?", cacheKey)
+}
+
+// ReplaceTranslationCache 新增或更新翻译缓存
+func (infra *BlogSummarySqliteInfra) ReplaceTranslationCache(ctx context.Context, cache *entity.TranslationCache) error {
+	return replaceRecord(infra.db, "ReplaceTranslationCache", cache, "cache_key=?", cache.CacheKey)
+}
@@ -0,0 +1,20 @@
+package dbs
+
+import (
+	"context"
+
+	"github.com/lupguo/copilot_develop/app/domain/entity"
+	"github.com/lupguo/copilot_develop/app/domain/repos"
+)
+
+var _ repos.IReposSQLiteWebClip = (*BlogSummarySqliteInfra)(nil)
+
+// SelWebClip 按网页地址查询摘录记录
+func (infra *BlogSummarySqliteInfra) SelWebClip(ctx context.Context, url string) (*entity.WebClip, error) {
+	return selRecord[entity.WebClip](infra.db.Debug(), "SelWebClip", "url=?", url)
+}
+
+// ReplaceWebClip 新增或更新网页摘录记录
+func (infra *BlogSummarySqliteInfra) ReplaceWebClip(ctx context.Context, clip *entity.WebClip) error {
+	return replaceRecord(infra.db.Debug(), "ReplaceWebClip", clip, "url=?", clip.Url)
+}
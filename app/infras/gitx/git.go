@@ -0,0 +1,121 @@
+package gitx
+
+import (
+	"bytes"
+	"context"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// GitRepo 本地git仓库，通过git命令行操作
+type GitRepo struct {
+	root string
+}
+
+// NewGitRepo 初始化dir所在的git仓库(dir可以是仓库内的子目录)
+func NewGitRepo(ctx context.Context, dir string) (*GitRepo, error) {
+	root, err := runGit(ctx, dir, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return nil, errors.Wrapf(err, "dir[%s] is not in a git repository", dir)
+	}
+	return &GitRepo{root: root}, nil
+}
+
+// Root 仓库根目录
+func (r *GitRepo) Root() string {
+	return r.root
+}
+
+// Pull 拉取远端更新，仅允许fast-forward
+func (r *GitRepo) Pull(ctx context.Context) error {
+	_, err := runGit(ctx, r.root, "pull", "--ff-only")
+	return err
+}
+
+// Head 当前HEAD的提交
+func (r *GitRepo) Head(ctx context.Context) (string, error) {
+	return runGit(ctx, r.root, "rev-parse", "HEAD")
+}
+
+// ChangedFiles 自since以来新增、修改(含重命名)的文件，包括工作区未提交的改动及未跟踪的文件，路径相对于仓库根目录
+func (r *GitRepo) ChangedFiles(ctx context.Context, since string) ([]string, error) {
+	if _, err := runGit(ctx, r.root, "rev-parse", "--verify", "--quiet", since+"^{commit}"); err != nil {
+		return nil, errors.Wrapf(err, "unknown git ref[%s]", since)
+	}
+	diff, err := runGit(ctx, r.root, "diff", "-z", "--name-only", "--diff-filter=ACMR", "--no-renames", since, "--")
+	if err != nil {
+		return nil, err
+	}
+	untracked, err := runGit(ctx, r.root, "ls-files", "-z", "--others", "--exclude-standard")
+	if err != nil {
+		return nil, err
+	}
+
+	// -z输出以NUL分隔，避免非ASCII(中文)路径被转义
+	var files []string
+	seen := make(map[string]bool)
+	for _, file := range strings.Split(diff+"\x00"+untracked, "\x00") {
+		if file != "" && !seen[file] {
+			seen[file] = true
+			files = append(files, file)
+		}
+	}
+	return files, nil
+}
+
+// Commit 提交指定文件(绝对路径或相对于仓库根目录)的改动，不影响暂存区中的其他文件，没有改动时返回空提交
+func (r *GitRepo) Commit(ctx context.Context, message string, files []string) (string, error) {
+	if len(files) == 0 {
+		return "", nil
+	}
+	paths := make([]string, 0, len(files))
+	for _, file := range files {
+		path, err := r.relPath(file)
+		if err != nil {
+			return "", err
+		}
+		paths = append(paths, path)
+	}
+
+	status, err := runGit(ctx, r.root, append([]string{"status", "--porcelain", "--"}, paths...)...)
+	if err != nil || status == "" {
+		return "", err
+	}
+	if _, err = runGit(ctx, r.root, append([]string{"add", "--"}, paths...)...); err != nil {
+		return "", err
+	}
+	if _, err = runGit(ctx, r.root, append([]string{"commit", "-m", message, "--"}, paths...)...); err != nil {
+		return "", err
+	}
+	return r.Head(ctx)
+}
+
+// relPath 转换为相对于仓库根目录的路径，按真实路径计算(文件路径可能经过软链)
+func (r *GitRepo) relPath(file string) (string, error) {
+	if !filepath.IsAbs(file) {
+		return file, nil
+	}
+	if real, err := filepath.EvalSymlinks(file); err == nil {
+		file = real
+	}
+	rel, err := filepath.Rel(r.root, file)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return "", errors.Errorf("file[%s] is out of git repository[%s]", file, r.root)
+	}
+	return rel, nil
+}
+
+// runGit 在dir目录执行git命令，返回去除首尾空白的标准输出
+func runGit(ctx context.Context, dir string, args ...string) (string, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if err := cmd.Run(); err != nil {
+		return "", errors.Wrapf(err, "git %s: %s", strings.Join(args, " "), strings.TrimSpace(stderr.String()))
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
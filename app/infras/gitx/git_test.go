@@ -0,0 +1,76 @@
+package gitx
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newTestRepo 初始化临时git仓库并提交一篇文章
+func newTestRepo(t *testing.T) (*GitRepo, string) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"config", "user.name", "tester"},
+		{"config", "user.email", "tester@example.com"},
+	} {
+		_, err := runGit(ctx, dir, args...)
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, os.MkdirAll(filepath.Join(dir, "content", "posts"), 0755))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "content", "posts", "go.md"), []byte("go"), 0644))
+	_, err := runGit(ctx, dir, "add", ".")
+	assert.NoError(t, err)
+	_, err = runGit(ctx, dir, "commit", "-q", "-m", "init")
+	assert.NoError(t, err)
+
+	repo, err := NewGitRepo(ctx, filepath.Join(dir, "content"))
+	assert.NoError(t, err)
+	return repo, dir
+}
+
+func TestGitRepo_ChangedFilesAndCommit(t *testing.T) {
+	ctx := context.Background()
+	repo, dir := newTestRepo(t)
+	realDir, _ := filepath.EvalSymlinks(dir)
+	assert.Equal(t, realDir, repo.Root())
+
+	base, err := repo.Head(ctx)
+	assert.NoError(t, err)
+	files, err := repo.ChangedFiles(ctx, base)
+	assert.NoError(t, err)
+	assert.Empty(t, files)
+
+	// 已提交、未提交、未跟踪(中文文件名)的改动
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "content", "posts", "rust.md"), []byte("rust"), 0644))
+	_, err = runGit(ctx, dir, "add", ".")
+	assert.NoError(t, err)
+	_, err = runGit(ctx, dir, "commit", "-q", "-m", "rust")
+	assert.NoError(t, err)
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "content", "posts", "go.md"), []byte("go edited"), 0644))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "content", "posts", "并发.md"), []byte("new"), 0644))
+
+	files, err = repo.ChangedFiles(ctx, base)
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{"content/posts/go.md", "content/posts/rust.md", "content/posts/并发.md"}, files)
+
+	_, err = repo.ChangedFiles(ctx, "no-such-ref")
+	assert.Error(t, err)
+
+	// 仅提交指定文件
+	commit, err := repo.Commit(ctx, "update go", []string{filepath.Join(dir, "content", "posts", "go.md")})
+	assert.NoError(t, err)
+	assert.NotEmpty(t, commit)
+	files, err = repo.ChangedFiles(ctx, commit)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"content/posts/并发.md"}, files)
+
+	// 没有改动时不提交
+	commit, err = repo.Commit(ctx, "noop", []string{"content/posts/go.md"})
+	assert.NoError(t, err)
+	assert.Empty(t, commit)
+}
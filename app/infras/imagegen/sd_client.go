@@ -0,0 +1,124 @@
+package imagegen
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/lupguo/copilot_develop/app/domain/entity"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// SDClient 本地Stable Diffusion HTTP服务(AUTOMATIC1111 WebUI API)的文生图客户端
+type SDClient struct {
+	baseURL string
+	steps   int
+	client  *http.Client
+}
+
+// NewSDClient 初始一个Stable Diffusion客户端，baseURL例如 http://127.0.0.1:7860
+func NewSDClient(baseURL string, steps int) *SDClient {
+	return &SDClient{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		steps:   steps,
+		client:  &http.Client{Timeout: 10 * time.Minute}, // 本地生成较慢
+	}
+}
+
+// sdTxt2ImgRequest /sdapi/v1/txt2img 请求
+type sdTxt2ImgRequest struct {
+	Prompt           string            `json:"prompt"`
+	NegativePrompt   string            `json:"negative_prompt,omitempty"`
+	Seed             int64             `json:"seed"`
+	Width            int               `json:"width"`
+	Height           int               `json:"height"`
+	Steps            int               `json:"steps,omitempty"`
+	OverrideSettings map[string]string `json:"override_settings,omitempty"`
+}
+
+// sdTxt2ImgResponse /sdapi/v1/txt2img 响应，info为json字符串
+type sdTxt2ImgResponse struct {
+	Images []string `json:"images"`
+	Info   string   `json:"info"`
+}
+
+// sdTxt2ImgInfo 生成参数，含实际使用的随机种子、模型
+type sdTxt2ImgInfo struct {
+	Seed        int64  `json:"seed"`
+	SDModelName string `json:"sd_model_name"`
+}
+
+// GenerateImage 请求 /sdapi/v1/txt2img 生成一张图片，model不为空时临时切换到该checkpoint
+func (c *SDClient) GenerateImage(ctx context.Context, req *entity.ImageGenRequest) (*entity.GeneratedImage, error) {
+	width, height, err := req.SizeWH()
+	if err != nil {
+		return nil, err
+	}
+	seed := req.Seed
+	if seed <= 0 {
+		seed = -1
+	}
+	body := &sdTxt2ImgRequest{
+		Prompt:         req.Prompt,
+		NegativePrompt: req.NegativePrompt,
+		Seed:           seed,
+		Width:          width,
+		Height:         height,
+		Steps:          c.steps,
+	}
+	if req.Model != "" {
+		body.OverrideSettings = map[string]string{"sd_model_checkpoint": req.Model}
+	}
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, errors.Wrap(err, "marshal sd txt2img request got err")
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/sdapi/v1/txt2img", bytes.NewReader(data))
+	if err != nil {
+		return nil, errors.Wrap(err, "new sd txt2img request got err")
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	resp, err := c.client.Do(httpReq)
+	if err != nil {
+		return nil, errors.Wrap(err, "do sd txt2img request got err")
+	}
+	defer resp.Body.Close()
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, errors.Wrap(err, "read sd txt2img response got err")
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("sd txt2img got status %d: %s", resp.StatusCode, bytes.TrimSpace(respBody))
+	}
+
+	var result sdTxt2ImgResponse
+	if err = json.Unmarshal(respBody, &result); err != nil {
+		return nil, errors.Wrap(err, "unmarshal sd txt2img response got err")
+	}
+	if len(result.Images) == 0 {
+		return nil, errors.New("sd txt2img got no images")
+	}
+	image, err := base64.StdEncoding.DecodeString(result.Images[0])
+	if err != nil {
+		return nil, errors.Wrap(err, "decode sd txt2img image got err")
+	}
+
+	generated := &entity.GeneratedImage{Data: image, Ext: ".png", Model: req.Model, Seed: seed}
+	var info sdTxt2ImgInfo
+	if err = json.Unmarshal([]byte(result.Info), &info); err != nil {
+		log.Warnf("unmarshal sd txt2img info got err: %s", err)
+	} else {
+		generated.Seed = info.Seed
+		if info.SDModelName != "" {
+			generated.Model = info.SDModelName
+		}
+	}
+	return generated, nil
+}
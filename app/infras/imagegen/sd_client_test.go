@@ -0,0 +1,46 @@
+package imagegen
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/lupguo/copilot_develop/app/domain/entity"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSDClient_GenerateImage(t *testing.T) {
+	var got sdTxt2ImgRequest
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/sdapi/v1/txt2img", r.URL.Path)
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&got))
+		if got.Prompt == "fail" {
+			http.Error(w, "out of memory", http.StatusInternalServerError)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"images": []string{base64.StdEncoding.EncodeToString([]byte("png"))},
+			"info":   `{"seed": 42, "sd_model_name": "sdxl"}`,
+		})
+	}))
+	defer srv.Close()
+
+	c := NewSDClient(srv.URL+"/", 20)
+	image, err := c.GenerateImage(context.Background(), &entity.ImageGenRequest{
+		Prompt: "a gopher", NegativePrompt: "text", Size: "1024x576", Model: "sdxl.safetensors",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, &entity.GeneratedImage{Data: []byte("png"), Ext: ".png", Model: "sdxl", Seed: 42}, image)
+	assert.Equal(t, sdTxt2ImgRequest{
+		Prompt: "a gopher", NegativePrompt: "text", Seed: -1, Width: 1024, Height: 576, Steps: 20,
+		OverrideSettings: map[string]string{"sd_model_checkpoint": "sdxl.safetensors"},
+	}, got)
+
+	_, err = c.GenerateImage(context.Background(), &entity.ImageGenRequest{Prompt: "fail", Size: "512x512"})
+	assert.ErrorContains(t, err, "out of memory")
+	_, err = c.GenerateImage(context.Background(), &entity.ImageGenRequest{Prompt: "a", Size: "big"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,52 @@
+package imagehost
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// CWebPEncoder 通过libwebp的cwebp命令行编码WebP(标准库不支持WebP编码)
+type CWebPEncoder struct {
+	bin string
+}
+
+// NewCWebPEncoder 查找cwebp命令(bin为命令名或路径)，未安装时返回错误
+func NewCWebPEncoder(bin string) (*CWebPEncoder, error) {
+	path, err := exec.LookPath(bin)
+	if err != nil {
+		return nil, errors.Wrapf(err, "look path of cwebp[%s] got err", bin)
+	}
+	return &CWebPEncoder{bin: path}, nil
+}
+
+// EncodeWebP 将PNG、JPEG图片编码为WebP，输入输出经过临时文件
+func (e *CWebPEncoder) EncodeWebP(ctx context.Context, data []byte, quality int) ([]byte, error) {
+	dir, err := os.MkdirTemp("", "cwebp-*")
+	if err != nil {
+		return nil, errors.Wrap(err, "mkdir temp for cwebp got err")
+	}
+	defer os.RemoveAll(dir)
+	in, out := filepath.Join(dir, "in"), filepath.Join(dir, "out.webp")
+	if err = os.WriteFile(in, data, 0600); err != nil {
+		return nil, errors.Wrap(err, "write cwebp input got err")
+	}
+
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, e.bin, "-quiet", "-q", strconv.Itoa(quality), in, "-o", out)
+	cmd.Stderr = &stderr
+	if err = cmd.Run(); err != nil {
+		return nil, errors.Wrapf(err, "cwebp got err: %s", strings.TrimSpace(stderr.String()))
+	}
+	webp, err := os.ReadFile(out)
+	if err != nil {
+		return nil, errors.Wrap(err, "read cwebp output got err")
+	}
+	return webp, nil
+}
@@ -0,0 +1,105 @@
+package imagehost
+
+import (
+	"context"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// LocalStorage 保存在本地目录的图床存储，图片由HTTP服务的 /i/ 路由提供访问
+type LocalStorage struct {
+	root    string
+	baseURL string
+}
+
+// NewLocalStorage 以root为根目录的本地存储，baseURL为图片访问地址的前缀(例如 https://img.tkstorm.com/i)
+func NewLocalStorage(root, baseURL string) (*LocalStorage, error) {
+	if err := os.MkdirAll(root, 0755); err != nil {
+		return nil, errors.Wrapf(err, "mkdir image storage[%s] got err", root)
+	}
+	return &LocalStorage{root: root, baseURL: strings.TrimRight(baseURL, "/")}, nil
+}
+
+// file key对应的文件，key不能跳出根目录
+func (s *LocalStorage) file(key string) (string, error) {
+	clean := path.Clean("/" + key)
+	if key == "" || clean == "/" || clean[1:] != key {
+		return "", errors.Errorf("invalid image key[%s]", key)
+	}
+	return filepath.Join(s.root, filepath.FromSlash(key)), nil
+}
+
+// Put 保存图片，先写临时文件再rename，避免读到写了一半的图片
+func (s *LocalStorage) Put(ctx context.Context, key string, data []byte, mimeType string) error {
+	file, err := s.file(key)
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return errors.Wrapf(err, "mkdir for image[%s] got err", key)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(file), ".upload-*")
+	if err != nil {
+		return errors.Wrapf(err, "create temp file for image[%s] got err", key)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err = tmp.Write(data); err != nil {
+		tmp.Close()
+		return errors.Wrapf(err, "write image[%s] got err", key)
+	}
+	if err = tmp.Close(); err != nil {
+		return errors.Wrapf(err, "close image[%s] got err", key)
+	}
+	if err = os.Chmod(tmp.Name(), 0644); err != nil {
+		return errors.Wrapf(err, "chmod image[%s] got err", key)
+	}
+	if err = os.Rename(tmp.Name(), file); err != nil {
+		return errors.Wrapf(err, "rename image[%s] got err", key)
+	}
+	return nil
+}
+
+// Open 读取图片
+func (s *LocalStorage) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	file, err := s.file(key)
+	if err != nil {
+		return nil, errors.Wrap(fs.ErrNotExist, err.Error())
+	}
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, errors.Wrapf(err, "open image[%s] got err", key)
+	}
+	if st, err := f.Stat(); err != nil || st.IsDir() {
+		f.Close()
+		return nil, errors.Wrapf(fs.ErrNotExist, "image[%s] is not a file", key)
+	}
+	return f, nil
+}
+
+// Exists 图片是否存在
+func (s *LocalStorage) Exists(ctx context.Context, key string) (bool, error) {
+	file, err := s.file(key)
+	if err != nil {
+		return false, err
+	}
+	_, err = os.Stat(file)
+	switch {
+	case err == nil:
+		return true, nil
+	case errors.Is(err, fs.ErrNotExist):
+		return false, nil
+	default:
+		return false, errors.Wrapf(err, "stat image[%s] got err", key)
+	}
+}
+
+// URL 图片的访问地址
+func (s *LocalStorage) URL(key string) string {
+	return s.baseURL + "/" + key
+}
@@ -0,0 +1,67 @@
+package imagehost
+
+import (
+	"context"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLocalStorage(t *testing.T) {
+	ctx := context.Background()
+	root := t.TempDir()
+	s, err := NewLocalStorage(filepath.Join(root, "images"), "https://img.tkstorm.com/i/")
+	assert.NoError(t, err)
+
+	ok, err := s.Exists(ctx, "ab/cd/abcd.png")
+	assert.NoError(t, err)
+	assert.False(t, ok)
+	_, err = s.Open(ctx, "ab/cd/abcd.png")
+	assert.True(t, errors.Is(err, fs.ErrNotExist))
+
+	assert.NoError(t, s.Put(ctx, "ab/cd/abcd.png", []byte("png"), "image/png"))
+	ok, err = s.Exists(ctx, "ab/cd/abcd.png")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	r, err := s.Open(ctx, "ab/cd/abcd.png")
+	assert.NoError(t, err)
+	data, err := io.ReadAll(r)
+	assert.NoError(t, err)
+	assert.NoError(t, r.Close())
+	assert.Equal(t, "png", string(data))
+	assert.Equal(t, "https://img.tkstorm.com/i/ab/cd/abcd.png", s.URL("ab/cd/abcd.png"))
+
+	// key不能跳出根目录
+	for _, key := range []string{"", "../secret.png", "ab/../../secret.png", "/abs.png", "ab//cd.png"} {
+		assert.Error(t, s.Put(ctx, key, []byte("x"), "image/png"), key)
+		_, err = s.Open(ctx, key)
+		assert.True(t, errors.Is(err, fs.ErrNotExist), key)
+	}
+	_, err = os.Stat(filepath.Join(root, "secret.png"))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestCWebPEncoder_EncodeWebP(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake cwebp is a shell script")
+	}
+	// 模拟cwebp: cwebp -quiet -q <quality> <in> -o <out>，输出 quality + 输入内容
+	bin := filepath.Join(t.TempDir(), "cwebp")
+	script := "#!/bin/sh\nprintf '%s:' \"$3\" > \"$6\" && cat \"$4\" >> \"$6\"\n"
+	assert.NoError(t, os.WriteFile(bin, []byte(script), 0755))
+
+	enc, err := NewCWebPEncoder(bin)
+	assert.NoError(t, err)
+	webp, err := enc.EncodeWebP(context.Background(), []byte("png"), 80)
+	assert.NoError(t, err)
+	assert.Equal(t, "80:png", string(webp))
+
+	_, err = NewCWebPEncoder(filepath.Join(t.TempDir(), "missing-cwebp"))
+	assert.Error(t, err)
+}
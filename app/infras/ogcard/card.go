@@ -0,0 +1,194 @@
+package ogcard
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/color"
+	"image/draw"
+	_ "image/jpeg"
+	"image/png"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/lupguo/copilot_develop/app/domain/entity"
+	"github.com/lupguo/copilot_develop/config"
+	"github.com/pkg/errors"
+)
+
+const (
+	titleMaxLines       = 3    // 标题最多行数
+	descriptionMaxLines = 3    // 描述最多行数
+	titleMinScale       = 0.6  // 标题过长时字号最多缩小到的比例
+	titleShrink         = 0.85 // 标题每次缩小的比例
+)
+
+// CardRenderer 社交分享卡片渲染：在模板图片(或纯色背景)上绘制站点名、标题、描述及标签，
+// 相同的内容及配置总是得到相同的图片
+type CardRenderer struct {
+	cfg        *config.OGCardConfig
+	titleFonts []*Font
+	fonts      []*Font
+	template   image.Image
+
+	background color.Color
+	foreground color.Color
+	muted      color.Color
+	accent     color.Color
+}
+
+// NewCardRenderer 加载配置的字体、模板图片
+func NewCardRenderer(cfg *config.OGCardConfig) (*CardRenderer, error) {
+	if len(cfg.TitleFonts) == 0 {
+		return nil, errors.New("og card needs title_fonts (TrueType or OpenType fonts)")
+	}
+	r := &CardRenderer{cfg: cfg}
+	var err error
+	if r.titleFonts, err = loadFonts(cfg.TitleFonts); err != nil {
+		return nil, err
+	}
+	if r.fonts, err = loadFonts(cfg.Fonts); err != nil {
+		return nil, err
+	}
+	if len(r.fonts) == 0 {
+		r.fonts = r.titleFonts
+	}
+
+	for _, c := range []struct {
+		dst   *color.Color
+		value string
+	}{
+		{&r.background, cfg.Background},
+		{&r.foreground, cfg.Foreground},
+		{&r.muted, cfg.Muted},
+		{&r.accent, cfg.Accent},
+	} {
+		if *c.dst, err = parseHexColor(c.value); err != nil {
+			return nil, err
+		}
+	}
+
+	if cfg.Template != "" {
+		f, err := os.Open(cfg.Template)
+		if err != nil {
+			return nil, errors.Wrapf(err, "open og card template[%s] got err", cfg.Template)
+		}
+		defer f.Close()
+		if r.template, _, err = image.Decode(f); err != nil {
+			return nil, errors.Wrapf(err, "decode og card template[%s] got err", cfg.Template)
+		}
+	}
+	return r, nil
+}
+
+// loadFonts 按顺序加载字体
+func loadFonts(paths []string) ([]*Font, error) {
+	var fonts []*Font
+	for _, path := range paths {
+		font, err := LoadFont(path)
+		if err != nil {
+			return nil, err
+		}
+		fonts = append(fonts, font)
+	}
+	return fonts, nil
+}
+
+// RenderCard 渲染卡片，返回PNG图片：左上为站点名，其下为标题(过长时缩小字号，最多3行)、描述，底部为标签
+func (r *CardRenderer) RenderCard(ctx context.Context, card *entity.OGCard) ([]byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	canvas := r.canvas()
+	bounds := canvas.Bounds()
+	pad := float64(r.cfg.Padding)
+	width := float64(bounds.Dx()) - 2*pad
+	bottom := float64(bounds.Dy()) - pad
+
+	textFace := NewFace(r.fonts, r.cfg.TextSize)
+	y := pad
+	if card.SiteName != "" {
+		brand := NewFace(r.fonts, r.cfg.TextSize*0.9)
+		siteName := card.SiteName
+		if brand.Measure(siteName) > width {
+			siteName = ellipsize(brand, siteName, width)
+		}
+		brand.Draw(canvas, pad, y+brand.Ascent(), siteName, r.accent)
+		y += brand.LineHeight() + pad/2
+	}
+
+	if len(card.Tags) > 0 {
+		tags := "#" + strings.Join(card.Tags, "  #")
+		if textFace.Measure(tags) > width {
+			tags = ellipsize(textFace, tags, width)
+		}
+		textFace.Draw(canvas, pad, bottom, tags, r.accent)
+		bottom -= textFace.LineHeight() + pad/4
+	}
+
+	size := r.cfg.TitleSize
+	titleFace := NewFace(r.titleFonts, size)
+	lines, truncated := WrapText(titleFace, card.Title, width, titleMaxLines)
+	for truncated && size*titleShrink >= r.cfg.TitleSize*titleMinScale {
+		size *= titleShrink
+		titleFace = NewFace(r.titleFonts, size)
+		lines, truncated = WrapText(titleFace, card.Title, width, titleMaxLines)
+	}
+	for _, line := range lines {
+		titleFace.Draw(canvas, pad, y+titleFace.Ascent(), line, r.foreground)
+		y += titleFace.LineHeight()
+	}
+
+	if card.Description != "" {
+		y += textFace.LineHeight() / 2
+		maxLines := int((bottom - y) / textFace.LineHeight())
+		if maxLines > descriptionMaxLines {
+			maxLines = descriptionMaxLines
+		}
+		if maxLines > 0 {
+			lines, _ = WrapText(textFace, card.Description, width, maxLines)
+			for _, line := range lines {
+				textFace.Draw(canvas, pad, y+textFace.Ascent(), line, r.muted)
+				y += textFace.LineHeight()
+			}
+		}
+	}
+
+	var b bytes.Buffer
+	if err := png.Encode(&b, canvas); err != nil {
+		return nil, errors.Wrap(err, "encode og card png got err")
+	}
+	return b.Bytes(), nil
+}
+
+// canvas 卡片画布：有模板时为模板图片的副本，否则为纯色背景加左侧装饰条
+func (r *CardRenderer) canvas() *image.RGBA {
+	if r.template != nil {
+		b := r.template.Bounds()
+		canvas := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+		draw.Draw(canvas, canvas.Bounds(), r.template, b.Min, draw.Src)
+		return canvas
+	}
+	canvas := image.NewRGBA(image.Rect(0, 0, r.cfg.Width, r.cfg.Height))
+	draw.Draw(canvas, canvas.Bounds(), image.NewUniform(r.background), image.Point{}, draw.Src)
+	bar := image.Rect(0, 0, r.cfg.Padding/6, r.cfg.Height)
+	draw.Draw(canvas, bar, image.NewUniform(r.accent), image.Point{}, draw.Src)
+	return canvas
+}
+
+// parseHexColor 解析 #rgb、#rrggbb、#rrggbbaa 形式的颜色
+func parseHexColor(s string) (color.Color, error) {
+	hex := strings.TrimPrefix(strings.TrimSpace(s), "#")
+	if len(hex) == 3 {
+		hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+	}
+	if len(hex) == 6 {
+		hex += "ff"
+	}
+	v, err := strconv.ParseUint(hex, 16, 32)
+	if len(hex) != 8 || err != nil {
+		return nil, errors.Errorf("invalid color[%s]", s)
+	}
+	return color.NRGBA{R: uint8(v >> 24), G: uint8(v >> 16), B: uint8(v >> 8), A: uint8(v)}, nil
+}
@@ -0,0 +1,137 @@
+package ogcard
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/lupguo/copilot_develop/app/domain/entity"
+	"github.com/lupguo/copilot_develop/config"
+	"github.com/stretchr/testify/assert"
+)
+
+const testFont = "/usr/share/fonts/truetype/dejavu/DejaVuSans.ttf"
+
+func loadTestFont(t *testing.T) *Font {
+	if _, err := os.Stat(testFont); err != nil {
+		t.Skipf("test font %s not found", testFont)
+	}
+	font, err := LoadFont(testFont)
+	assert.NoError(t, err)
+	return font
+}
+
+const testCFFFont = "testdata/CFFTest.otf"
+
+func TestParseFont(t *testing.T) {
+	font := loadTestFont(t)
+	assert.NotZero(t, font.GlyphIndex('A'))
+	assert.NotEqual(t, font.GlyphIndex('A'), font.GlyphIndex('B'))
+	assert.Zero(t, font.GlyphIndex('中'))
+
+	// 等宽数字，字号翻倍宽度翻倍
+	assert.Equal(t, NewFace([]*Font{font}, 32).Measure("1"), NewFace([]*Font{font}, 32).Measure("8"))
+	assert.InDelta(t, 2*NewFace([]*Font{font}, 16).Measure("W"), NewFace([]*Font{font}, 32).Measure("W"), 2.0/64)
+
+	_, err := ParseFont([]byte("OTTO\x00\x00\x00\x00\x00\x00\x00\x00"), 0)
+	assert.Error(t, err)
+	_, err = LoadFont(testFont + "#1")
+	assert.ErrorContains(t, err, "no font #1")
+
+	// CFF轮廓的OpenType字体(.otf)
+	cff, err := LoadFont(testCFFFont)
+	assert.NoError(t, err)
+	assert.NotZero(t, cff.GlyphIndex('中'))
+	assert.Zero(t, cff.GlyphIndex('A'))
+}
+
+func TestFace_Draw(t *testing.T) {
+	cff, err := LoadFont(testCFFFont)
+	assert.NoError(t, err)
+	face := NewFace([]*Font{loadTestFont(t), cff}, 40)
+
+	// 西文字体不包含的字符回退到CFF字体
+	assert.True(t, face.Has('中'))
+	assert.False(t, face.Has('日'))
+	assert.Greater(t, face.Measure("中"), 0.0)
+
+	inked := func(s string) int {
+		dst := image.NewRGBA(image.Rect(0, 0, 200, 60))
+		face.Draw(dst, 10, 10+face.Ascent(), s, color.Black)
+		n := 0
+		for i := 3; i < len(dst.Pix); i += 4 {
+			if dst.Pix[i] > 0 {
+				n++
+			}
+		}
+		return n
+	}
+	assert.Zero(t, inked(" "))
+	assert.Greater(t, inked("中"), 0)
+	assert.Greater(t, inked("é"), inked("e")) // 组合字形(带重音符号)
+}
+
+func TestWrapText(t *testing.T) {
+	face := NewFace([]*Font{loadTestFont(t)}, 20)
+	width := face.Measure("hello world")
+
+	lines, truncated := WrapText(face, "hello world hello   world", width, 0)
+	assert.False(t, truncated)
+	assert.Equal(t, []string{"hello world", "hello world"}, lines)
+
+	// 中日韩文字可在任意位置断行，超出行数时以省略号结尾
+	assert.Equal(t, []string{"Go", " ", "语", "言", "GMP"}, wrapTokens(" Go  语言GMP "))
+	lines, truncated = WrapText(face, "hello world hello world hello", width, 2)
+	assert.True(t, truncated)
+	assert.Len(t, lines, 2)
+	assert.Equal(t, "…", lines[1][len(lines[1])-len("…"):])
+	assert.LessOrEqual(t, face.Measure(lines[1]), width)
+
+	// 比整行还宽的单词按字符拆开
+	lines, _ = WrapText(face, "abcdefghijklmnopqrstuvwxyz", face.Measure("abcdefghij"), 0)
+	assert.Equal(t, "abcdefghij", lines[0])
+	assert.Equal(t, "abcdefghijklmnopqrstuvwxyz", strings.Join(lines, ""))
+	for _, line := range lines {
+		assert.LessOrEqual(t, face.Measure(line), face.Measure("abcdefghij"))
+	}
+}
+
+func TestParseHexColor(t *testing.T) {
+	c, err := parseHexColor("#0f172a")
+	assert.NoError(t, err)
+	assert.Equal(t, color.NRGBA{R: 0x0f, G: 0x17, B: 0x2a, A: 0xff}, c)
+	c, err = parseHexColor("fff")
+	assert.NoError(t, err)
+	assert.Equal(t, color.NRGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}, c)
+	_, err = parseHexColor("#12345")
+	assert.Error(t, err)
+}
+
+func TestCardRenderer_RenderCard(t *testing.T) {
+	loadTestFont(t)
+	cfg := &config.OGCardConfig{Width: 600, Height: 315, TitleFonts: []string{testFont, testCFFFont}, TitleSize: 40, TextSize: 18,
+		Padding: 40, Background: "#000000", Foreground: "#ffffff", Muted: "#888888", Accent: "#ff0000"}
+	r, err := NewCardRenderer(cfg)
+	assert.NoError(t, err)
+
+	card := &entity.OGCard{Title: "Go scheduler 中", Description: "GMP in depth", Tags: []string{"go"}, SiteName: "tkstorm.com"}
+	data, err := r.RenderCard(context.Background(), card)
+	assert.NoError(t, err)
+	img, err := png.Decode(bytes.NewReader(data))
+	assert.NoError(t, err)
+	assert.Equal(t, image.Rect(0, 0, 600, 315), img.Bounds())
+	assert.Equal(t, color.RGBA{R: 0xff, A: 0xff}, img.At(1, 100)) // 左侧装饰条
+
+	// 相同内容渲染结果相同
+	again, err := r.RenderCard(context.Background(), card)
+	assert.NoError(t, err)
+	assert.Equal(t, data, again)
+
+	_, err = NewCardRenderer(&config.OGCardConfig{})
+	assert.Error(t, err)
+}
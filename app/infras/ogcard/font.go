@@ -0,0 +1,70 @@
+package ogcard
+
+import (
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/font/sfnt"
+)
+
+// Font 解析后的OpenType字体，支持TrueType(glyf)及CFF轮廓(.ttf、.otf、.ttc、.otc，例如Noto Sans CJK)
+type Font struct {
+	sfnt *sfnt.Font
+}
+
+// LoadFont 加载字体文件，字体集合(.ttc、.otc)可以 path#index 指定其中的字体，默认第一个
+func LoadFont(path string) (*Font, error) {
+	index := 0
+	if i := strings.LastIndex(path, "#"); i > 0 {
+		n, err := strconv.Atoi(path[i+1:])
+		if err == nil {
+			path, index = path[:i], n
+		}
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "read font[%s] got err", path)
+	}
+	f, err := ParseFont(data, index)
+	if err != nil {
+		return nil, errors.Wrapf(err, "parse font[%s] got err", path)
+	}
+	return f, nil
+}
+
+// ParseFont 解析字体数据，字体集合时取第index个字体
+func ParseFont(data []byte, index int) (*Font, error) {
+	collection, err := opentype.ParseCollection(data)
+	if err != nil {
+		return nil, errors.Wrap(err, "parse opentype got err")
+	}
+	if index < 0 || index >= collection.NumFonts() {
+		return nil, errors.Errorf("font collection has no font #%d", index)
+	}
+	f, err := collection.Font(index)
+	if err != nil {
+		return nil, errors.Wrapf(err, "parse font #%d got err", index)
+	}
+	return &Font{sfnt: f}, nil
+}
+
+// GlyphIndex 字符对应的字形，字体不包含该字符时返回0
+func (f *Font) GlyphIndex(r rune) int {
+	var buf sfnt.Buffer
+	g, err := f.sfnt.GlyphIndex(&buf, r)
+	if err != nil {
+		return 0
+	}
+	return int(g)
+}
+
+// face size(像素)字号的字形渲染，返回的Face不能并发使用
+func (f *Font) face(size float64) font.Face {
+	// 选项有效时opentype.NewFace不会返回错误
+	face, _ := opentype.NewFace(f.sfnt, &opentype.FaceOptions{Size: size, DPI: 72, Hinting: font.HintingNone})
+	return face
+}
@@ -0,0 +1,191 @@
+package ogcard
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+	"strings"
+	"unicode"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
+)
+
+// Face 指定字号的字体组合：按顺序回退，前面的字体不包含的字符(例如中文)使用后面的字体；不能并发使用
+type Face struct {
+	fonts []*Font
+	faces []font.Face
+}
+
+// NewFace 按字号(像素)创建字体组合
+func NewFace(fonts []*Font, size float64) *Face {
+	f := &Face{fonts: fonts}
+	for _, ft := range fonts {
+		f.faces = append(f.faces, ft.face(size))
+	}
+	return f
+}
+
+// glyph 字符使用的字体，所有字体都不包含时使用第一个字体(绘制缺字字形)
+func (f *Face) glyph(r rune) (font.Face, bool) {
+	for i, ft := range f.fonts {
+		if ft.GlyphIndex(r) != 0 {
+			return f.faces[i], true
+		}
+	}
+	return f.faces[0], false
+}
+
+// Has 字体组合中是否有字体包含字符r
+func (f *Face) Has(r rune) bool {
+	_, ok := f.glyph(r)
+	return ok
+}
+
+// Ascent 第一个字体基线以上的高度
+func (f *Face) Ascent() float64 {
+	return fromFixed(f.faces[0].Metrics().Ascent)
+}
+
+// LineHeight 行高
+func (f *Face) LineHeight() float64 {
+	return fromFixed(f.faces[0].Metrics().Height)
+}
+
+// Measure 文字的宽度(像素)
+func (f *Face) Measure(s string) float64 {
+	var w fixed.Int26_6
+	for _, r := range s {
+		face, _ := f.glyph(r)
+		advance, _ := face.GlyphAdvance(r)
+		w += advance
+	}
+	return fromFixed(w)
+}
+
+// Draw 以(x, baseline)为起点绘制一行文字
+func (f *Face) Draw(dst draw.Image, x, baseline float64, s string, c color.Color) {
+	src := image.NewUniform(c)
+	dot := fixed.Point26_6{X: toFixed(x), Y: toFixed(baseline)}
+	for _, r := range s {
+		face, _ := f.glyph(r)
+		dr, mask, maskp, advance, ok := face.Glyph(dot, r)
+		if ok && !dr.Empty() {
+			draw.DrawMask(dst, dr, src, image.Point{}, mask, maskp, draw.Over)
+		}
+		dot.X += advance
+	}
+}
+
+// toFixed 像素转为26.6定点数
+func toFixed(v float64) fixed.Int26_6 {
+	return fixed.Int26_6(math.Round(v * 64))
+}
+
+// fromFixed 26.6定点数转为像素
+func fromFixed(v fixed.Int26_6) float64 {
+	return float64(v) / 64
+}
+
+// isBreakAnywhere 可在任意位置断行的字符(中日韩文字及全角标点)
+func isBreakAnywhere(r rune) bool {
+	return unicode.Is(unicode.Han, r) || unicode.Is(unicode.Hiragana, r) || unicode.Is(unicode.Katakana, r) ||
+		unicode.Is(unicode.Hangul, r) || (r >= 0x3000 && r <= 0x303f) || (r >= 0xff00 && r <= 0xffef)
+}
+
+// wrapTokens 断行单位：连续的非中日韩字符组成的单词、单个中日韩字符，空白合并为一个空格
+func wrapTokens(s string) []string {
+	var tokens []string
+	var word strings.Builder
+	flush := func() {
+		if word.Len() > 0 {
+			tokens = append(tokens, word.String())
+			word.Reset()
+		}
+	}
+	for _, r := range s {
+		switch {
+		case unicode.IsSpace(r):
+			flush()
+			if len(tokens) > 0 && tokens[len(tokens)-1] != " " {
+				tokens = append(tokens, " ")
+			}
+		case isBreakAnywhere(r):
+			flush()
+			tokens = append(tokens, string(r))
+		default:
+			word.WriteRune(r)
+		}
+	}
+	flush()
+	if len(tokens) > 0 && tokens[len(tokens)-1] == " " {
+		tokens = tokens[:len(tokens)-1]
+	}
+	return tokens
+}
+
+// WrapText 按宽度断行，最多maxLines行(<=0时不限)，超出时最后一行截断并以省略号结尾；
+// 返回的第二个值表示文字是否被截断
+func WrapText(face *Face, s string, maxWidth float64, maxLines int) ([]string, bool) {
+	var lines []string
+	var line strings.Builder
+	width := 0.0
+	newLine := func() {
+		lines = append(lines, strings.TrimRight(line.String(), " "))
+		line.Reset()
+		width = 0
+	}
+
+	tokens := wrapTokens(s)
+	for i := 0; i < len(tokens); i++ {
+		token := tokens[i]
+		w := face.Measure(token)
+		switch {
+		case token == " " && width == 0:
+			continue
+		case width+w <= maxWidth:
+		case width > 0:
+			newLine()
+			i--
+			continue
+		default:
+			// 单词比整行还宽时按字符拆开
+			runes := []rune(token)
+			n := 1
+			for n < len(runes) && face.Measure(string(runes[:n+1])) <= maxWidth {
+				n++
+			}
+			if n < len(runes) {
+				tokens = append(tokens[:i+1], tokens[i:]...)
+				tokens[i], tokens[i+1] = string(runes[:n]), string(runes[n:])
+				token, w = tokens[i], face.Measure(tokens[i])
+			}
+		}
+		line.WriteString(token)
+		width += w
+	}
+	if line.Len() > 0 {
+		newLine()
+	}
+
+	if maxLines <= 0 || len(lines) <= maxLines {
+		return lines, false
+	}
+	lines = lines[:maxLines]
+	lines[maxLines-1] = ellipsize(face, lines[maxLines-1], maxWidth)
+	return lines, true
+}
+
+// ellipsize 截断文字并加上省略号，使其不超过maxWidth
+func ellipsize(face *Face, s string, maxWidth float64) string {
+	ellipsis := "…"
+	if !face.Has('…') {
+		ellipsis = "..."
+	}
+	runes := []rune(strings.TrimRight(s, " "))
+	for len(runes) > 0 && face.Measure(string(runes)+ellipsis) > maxWidth {
+		runes = runes[:len(runes)-1]
+	}
+	return strings.TrimRight(string(runes), " ") + ellipsis
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"net/http"
 	"net/url"
+	"time"
 
 	"github.com/hold7techs/go-shim/shim"
 	"github.com/lupguo/copilot_develop/config"
@@ -15,6 +16,8 @@ import (
 // OpenAIHttpProxyClient OpenAI Http代理客户端
 type OpenAIHttpProxyClient struct {
 	proxyClient *openai.Client
+	limiter     *tokenLimiter
+	maxRetries  int
 }
 
 // NewOpenAIHttpProxyClient 初始一个OpenAI代理实例
@@ -40,14 +43,19 @@ func NewOpenAIHttpProxyClient() (*OpenAIHttpProxyClient, error) {
 
 	return &OpenAIHttpProxyClient{
 		proxyClient: openai.NewClientWithConfig(openaiCfg),
+		limiter:     newTokenLimiter(cfg.TokensPerMinute),
+		maxRetries:  cfg.MaxRetries,
 	}, nil
 }
 
 // DoAIChatCompletionRequest 通用的AI ChatCompletion代理请求
 func (o *OpenAIHttpProxyClient) DoAIChatCompletionRequest(ctx context.Context, req *openai.ChatCompletionRequest) (response *openai.ChatCompletionResponse, err error) {
-	resp, err := o.proxyClient.CreateChatCompletion(ctx, *req)
+	var resp openai.ChatCompletionResponse
+	err = o.doWithRetry(ctx, "DoAIChatCompletionRequest", func() (int, error) {
+		resp, err = o.proxyClient.CreateChatCompletion(ctx, *req)
+		return resp.Usage.TotalTokens, err
+	})
 	if err != nil {
-		log.Errorf("DoAIChatCompletionRequest() got error: %v\n", err)
 		return nil, errors.Wrap(err, "do AI chat completion request got err")
 	}
 
@@ -57,3 +65,46 @@ func (o *OpenAIHttpProxyClient) DoAIChatCompletionRequest(ctx context.Context, r
 
 	return &resp, nil
 }
+
+// DoAIEmbeddingRequest 通用的AI Embedding代理请求
+func (o *OpenAIHttpProxyClient) DoAIEmbeddingRequest(ctx context.Context, req *openai.EmbeddingRequest) (response *openai.EmbeddingResponse, err error) {
+	var resp openai.EmbeddingResponse
+	err = o.doWithRetry(ctx, "DoAIEmbeddingRequest", func() (int, error) {
+		resp, err = o.proxyClient.CreateEmbeddings(ctx, *req)
+		return resp.Usage.TotalTokens, err
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "do AI embedding request got err")
+	}
+	log.Debugf("AI embedding model[%s] got %d vectors, usage %d tokens", req.Model, len(resp.Data), resp.Usage.TotalTokens)
+
+	return &resp, nil
+}
+
+// doWithRetry 按每分钟token限额发起请求，限频失败时间隔一定时间重试，do返回本次消耗的token数
+func (o *OpenAIHttpProxyClient) doWithRetry(ctx context.Context, name string, do func() (int, error)) error {
+	for retry := 0; ; retry++ {
+		// 每分钟token限额
+		if err := o.limiter.Wait(ctx); err != nil {
+			return errors.Wrap(err, "wait for openai token limiter got err")
+		}
+
+		tokens, err := do()
+		if err == nil {
+			o.limiter.Add(tokens)
+			return nil
+		}
+
+		// 限频失败重试(间隔一定时间)
+		if !isRateLimitErr(err) || retry >= o.maxRetries {
+			log.Errorf("%s() got error: %v\n", name, err)
+			return err
+		}
+		log.Warnf("%s() rate limited, retry %d/%d: %v", name, retry+1, o.maxRetries, err)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(time.Duration(retry+1) * retryInterval):
+		}
+	}
+}
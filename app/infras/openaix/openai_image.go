@@ -0,0 +1,52 @@
+package openaix
+
+import (
+	"context"
+	"encoding/base64"
+
+	"github.com/lupguo/copilot_develop/app/domain/entity"
+	"github.com/pkg/errors"
+	"github.com/sashabaranov/go-openai"
+	log "github.com/sirupsen/logrus"
+)
+
+// GenerateImage 通过OpenAI Images生成一张图片(b64_json格式返回)，OpenAI不支持指定随机种子
+func (o *OpenAIHttpProxyClient) GenerateImage(ctx context.Context, req *entity.ImageGenRequest) (*entity.GeneratedImage, error) {
+	model := req.Model
+	if model == "" {
+		model = openai.CreateImageModelDallE3
+	}
+	imageReq := openai.ImageRequest{
+		Prompt:         req.Prompt,
+		Model:          model,
+		N:              1,
+		Size:           req.Size,
+		ResponseFormat: openai.CreateImageResponseFormatB64JSON,
+	}
+
+	var resp openai.ImageResponse
+	err := o.doWithRetry(ctx, "GenerateImage", func() (int, error) {
+		var err error
+		resp, err = o.proxyClient.CreateImage(ctx, imageReq)
+		return 0, err
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "do AI image request got err")
+	}
+	if len(resp.Data) == 0 {
+		return nil, errors.New("AI image request got empty data")
+	}
+
+	data, err := base64.StdEncoding.DecodeString(resp.Data[0].B64JSON)
+	if err != nil {
+		return nil, errors.Wrap(err, "decode AI image b64_json got err")
+	}
+	log.Debugf("AI image model[%s] size[%s] got %d bytes, revised prompt: %s", model, req.Size, len(data), resp.Data[0].RevisedPrompt)
+
+	return &entity.GeneratedImage{
+		Data:          data,
+		Ext:           ".png",
+		Model:         model,
+		RevisedPrompt: resp.Data[0].RevisedPrompt,
+	}, nil
+}
@@ -19,6 +19,8 @@ type Prompt struct {
 	AIMode            string                         `yaml:"ai_mode"`
 	MaxTokens         int                            `yaml:"max_tokens"`
 	PredefinedPrompts []openai.ChatCompletionMessage `yaml:"predefined_prompts"` // 预先定义的提示内容（例如定义AI角色）
+	Languages         []string                       `yaml:"languages"`          // 额外生成的目标语言，例如 [en, ja]
+	LangOutput        string                         `yaml:"lang_output"`        // 多语言输出方式: keys(默认) | sibling
 }
 
 var defaultPromptSetting map[string]*Prompt
@@ -39,7 +41,8 @@ func ParseAppPromptConfig(filename string) (error, map[string]*Prompt) {
 
 	// 转成map
 	defaultPromptSetting = make(map[string]*Prompt)
-	for _, prompt := range cfg.AppPrompts {
+	for i := range cfg.AppPrompts {
+		prompt := cfg.AppPrompts[i]
 		defaultPromptSetting[prompt.Name] = &prompt
 	}
 
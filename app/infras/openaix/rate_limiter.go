@@ -0,0 +1,77 @@
+package openaix
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sashabaranov/go-openai"
+)
+
+// retryInterval 限频重试的基础间隔，第n次重试等待n倍间隔
+var retryInterval = 10 * time.Second
+
+// tokenLimiter 按分钟窗口统计响应中的total_tokens，超过限额时等待窗口到期重置计数
+type tokenLimiter struct {
+	mu       sync.Mutex
+	limit    int
+	used     int
+	windowAt time.Time
+}
+
+func newTokenLimiter(limit int) *tokenLimiter {
+	return &tokenLimiter{limit: limit, windowAt: time.Now()}
+}
+
+// Wait 当前窗口额度用尽时阻塞到下一个窗口
+func (l *tokenLimiter) Wait(ctx context.Context) error {
+	if l == nil || l.limit <= 0 {
+		return nil
+	}
+
+	for {
+		l.mu.Lock()
+		now := time.Now()
+		if now.Sub(l.windowAt) >= time.Minute {
+			l.windowAt, l.used = now, 0
+		}
+		if l.used < l.limit {
+			l.mu.Unlock()
+			return nil
+		}
+		sleep := l.windowAt.Add(time.Minute).Sub(now)
+		l.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(sleep):
+		}
+	}
+}
+
+// Add 累计响应中消耗的token
+func (l *tokenLimiter) Add(tokens int) {
+	if l == nil || l.limit <= 0 {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.used += tokens
+}
+
+// isRateLimitErr 是否为OpenAI限频错误(429)
+func isRateLimitErr(err error) bool {
+	var apiErr *openai.APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.HTTPStatusCode == http.StatusTooManyRequests
+	}
+	var reqErr *openai.RequestError
+	if errors.As(err, &reqErr) {
+		return reqErr.HTTPStatusCode == http.StatusTooManyRequests
+	}
+	return false
+}
@@ -0,0 +1,38 @@
+package openaix
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sashabaranov/go-openai"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTokenLimiter_Wait(t *testing.T) {
+	limiter := newTokenLimiter(100)
+	assert.NoError(t, limiter.Wait(context.Background()))
+
+	// 额度用尽后阻塞，直到ctx超时
+	limiter.Add(100)
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, limiter.Wait(ctx), context.DeadlineExceeded)
+
+	// 窗口到期后重置
+	limiter.windowAt = time.Now().Add(-time.Minute)
+	assert.NoError(t, limiter.Wait(context.Background()))
+
+	// 未配置限额不阻塞
+	var noLimit *tokenLimiter
+	noLimit.Add(1000)
+	assert.NoError(t, noLimit.Wait(context.Background()))
+}
+
+func TestIsRateLimitErr(t *testing.T) {
+	assert.True(t, isRateLimitErr(errors.Wrap(&openai.APIError{HTTPStatusCode: http.StatusTooManyRequests}, "wrap")))
+	assert.False(t, isRateLimitErr(&openai.APIError{HTTPStatusCode: http.StatusBadRequest}))
+	assert.False(t, isRateLimitErr(errors.New("other")))
+}
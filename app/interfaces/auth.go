@@ -0,0 +1,51 @@
+package interfaces
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/lupguo/copilot_develop/config"
+	"github.com/pkg/errors"
+)
+
+var (
+	errAdminAuth               = errors.New("invalid admin credentials")
+	errAdminTokenNotConfigured = errors.New("admin token is not configured")
+)
+
+// adminAuth 管理页面、/api接口的认证：Authorization: Bearer <server.admin_token>，
+// 或Basic认证(用户名server.admin_user、密码server.admin_token，便于浏览器访问管理页面)
+func adminAuth(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(ctx echo.Context) error {
+		switch err := verifyAdminAuth(ctx.Request(), config.GetServerConfig()); {
+		case errors.Is(err, errAdminTokenNotConfigured):
+			return echo.NewHTTPError(http.StatusServiceUnavailable, err.Error())
+		case err != nil:
+			ctx.Response().Header().Set(echo.HeaderWWWAuthenticate, `Basic realm="copilot_develop"`)
+			return echo.NewHTTPError(http.StatusUnauthorized, err.Error())
+		}
+		return next(ctx)
+	}
+}
+
+// verifyAdminAuth 校验管理接口的令牌，未配置admin_token时拒绝所有请求
+func verifyAdminAuth(req *http.Request, cfg *config.ServerConfig) error {
+	if cfg.AdminToken == "" {
+		return errAdminTokenNotConfigured
+	}
+	if user, password, ok := req.BasicAuth(); ok {
+		userOK := subtle.ConstantTimeCompare([]byte(user), []byte(cfg.AdminUser))
+		passwordOK := subtle.ConstantTimeCompare([]byte(password), []byte(cfg.AdminToken))
+		if userOK&passwordOK != 1 {
+			return errAdminAuth
+		}
+		return nil
+	}
+	got, ok := strings.CutPrefix(req.Header.Get(echo.HeaderAuthorization), "Bearer ")
+	if !ok || subtle.ConstantTimeCompare([]byte(got), []byte(cfg.AdminToken)) != 1 {
+		return errAdminAuth
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package interfaces
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/lupguo/copilot_develop/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCopilotDevelop_AdminAuth(t *testing.T) {
+	e, _ := newTestServer(t)
+	send := func(target string, auth func(req *http.Request)) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		if auth != nil {
+			auth(req)
+		}
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+	bearer := func(token string) func(req *http.Request) {
+		return func(req *http.Request) { req.Header.Set(echo.HeaderAuthorization, "Bearer "+token) }
+	}
+	basic := func(user, password string) func(req *http.Request) {
+		return func(req *http.Request) { req.SetBasicAuth(user, password) }
+	}
+
+	// /api、管理页面需要令牌，浏览器可通过Basic认证访问
+	for _, target := range []string{"/api/articles", "/api/stats", "/dashboard/", "/api/unknown"} {
+		rec := send(target, nil)
+		assert.Equal(t, http.StatusUnauthorized, rec.Code, target)
+		assert.Contains(t, rec.Header().Get(echo.HeaderWWWAuthenticate), "Basic", target)
+		assert.Equal(t, http.StatusUnauthorized, send(target, bearer("wrong")).Code, target)
+		assert.Equal(t, http.StatusUnauthorized, send(target, basic("guest", testAdminToken)).Code, target)
+	}
+	assert.Equal(t, http.StatusOK, send("/api/articles", bearer(testAdminToken)).Code)
+	assert.Equal(t, http.StatusOK, send("/api/articles", basic("admin", testAdminToken)).Code)
+	assert.Equal(t, http.StatusOK, send("/dashboard/", basic("admin", testAdminToken)).Code)
+	assert.Equal(t, http.StatusMovedPermanently, send("/dashboard", bearer(testAdminToken)).Code)
+
+	// 短链不需要认证
+	assert.Equal(t, http.StatusNotFound, send("/s/missing", nil).Code)
+
+	// 未配置令牌时拒绝所有请求
+	assert.ErrorIs(t, verifyAdminAuth(httptest.NewRequest(http.MethodGet, "/api/articles", nil), &config.ServerConfig{}), errAdminTokenNotConfigured)
+	req := httptest.NewRequest(http.MethodGet, "/api/articles", nil)
+	req.Header.Set(echo.HeaderAuthorization, "Bearer ")
+	assert.ErrorIs(t, verifyAdminAuth(req, &config.ServerConfig{AdminUser: "admin"}), errAdminTokenNotConfigured)
+}
@@ -0,0 +1,289 @@
+package interfaces
+
+import (
+	"encoding/json"
+
+	"github.com/lupguo/copilot_develop/app/domain/entity"
+)
+
+// ArticleListReq 文章列表/搜索请求
+type ArticleListReq struct {
+	Q        string `query:"q"`        // 搜索标题、关键字、摘要、描述
+	Category string `query:"category"` // 分类
+	Tag      string `query:"tag"`      // 标签
+	Draft    *bool  `query:"draft"`    // 是否手稿
+	Page     int    `query:"page"`
+	Size     int    `query:"size"`
+
+	MissingSummary bool `query:"missing_summary"` // 仅缺少摘要的文章
+	StaleSummary   bool `query:"stale_summary"`   // 仅摘要已过期的文章
+	PendingReview  bool `query:"pending_review"`  // 仅有待审核摘要的文章
+}
+
+// StatsReq 全站统计请求
+type StatsReq struct {
+	Format string `query:"format"` // json(默认)、markdown、table
+	Top    *int   `query:"top"`    // 各列表展示的条数，默认20，0为不限
+}
+
+// ArticleItem 文章列表项
+type ArticleItem struct {
+	ID          uint     `json:"id"`
+	Path        string   `json:"path"`
+	Title       string   `json:"title"`
+	Date        string   `json:"date"`
+	ShortMark   string   `json:"short_mark"`
+	Slug        string   `json:"slug,omitempty"`
+	Categories  []string `json:"categories"`
+	Tags        []string `json:"tags"`
+	Draft       bool     `json:"draft"`
+	Weight      int      `json:"weight"`
+	WordCount   int      `json:"word_count"`
+	Lang        string   `json:"lang,omitempty"`
+	Keywords    string   `json:"keywords"`
+	Description string   `json:"description"`
+	Missing     bool     `json:"missing_summary"`
+	Stale       bool     `json:"stale_summary"`
+	UpdatedAt   string   `json:"updated_at"`
+}
+
+// ArticleListResp 文章列表响应
+type ArticleListResp struct {
+	Total int64          `json:"total"`
+	Page  int            `json:"page"`
+	Size  int            `json:"size"`
+	Items []*ArticleItem `json:"items"`
+}
+
+// ArticleDetailResp 文章详情响应，包含摘要历史
+type ArticleDetailResp struct {
+	*ArticleItem
+	SEOTitle  string                `json:"seo_title,omitempty"`
+	Summary   string                `json:"summary"`
+	Aliases   []string              `json:"aliases"`
+	Histories []*SummaryHistoryItem `json:"histories"`
+}
+
+// SummaryHistoryItem 摘要历史版本
+type SummaryHistoryItem struct {
+	ID          uint   `json:"id"`
+	Lang        string `json:"lang"`
+	Keywords    string `json:"keywords"`
+	Summary     string `json:"summary"`
+	Description string `json:"description"`
+	Status      string `json:"status"`
+	CreatedAt   string `json:"created_at"`
+}
+
+// SummaryReviewItem 待审核的摘要
+type SummaryReviewItem struct {
+	Article *ArticleItem           `json:"article"`
+	Current *entity.ArticleSummary `json:"current"` // 文章当前的摘要
+	History *SummaryHistoryItem    `json:"history"` // 待审核的摘要版本
+}
+
+// ApproveSummaryReq 审核通过摘要历史版本请求
+type ApproveSummaryReq struct {
+	HistoryID uint `json:"history_id"`
+}
+
+// RejectSummaryReq 审核拒绝摘要历史版本请求
+type RejectSummaryReq struct {
+	HistoryID uint `json:"history_id"`
+}
+
+// EditSummaryReq 人工编辑摘要请求，history_id不为0时修改该待审核版本并审核通过，lang为空时为文章源语言
+type EditSummaryReq struct {
+	HistoryID   uint   `json:"history_id"`
+	Lang        string `json:"lang"`
+	Keywords    string `json:"keywords"`
+	Summary     string `json:"summary"`
+	Description string `json:"description"`
+}
+
+// JobReq 提交摘要任务请求，path为空时处理整个blog目录
+type JobReq struct {
+	Path string `json:"path"`
+}
+
+// WebhookResp push webhook响应，提交了摘要任务时返回任务
+type WebhookResp struct {
+	Message string   `json:"message"`
+	Job     *JobResp `json:"job,omitempty"`
+}
+
+// JobListReq 任务列表请求
+type JobListReq struct {
+	Limit int `query:"limit"`
+}
+
+// JobResp 摘要任务状态
+type JobResp struct {
+	ID         uint   `json:"id"`
+	Path       string `json:"path"`
+	Status     string `json:"status"`
+	Total      int    `json:"total"`
+	Done       int    `json:"done"`
+	Skipped    int    `json:"skipped"`
+	Failed     int    `json:"failed"`
+	CreatedAt  string `json:"created_at"`
+	UpdatedAt  string `json:"updated_at"`
+	FinishedAt string `json:"finished_at,omitempty"`
+}
+
+// JobDetailResp 摘要任务状态及文件明细
+type JobDetailResp struct {
+	*JobResp
+	Items []*JobItemResp `json:"items"`
+}
+
+// JobItemResp 任务内单个文件的处理状态
+type JobItemResp struct {
+	ID        uint   `json:"id"`
+	Path      string `json:"path"`
+	Status    string `json:"status"`
+	Reason    string `json:"reason,omitempty"`
+	Attempts  int    `json:"attempts"`
+	UpdatedAt string `json:"updated_at"`
+}
+
+// JobEventResp 摘要任务进度事件，作为SSE的data
+type JobEventResp struct {
+	JobID  uint     `json:"job_id"`
+	Type   string   `json:"type"`
+	Path   string   `json:"path,omitempty"`
+	Model  string   `json:"model,omitempty"`
+	Tokens int      `json:"tokens,omitempty"`
+	Reason string   `json:"reason,omitempty"`
+	Error  string   `json:"error,omitempty"`
+	Job    *JobResp `json:"job,omitempty"`
+	Time   string   `json:"time"`
+}
+
+// jsonStrings DB中按json数组存储的字段转成[]string
+func jsonStrings(s string) []string {
+	var strs []string
+	_ = json.Unmarshal([]byte(s), &strs)
+	if strs == nil {
+		strs = []string{}
+	}
+	return strs
+}
+
+func toArticleItem(a *entity.BlogArticle) *ArticleItem {
+	return &ArticleItem{
+		ID:          a.ID,
+		Path:        a.Path,
+		Title:       a.Title,
+		Date:        a.Date,
+		ShortMark:   a.ShortMark,
+		Slug:        a.Slug,
+		Categories:  jsonStrings(a.Categories),
+		Tags:        jsonStrings(a.Tags),
+		Draft:       a.Draft,
+		Weight:      a.Weight,
+		WordCount:   a.WordCount,
+		Lang:        a.Lang,
+		Keywords:    a.Keywords,
+		Description: a.Description,
+		Missing:     a.Summary == "",
+		Stale:       a.IsSummaryStale(),
+		UpdatedAt:   a.UpdatedAt,
+	}
+}
+
+func toArticleDetailResp(a *entity.BlogArticle, histories []*entity.BlogSummaryHistory) *ArticleDetailResp {
+	resp := &ArticleDetailResp{
+		ArticleItem: toArticleItem(a),
+		SEOTitle:    a.SEOTitle,
+		Summary:     a.Summary,
+		Aliases:     jsonStrings(a.Aliases),
+		Histories:   make([]*SummaryHistoryItem, 0, len(histories)),
+	}
+	for _, h := range histories {
+		resp.Histories = append(resp.Histories, toSummaryHistoryItem(h))
+	}
+	return resp
+}
+
+func toSummaryHistoryItem(h *entity.BlogSummaryHistory) *SummaryHistoryItem {
+	return &SummaryHistoryItem{
+		ID:          h.ID,
+		Lang:        h.Lang,
+		Keywords:    h.Keywords,
+		Summary:     h.Summary,
+		Description: h.Description,
+		Status:      string(summaryStatus(h)),
+		CreatedAt:   h.CreatedAt,
+	}
+}
+
+// summaryStatus 历史版本的审核状态，早期没有状态的记录视为approved
+func summaryStatus(h *entity.BlogSummaryHistory) entity.SummaryStatus {
+	if h.Status == "" {
+		return entity.SummaryApproved
+	}
+	return h.Status
+}
+
+func toSummaryReviewItem(r *entity.SummaryReview) *SummaryReviewItem {
+	return &SummaryReviewItem{
+		Article: toArticleItem(r.Article),
+		Current: &entity.ArticleSummary{
+			Keywords:    r.Article.Keywords,
+			Summary:     r.Article.Summary,
+			Description: r.Article.Description,
+		},
+		History: toSummaryHistoryItem(r.History),
+	}
+}
+
+func toJobResp(job *entity.SummaryJob) *JobResp {
+	return &JobResp{
+		ID:         job.ID,
+		Path:       job.Path,
+		Status:     string(job.Status),
+		Total:      job.Total,
+		Done:       job.Done,
+		Skipped:    job.Skipped,
+		Failed:     job.Failed,
+		CreatedAt:  job.CreatedAt,
+		UpdatedAt:  job.UpdatedAt,
+		FinishedAt: job.FinishedAt,
+	}
+}
+
+func toJobDetailResp(job *entity.SummaryJob, items []*entity.SummaryJobItem) *JobDetailResp {
+	resp := &JobDetailResp{
+		JobResp: toJobResp(job),
+		Items:   make([]*JobItemResp, 0, len(items)),
+	}
+	for _, item := range items {
+		resp.Items = append(resp.Items, &JobItemResp{
+			ID:        item.ID,
+			Path:      item.Path,
+			Status:    string(item.Status),
+			Reason:    item.Reason,
+			Attempts:  item.Attempts,
+			UpdatedAt: item.UpdatedAt,
+		})
+	}
+	return resp
+}
+
+func toJobEventResp(event *entity.JobEvent) *JobEventResp {
+	resp := &JobEventResp{
+		JobID:  event.JobID,
+		Type:   string(event.Type),
+		Path:   event.Path,
+		Model:  event.Model,
+		Tokens: event.Tokens,
+		Reason: event.Reason,
+		Error:  event.Error,
+		Time:   event.Time,
+	}
+	if event.Job != nil {
+		resp.Job = toJobResp(event.Job)
+	}
+	return resp
+}
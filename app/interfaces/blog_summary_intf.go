@@ -1,12 +1,365 @@
 package interfaces
 
 import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
 	"github.com/lupguo/copilot_develop/app/application"
+	"github.com/lupguo/copilot_develop/app/domain/entity"
+	"github.com/lupguo/copilot_develop/config"
+	"github.com/pkg/errors"
 )
 
 // CopilotDevelop 助手
 type CopilotDevelop struct {
 	blogSummaryApp *application.BlogSummaryApp
+	blogFeedApp    *application.BlogFeedApp
+	imageHostApp   *application.BlogImageHostApp
+}
+
+// NewCopilotDevelop 初始一个CopilotDevelop助手
+func NewCopilotDevelop(blogSummaryApp *application.BlogSummaryApp) *CopilotDevelop {
+	return &CopilotDevelop{
+		blogSummaryApp: blogSummaryApp,
+	}
+}
+
+// SetFeedApp 设置RSS/Atom订阅App，未设置时不轮询订阅
+func (c *CopilotDevelop) SetFeedApp(feedApp *application.BlogFeedApp) {
+	c.blogFeedApp = feedApp
+}
+
+// SetImageHostApp 设置图床App，未设置时图床接口返回503
+func (c *CopilotDevelop) SetImageHostApp(imageHostApp *application.BlogImageHostApp) {
+	c.imageHostApp = imageHostApp
+}
+
+// RegisterRoutes 注册HTTP路由
+func (c *CopilotDevelop) RegisterRoutes(e *echo.Echo) {
+	// 短链解析
+	e.GET("/s/:mark", c.ResolveShortLink)
+
+	// 内嵌的管理页面
+	registerDashboard(e, adminAuth)
+
+	// git推送webhook
+	e.POST("/webhooks/push", c.PushWebhook)
+
+	// 图床图片
+	e.GET("/i/*", c.ServeImage)
+
+	// 图片上传使用image_host.token单独认证，便于截图工具直接上传
+	e.POST("/api/images", c.UploadImages)
+
+	api := e.Group("/api", adminAuth)
+	api.GET("/articles", c.ListArticles)
+	api.GET("/articles/:id", c.GetArticle)
+	api.POST("/articles/:id/approve", c.ApproveSummary)
+	api.POST("/articles/:id/reject", c.RejectSummary)
+	api.PUT("/articles/:id/summary", c.EditSummary)
+	api.POST("/articles/:id/regenerate", c.RegenerateSummary)
+	api.GET("/reviews", c.ListPendingSummaries)
+	api.GET("/stats", c.GetStats)
+	api.POST("/jobs", c.SubmitJob)
+	api.GET("/jobs", c.ListJobs)
+	api.GET("/jobs/:id", c.GetJob)
+	api.GET("/jobs/:id/events", c.JobEvents)
+	api.GET("/images", c.ListImages)
+}
+
+// StartFeedPoller 按配置的间隔轮询RSS/Atom订阅，未设置订阅App或未配置订阅地址时直接返回
+func (c *CopilotDevelop) StartFeedPoller(ctx context.Context) {
+	cfg := config.GetFeedsConfig()
+	if c.blogFeedApp == nil || len(cfg.Urls) == 0 {
+		return
+	}
+	c.blogFeedApp.StartFeedPoller(ctx, cfg.Urls, time.Duration(cfg.IntervalMinutes)*time.Minute)
+}
+
+// StartJobWorkers 启动后台摘要任务worker，直到ctx结束
+func (c *CopilotDevelop) StartJobWorkers(ctx context.Context) {
+	c.blogSummaryApp.StartJobWorkers(ctx)
+}
+
+// ListArticles 文章列表及搜索 GET /api/articles?q=&category=&tag=&draft=&page=&size=&missing_summary=&stale_summary=&pending_review=
+func (c *CopilotDevelop) ListArticles(ctx echo.Context) error {
+	req := &ArticleListReq{}
+	if err := ctx.Bind(req); err != nil {
+		return err
+	}
+
+	query := &entity.ArticleQuery{
+		Keyword:  req.Q,
+		Category: req.Category,
+		Tag:      req.Tag,
+		Draft:    req.Draft,
+		Page:     req.Page,
+		Size:     req.Size,
+
+		MissingSummary: req.MissingSummary,
+		StaleSummary:   req.StaleSummary,
+		PendingReview:  req.PendingReview,
+	}
+	articles, total, err := c.blogSummaryApp.ListArticles(ctx.Request().Context(), query)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	resp := &ArticleListResp{
+		Total: total,
+		Page:  query.Page,
+		Size:  query.Size,
+		Items: make([]*ArticleItem, 0, len(articles)),
+	}
+	for _, article := range articles {
+		resp.Items = append(resp.Items, toArticleItem(article))
+	}
+	return ctx.JSON(http.StatusOK, resp)
+}
+
+// GetArticle 文章元信息及摘要历史 GET /api/articles/:id
+func (c *CopilotDevelop) GetArticle(ctx echo.Context) error {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid article id")
+	}
+
+	article, histories, err := c.blogSummaryApp.GetArticle(ctx.Request().Context(), uint(id))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	if article == nil {
+		return echo.NewHTTPError(http.StatusNotFound, "article not found")
+	}
+
+	return ctx.JSON(http.StatusOK, toArticleDetailResp(article, histories))
+}
+
+// ApproveSummary 审核通过摘要历史版本并回写文章 POST /api/articles/:id/approve {"history_id": 1}
+func (c *CopilotDevelop) ApproveSummary(ctx echo.Context) error {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid article id")
+	}
+	req := &ApproveSummaryReq{}
+	if err = ctx.Bind(req); err != nil {
+		return err
+	}
+
+	article, err := c.blogSummaryApp.ApproveSummary(ctx.Request().Context(), uint(id), req.HistoryID)
+	if err != nil {
+		return summaryHTTPError(err)
+	}
+	return ctx.JSON(http.StatusOK, toArticleItem(article))
+}
+
+// RejectSummary 审核拒绝摘要历史版本 POST /api/articles/:id/reject {"history_id": 1}
+func (c *CopilotDevelop) RejectSummary(ctx echo.Context) error {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid article id")
+	}
+	req := &RejectSummaryReq{}
+	if err = ctx.Bind(req); err != nil {
+		return err
+	}
+
+	if err = c.blogSummaryApp.RejectSummary(ctx.Request().Context(), uint(id), req.HistoryID); err != nil {
+		return summaryHTTPError(err)
+	}
+	return ctx.NoContent(http.StatusNoContent)
+}
+
+// ListPendingSummaries 待审核的摘要 GET /api/reviews
+func (c *CopilotDevelop) ListPendingSummaries(ctx echo.Context) error {
+	reviews, err := c.blogSummaryApp.ListPendingSummaries(ctx.Request().Context())
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	resp := make([]*SummaryReviewItem, 0, len(reviews))
+	for _, review := range reviews {
+		resp = append(resp, toSummaryReviewItem(review))
+	}
+	return ctx.JSON(http.StatusOK, resp)
+}
+
+// EditSummary 人工编辑摘要并回写文章 PUT /api/articles/:id/summary
+func (c *CopilotDevelop) EditSummary(ctx echo.Context) error {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid article id")
+	}
+	req := &EditSummaryReq{}
+	if err = ctx.Bind(req); err != nil {
+		return err
+	}
+
+	summary := &entity.ArticleSummary{Keywords: req.Keywords, Summary: req.Summary, Description: req.Description}
+	article, err := c.blogSummaryApp.EditSummary(ctx.Request().Context(), uint(id), req.HistoryID, req.Lang, summary)
+	if err != nil {
+		return summaryHTTPError(err)
+	}
+	return ctx.JSON(http.StatusOK, toArticleItem(article))
+}
+
+// RegenerateSummary 重新生成摘要(仅新增历史版本，需审核通过后回写) POST /api/articles/:id/regenerate
+func (c *CopilotDevelop) RegenerateSummary(ctx echo.Context) error {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid article id")
+	}
+
+	history, err := c.blogSummaryApp.RegenerateSummary(ctx.Request().Context(), uint(id))
+	if err != nil {
+		return summaryHTTPError(err)
+	}
+	return ctx.JSON(http.StatusCreated, toSummaryHistoryItem(history))
+}
+
+// summaryHTTPError 摘要审核相关错误转换为HTTP错误
+func summaryHTTPError(err error) error {
+	switch {
+	case errors.Is(err, application.ErrArticleNotFound), errors.Is(err, application.ErrSummaryHistoryNotFound):
+		return echo.NewHTTPError(http.StatusNotFound, err.Error())
+	case errors.Is(err, entity.ErrMDContentChanged):
+		return echo.NewHTTPError(http.StatusConflict, err.Error())
+	default:
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+}
+
+// SubmitJob 提交摘要任务 POST /api/jobs {"path": "..."}，path为空时处理整个blog目录，由后台worker异步处理
+func (c *CopilotDevelop) SubmitJob(ctx echo.Context) error {
+	req := &JobReq{}
+	if err := ctx.Bind(req); err != nil {
+		return err
+	}
+
+	job, err := c.blogSummaryApp.SubmitSummaryJob(ctx.Request().Context(), config.GetBlogPath(), req.Path)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	return ctx.JSON(http.StatusAccepted, toJobResp(job))
+}
+
+// GetStats 全站内容统计及健康报告 GET /api/stats?format=json|markdown|table&top=20
+func (c *CopilotDevelop) GetStats(ctx echo.Context) error {
+	req := &StatsReq{}
+	if err := ctx.Bind(req); err != nil {
+		return err
+	}
+	format := entity.StatsFormatJSON
+	if req.Format != "" {
+		var err error
+		if format, err = entity.ParseStatsFormat(req.Format); err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		}
+	}
+	top := entity.DefaultStatsTop
+	if req.Top != nil {
+		top = *req.Top
+	}
+
+	stats, err := c.blogSummaryApp.GetBlogStats(ctx.Request().Context(), top)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	switch format {
+	case entity.StatsFormatJSON:
+		return ctx.JSON(http.StatusOK, stats)
+	case entity.StatsFormatMarkdown:
+		ctx.Response().Header().Set(echo.HeaderContentType, "text/markdown; charset=utf-8")
+	default:
+		ctx.Response().Header().Set(echo.HeaderContentType, echo.MIMETextPlainCharsetUTF8)
+	}
+	ctx.Response().WriteHeader(http.StatusOK)
+	return stats.Write(ctx.Response(), format)
+}
+
+// ListJobs 最近的摘要任务 GET /api/jobs?limit=
+func (c *CopilotDevelop) ListJobs(ctx echo.Context) error {
+	req := &JobListReq{}
+	if err := ctx.Bind(req); err != nil {
+		return err
+	}
+
+	jobs, err := c.blogSummaryApp.ListJobs(ctx.Request().Context(), req.Limit)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	resp := make([]*JobResp, 0, len(jobs))
+	for _, job := range jobs {
+		resp = append(resp, toJobResp(job))
+	}
+	return ctx.JSON(http.StatusOK, resp)
+}
+
+// GetJob 查询摘要任务状态及文件明细 GET /api/jobs/:id
+func (c *CopilotDevelop) GetJob(ctx echo.Context) error {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid job id")
+	}
+
+	job, items, err := c.blogSummaryApp.GetJob(ctx.Request().Context(), uint(id))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	if job == nil {
+		return echo.NewHTTPError(http.StatusNotFound, "job not found")
+	}
+	return ctx.JSON(http.StatusOK, toJobDetailResp(job, items))
+}
+
+// JobEvents 摘要任务进度事件流(SSE) GET /api/jobs/:id/events，先回放已处理文件的状态，任务结束时以summary事件结束
+func (c *CopilotDevelop) JobEvents(ctx echo.Context) error {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid job id")
+	}
+
+	events, err := c.blogSummaryApp.WatchJob(ctx.Request().Context(), uint(id))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusNotFound, err.Error())
+	}
+
+	w := ctx.Response()
+	w.Header().Set(echo.HeaderContentType, "text/event-stream")
+	w.Header().Set(echo.HeaderCacheControl, "no-cache")
+	w.Header().Set(echo.HeaderConnection, "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	for event := range events {
+		data, err := json.Marshal(toJobEventResp(event))
+		if err != nil {
+			return err
+		}
+		if _, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Type, data); err != nil {
+			return err
+		}
+		w.Flush()
+	}
+	return nil
+}
+
+// ResolveShortLink 短链解析 GET /s/:mark，重定向到文章的permalink
+func (c *CopilotDevelop) ResolveShortLink(ctx echo.Context) error {
+	mark := ctx.Param("mark")
+	permalink, err := c.blogSummaryApp.ResolveShortMark(ctx.Request().Context(), mark)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	if permalink == "" {
+		return echo.NewHTTPError(http.StatusNotFound, "short mark not found")
+	}
+
+	return ctx.Redirect(http.StatusFound, permalink)
 }
 
 // UpdateBlogSummary 更新BlogSummary信息
@@ -0,0 +1,220 @@
+package interfaces
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/lupguo/copilot_develop/app/application"
+	"github.com/lupguo/copilot_develop/app/domain/entity"
+	"github.com/lupguo/copilot_develop/app/infras/dbs"
+	"github.com/lupguo/copilot_develop/config"
+	"github.com/stretchr/testify/assert"
+)
+
+const testAdminToken = "admin-token"
+
+// newTestServer 基于临时sqlite构建echo服务，管理接口的令牌为testAdminToken
+func newTestServer(t *testing.T) (*echo.Echo, *dbs.BlogSummarySqliteInfra) {
+	ctx := context.Background()
+	root := t.TempDir()
+	confFile := filepath.Join(t.TempDir(), "config.yaml")
+	assert.NoError(t, os.WriteFile(confFile, []byte(fmt.Sprintf(`app:
+  root_path: %s
+  openai_proxy: {}
+  blog_summary:
+    blog_path: %s
+  server:
+    admin_token: %s
+`, root, root, testAdminToken)), 0644))
+	assert.NoError(t, config.ParseConfig(confFile))
+
+	infra, err := dbs.NewBlogSummarySqliteInfra(filepath.Join(t.TempDir(), "blog_summary.db"))
+	assert.NoError(t, err)
+	assert.NoError(t, infra.InitBlogSummaryDB(ctx))
+
+	e := echo.New()
+	NewCopilotDevelop(application.NewBlogSummaryApp(nil, infra)).RegisterRoutes(e)
+	return e, infra
+}
+
+func doRequest(e *echo.Echo, method, target string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(method, target, nil)
+	req.Header.Set(echo.HeaderAuthorization, "Bearer "+testAdminToken)
+	e.ServeHTTP(rec, req)
+	return rec
+}
+
+func doJSONRequest(e *echo.Echo, method, target, body string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	req.Header.Set(echo.HeaderAuthorization, "Bearer "+testAdminToken)
+	e.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestCopilotDevelop_Articles(t *testing.T) {
+	e, infra := newTestServer(t)
+	ctx := context.Background()
+	for _, md := range []*entity.BlogMD{
+		{Filepath: "/content/posts/go.md", MDHeader: &entity.YamlHeader{Title: "Go并发", Date: "2023-08-01", Tags: []string{"golang"}, Summary: "goroutine"}},
+		{Filepath: "/content/posts/draft.md", MDHeader: &entity.YamlHeader{Title: "草稿", Date: "2023-08-02", Draft: true}},
+	} {
+		assert.NoError(t, infra.AddBlogMDRecord(ctx, md))
+	}
+	assert.NoError(t, infra.AddSummaryHistory(ctx, &entity.BlogSummaryHistory{Path: "/content/posts/go.md", Summary: "old"}))
+
+	// 列表 + 过滤
+	rec := doRequest(e, http.MethodGet, "/api/articles?draft=false&tag=golang")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	list := &ArticleListResp{}
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), list))
+	assert.Equal(t, int64(1), list.Total)
+	assert.Equal(t, []string{"golang"}, list.Items[0].Tags)
+
+	// 搜索
+	rec = doRequest(e, http.MethodGet, "/api/articles?q=goroutine")
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), list))
+	assert.Equal(t, "Go并发", list.Items[0].Title)
+
+	// 详情及摘要历史
+	rec = doRequest(e, http.MethodGet, "/api/articles/"+jsonID(list.Items[0].ID))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	detail := &ArticleDetailResp{}
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), detail))
+	assert.Equal(t, "goroutine", detail.Summary)
+	assert.Equal(t, "old", detail.Histories[0].Summary)
+
+	assert.Equal(t, http.StatusNotFound, doRequest(e, http.MethodGet, "/api/articles/999").Code)
+}
+
+func TestCopilotDevelop_Jobs(t *testing.T) {
+	e, _ := newTestServer(t)
+
+	rec := doRequest(e, http.MethodGet, "/api/jobs")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, "[]", rec.Body.String())
+
+	assert.Equal(t, http.StatusBadRequest, doRequest(e, http.MethodGet, "/api/jobs/none").Code)
+	assert.Equal(t, http.StatusNotFound, doRequest(e, http.MethodGet, "/api/jobs/999").Code)
+	assert.Equal(t, http.StatusNotFound, doRequest(e, http.MethodGet, "/api/jobs/999/events").Code)
+}
+
+func TestCopilotDevelop_JobEvents(t *testing.T) {
+	e, infra := newTestServer(t)
+	ctx := context.Background()
+	job := &entity.SummaryJob{Path: "/content/posts"}
+	assert.NoError(t, infra.AddJob(ctx, job, []string{"/content/posts/go.md"}))
+	item, err := infra.ClaimJobItems(ctx, job.ID, 1)
+	assert.NoError(t, err)
+	item[0].Status, item[0].Reason = entity.JobItemSkipped, "needn't update"
+	assert.NoError(t, infra.UpdateJobItem(ctx, item[0]))
+	_, _, err = infra.RefreshJobStats(ctx, job.ID)
+	assert.NoError(t, err)
+
+	// 已结束的任务：回放文件状态后以summary事件结束
+	rec := doRequest(e, http.MethodGet, "/api/jobs/"+jsonID(job.ID)+"/events")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "text/event-stream", rec.Header().Get(echo.HeaderContentType))
+	body := rec.Body.String()
+	assert.Contains(t, body, "event: skipped\ndata: {\"job_id\":1,\"type\":\"skipped\",\"path\":\"/content/posts/go.md\",\"reason\":\"needn't update\"")
+	assert.Contains(t, body, "event: summary\n")
+	assert.Contains(t, body, "\"status\":\"succeeded\"")
+}
+
+func jsonID(id uint) string {
+	b, _ := json.Marshal(id)
+	return string(b)
+}
+
+func TestCopilotDevelop_Dashboard(t *testing.T) {
+	e, _ := newTestServer(t)
+
+	assert.Equal(t, http.StatusMovedPermanently, doRequest(e, http.MethodGet, "/dashboard").Code)
+	rec := doRequest(e, http.MethodGet, "/dashboard/")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "Blog Summary Dashboard")
+	assert.Equal(t, http.StatusOK, doRequest(e, http.MethodGet, "/dashboard/app.js").Code)
+
+	assert.Equal(t, http.StatusNotFound, doRequest(e, http.MethodPost, "/api/articles/999/approve").Code)
+	assert.Equal(t, http.StatusNotFound, doRequest(e, http.MethodPost, "/api/articles/999/regenerate").Code)
+	assert.Equal(t, http.StatusBadRequest, doRequest(e, http.MethodPut, "/api/articles/999/summary").Code)
+}
+
+func TestCopilotDevelop_Reviews(t *testing.T) {
+	e, infra := newTestServer(t)
+	ctx := context.Background()
+	for _, md := range []*entity.BlogMD{
+		{Filepath: "/content/posts/go.md", MDHeader: &entity.YamlHeader{Title: "Go并发", Date: "2023-08-01"}},
+		{Filepath: "/content/posts/rust.md", MDHeader: &entity.YamlHeader{Title: "Rust", Date: "2023-08-02"}},
+	} {
+		assert.NoError(t, infra.AddBlogMDRecord(ctx, md))
+	}
+	pending := &entity.BlogSummaryHistory{Path: "/content/posts/go.md", Summary: "new", Status: entity.SummaryPending}
+	assert.NoError(t, infra.AddSummaryHistory(ctx, pending))
+	assert.NoError(t, infra.AddSummaryHistory(ctx, &entity.BlogSummaryHistory{Path: "/content/posts/rust.md", Summary: "old"}))
+
+	// 待审核列表
+	rec := doRequest(e, http.MethodGet, "/api/reviews")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	var reviews []*SummaryReviewItem
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &reviews))
+	assert.Len(t, reviews, 1)
+	assert.Equal(t, "Go并发", reviews[0].Article.Title)
+	assert.Equal(t, string(entity.SummaryPending), reviews[0].History.Status)
+
+	list := &ArticleListResp{}
+	rec = doRequest(e, http.MethodGet, "/api/articles?pending_review=true")
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), list))
+	assert.Equal(t, int64(1), list.Total)
+
+	// 拒绝后不再待审核
+	articleID := jsonID(reviews[0].Article.ID)
+	body := `{"history_id": ` + jsonID(pending.ID) + `}`
+	assert.Equal(t, http.StatusNotFound, doJSONRequest(e, http.MethodPost, "/api/articles/999/reject", body).Code)
+	assert.Equal(t, http.StatusNoContent, doJSONRequest(e, http.MethodPost, "/api/articles/"+articleID+"/reject", body).Code)
+	rec = doRequest(e, http.MethodGet, "/api/reviews")
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &reviews))
+	assert.Empty(t, reviews)
+
+	detail := &ArticleDetailResp{}
+	rec = doRequest(e, http.MethodGet, "/api/articles/"+articleID)
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), detail))
+	assert.Equal(t, string(entity.SummaryRejected), detail.Histories[0].Status)
+}
+
+func TestCopilotDevelop_Stats(t *testing.T) {
+	e, infra := newTestServer(t)
+	ctx := context.Background()
+	for _, md := range []*entity.BlogMD{
+		{Filepath: "/content/posts/go.md", MDHeader: &entity.YamlHeader{Title: "Go并发", Date: "2023-08-01", Tags: []string{"golang"}, Summary: "goroutine", WordCounts: 1200}},
+		{Filepath: "/content/posts/draft.md", MDHeader: &entity.YamlHeader{Title: "草稿", Date: "2023-08-02", Draft: true}},
+	} {
+		assert.NoError(t, infra.AddBlogMDRecord(ctx, md))
+	}
+
+	rec := doRequest(e, http.MethodGet, "/api/stats")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	stats := &entity.BlogStats{}
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), stats))
+	assert.Equal(t, 2, stats.Posts)
+	assert.Equal(t, 1, stats.Drafts)
+	assert.Equal(t, []*entity.StatCount{{Key: "2023-08", Count: 1}}, stats.PostsByMonth)
+	assert.Equal(t, 1, stats.MissingKeywords.Total)
+
+	rec = doRequest(e, http.MethodGet, "/api/stats?format=markdown&top=0")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "text/markdown; charset=utf-8", rec.Header().Get(echo.HeaderContentType))
+	assert.Contains(t, rec.Body.String(), "| golang | 1 |")
+
+	assert.Equal(t, http.StatusBadRequest, doRequest(e, http.MethodGet, "/api/stats?format=xml").Code)
+}
@@ -0,0 +1,22 @@
+package interfaces
+
+import (
+	"embed"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+// dashboardAssets 管理页面静态资源，编译时内嵌
+//
+//go:embed dashboard
+var dashboardAssets embed.FS
+
+// registerDashboard 注册管理页面 /dashboard/，页面数据通过 /api 接口获取，与 /api 使用相同的认证
+func registerDashboard(e *echo.Echo, m ...echo.MiddlewareFunc) {
+	g := e.Group("/dashboard", m...)
+	g.GET("", func(ctx echo.Context) error {
+		return ctx.Redirect(http.StatusMovedPermanently, "/dashboard/")
+	})
+	g.StaticFS("/", echo.MustSubFS(dashboardAssets, "dashboard"))
+}
@@ -0,0 +1,199 @@
+// Blog Summary Dashboard: 文章列表、摘要历史、审核(通过/拒绝)/编辑/重新生成
+(function () {
+  'use strict';
+
+  const pageSize = 20;
+  const state = {page: 1, total: 0, query: {}, article: null};
+  const $ = (id) => document.getElementById(id);
+
+  async function api(method, url, body) {
+    const opts = {method: method, headers: {}};
+    if (body !== undefined) {
+      opts.headers['Content-Type'] = 'application/json';
+      opts.body = JSON.stringify(body);
+    }
+    const resp = await fetch(url, opts);
+    const data = await resp.json().catch(() => ({}));
+    if (!resp.ok) {
+      throw new Error(data.message || resp.statusText);
+    }
+    return data;
+  }
+
+  function badges(article) {
+    const out = [];
+    if (article.draft) {
+      out.push('<span class="badge">草稿</span>');
+    }
+    if (article.missing_summary) {
+      out.push('<span class="badge missing">缺少摘要</span>');
+    }
+    if (article.stale_summary) {
+      out.push('<span class="badge stale">摘要过期</span>');
+    }
+    return out.join('');
+  }
+
+  const statusNames = {pending: '待审核', approved: '已通过', rejected: '已拒绝'};
+
+  function escapeHTML(s) {
+    const div = document.createElement('div');
+    div.textContent = s == null ? '' : String(s);
+    return div.innerHTML;
+  }
+
+  async function loadArticles() {
+    const params = new URLSearchParams(state.query);
+    params.set('page', state.page);
+    params.set('size', pageSize);
+    const data = await api('GET', '/api/articles?' + params.toString());
+    state.total = data.total;
+
+    const rows = data.items.map((a) => `<tr data-id="${a.id}">
+      <td>${escapeHTML(a.title)}</td>
+      <td>${escapeHTML(a.date)}</td>
+      <td>${escapeHTML(a.categories.join(', '))}</td>
+      <td>${badges(a)}</td>
+    </tr>`);
+    $('articles').innerHTML = rows.join('');
+
+    const pages = Math.max(1, Math.ceil(state.total / pageSize));
+    $('page-info').textContent = `${state.page} / ${pages}，共 ${state.total} 篇`;
+    $('prev').disabled = state.page <= 1;
+    $('next').disabled = state.page >= pages;
+  }
+
+  async function loadArticle(id) {
+    const article = await api('GET', '/api/articles/' + id);
+    state.article = article;
+
+    $('detail').hidden = false;
+    $('detail-title').textContent = article.title;
+    $('detail-meta').textContent = `${article.path} · ${article.lang || '-'} · ${article.word_count} 字`;
+    showNotice(article.stale_summary ? '正文在摘要生成后有修改，摘要可能已过期' : '');
+
+    const form = $('edit');
+    form.keywords.value = article.keywords;
+    form.description.value = article.description;
+    form.summary.value = article.summary;
+
+    const list = $('histories');
+    list.innerHTML = '';
+    article.histories.forEach((h) => list.appendChild(renderHistory(h)));
+
+    document.querySelectorAll('#articles tr').forEach((tr) => {
+      tr.classList.toggle('active', tr.dataset.id === String(id));
+    });
+  }
+
+  function renderHistory(h) {
+    const li = $('history-tpl').content.firstElementChild.cloneNode(true);
+    li.querySelector('.time').textContent = h.created_at;
+    li.querySelector('.lang').textContent = h.lang;
+    li.querySelector('.keywords').textContent = h.keywords;
+    li.querySelector('.description').textContent = h.description;
+    li.querySelector('.summary').textContent = h.summary;
+    const status = li.querySelector('.status');
+    status.textContent = statusNames[h.status] || h.status;
+    status.classList.add(h.status);
+    li.querySelector('.reject').hidden = h.status !== 'pending';
+    li.querySelector('.reject').addEventListener('click', () => run(async () => {
+      await api('POST', `/api/articles/${state.article.id}/reject`, {history_id: h.id});
+      await refresh();
+    }));
+    li.querySelector('.approve').addEventListener('click', () => run(async () => {
+      await api('POST', `/api/articles/${state.article.id}/approve`, {history_id: h.id});
+      await refresh();
+    }));
+    li.querySelector('.use').addEventListener('click', () => {
+      const form = $('edit');
+      form.keywords.value = h.keywords;
+      form.description.value = h.description;
+      form.summary.value = h.summary;
+      form.dataset.lang = h.lang;
+      // 编辑待审核版本，保存时该版本审核通过
+      if (h.status === 'pending') {
+        form.dataset.historyId = h.id;
+      } else {
+        delete form.dataset.historyId;
+      }
+    });
+    return li;
+  }
+
+  function showNotice(msg) {
+    $('detail-notice').hidden = !msg;
+    $('detail-notice').textContent = msg;
+  }
+
+  async function refresh() {
+    const id = state.article.id;
+    await Promise.all([loadArticles(), loadArticle(id)]);
+  }
+
+  async function run(fn) {
+    try {
+      await fn();
+    } catch (err) {
+      showNotice(err.message);
+    }
+  }
+
+  $('filters').addEventListener('submit', (e) => {
+    e.preventDefault();
+    const query = {};
+    new FormData(e.target).forEach((v, k) => {
+      if (v !== '') {
+        query[k] = v;
+      }
+    });
+    state.query = query;
+    state.page = 1;
+    run(loadArticles);
+  });
+
+  $('articles').addEventListener('click', (e) => {
+    const tr = e.target.closest('tr');
+    if (tr) {
+      run(() => loadArticle(tr.dataset.id));
+    }
+  });
+
+  $('prev').addEventListener('click', () => {
+    state.page--;
+    run(loadArticles);
+  });
+  $('next').addEventListener('click', () => {
+    state.page++;
+    run(loadArticles);
+  });
+
+  $('edit').addEventListener('submit', (e) => {
+    e.preventDefault();
+    const form = e.target;
+    run(async () => {
+      await api('PUT', `/api/articles/${state.article.id}/summary`, {
+        history_id: Number(form.dataset.historyId || 0),
+        lang: form.dataset.lang || '',
+        keywords: form.keywords.value,
+        description: form.description.value,
+        summary: form.summary.value,
+      });
+      delete form.dataset.lang;
+      delete form.dataset.historyId;
+      await refresh();
+    });
+  });
+
+  $('regenerate').addEventListener('click', (e) => {
+    e.target.disabled = true;
+    run(async () => {
+      await api('POST', `/api/articles/${state.article.id}/regenerate`);
+      await loadArticle(state.article.id);
+    }).finally(() => {
+      e.target.disabled = false;
+    });
+  });
+
+  run(loadArticles);
+})();
@@ -0,0 +1,257 @@
+package interfaces
+
+import (
+	"crypto/subtle"
+	"io"
+	"io/fs"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/lupguo/copilot_develop/app/application"
+	"github.com/lupguo/copilot_develop/app/domain/entity"
+	"github.com/lupguo/copilot_develop/config"
+	"github.com/pkg/errors"
+)
+
+// imageUploadFormOverhead multipart请求体中表单字段、分隔符等图片以外内容的上限
+const imageUploadFormOverhead = 1 << 20
+
+var (
+	errUploadToken              = errors.New("invalid upload token")
+	errUploadTokenNotConfigured = errors.New("upload token is not configured")
+)
+
+// ImageListReq 图床图片列表请求
+type ImageListReq struct {
+	Limit int `query:"limit"` // 默认20
+}
+
+// ImageVariantResp 图片的缩放、WebP版本
+type ImageVariantResp struct {
+	Name     string `json:"name"`
+	URL      string `json:"url"`
+	MimeType string `json:"mime_type"`
+	Width    int    `json:"width"`
+	Height   int    `json:"height"`
+	Size     int    `json:"size"`
+}
+
+// ImageResp 图床中的图片
+type ImageResp struct {
+	Hash      string              `json:"hash"`
+	Name      string              `json:"name"`
+	URL       string              `json:"url"`
+	Markdown  string              `json:"markdown"` // 可直接粘贴到文章的Markdown图片引用
+	MimeType  string              `json:"mime_type"`
+	Width     int                 `json:"width"`
+	Height    int                 `json:"height"`
+	Size      int                 `json:"size"`
+	Duplicate bool                `json:"duplicate"` // 相同内容的图片已上传过
+	CreatedAt string              `json:"created_at"`
+	Variants  []*ImageVariantResp `json:"variants"`
+}
+
+// ImageUploadResp 图片上传结果
+type ImageUploadResp struct {
+	Images   []*ImageResp `json:"images"`
+	Markdown string       `json:"markdown"` // 各图片的Markdown引用，一行一个
+}
+
+// imageUpload 请求中的一张图片
+type imageUpload struct {
+	name string
+	alt  string
+	data []byte
+}
+
+// UploadImages 上传图片到图床 POST /api/images：multipart表单的file字段(可多个，alt字段为alt文字)，
+// 或请求体为图片内容(?name=&alt=，例如截图工具直接上传)；需要 Authorization: Bearer <image_host.token>，未配置令牌时拒绝上传
+func (c *CopilotDevelop) UploadImages(ctx echo.Context) error {
+	if c.imageHostApp == nil {
+		return uploadHTTPError(application.ErrImageHostNotConfigured)
+	}
+	cfg := config.GetImageHostConfig()
+	req := ctx.Request()
+	switch err := verifyUploadToken(req.Header, cfg.Token); {
+	case errors.Is(err, errUploadTokenNotConfigured):
+		return echo.NewHTTPError(http.StatusServiceUnavailable, err.Error())
+	case err != nil:
+		return echo.NewHTTPError(http.StatusUnauthorized, err.Error())
+	}
+	maxBytes := int64(cfg.MaxSizeMB) << 20
+	req.Body = http.MaxBytesReader(ctx.Response(), req.Body, maxBytes+imageUploadFormOverhead)
+
+	var uploads []*imageUpload
+	if strings.HasPrefix(req.Header.Get(echo.HeaderContentType), echo.MIMEMultipartForm) {
+		form, err := ctx.MultipartForm()
+		if err != nil {
+			return uploadHTTPError(err)
+		}
+		if len(form.File["file"]) == 0 {
+			return echo.NewHTTPError(http.StatusBadRequest, "multipart form needs file field")
+		}
+		for _, fh := range form.File["file"] {
+			data, err := readMultipartFile(fh)
+			if err != nil {
+				return uploadHTTPError(err)
+			}
+			uploads = append(uploads, &imageUpload{name: fh.Filename, alt: ctx.FormValue("alt"), data: data})
+		}
+	} else {
+		data, err := io.ReadAll(req.Body)
+		if err != nil {
+			return uploadHTTPError(err)
+		}
+		if len(data) == 0 {
+			return echo.NewHTTPError(http.StatusBadRequest, "empty image body")
+		}
+		uploads = append(uploads, &imageUpload{name: ctx.QueryParam("name"), alt: ctx.QueryParam("alt"), data: data})
+	}
+
+	// 全部图片都已上传过时返回200，否则返回201
+	status := http.StatusOK
+	resp := &ImageUploadResp{Images: make([]*ImageResp, 0, len(uploads))}
+	var markdowns []string
+	for _, upload := range uploads {
+		uploaded, err := c.imageHostApp.UploadImage(req.Context(), path.Base("/"+upload.name), upload.data)
+		if err != nil {
+			return uploadHTTPError(err)
+		}
+		if !uploaded.Duplicate {
+			status = http.StatusCreated
+		}
+		item := c.toImageResp(uploaded, upload.alt)
+		resp.Images = append(resp.Images, item)
+		markdowns = append(markdowns, item.Markdown)
+	}
+	resp.Markdown = strings.Join(markdowns, "\n")
+	return ctx.JSON(status, resp)
+}
+
+// ListImages 最近上传的图片 GET /api/images?limit=
+func (c *CopilotDevelop) ListImages(ctx echo.Context) error {
+	if c.imageHostApp == nil {
+		return uploadHTTPError(application.ErrImageHostNotConfigured)
+	}
+	req := &ImageListReq{}
+	if err := ctx.Bind(req); err != nil {
+		return err
+	}
+	if req.Limit <= 0 {
+		req.Limit = 20
+	}
+
+	images, err := c.imageHostApp.ListImages(ctx.Request().Context(), req.Limit)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	resp := make([]*ImageResp, 0, len(images))
+	for _, img := range images {
+		resp = append(resp, c.toImageResp(img, ""))
+	}
+	return ctx.JSON(http.StatusOK, resp)
+}
+
+// ServeImage 图床中的图片 GET /i/*，按内容寻址的图片不会变化，可长期缓存
+func (c *CopilotDevelop) ServeImage(ctx echo.Context) error {
+	if c.imageHostApp == nil {
+		return uploadHTTPError(application.ErrImageHostNotConfigured)
+	}
+	key := ctx.Param("*")
+	rc, err := c.imageHostApp.OpenImage(ctx.Request().Context(), key)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		return echo.NewHTTPError(http.StatusNotFound, "image not found")
+	case err != nil:
+		return uploadHTTPError(err)
+	}
+	defer rc.Close()
+
+	contentType := mime.TypeByExtension(path.Ext(key))
+	if contentType == "" {
+		contentType = echo.MIMEOctetStream
+	}
+	header := ctx.Response().Header()
+	header.Set(echo.HeaderCacheControl, "public, max-age=31536000, immutable")
+	header.Set(echo.HeaderXContentTypeOptions, "nosniff")
+	return ctx.Stream(http.StatusOK, contentType, rc)
+}
+
+// readMultipartFile 读取multipart表单中的文件
+func readMultipartFile(fh *multipart.FileHeader) ([]byte, error) {
+	f, err := fh.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return io.ReadAll(f)
+}
+
+// verifyUploadToken 校验上传令牌，未配置token时拒绝所有上传
+func verifyUploadToken(header http.Header, token string) error {
+	if token == "" {
+		return errUploadTokenNotConfigured
+	}
+	got := strings.TrimPrefix(header.Get(echo.HeaderAuthorization), "Bearer ")
+	if subtle.ConstantTimeCompare([]byte(got), []byte(token)) != 1 {
+		return errUploadToken
+	}
+	return nil
+}
+
+// uploadHTTPError 图床相关错误转换为HTTP错误
+func uploadHTTPError(err error) error {
+	var maxBytesErr *http.MaxBytesError
+	switch {
+	case errors.As(err, &maxBytesErr), errors.Is(err, application.ErrImageTooLarge):
+		return echo.NewHTTPError(http.StatusRequestEntityTooLarge, err.Error())
+	case errors.Is(err, application.ErrUnsupportedImage):
+		return echo.NewHTTPError(http.StatusUnsupportedMediaType, err.Error())
+	case errors.Is(err, application.ErrImageHostNotConfigured):
+		return echo.NewHTTPError(http.StatusServiceUnavailable, err.Error())
+	case errors.Is(err, http.ErrNotMultipart), errors.Is(err, multipart.ErrMessageTooLarge):
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	default:
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+}
+
+// toImageResp 图片及其Markdown引用，alt为空时使用文件名(不含扩展名)
+func (c *CopilotDevelop) toImageResp(uploaded *application.UploadedImage, alt string) *ImageResp {
+	img := uploaded.Image
+	if alt == "" {
+		alt = strings.TrimSuffix(img.Name, path.Ext(img.Name))
+	}
+	if alt == "" {
+		alt = "image"
+	}
+	url := c.imageHostApp.ImageURL(img.StorageKey)
+	resp := &ImageResp{
+		Hash:      img.Hash,
+		Name:      img.Name,
+		URL:       url,
+		Markdown:  entity.MarkdownImage(alt, url),
+		MimeType:  img.MimeType,
+		Width:     img.Width,
+		Height:    img.Height,
+		Size:      img.Size,
+		Duplicate: uploaded.Duplicate,
+		CreatedAt: img.CreatedAt,
+		Variants:  make([]*ImageVariantResp, 0, len(uploaded.Variants)),
+	}
+	for _, v := range uploaded.Variants {
+		resp.Variants = append(resp.Variants, &ImageVariantResp{
+			Name:     v.Name,
+			URL:      c.imageHostApp.ImageURL(v.Key),
+			MimeType: v.MimeType,
+			Width:    v.Width,
+			Height:   v.Height,
+			Size:     v.Size,
+		})
+	}
+	return resp
+}
@@ -0,0 +1,141 @@
+package interfaces
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/png"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/lupguo/copilot_develop/app/application"
+	"github.com/lupguo/copilot_develop/app/infras/dbs"
+	"github.com/lupguo/copilot_develop/app/infras/imagehost"
+	"github.com/lupguo/copilot_develop/config"
+	"github.com/stretchr/testify/assert"
+)
+
+const testUploadToken = "upload-token"
+
+func TestCopilotDevelop_UploadImages(t *testing.T) {
+	ctx := context.Background()
+	root := t.TempDir()
+	confFile := filepath.Join(t.TempDir(), "config.yaml")
+	assert.NoError(t, os.WriteFile(confFile, []byte(fmt.Sprintf(`app:
+  root_path: %s
+  openai_proxy: {}
+  blog_summary:
+    blog_path: %s
+  image_host:
+    dir: %s
+    base_url: /i
+    token: %s
+    max_size_mb: 1
+    widths: [64]
+    webp: false
+  server:
+    admin_token: %s
+`, root, root, filepath.Join(root, "images"), testUploadToken, testAdminToken)), 0644))
+	assert.NoError(t, config.ParseConfig(confFile))
+
+	infra, err := dbs.NewBlogSummarySqliteInfra(filepath.Join(t.TempDir(), "blog_summary.db"))
+	assert.NoError(t, err)
+	assert.NoError(t, infra.InitBlogSummaryDB(ctx))
+	app := application.NewBlogSummaryApp(nil, infra)
+	e := echo.New()
+	copilot := NewCopilotDevelop(app)
+	copilot.RegisterRoutes(e)
+
+	var b bytes.Buffer
+	assert.NoError(t, png.Encode(&b, image.NewGray(image.Rect(0, 0, 128, 32))))
+	pngData := b.Bytes()
+	send := func(req *http.Request, token string) *httptest.ResponseRecorder {
+		if token != "" {
+			req.Header.Set(echo.HeaderAuthorization, "Bearer "+token)
+		}
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+	raw := func(query string, data []byte) *http.Request {
+		req := httptest.NewRequest(http.MethodPost, "/api/images"+query, bytes.NewReader(data))
+		req.Header.Set(echo.HeaderContentType, "image/png")
+		return req
+	}
+
+	// 未配置存储、令牌错误
+	assert.Equal(t, http.StatusServiceUnavailable, send(raw("", pngData), testUploadToken).Code)
+	storage, err := imagehost.NewLocalStorage(filepath.Join(root, "images"), "/i")
+	assert.NoError(t, err)
+	opts := application.ImageHostOptionsFromConfig(config.GetImageHostConfig())
+	copilot.SetImageHostApp(application.NewBlogImageHostApp(infra, storage, nil, opts))
+	assert.Equal(t, http.StatusUnauthorized, send(raw("", pngData), "").Code)
+	assert.Equal(t, http.StatusUnauthorized, send(raw("", pngData), "wrong").Code)
+	assert.ErrorIs(t, verifyUploadToken(http.Header{}, ""), errUploadTokenNotConfigured)
+
+	// multipart上传
+	var form bytes.Buffer
+	mw := multipart.NewWriter(&form)
+	fw, err := mw.CreateFormFile("file", "screen shot.png")
+	assert.NoError(t, err)
+	_, _ = fw.Write(pngData)
+	assert.NoError(t, mw.WriteField("alt", "架构图"))
+	assert.NoError(t, mw.Close())
+	req := httptest.NewRequest(http.MethodPost, "/api/images", &form)
+	req.Header.Set(echo.HeaderContentType, mw.FormDataContentType())
+	rec := send(req, testUploadToken)
+	assert.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	resp := &ImageUploadResp{}
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), resp))
+	assert.Len(t, resp.Images, 1)
+	img := resp.Images[0]
+	assert.Equal(t, "screen shot.png", img.Name)
+	assert.Equal(t, []int{128, 32}, []int{img.Width, img.Height})
+	assert.Equal(t, "![架构图]("+img.URL+")", resp.Markdown)
+	assert.Len(t, img.Variants, 1)
+	assert.Equal(t, "w64", img.Variants[0].Name)
+
+	// 请求体上传相同内容，返回200
+	rec = send(raw("?name=dup.png", pngData), testUploadToken)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), resp))
+	assert.True(t, resp.Images[0].Duplicate)
+	assert.Equal(t, img.URL, resp.Images[0].URL)
+	assert.Equal(t, "![screen shot]("+img.URL+")", resp.Markdown)
+
+	// 超过大小上限、不支持的类型、空请求体
+	assert.Equal(t, http.StatusRequestEntityTooLarge, send(raw("", make([]byte, 3<<20)), testUploadToken).Code)
+	assert.Equal(t, http.StatusUnsupportedMediaType, send(raw("", []byte("<svg></svg>")), testUploadToken).Code)
+	assert.Equal(t, http.StatusBadRequest, send(raw("", nil), testUploadToken).Code)
+
+	// 访问图片及缩放版本
+	rec = send(httptest.NewRequest(http.MethodGet, img.URL, nil), "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "image/png", rec.Header().Get(echo.HeaderContentType))
+	assert.Contains(t, rec.Header().Get(echo.HeaderCacheControl), "immutable")
+	assert.Equal(t, pngData, rec.Body.Bytes())
+	rec = send(httptest.NewRequest(http.MethodGet, img.Variants[0].URL, nil), "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	cfg, err := png.DecodeConfig(rec.Body)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{64, 16}, []int{cfg.Width, cfg.Height})
+	for _, url := range []string{"/i/ab/cd/missing.png", "/i/" + img.Hash[:2], "/i/../config.yaml"} {
+		assert.Equal(t, http.StatusNotFound, send(httptest.NewRequest(http.MethodGet, url, nil), "").Code, url)
+	}
+
+	// 图片列表
+	assert.Equal(t, http.StatusUnauthorized, send(httptest.NewRequest(http.MethodGet, "/api/images", nil), testUploadToken).Code)
+	rec = send(httptest.NewRequest(http.MethodGet, "/api/images?limit=5", nil), testAdminToken)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	var list []*ImageResp
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &list))
+	assert.Len(t, list, 1)
+	assert.Equal(t, img.Hash, list[0].Hash)
+}
@@ -0,0 +1,145 @@
+package interfaces
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/lupguo/copilot_develop/app/application"
+	"github.com/lupguo/copilot_develop/app/domain/entity"
+	"github.com/lupguo/copilot_develop/config"
+	"github.com/pkg/errors"
+)
+
+// webhookMaxBodySize push webhook请求体上限
+const webhookMaxBodySize = 5 << 20
+
+// 各平台push webhook的请求头
+const (
+	headerGitHubEvent     = "X-GitHub-Event"
+	headerGitHubSignature = "X-Hub-Signature-256" // sha256=<hex>
+	headerGiteaEvent      = "X-Gitea-Event"
+	headerGiteaSignature  = "X-Gitea-Signature" // <hex>
+	headerGitLabEvent     = "X-Gitlab-Event"
+	headerGitLabToken     = "X-Gitlab-Token" // Secret token明文
+)
+
+var (
+	errWebhookUnknownProvider = errors.New("unknown webhook provider")
+	errWebhookSignature       = errors.New("invalid webhook signature")
+)
+
+// pushPayload GitHub/Gitea/GitLab push webhook的公共字段
+type pushPayload struct {
+	Ref     string `json:"ref"`
+	Before  string `json:"before"`
+	After   string `json:"after"`
+	Commits []struct {
+		ID       string   `json:"id"`
+		Added    []string `json:"added"`
+		Modified []string `json:"modified"`
+		Removed  []string `json:"removed"`
+	} `json:"commits"`
+}
+
+// PushWebhook git推送webhook POST /webhooks/push，校验签名后按新增、修改的markdown文章提交摘要任务
+func (c *CopilotDevelop) PushWebhook(ctx echo.Context) error {
+	body, err := io.ReadAll(io.LimitReader(ctx.Request().Body, webhookMaxBodySize))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	cfg := config.GetWebhookConfig()
+	provider, event, err := verifyPushWebhook(ctx.Request().Header, body, cfg.Secret)
+	switch {
+	case errors.Is(err, errWebhookUnknownProvider):
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	case err != nil:
+		return echo.NewHTTPError(http.StatusUnauthorized, err.Error())
+	case event != "push" && event != "Push Hook": // ping等其他事件
+		return ctx.JSON(http.StatusOK, &WebhookResp{Message: "ignored event " + event})
+	}
+
+	push, err := parsePushPayload(provider, body)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	if push.IsDeleted() || (cfg.Branch != "" && push.Branch() != cfg.Branch) {
+		return ctx.JSON(http.StatusOK, &WebhookResp{Message: "ignored ref " + push.Ref})
+	}
+
+	job, err := c.blogSummaryApp.SubmitPushJob(ctx.Request().Context(), config.GetBlogPath(), push, cfg.Pull)
+	switch {
+	case errors.Is(err, application.ErrGitRepoNotConfigured):
+		return echo.NewHTTPError(http.StatusServiceUnavailable, err.Error())
+	case err != nil:
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	case job == nil:
+		return ctx.JSON(http.StatusOK, &WebhookResp{Message: "no markdown changes"})
+	}
+	return ctx.JSON(http.StatusAccepted, &WebhookResp{Message: "job submitted", Job: toJobResp(job)})
+}
+
+// verifyPushWebhook 按请求头识别平台并校验签名，返回平台及事件类型；secret为空时拒绝所有请求
+func verifyPushWebhook(header http.Header, body []byte, secret string) (provider, event string, err error) {
+	switch {
+	case header.Get(headerGitHubEvent) != "":
+		provider, event = "github", header.Get(headerGitHubEvent)
+		err = verifyHMACSignature(strings.TrimPrefix(header.Get(headerGitHubSignature), "sha256="), body, secret)
+	case header.Get(headerGiteaEvent) != "":
+		provider, event = "gitea", header.Get(headerGiteaEvent)
+		err = verifyHMACSignature(header.Get(headerGiteaSignature), body, secret)
+	case header.Get(headerGitLabEvent) != "":
+		provider, event = "gitlab", header.Get(headerGitLabEvent)
+		if secret == "" || !hmac.Equal([]byte(header.Get(headerGitLabToken)), []byte(secret)) {
+			err = errWebhookSignature
+		}
+	default:
+		return "", "", errWebhookUnknownProvider
+	}
+	return provider, event, err
+}
+
+// verifyHMACSignature 校验HMAC-SHA256签名(hex编码)
+func verifyHMACSignature(signature string, body []byte, secret string) error {
+	got, err := hex.DecodeString(signature)
+	if secret == "" || err != nil {
+		return errWebhookSignature
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	if !hmac.Equal(got, mac.Sum(nil)) {
+		return errWebhookSignature
+	}
+	return nil
+}
+
+// parsePushPayload 解析push webhook的请求体
+func parsePushPayload(provider string, body []byte) (*entity.GitPush, error) {
+	payload := &pushPayload{}
+	if err := json.Unmarshal(body, payload); err != nil {
+		return nil, errors.Wrapf(err, "unmarshal %s push payload got err", provider)
+	}
+
+	push := &entity.GitPush{
+		Provider: provider,
+		Ref:      payload.Ref,
+		Before:   payload.Before,
+		After:    payload.After,
+		Commits:  make([]*entity.GitPushCommit, 0, len(payload.Commits)),
+	}
+	for _, commit := range payload.Commits {
+		push.Commits = append(push.Commits, &entity.GitPushCommit{
+			ID:       commit.ID,
+			Added:    commit.Added,
+			Modified: commit.Modified,
+			Removed:  commit.Removed,
+		})
+	}
+	return push, nil
+}
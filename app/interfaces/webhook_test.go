@@ -0,0 +1,174 @@
+package interfaces
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/lupguo/copilot_develop/app/application"
+	"github.com/lupguo/copilot_develop/app/infras/dbs"
+	"github.com/lupguo/copilot_develop/config"
+	"github.com/stretchr/testify/assert"
+)
+
+const testWebhookSecret = "s3cret"
+
+// fakeGitRepo 本地仓库，记录pull次数
+type fakeGitRepo struct {
+	root  string
+	pulls int
+}
+
+func (r *fakeGitRepo) Root() string {
+	return r.root
+}
+
+func (r *fakeGitRepo) Pull(ctx context.Context) error {
+	r.pulls++
+	return nil
+}
+
+func (r *fakeGitRepo) Head(ctx context.Context) (string, error) {
+	return "", nil
+}
+
+func (r *fakeGitRepo) ChangedFiles(ctx context.Context, since string) ([]string, error) {
+	return nil, nil
+}
+
+func (r *fakeGitRepo) Commit(ctx context.Context, message string, files []string) (string, error) {
+	return "", nil
+}
+
+// fakeWebhookSender 模拟GitHub/Gitea/GitLab发送push webhook
+type fakeWebhookSender struct {
+	e      *echo.Echo
+	secret string
+}
+
+func (s *fakeWebhookSender) send(provider, event string, payload interface{}) *httptest.ResponseRecorder {
+	body, _ := json.Marshal(payload)
+	req := httptest.NewRequest(http.MethodPost, "/webhooks/push", bytes.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+
+	mac := hmac.New(sha256.New, []byte(s.secret))
+	mac.Write(body)
+	signature := hex.EncodeToString(mac.Sum(nil))
+	switch provider {
+	case "github":
+		req.Header.Set(headerGitHubEvent, event)
+		req.Header.Set(headerGitHubSignature, "sha256="+signature)
+	case "gitea":
+		req.Header.Set(headerGiteaEvent, event)
+		req.Header.Set(headerGiteaSignature, signature)
+	case "gitlab":
+		req.Header.Set(headerGitLabEvent, event)
+		req.Header.Set(headerGitLabToken, s.secret)
+	}
+
+	rec := httptest.NewRecorder()
+	s.e.ServeHTTP(rec, req)
+	return rec
+}
+
+func pushPayloadOf(after string, added, modified, removed []string) map[string]interface{} {
+	return map[string]interface{}{
+		"ref":    "refs/heads/main",
+		"before": "0a1b2c",
+		"after":  after,
+		"commits": []map[string]interface{}{
+			{"id": after, "added": added, "modified": modified, "removed": removed},
+		},
+	}
+}
+
+func TestCopilotDevelop_PushWebhook(t *testing.T) {
+	ctx := context.Background()
+	repoRoot := t.TempDir()
+	blogPath := filepath.Join(repoRoot, "content")
+	assert.NoError(t, os.MkdirAll(filepath.Join(blogPath, "posts"), 0755))
+	for _, file := range []string{"posts/go.md", "posts/rust.md", "posts/_index.md"} {
+		assert.NoError(t, os.WriteFile(filepath.Join(blogPath, file), []byte("---\ntitle: t\n---\nbody"), 0644))
+	}
+	assert.NoError(t, os.WriteFile(filepath.Join(repoRoot, "README.md"), []byte("readme"), 0644))
+
+	confFile := filepath.Join(t.TempDir(), "config.yaml")
+	assert.NoError(t, os.WriteFile(confFile, []byte(fmt.Sprintf(`app:
+  root_path: %s
+  openai_proxy: {}
+  blog_summary:
+    blog_path: %s
+  webhook:
+    secret: %s
+    branch: main
+    pull: true
+`, repoRoot, blogPath, testWebhookSecret)), 0644))
+	assert.NoError(t, config.ParseConfig(confFile))
+
+	infra, err := dbs.NewBlogSummarySqliteInfra(filepath.Join(t.TempDir(), "blog_summary.db"))
+	assert.NoError(t, err)
+	assert.NoError(t, infra.InitBlogSummaryDB(ctx))
+	app := application.NewBlogSummaryApp(nil, infra)
+	e := echo.New()
+	NewCopilotDevelop(app).RegisterRoutes(e)
+
+	// 未配置git仓库
+	sender := &fakeWebhookSender{e: e, secret: testWebhookSecret}
+	rec := sender.send("github", "push", pushPayloadOf("c1", []string{"content/posts/go.md"}, nil, nil))
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+
+	gitRepo := &fakeGitRepo{root: repoRoot}
+	app.SetGitRepo(gitRepo)
+
+	// 签名错误、未知平台
+	bad := &fakeWebhookSender{e: e, secret: "wrong"}
+	for _, provider := range []string{"github", "gitea", "gitlab"} {
+		assert.Equal(t, http.StatusUnauthorized, bad.send(provider, "push", pushPayloadOf("c1", nil, nil, nil)).Code, provider)
+	}
+	assert.Equal(t, http.StatusBadRequest, bad.send("bitbucket", "push", pushPayloadOf("c1", nil, nil, nil)).Code)
+
+	// ping事件、其他分支忽略
+	rec = sender.send("github", "ping", map[string]string{"zen": "hi"})
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "ignored event ping")
+	other := pushPayloadOf("c1", []string{"content/posts/go.md"}, nil, nil)
+	other["ref"] = "refs/heads/dev"
+	assert.Contains(t, sender.send("github", "push", other).Body.String(), "ignored ref")
+
+	// 仅处理blog_path下新增、修改的文章，排除_index.md、已删除的文件
+	rec = sender.send("github", "push", pushPayloadOf("c1",
+		[]string{"content/posts/go.md", "README.md", "content/posts/_index.md"},
+		[]string{"content/posts/rust.md", "content/posts/missing.md"}, nil))
+	assert.Equal(t, http.StatusAccepted, rec.Code)
+	resp := &WebhookResp{}
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), resp))
+	assert.Equal(t, 2, resp.Job.Total)
+	assert.Equal(t, 1, gitRepo.pulls)
+	_, items, err := app.GetJob(ctx, resp.Job.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, filepath.Join(blogPath, "posts/go.md"), items[0].Path)
+	assert.Equal(t, filepath.Join(blogPath, "posts/rust.md"), items[1].Path)
+
+	// 同一提交重复投递不重复提交
+	rec = sender.send("gitea", "push", pushPayloadOf("c1", []string{"content/posts/go.md"}, nil, nil))
+	assert.Equal(t, http.StatusAccepted, rec.Code)
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), resp))
+	assert.Equal(t, 2, resp.Job.Total)
+
+	// GitLab：删除的文件不处理
+	rec = sender.send("gitlab", "Push Hook", pushPayloadOf("c2", nil, []string{"content/posts/go.md"}, []string{"content/posts/go.md"}))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "no markdown changes")
+	rec = sender.send("gitlab", "Push Hook", pushPayloadOf("c3", nil, []string{"content/posts/go.md"}, nil))
+	assert.Equal(t, http.StatusAccepted, rec.Code)
+}
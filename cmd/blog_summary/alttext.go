@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+
+	"github.com/lupguo/copilot_develop/app/application"
+	"github.com/lupguo/copilot_develop/app/domain/entity"
+	"github.com/lupguo/copilot_develop/config"
+	log "github.com/sirupsen/logrus"
+)
+
+// runAltText 为alt文字缺失的图片生成alt文字: blog_summary alt-text [path] [--vision] [--apply] [--format table|json]，
+// 未指定--apply时只生成、记录并列出alt文字，--apply时写入文章(已生成的不再请求AI)
+func runAltText(ctx context.Context, args []string) {
+	fs := newFlagSet("alt-text")
+	formatFlag := fs.String("format", "table", "Output format: table or json")
+	apply := fs.Bool("apply", false, "Insert the generated alt texts into the posts")
+	vision := fs.Bool("vision", false, "Describe the images themselves with the vision-capable alt-text-vision prompt (default from config)")
+	args = parseFlags(fs, args)
+
+	format, err := entity.ParseStatsFormat(*formatFlag)
+	if err != nil {
+		log.Fatal(err)
+	}
+	app, err := buildBlogSummaryApp()
+	if err != nil {
+		log.Fatalf("init blog summary got err: %s", err)
+	}
+
+	path := blogPath
+	if len(args) > 0 {
+		path = args[0]
+	}
+	opts := application.AltTextOptionsFromConfig(config.GetSiteConfig(), config.GetAltTextConfig())
+	opts.Vision = opts.Vision || *vision
+	opts.Apply = *apply
+	results, err := app.GenerateAltTexts(ctx, path, opts)
+	if err != nil {
+		log.Fatalf("generate image alt texts got err: %s", err)
+	}
+
+	if format == entity.StatsFormatJSON {
+		if results == nil {
+			results = []*entity.AltTextResult{}
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err = enc.Encode(results); err != nil {
+			log.Fatalf("write image alt texts got err: %s", err)
+		}
+		return
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	lastPath, applied := "", 0
+	for _, r := range results {
+		if r.Path != lastPath {
+			lastPath = r.Path
+			path := r.Path
+			if rel, err := filepath.Rel(blogPath, path); err == nil && !filepath.IsAbs(rel) && rel[0] != '.' {
+				path = rel
+			}
+			fmt.Fprintf(tw, "%s\n", path)
+		}
+		if r.Applied {
+			applied++
+		}
+		fmt.Fprintf(tw, "  L%d\t%s\t%s\t%s\n", r.Line, r.Target, r.AltText, r.Mode)
+	}
+	if err = tw.Flush(); err != nil {
+		log.Fatalf("write image alt texts got err: %s", err)
+	}
+	fmt.Printf("%d images without alt text, %d written\n", len(results), applied)
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/lupguo/copilot_develop/app/application"
+	"github.com/lupguo/copilot_develop/app/domain/repos"
+	"github.com/lupguo/copilot_develop/app/infras/crawler"
+	"github.com/lupguo/copilot_develop/config"
+	log "github.com/sirupsen/logrus"
+)
+
+// newCrawler 按爬虫配置初始化爬虫，visits记录访问过的url用于增量爬取
+func newCrawler(maxDepth int, visits repos.IReposCrawlVisit) (*crawler.Crawler, error) {
+	cfg := config.GetCrawlerConfig()
+	return crawler.NewCrawler(&crawler.Options{
+		MaxDepth:     maxDepth,
+		Delay:        time.Duration(cfg.DelayMs) * time.Millisecond,
+		UserAgent:    cfg.UserAgent,
+		IgnoreRobots: cfg.IgnoreRobots,
+		Sitemap:      cfg.Sitemap,
+		Visits:       visits,
+		Revisit:      time.Duration(cfg.RevisitHours) * time.Hour,
+	})
+}
+
+// runClip 摘录外部网页: blog_summary clip [--force] <url>...，或 blog_summary clip --crawl [--depth 1] [--match regex] <url>
+func runClip(ctx context.Context, args []string) {
+	fs := newFlagSet("clip")
+	force := fs.Bool("force", false, "Clip even if the clip is up to date")
+	crawl := fs.Bool("crawl", false, "Crawl pages of the same site from the given url and clip them in batch")
+	crawlDepth := fs.Int("depth", 0, "Max crawl depth of the --crawl mode (default from config, or 1)")
+	match := fs.String("match", "", "Only clip crawled urls matching this regex (--crawl mode)")
+	urls := parseFlags(fs, args)
+
+	if len(urls) == 0 {
+		log.Fatalf("clip command needs at least one url")
+	}
+
+	cfg := config.GetClipConfig()
+	if cfg.Dir == "" {
+		log.Fatalf("clip dir is not configured, set blog_summary.clip.dir or blog_path")
+	}
+	sqliteDbInfra, aiService, err := buildInfras()
+	if err != nil {
+		log.Fatalf("init blog summary got err: %s", err)
+	}
+	c, err := newCrawler(cfg.MaxDepth, sqliteDbInfra)
+	if err != nil {
+		log.Fatalf("init crawler got err: %s", err)
+	}
+	app := application.NewBlogClipApp(aiService, sqliteDbInfra, c, cfg.Dir)
+
+	if *crawl {
+		depth := *crawlDepth
+		if depth <= 0 {
+			depth = cfg.MaxDepth
+		}
+		for _, seed := range urls {
+			clips, err := app.ClipSite(ctx, seed, depth, *match, *force)
+			for _, clip := range clips {
+				fmt.Printf("%s => %s\n", clip.Url, clip.Filepath)
+			}
+			if err != nil {
+				log.Fatalf("clip site[%s] got err: %s", seed, err)
+			}
+		}
+		return
+	}
+
+	for _, url := range urls {
+		clip, skip, err := app.ClipURL(ctx, url, *force)
+		if err != nil {
+			log.Fatalf("clip web page[%s] got err: %s", url, err)
+		}
+		if skip != "" {
+			fmt.Printf("%s skipped (%s): %s\n", url, skip, clip.Filepath)
+			continue
+		}
+		fmt.Printf("%s => %s\n", clip.Url, clip.Filepath)
+	}
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+
+	"github.com/lupguo/copilot_develop/app/application"
+	"github.com/lupguo/copilot_develop/app/domain/entity"
+	"github.com/lupguo/copilot_develop/app/domain/repos"
+	"github.com/lupguo/copilot_develop/app/infras/imagegen"
+	"github.com/lupguo/copilot_develop/app/infras/openaix"
+	"github.com/lupguo/copilot_develop/config"
+	log "github.com/sirupsen/logrus"
+)
+
+// runCover 生成文章封面图: blog_summary cover [post.md|dir] [--force] [--format table|json]，
+// 文生图服务按配置使用OpenAI Images或本地Stable Diffusion
+func runCover(ctx context.Context, args []string) {
+	fs := newFlagSet("cover")
+	formatFlag := fs.String("format", "table", "Output format: table or json")
+	force := fs.Bool("force", false, "Regenerate existing covers")
+	args = parseFlags(fs, args)
+
+	format, err := entity.ParseStatsFormat(*formatFlag)
+	if err != nil {
+		log.Fatal(err)
+	}
+	sqliteDbInfra, _, err := buildInfras()
+	if err != nil {
+		log.Fatalf("init blog summary got err: %s", err)
+	}
+
+	cfg := config.GetCoverConfig()
+	var gen repos.IReposImageGenerator
+	switch cfg.Provider {
+	case "openai":
+		if gen, err = openaix.NewOpenAIHttpProxyClient(); err != nil {
+			log.Fatalf("init openai image generator got err: %s", err)
+		}
+	case "sd":
+		gen = imagegen.NewSDClient(cfg.SDURL, cfg.Steps)
+	default:
+		log.Fatalf("unknown cover provider: %s", cfg.Provider)
+	}
+	app := application.NewBlogCoverApp(sqliteDbInfra, newScanRules(), gen)
+
+	path := ""
+	if len(args) > 0 {
+		path = args[0]
+	}
+	opts := application.CoverOptionsFromConfig(config.GetSiteConfig(), cfg)
+	opts.Force = *force
+	covers, err := app.GenerateCovers(ctx, blogPath, path, opts)
+	if err != nil {
+		log.Fatalf("generate covers got err: %s", err)
+	}
+
+	if format == entity.StatsFormatJSON {
+		if covers == nil {
+			covers = []*entity.CoverImage{}
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err = enc.Encode(covers); err != nil {
+			log.Fatalf("write covers got err: %s", err)
+		}
+		return
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	for _, c := range covers {
+		path := c.Path
+		if rel, err := filepath.Rel(blogPath, path); err == nil && !filepath.IsAbs(rel) && rel[0] != '.' {
+			path = rel
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\tseed %d\n", path, c.Ref, c.Model, c.Seed)
+	}
+	if err = tw.Flush(); err != nil {
+		log.Fatalf("write covers got err: %s", err)
+	}
+	fmt.Printf("%d covers generated\n", len(covers))
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/lupguo/copilot_develop/app/application"
+	"github.com/lupguo/copilot_develop/app/domain/entity"
+	"github.com/lupguo/copilot_develop/config"
+	log "github.com/sirupsen/logrus"
+)
+
+// runFeeds 检查RSS/Atom订阅并摘要新文章: blog_summary feeds [--output digest|notes] [feed_url...]，未指定url时使用配置的订阅
+func runFeeds(ctx context.Context, args []string) {
+	fs := newFlagSet("feeds")
+	output := fs.String("output", "", "Output of new feed entries: digest (a daily digest post) or notes (a note per entry) (default from config)")
+	urls := parseFlags(fs, args)
+
+	cfg := config.GetFeedsConfig()
+	if len(urls) == 0 {
+		urls = cfg.Urls
+	}
+	if len(urls) == 0 {
+		log.Fatalf("no feed url, set blog_summary.feeds.urls or pass feed urls")
+	}
+	if cfg.Dir == "" {
+		log.Fatalf("feed dir is not configured, set blog_summary.feeds.dir or blog_path")
+	}
+	opts := application.FeedOptionsFromConfig(cfg)
+	if *output != "" {
+		opts.Output = entity.FeedOutput(*output)
+	}
+	if opts.Output != entity.FeedOutputDigest && opts.Output != entity.FeedOutputNotes {
+		log.Fatalf("unknown feed output: %s", opts.Output)
+	}
+
+	sqliteDbInfra, aiService, err := buildInfras()
+	if err != nil {
+		log.Fatalf("init blog summary got err: %s", err)
+	}
+	c, err := newCrawler(0, sqliteDbInfra)
+	if err != nil {
+		log.Fatalf("init crawler got err: %s", err)
+	}
+	app := application.NewBlogFeedApp(aiService, sqliteDbInfra, c, opts)
+
+	results, pollErr := app.PollFeeds(ctx, urls)
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "FEED\tSTATUS\tNEW\tSKIPPED\tFAILED")
+	for _, result := range results {
+		status := "ok"
+		switch {
+		case result.Err != nil:
+			status = "error"
+		case result.NotModified:
+			status = "not modified"
+		}
+		name := result.Url
+		if result.Title != "" {
+			name = result.Title
+		}
+		fmt.Fprintf(w, "%s\t%s\t%d\t%d\t%d\n", name, status, len(result.Entries), result.Skipped, result.Failed)
+	}
+	w.Flush()
+	for _, result := range results {
+		for _, entry := range result.Entries {
+			fmt.Printf("%s => %s\n", entry.Url, entry.Filepath)
+		}
+		if result.Err != nil {
+			log.Errorf("feed[%s] got err: %s", result.Url, result.Err)
+		}
+	}
+	if pollErr != nil {
+		log.Fatalf("poll feeds got err: %s", pollErr)
+	}
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+
+	"github.com/lupguo/copilot_develop/app/application"
+	"github.com/lupguo/copilot_develop/app/domain/entity"
+	"github.com/lupguo/copilot_develop/config"
+	log "github.com/sirupsen/logrus"
+)
+
+// runInterlink 站内互链建议: blog_summary interlink [path] [--format table|json] [--apply]，
+// --suggestions <file.json> 时将审核(删改)后的建议列表插入文章
+func runInterlink(ctx context.Context, args []string) {
+	fs := newFlagSet("interlink")
+	formatFlag := fs.String("format", "table", "Output format: table or json")
+	apply := fs.Bool("apply", false, "Insert the suggested links into the posts")
+	suggestionsFile := fs.String("suggestions", "", "Insert the links listed in this reviewed JSON file, as written by interlink --format json")
+	args = parseFlags(fs, args)
+
+	format, err := entity.ParseStatsFormat(*formatFlag)
+	if err != nil {
+		log.Fatal(err)
+	}
+	app, err := buildBlogSummaryApp()
+	if err != nil {
+		log.Fatalf("init blog summary got err: %s", err)
+	}
+
+	var suggestions []*entity.InterlinkSuggestion
+	if *suggestionsFile != "" {
+		data, err := os.ReadFile(*suggestionsFile)
+		if err != nil {
+			log.Fatalf("read interlink suggestions got err: %s", err)
+		}
+		if err = json.Unmarshal(data, &suggestions); err != nil {
+			log.Fatalf("parse interlink suggestions[%s] got err: %s", *suggestionsFile, err)
+		}
+	} else {
+		path := blogPath
+		if len(args) > 0 {
+			path = args[0]
+		}
+		opts := application.InterlinkOptionsFromConfig(config.GetInterlinkConfig())
+		if suggestions, err = app.SuggestInterlinks(ctx, path, opts); err != nil {
+			log.Fatalf("suggest blog interlinks got err: %s", err)
+		}
+	}
+
+	if *apply || *suggestionsFile != "" {
+		applied, err := app.ApplyInterlinks(ctx, suggestions)
+		if err != nil {
+			log.Fatalf("apply blog interlinks got err: %s", err)
+		}
+		fmt.Printf("%d of %d suggested links inserted\n", len(applied), len(suggestions))
+		return
+	}
+
+	if format == entity.StatsFormatJSON {
+		if suggestions == nil {
+			suggestions = []*entity.InterlinkSuggestion{}
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err = enc.Encode(suggestions); err != nil {
+			log.Fatalf("write blog interlinks got err: %s", err)
+		}
+		return
+	}
+	writeInterlinks(suggestions)
+}
+
+// writeInterlinks 按文章输出互链建议，路径相对blog_path
+func writeInterlinks(suggestions []*entity.InterlinkSuggestion) {
+	rel := func(path string) string {
+		if r, err := filepath.Rel(blogPath, path); err == nil && !filepath.IsAbs(r) && r[0] != '.' {
+			return r
+		}
+		return path
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	lastSource := ""
+	sources := 0
+	for _, s := range suggestions {
+		if s.Source != lastSource {
+			lastSource = s.Source
+			sources++
+			fmt.Fprintf(tw, "%s\n", rel(s.Source))
+		}
+		match := string(s.Match)
+		if s.Similarity > 0 {
+			match = fmt.Sprintf("%s %.2f", s.Match, s.Similarity)
+		}
+		fmt.Fprintf(tw, "  L%d\t%s\t=> %s\t%s\t%s\n", s.Line, s.Anchor, s.Url, rel(s.Target), match)
+	}
+	if err := tw.Flush(); err != nil {
+		log.Fatalf("write blog interlinks got err: %s", err)
+	}
+	fmt.Printf("%d links suggested in %d posts\n", len(suggestions), sources)
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// runSubmit 仅提交摘要任务，由HTTP服务的后台worker处理: blog_summary submit [path]
+func runSubmit(ctx context.Context, args []string) {
+	args = parseFlags(newFlagSet("submit"), args)
+	path := blogPath
+	if len(args) > 0 {
+		path = args[0]
+	}
+
+	app, err := buildBlogSummaryApp()
+	if err != nil {
+		log.Fatalf("init blog summary got err: %s", err)
+	}
+	job, err := app.SubmitSummaryJob(ctx, blogPath, path)
+	if err != nil {
+		log.Fatalf("submit summary job got err: %s", err)
+	}
+	fmt.Printf("job[%d] %s, path: %s, total: %d\n", job.ID, job.Status, job.Path, job.Total)
+}
+
+// runJobs 查看最近的任务，或指定任务的文件明细: blog_summary jobs [id]
+func runJobs(ctx context.Context, args []string) {
+	args = parseFlags(newFlagSet("jobs"), args)
+	app, err := buildBlogSummaryApp()
+	if err != nil {
+		log.Fatalf("init blog summary got err: %s", err)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	defer w.Flush()
+
+	// 任务列表
+	if len(args) == 0 {
+		jobs, err := app.ListJobs(ctx, 0)
+		if err != nil {
+			log.Fatalf("list summary jobs got err: %s", err)
+		}
+		fmt.Fprintln(w, "ID\tSTATUS\tTOTAL\tDONE\tSKIPPED\tFAILED\tCREATED\tPATH")
+		for _, job := range jobs {
+			fmt.Fprintf(w, "%d\t%s\t%d\t%d\t%d\t%d\t%s\t%s\n",
+				job.ID, job.Status, job.Total, job.Done, job.Skipped, job.Failed, job.CreatedAt, job.Path)
+		}
+		return
+	}
+
+	// 任务明细
+	id, err := strconv.ParseUint(args[0], 10, 64)
+	if err != nil {
+		log.Fatalf("invalid job id: %s", args[0])
+	}
+	job, items, err := app.GetJob(ctx, uint(id))
+	if err != nil {
+		log.Fatalf("get summary job[%d] got err: %s", id, err)
+	}
+	if job == nil {
+		log.Fatalf("summary job[%d] not found", id)
+	}
+	fmt.Fprintf(w, "job[%d] %s, path: %s, total: %d, done: %d, skipped: %d, failed: %d\n\n",
+		job.ID, job.Status, job.Path, job.Total, job.Done, job.Skipped, job.Failed)
+	fmt.Fprintln(w, "STATUS\tATTEMPTS\tPATH\tREASON")
+	for _, item := range items {
+		fmt.Fprintf(w, "%s\t%d\t%s\t%s\n", item.Status, item.Attempts, item.Path, item.Reason)
+	}
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+
+	"github.com/lupguo/copilot_develop/app/application"
+	"github.com/lupguo/copilot_develop/app/domain/entity"
+	"github.com/lupguo/copilot_develop/app/domain/repos"
+	"github.com/lupguo/copilot_develop/config"
+	log "github.com/sirupsen/logrus"
+)
+
+// runLinks 检查失效链接、缺失及孤立图片: blog_summary links [--external] [--suggest] [--format table|json]，
+// 存在失效链接或缺失图片时以状态码1退出
+func runLinks(ctx context.Context, args []string) {
+	fs := newFlagSet("links")
+	formatFlag := fs.String("format", "table", "Output format: table or json")
+	external := fs.Bool("external", false, "Also check external links with HEAD/GET requests (default from config)")
+	suggest := fs.Bool("suggest", false, "Ask the AI to suggest replacement internal links for broken links")
+	parseFlags(fs, args)
+
+	format, err := entity.ParseStatsFormat(*formatFlag)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	sqliteDbInfra, aiService, err := buildInfras()
+	if err != nil {
+		log.Fatalf("init blog summary got err: %s", err)
+	}
+	opts := application.LinkCheckOptionsFromConfig(config.GetSiteConfig(), config.GetLinksConfig())
+	opts.External = opts.External || *external
+	opts.Suggest = *suggest
+	var webCrawler repos.IReposWebCrawler
+	if opts.External {
+		if webCrawler, err = newCrawler(1, nil); err != nil {
+			log.Fatalf("init crawler got err: %s", err)
+		}
+	}
+	app := application.NewBlogLinkApp(aiService, sqliteDbInfra, newScanRules(), webCrawler)
+
+	report, err := app.CheckLinks(ctx, blogPath, opts)
+	if err != nil {
+		log.Fatalf("check blog links got err: %s", err)
+	}
+
+	if format == entity.StatsFormatJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err = enc.Encode(report); err != nil {
+			log.Fatalf("write blog links report got err: %s", err)
+		}
+	} else {
+		writeLinkReport(report)
+	}
+
+	counts := report.CountLinkIssues()
+	if counts[entity.LinkIssueBroken]+counts[entity.LinkIssueMissingImage]+counts[entity.LinkIssueExternal] > 0 {
+		os.Exit(1)
+	}
+}
+
+// writeLinkReport 按文件输出链接问题，路径相对blog_path
+func writeLinkReport(report *entity.LinkReport) {
+	tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	lastPath := ""
+	for _, issue := range report.Issues {
+		if issue.Path != lastPath {
+			lastPath = issue.Path
+			path := issue.Path
+			if rel, err := filepath.Rel(blogPath, path); err == nil && !filepath.IsAbs(rel) && rel[0] != '.' {
+				path = rel
+			}
+			fmt.Fprintf(tw, "%s\n", path)
+		}
+		line := "-"
+		if issue.Line > 0 {
+			line = fmt.Sprintf("L%d", issue.Line)
+		}
+		detail := issue.Reason
+		if issue.Suggestion != "" {
+			detail = "=> " + issue.Suggestion
+		}
+		fmt.Fprintf(tw, "  %s\t%s\t%s\t%s\n", line, issue.Kind, issue.Target, detail)
+	}
+	if err := tw.Flush(); err != nil {
+		log.Fatalf("write blog links report got err: %s", err)
+	}
+
+	counts := report.CountLinkIssues()
+	fmt.Printf("%d posts, %d links, %d images, %d external urls checked: %d broken, %d missing images, %d broken external, %d orphan images\n",
+		report.Files, report.Links, report.Images, report.External, counts[entity.LinkIssueBroken],
+		counts[entity.LinkIssueMissingImage], counts[entity.LinkIssueExternal], counts[entity.LinkIssueOrphanImage])
+}
@@ -2,11 +2,16 @@ package main
 
 import (
 	"context"
+	"fmt"
+	"os"
+	"strings"
 	"time"
 
 	"github.com/lupguo/copilot_develop/app/application"
+	"github.com/lupguo/copilot_develop/app/domain/entity"
 	"github.com/lupguo/copilot_develop/app/domain/service"
 	"github.com/lupguo/copilot_develop/app/infras/dbs"
+	"github.com/lupguo/copilot_develop/app/infras/gitx"
 	"github.com/lupguo/copilot_develop/app/infras/openaix"
 	"github.com/lupguo/copilot_develop/config"
 	"github.com/pkg/errors"
@@ -17,22 +22,145 @@ import (
 var (
 	configFile string // 应用配置文件
 	blogPath   string // blog路径
+
+	// 文章扫描规则，追加到配置的规则
+	includes     []string
+	excludes     []string
+	skipSections []string
+
+	// summary 命令参数
+	concurrency int    // 摘要任务并发数
+	reviewMode  bool   // 审核模式
+	since       string // 增量模式：仅处理自该git ref以来变更的文章
+	sinceLast   bool   // 增量模式：以上次成功任务记录的提交为起点
+	gitCommit   bool   // 处理完成后提交重写了front matter的文章
+)
+
+var (
+	// commonFlags 所有子命令共用的参数
+	commonFlags = pflag.NewFlagSet("blog_summary", pflag.ExitOnError)
+
+	// summaryFlags 默认summary命令的参数，可写在子命令名之前
+	summaryFlags = pflag.NewFlagSet("summary", pflag.ExitOnError)
 )
 
 func init() {
-	pflag.StringVar(&configFile, "conf", "./config.yaml", "Path to the app YAML config file")
-	pflag.StringVar(&blogPath, "blog_path", "/private/data/www/tkstorm.com/content/", "The path of Blog content AI Summary")
+	commonFlags.StringVar(&configFile, "conf", "./config.yaml", "Path to the app YAML config file")
+	commonFlags.StringVar(&blogPath, "blog_path", "/private/data/www/tkstorm.com/content/", "The path of Blog content AI Summary")
+	commonFlags.StringSliceVar(&includes, "include", nil, "Glob of files to scan, relative to blog_path (repeatable, default *.md)")
+	commonFlags.StringSliceVar(&excludes, "exclude", nil, "Glob of files to skip, relative to blog_path (repeatable)")
+	commonFlags.StringSliceVar(&skipSections, "skip-section", nil, "Section directory under blog_path to skip, e.g. about (repeatable)")
+
+	summaryFlags.IntVar(&concurrency, "concurrency", 0, "Number of files processed concurrently by a summary job (default from config, or 10)")
+	summaryFlags.StringVar(&since, "since", "", "Only process Markdown files changed since this git ref (including uncommitted changes)")
+	summaryFlags.BoolVar(&sinceLast, "since-last", false, "Only process Markdown files changed since the commit of the last successful run")
+	summaryFlags.BoolVar(&gitCommit, "commit", false, "Commit the rewritten front matter to the blog git repository after the run")
+	summaryFlags.BoolVar(&reviewMode, "review", false, "Store AI summaries as pending until approved by the review command (default from config)")
 }
 
 // Blog总结基本流程
 // 1. 获取指定目录的所有文件内容，返回文件的绝对路径集合
 // 2. 并行化读取文件内容，通过OpenAI提取文件内容摘要、关键字信息，对原MD进行替换
+//
+// 子命令(公共参数 --conf、--blog_path、--include 等可写在子命令前后，其余参数只对所属子命令生效):
+//   - (默认) summary [path]: 提交(或恢复未完成的)摘要任务并在当前进程处理完，
+//     --since <ref>/--since-last 时仅处理blog git仓库中变更的文章，--commit 时提交重写的文章
+//   - submit [path]: 仅提交摘要任务，由HTTP服务的后台worker处理
+//   - jobs [id]: 查看最近的任务，或指定任务的文件明细
+//   - review: 逐个审核待审核的摘要(接受、编辑、重新生成、拒绝)
+//   - stats: 全站内容统计及健康报告，--format table|json|markdown
+//   - weights: 预览按权重公式重算后文章排序的变化，--apply 时写入文章
+//   - sections [path]: 基于子文章已生成的摘要汇总栏目(_index.md)摘要，摘要任务结束后也会自动执行
+//   - translate <post.md>: 翻译文章到Hugo多语言兄弟文件
+//   - clip <url>...: 摘录外部网页，AI摘要后写入摘录笔记目录，--crawl 时从url开始爬取同站点网页批量摘录
+//   - feeds [url...]: 检查RSS/Atom订阅，新文章AI摘要后写入每日摘要文章或逐篇摘录笔记(--output digest|notes)
+//   - links: 检查失效的站内链接、缺失及孤立图片，--external 时检查站外链接，--suggest 时AI建议替换的站内链接
+//   - interlink [path]: 按标题、关键字(及embedding相似度)建议站内互链，--apply 时插入文章，--suggestions 时插入审核后的建议列表
+//   - alt-text [path]: AI为alt文字缺失的图片生成alt文字(--vision 时看图生成)，--apply 时写入文章
+//   - cover [post.md|dir]: 基于标题、摘要文生图生成封面图，写入page bundle或static目录及front matter，--force 时重新生成
+//   - og-card [post.md|dir]: 在模板图片上绘制标题、描述、标签及站点名生成社交分享卡片og.png，标题、描述变化时才重新生成
+//   - upload <file>...: 上传本地图片到图床(生成缩放及WebP版本)，输出Markdown图片引用
 func main() {
-	pflag.Parse()
-	// client config
+	// 子命令名之前为公共参数及summary命令的参数，之后由子命令自己的FlagSet解析
+	root := newFlagSet("")
+	root.AddFlagSet(summaryFlags)
+	root.SetInterspersed(false)
+	_ = root.Parse(os.Args[1:])
+
+	ctx := context.Background()
+	args := root.Args()
+	if len(args) > 0 {
+		args = args[1:]
+	}
+	if cmd := root.Arg(0); cmd != "" && cmd != "summary" {
+		summaryFlags.VisitAll(func(f *pflag.Flag) {
+			if f.Changed {
+				log.Fatalf("flag --%s only applies to the summary command", f.Name)
+			}
+		})
+	}
+	switch cmd := root.Arg(0); cmd {
+	case "", "summary":
+		runSummary(ctx, args)
+	case "submit":
+		runSubmit(ctx, args)
+	case "jobs":
+		runJobs(ctx, args)
+	case "review":
+		runReview(ctx, args)
+	case "stats":
+		runStats(ctx, args)
+	case "weights":
+		runWeights(ctx, args)
+	case "sections":
+		runSections(ctx, args)
+	case "translate":
+		runTranslate(ctx, args)
+	case "clip":
+		runClip(ctx, args)
+	case "feeds":
+		runFeeds(ctx, args)
+	case "links":
+		runLinks(ctx, args)
+	case "interlink":
+		runInterlink(ctx, args)
+	case "alt-text":
+		runAltText(ctx, args)
+	case "cover":
+		runCover(ctx, args)
+	case "og-card":
+		runOGCard(ctx, args)
+	case "upload":
+		runUpload(ctx, args)
+	default:
+		log.Fatalf("unknown command: %s", cmd)
+	}
+}
+
+// newFlagSet 子命令的FlagSet，包含公共参数
+func newFlagSet(cmd string) *pflag.FlagSet {
+	fs := pflag.NewFlagSet(strings.TrimSpace("blog_summary "+cmd), pflag.ExitOnError)
+	fs.AddFlagSet(commonFlags)
+	return fs
+}
+
+// parseFlags 解析子命令参数并加载配置，返回位置参数
+func parseFlags(fs *pflag.FlagSet, args []string) []string {
+	_ = fs.Parse(args)
 	if err := config.ParseConfig(configFile); err != nil {
 		log.Fatalf("parse config got err: %s", err)
 	}
+	if !commonFlags.Changed("blog_path") && config.GetBlogPath() != "" {
+		blogPath = config.GetBlogPath()
+	}
+	return fs.Args()
+}
+
+// runSummary 批量更新blog摘要: blog_summary [summary] [--since ref|--since-last] [--commit] [path]，中断后再次执行会继续处理未完成的文件
+func runSummary(ctx context.Context, args []string) {
+	fs := newFlagSet("summary")
+	fs.AddFlagSet(summaryFlags)
+	args = parseFlags(fs, args)
 
 	start := time.Now()
 	app, err := buildBlogSummaryApp()
@@ -40,37 +168,131 @@ func main() {
 		log.Fatalf("init blog summary got err: %s", err)
 	}
 
-	err = app.UpdateBlogHeaderYaml(context.Background(), blogPath)
+	path := blogPath
+	if len(args) > 0 {
+		path = args[0]
+	}
+	var job *entity.SummaryJob
+	if since != "" || sinceLast {
+		job, err = app.SubmitSinceJob(ctx, blogPath, since)
+	} else {
+		job, err = app.SubmitSummaryJob(ctx, blogPath, path)
+	}
 	if err != nil {
-		log.Fatalf("update blog summary content got err: %s", err)
+		log.Fatalf("submit summary job got err: %s", err)
+	}
+	jobID := job.ID
+
+	// 终端下按进度事件渲染进度条，日志仅输出错误避免打乱进度条
+	var watchDone chan struct{}
+	if isTerminal(os.Stdout) {
+		events, err := app.WatchJob(ctx, jobID)
+		if err != nil {
+			log.Fatalf("watch summary job[%d] got err: %s", jobID, err)
+		}
+		log.SetLevel(log.ErrorLevel)
+		bar := newProgressBar(os.Stdout, job.Total)
+		watchDone = make(chan struct{})
+		go func() {
+			defer close(watchDone)
+			for event := range events {
+				bar.Handle(event)
+			}
+		}()
+	}
+
+	if job, err = app.RunSummaryJob(ctx, jobID); err != nil {
+		log.Fatalf("run summary job[%d] got err: %s", jobID, err)
+	}
+	if watchDone != nil {
+		<-watchDone
+	}
+	log.Infof("summary job[%d] %s, total: %d, done: %d, skipped: %d, failed: %d",
+		job.ID, job.Status, job.Total, job.Done, job.Skipped, job.Failed)
+
+	if gitCommit {
+		commit, err := app.CommitJobChanges(ctx, jobID)
+		if err != nil {
+			log.Fatalf("commit summary job[%d] changes got err: %s", jobID, err)
+		}
+		if commit != "" {
+			fmt.Printf("committed %d updated posts: %s\n", job.Done, commit)
+		}
 	}
 
 	log.Infof("update blog summary using time: %s", time.Since(start))
 }
 
-func buildBlogSummaryApp() (*application.BlogSummaryApp, error) {
+// buildInfras 初始化sqlite、OpenAI基础设施及AI服务
+func buildInfras() (*dbs.BlogSummarySqliteInfra, *service.AIService, error) {
 	// sqlite infra
 	sqliteDbInfra, err := dbs.NewBlogSummarySqliteInfra(config.GetDBFilePath())
 	if err != nil {
-		return nil, errors.Wrap(err, "NewBlogSummarySqliteInfra got err")
+		return nil, nil, errors.Wrap(err, "NewBlogSummarySqliteInfra got err")
+	}
+	if err = sqliteDbInfra.InitBlogSummaryDB(context.Background()); err != nil {
+		return nil, nil, errors.Wrap(err, "InitBlogSummaryDB got err")
 	}
 
 	// openAI Infra
 	openAIProxy, err := openaix.NewOpenAIHttpProxyClient()
 	if err != nil {
-		return nil, errors.Wrap(err, "NewOpenAIHttpProxyClient got err")
+		return nil, nil, errors.Wrap(err, "NewOpenAIHttpProxyClient got err")
 	}
 
 	// openAI Service
 	aiService, err := service.NewAIService(openAIProxy, config.GetPromptConfigPath())
 	if err != nil {
-		return nil, errors.Wrap(err, "NewAIService got err")
+		return nil, nil, errors.Wrap(err, "NewAIService got err")
+	}
+	aiService.SetTranslationCache(sqliteDbInfra)
+
+	return sqliteDbInfra, aiService, nil
+}
+
+func buildBlogSummaryApp() (*application.BlogSummaryApp, error) {
+	sqliteDbInfra, aiService, err := buildInfras()
+	if err != nil {
+		return nil, err
+	}
+	return newBlogSummaryApp(sqliteDbInfra, aiService), nil
+}
+
+// newScanRules 配置的扫描规则，追加命令行指定的include/exclude及跳过的栏目
+func newScanRules() *entity.BlogScanRules {
+	scanRules := application.ScanRulesFromConfig(config.GetScanConfig())
+	scanRules.Include = append(scanRules.Include, includes...)
+	scanRules.Exclude = append(scanRules.Exclude, excludes...)
+	for _, section := range skipSections {
+		scanRules.Sections = append(scanRules.Sections, &entity.BlogSectionRule{Path: section, Skip: true})
 	}
+	return scanRules
+}
 
+// newBlogSummaryApp 基于已初始化的infra构建app，按配置及命令行参数设置扫描、权重规则及git仓库
+func newBlogSummaryApp(sqliteDbInfra *dbs.BlogSummarySqliteInfra, aiService *service.AIService) *application.BlogSummaryApp {
 	// blog summary app
 	blogSummaryApp := application.NewBlogSummaryApp(
 		aiService,
 		sqliteDbInfra,
 	)
-	return blogSummaryApp, nil
+	blogSummaryApp.SetConcurrency(config.GetJobConcurrency())
+	blogSummaryApp.SetConcurrency(concurrency)
+	blogSummaryApp.SetReviewMode(reviewMode || config.GetReviewMode())
+
+	blogSummaryApp.SetScanRules(newScanRules())
+	blogSummaryApp.SetWeightRules(application.WeightRulesFromConfig(config.GetWeightConfig()))
+
+	// blog git仓库，增量模式、提交重写的文章时使用；命令行指定blog_path时使用其所在的仓库
+	repoPath := config.GetGitRepoPath()
+	if commonFlags.Changed("blog_path") {
+		repoPath = blogPath
+	}
+	gitRepo, err := gitx.NewGitRepo(context.Background(), repoPath)
+	if err != nil {
+		log.Warnf("init blog git repo got err: %s", err)
+	} else {
+		blogSummaryApp.SetGitRepo(gitRepo)
+	}
+	return blogSummaryApp
 }
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+
+	"github.com/lupguo/copilot_develop/app/application"
+	"github.com/lupguo/copilot_develop/app/domain/entity"
+	"github.com/lupguo/copilot_develop/app/infras/ogcard"
+	"github.com/lupguo/copilot_develop/config"
+	log "github.com/sirupsen/logrus"
+)
+
+// runOGCard 生成文章社交分享卡片: blog_summary og-card [post.md|dir] [--force] [--format table|json]，
+// 标题、描述未变化的文章跳过
+func runOGCard(ctx context.Context, args []string) {
+	fs := newFlagSet("og-card")
+	formatFlag := fs.String("format", "table", "Output format: table or json")
+	force := fs.Bool("force", false, "Regenerate og cards even if the title and description are unchanged")
+	args = parseFlags(fs, args)
+
+	format, err := entity.ParseStatsFormat(*formatFlag)
+	if err != nil {
+		log.Fatal(err)
+	}
+	sqliteDbInfra, _, err := buildInfras()
+	if err != nil {
+		log.Fatalf("init blog summary got err: %s", err)
+	}
+	cfg := config.GetOGCardConfig()
+	renderer, err := ogcard.NewCardRenderer(cfg)
+	if err != nil {
+		log.Fatalf("init og card renderer got err: %s", err)
+	}
+	app := application.NewBlogOGCardApp(sqliteDbInfra, newScanRules(), renderer)
+
+	path := ""
+	if len(args) > 0 {
+		path = args[0]
+	}
+	opts := application.OGCardOptionsFromConfig(config.GetSiteConfig(), cfg)
+	opts.Force = *force
+	cards, err := app.GenerateOGCards(ctx, blogPath, path, opts)
+	if err != nil {
+		log.Fatalf("generate og cards got err: %s", err)
+	}
+
+	if format == entity.StatsFormatJSON {
+		if cards == nil {
+			cards = []*entity.OGCardImage{}
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err = enc.Encode(cards); err != nil {
+			log.Fatalf("write og cards got err: %s", err)
+		}
+		return
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	for _, c := range cards {
+		path := c.Path
+		if rel, err := filepath.Rel(blogPath, path); err == nil && !filepath.IsAbs(rel) && rel[0] != '.' {
+			path = rel
+		}
+		fmt.Fprintf(tw, "%s\t%s\n", path, c.Ref)
+	}
+	if err = tw.Flush(); err != nil {
+		log.Fatalf("write og cards got err: %s", err)
+	}
+	fmt.Printf("%d og cards generated\n", len(cards))
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/lupguo/copilot_develop/app/domain/entity"
+)
+
+// progressBarWidth 进度条宽度(字符数)
+const progressBarWidth = 30
+
+// progressBar 基于任务进度事件在终端渲染单行进度条
+type progressBar struct {
+	out     io.Writer
+	total   int
+	written int
+	pending int
+	skipped int
+	failed  int
+	tokens  int
+	current string
+}
+
+func newProgressBar(out io.Writer, total int) *progressBar {
+	return &progressBar{out: out, total: total}
+}
+
+// isTerminal 输出是否为终端(非管道、文件重定向)
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// Handle 处理进度事件并刷新进度条，summary事件时输出最终统计
+func (p *progressBar) Handle(event *entity.JobEvent) {
+	switch event.Type {
+	case entity.JobEventAIRequest:
+		p.current = event.Path
+	case entity.JobEventTokens:
+		p.tokens += event.Tokens
+	case entity.JobEventWritten:
+		p.written++
+	case entity.JobEventPending:
+		p.pending++
+	case entity.JobEventSkipped:
+		p.skipped++
+	case entity.JobEventFailed:
+		p.failed++
+	case entity.JobEventSummary:
+		p.render()
+		job := event.Job
+		fmt.Fprintf(p.out, "\njob[%d] %s, total: %d, written: %d, skipped: %d, failed: %d, tokens: %d\n",
+			job.ID, job.Status, job.Total, job.Done, job.Skipped, job.Failed, p.tokens)
+		return
+	default:
+		return
+	}
+	p.render()
+}
+
+func (p *progressBar) render() {
+	finished := p.written + p.pending + p.skipped + p.failed
+	filled := progressBarWidth
+	if p.total > 0 {
+		filled = finished * progressBarWidth / p.total
+	}
+	bar := strings.Repeat("=", filled) + strings.Repeat(" ", progressBarWidth-filled)
+
+	// \r回到行首，\033[K清除行尾残留
+	fmt.Fprintf(p.out, "\r\033[K[%s] %d/%d written %d pending %d skipped %d failed %d tokens %d %s",
+		bar, finished, p.total, p.written, p.pending, p.skipped, p.failed, p.tokens, filepath.Base(p.current))
+}
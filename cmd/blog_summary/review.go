@@ -0,0 +1,139 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/lupguo/copilot_develop/app/application"
+	"github.com/lupguo/copilot_develop/app/domain/entity"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
+)
+
+// reviewSummaryYaml 在编辑器中编辑的摘要内容
+type reviewSummaryYaml struct {
+	Keywords    string `yaml:"keywords"`
+	Description string `yaml:"description"`
+	Summary     string `yaml:"summary"`
+}
+
+// runReview 逐个审核待审核的摘要: blog_summary review
+func runReview(ctx context.Context, args []string) {
+	parseFlags(newFlagSet("review"), args)
+	app, err := buildBlogSummaryApp()
+	if err != nil {
+		log.Fatalf("init blog summary got err: %s", err)
+	}
+
+	reviews, err := app.ListPendingSummaries(ctx)
+	if err != nil {
+		log.Fatalf("list pending summaries got err: %s", err)
+	}
+	if len(reviews) == 0 {
+		fmt.Println("no pending summaries")
+		return
+	}
+
+	in := bufio.NewReader(os.Stdin)
+	for i := 0; i < len(reviews); i++ {
+		review := reviews[i]
+		printReview(os.Stdout, i+1, len(reviews), review)
+
+		fmt.Print("[a]ccept / [e]dit / [r]egenerate / [x] reject / [s]kip / [q]uit: ")
+		line, err := in.ReadString('\n')
+		if err != nil && line == "" {
+			return
+		}
+
+		articleID, historyID := review.Article.ID, review.History.ID
+		switch strings.ToLower(strings.TrimSpace(line)) {
+		case "a":
+			_, err = app.ApproveSummary(ctx, articleID, historyID)
+		case "e":
+			err = editReview(ctx, app, review)
+		case "r":
+			var history *entity.BlogSummaryHistory
+			if err = app.RejectSummary(ctx, articleID, historyID); err == nil {
+				if history, err = app.RegenerateSummary(ctx, articleID); err == nil {
+					review.History = history
+					i-- // 重新审核新生成的版本
+				}
+			}
+		case "x":
+			err = app.RejectSummary(ctx, articleID, historyID)
+		case "s", "":
+		case "q":
+			return
+		default:
+			fmt.Println("unknown choice")
+			i--
+		}
+		if err != nil {
+			fmt.Printf("review article[%s] got err: %s\n", review.Article.Path, err)
+		}
+	}
+}
+
+// printReview 对比输出文章当前摘要与待审核版本
+func printReview(w io.Writer, n, total int, review *entity.SummaryReview) {
+	article, history := review.Article, review.History
+	fmt.Fprintf(w, "\n(%d/%d) %s\n  path: %s\n  lang: %s, generated at: %s\n",
+		n, total, article.Title, article.Path, history.Lang, history.CreatedAt)
+	fmt.Fprintf(w, "--- current\n  keywords: %s\n  description: %s\n  summary: %s\n",
+		article.Keywords, article.Description, article.Summary)
+	fmt.Fprintf(w, "+++ proposed\n  keywords: %s\n  description: %s\n  summary: %s\n",
+		history.Keywords, history.Description, history.Summary)
+}
+
+// editReview 在$EDITOR(默认vi)中编辑待审核的摘要，保存后审核通过并写入文章
+func editReview(ctx context.Context, app *application.BlogSummaryApp, review *entity.SummaryReview) error {
+	f, err := os.CreateTemp("", "blog_summary_review_*.yaml")
+	if err != nil {
+		return errors.Wrap(err, "create review temp file got err")
+	}
+	defer os.Remove(f.Name())
+
+	history := review.History
+	content, err := yaml.Marshal(&reviewSummaryYaml{
+		Keywords:    history.Keywords,
+		Description: history.Description,
+		Summary:     history.Summary,
+	})
+	if err != nil {
+		return errors.Wrap(err, "marshal review summary got err")
+	}
+	if _, err = f.Write(content); err != nil {
+		f.Close()
+		return errors.Wrap(err, "write review temp file got err")
+	}
+	f.Close()
+
+	editor := os.Getenv("EDITOR")
+	if editor == "" {
+		editor = "vi"
+	}
+	cmd := exec.CommandContext(ctx, editor, f.Name())
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err = cmd.Run(); err != nil {
+		return errors.Wrapf(err, "run editor[%s] got err", editor)
+	}
+
+	content, err = os.ReadFile(f.Name())
+	if err != nil {
+		return errors.Wrap(err, "read review temp file got err")
+	}
+	edited := &reviewSummaryYaml{}
+	if err = yaml.Unmarshal(content, edited); err != nil {
+		return errors.Wrap(err, "unmarshal edited summary got err")
+	}
+
+	summary := &entity.ArticleSummary{Keywords: edited.Keywords, Summary: edited.Summary, Description: edited.Description}
+	_, err = app.EditSummary(ctx, review.Article.ID, history.ID, history.Lang, summary)
+	return err
+}
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// runSections 汇总栏目摘要，子摘要没有变化的栏目跳过: blog_summary sections [path]
+func runSections(ctx context.Context, args []string) {
+	args = parseFlags(newFlagSet("sections"), args)
+	path := blogPath
+	if len(args) > 0 {
+		path = args[0]
+	}
+
+	app, err := buildBlogSummaryApp()
+	if err != nil {
+		log.Fatalf("init blog summary got err: %s", err)
+	}
+	updated, err := app.SummarizeSections(ctx, blogPath, path)
+	for _, index := range updated {
+		fmt.Printf("updated %s\n", index)
+	}
+	if err != nil {
+		log.Fatalf("summarize sections got err: %s", err)
+	}
+	fmt.Printf("%d sections updated\n", len(updated))
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"context"
+	"os"
+
+	"github.com/lupguo/copilot_develop/app/domain/entity"
+	log "github.com/sirupsen/logrus"
+)
+
+// runStats 输出全站内容统计及健康报告: blog_summary stats [--format table|json|markdown] [--top 20]
+func runStats(ctx context.Context, args []string) {
+	fs := newFlagSet("stats")
+	formatFlag := fs.String("format", "table", "Output format: table, json or markdown")
+	top := fs.Int("top", entity.DefaultStatsTop, "Number of items listed per section (0 for all)")
+	parseFlags(fs, args)
+
+	format, err := entity.ParseStatsFormat(*formatFlag)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	app, err := buildBlogSummaryApp()
+	if err != nil {
+		log.Fatalf("init blog summary got err: %s", err)
+	}
+	stats, err := app.GetBlogStats(ctx, *top)
+	if err != nil {
+		log.Fatalf("get blog stats got err: %s", err)
+	}
+	if err = stats.Write(os.Stdout, format); err != nil {
+		log.Fatalf("write blog stats got err: %s", err)
+	}
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"context"
+	"path/filepath"
+	"time"
+
+	"github.com/lupguo/copilot_develop/app/application"
+	log "github.com/sirupsen/logrus"
+)
+
+// runTranslate 翻译指定文章: blog_summary translate [--lang en] [--force] post.md...
+func runTranslate(ctx context.Context, args []string) {
+	fs := newFlagSet("translate")
+	lang := fs.String("lang", "en", "Target language, output to name.<lang>.md")
+	force := fs.Bool("force", false, "Translate even if the translation is up to date")
+	paths := parseFlags(fs, args)
+
+	if len(paths) == 0 {
+		log.Fatalf("translate command needs at least one markdown path")
+	}
+
+	sqliteDbInfra, aiService, err := buildInfras()
+	if err != nil {
+		log.Fatalf("init blog translate got err: %s", err)
+	}
+	app := application.NewBlogTranslateApp(aiService, sqliteDbInfra)
+
+	for _, path := range paths {
+		// 翻译、摘要记录使用绝对路径
+		path, err := filepath.Abs(path)
+		if err != nil {
+			log.Fatalf("abs path of md[%s] got err: %s", path, err)
+		}
+		start := time.Now()
+		target, err := app.TranslateBlog(ctx, path, *lang, *force)
+		if err != nil {
+			log.Fatalf("translate md[%s] got err: %s", path, err)
+		}
+		log.Infof("translate md[%s] => [%s] using time: %s", path, target, time.Since(start))
+	}
+}
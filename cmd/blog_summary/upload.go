@@ -0,0 +1,94 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/lupguo/copilot_develop/app/application"
+	"github.com/lupguo/copilot_develop/app/domain/entity"
+	"github.com/lupguo/copilot_develop/app/domain/repos"
+	"github.com/lupguo/copilot_develop/app/infras/imagehost"
+	"github.com/lupguo/copilot_develop/config"
+	log "github.com/sirupsen/logrus"
+)
+
+// uploadResult 上传到图床的图片
+type uploadResult struct {
+	File      string                 `json:"file"`
+	URL       string                 `json:"url"`
+	Markdown  string                 `json:"markdown"`
+	Duplicate bool                   `json:"duplicate"`
+	Variants  []*entity.ImageVariant `json:"variants"`
+}
+
+// runUpload 上传本地图片到图床: blog_summary upload <file>... [--format table|json]，
+// 保存到HTTP服务 /i/ 路由所在的图床目录，输出可直接粘贴到文章的Markdown图片引用
+func runUpload(ctx context.Context, args []string) {
+	fs := newFlagSet("upload")
+	formatFlag := fs.String("format", "table", "Output format: table or json")
+	args = parseFlags(fs, args)
+
+	if len(args) == 0 {
+		log.Fatal("usage: blog_summary upload <file>...")
+	}
+	format, err := entity.ParseStatsFormat(*formatFlag)
+	if err != nil {
+		log.Fatal(err)
+	}
+	sqliteDbInfra, _, err := buildInfras()
+	if err != nil {
+		log.Fatalf("init blog summary got err: %s", err)
+	}
+	cfg := config.GetImageHostConfig()
+	storage, err := imagehost.NewLocalStorage(cfg.Dir, cfg.BaseURL)
+	if err != nil {
+		log.Fatalf("init image storage got err: %s", err)
+	}
+	var webpEncoder repos.IReposWebPEncoder
+	if cfg.WebP {
+		encoder, err := imagehost.NewCWebPEncoder(cfg.CWebP)
+		if err != nil {
+			log.Warnf("init cwebp encoder got err, webp variants are disabled: %s", err)
+		} else {
+			webpEncoder = encoder
+		}
+	}
+	app := application.NewBlogImageHostApp(sqliteDbInfra, storage, webpEncoder, application.ImageHostOptionsFromConfig(cfg))
+
+	results := make([]*uploadResult, 0, len(args))
+	for _, file := range args {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			log.Fatalf("read image[%s] got err: %s", file, err)
+		}
+		uploaded, err := app.UploadImage(ctx, filepath.Base(file), data)
+		if err != nil {
+			log.Fatalf("upload image[%s] got err: %s", file, err)
+		}
+		url := app.ImageURL(uploaded.Image.StorageKey)
+		alt := strings.TrimSuffix(filepath.Base(file), filepath.Ext(file))
+		results = append(results, &uploadResult{
+			File:      file,
+			URL:       url,
+			Markdown:  entity.MarkdownImage(alt, url),
+			Duplicate: uploaded.Duplicate,
+			Variants:  uploaded.Variants,
+		})
+	}
+
+	if format == entity.StatsFormatJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err = enc.Encode(results); err != nil {
+			log.Fatalf("write upload results got err: %s", err)
+		}
+		return
+	}
+	for _, r := range results {
+		fmt.Println(r.Markdown)
+	}
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/lupguo/copilot_develop/app/domain/entity"
+	log "github.com/sirupsen/logrus"
+)
+
+// runWeights 预览按权重公式重算后文章排序的变化: blog_summary weights [--top 20] [--apply]
+func runWeights(ctx context.Context, args []string) {
+	fs := newFlagSet("weights")
+	top := fs.Int("top", entity.DefaultStatsTop, "Number of posts previewed (0 for all)")
+	apply := fs.Bool("apply", false, "Write the new weights into the posts' front matter")
+	parseFlags(fs, args)
+
+	app, err := buildBlogSummaryApp()
+	if err != nil {
+		log.Fatalf("init blog summary got err: %s", err)
+	}
+
+	var changes []*entity.WeightChange
+	if *apply {
+		changes, err = app.ApplyWeights(ctx, blogPath)
+	} else {
+		changes, err = app.PreviewWeights(ctx, blogPath)
+	}
+	if err != nil {
+		log.Fatalf("calc blog weights got err: %s", err)
+	}
+
+	changed, moved := 0, 0
+	for _, c := range changes {
+		if c.NewWeight != c.OldWeight {
+			changed++
+		}
+		if c.NewRank != c.OldRank {
+			moved++
+		}
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "RANK\tMOVE\tWEIGHT\tBASE\tRECENCY\tWORDS\tPINNED\tCATEGORY\tLINKS\tTITLE")
+	for i, c := range changes {
+		if *top > 0 && i >= *top {
+			fmt.Fprintf(tw, "... %d more\n", len(changes)-*top)
+			break
+		}
+		s := c.Score
+		base := strconv.Itoa(s.Base)
+		if s.Draft {
+			base = "draft"
+		}
+		fmt.Fprintf(tw, "%d\t%s\t%d -> %d\t%s\t%+d\t%+d\t%+d\t%+d\t%+d\t%s\n",
+			c.NewRank, rankMove(c.OldRank, c.NewRank), c.OldWeight, c.NewWeight,
+			base, s.Recency, s.Words, s.Pinned, s.Category, s.Links, c.Title)
+	}
+	if err = tw.Flush(); err != nil {
+		log.Fatalf("write blog weights got err: %s", err)
+	}
+
+	action := "would change"
+	if *apply {
+		action = "changed"
+	}
+	fmt.Printf("%d posts, %d weights %s, %d posts move in the ordering\n", len(changes), changed, action, moved)
+}
+
+// rankMove 名次变化，上升为+
+func rankMove(oldRank, newRank int) string {
+	if oldRank == newRank {
+		return "="
+	}
+	return fmt.Sprintf("%+d", oldRank-newRank)
+}
@@ -1,6 +1,7 @@
 package config
 
 import (
+	"net/url"
 	"os"
 	"path/filepath"
 
@@ -9,13 +10,196 @@ import (
 )
 
 type OpenAIProxyConfig struct {
-	AuthToken string `yaml:"auth_token"`
-	SocksURL  string `yaml:"socks_url"`
+	AuthToken       string `yaml:"auth_token"`
+	SocksURL        string `yaml:"socks_url"`
+	TokensPerMinute int    `yaml:"tokens_per_minute"` // 每分钟token限额，超过后等待下一个时间窗口，0表示不限制
+	MaxRetries      int    `yaml:"max_retries"`       // 限频(429)时的重试次数
 }
 
 type BlogSummaryConfig struct {
-	AIPromptFile string `yaml:"ai_prompt_file"` // blog summary prompt配置
-	SQLiteDBFile string `yaml:"sqlite_db_file"` // blog sqlite db存储
+	AIPromptFile string           `yaml:"ai_prompt_file"` // blog summary prompt配置
+	SQLiteDBFile string           `yaml:"sqlite_db_file"` // blog sqlite db存储
+	ShortMark    *ShortMarkConfig `yaml:"short_mark"`     // 文章短标记生成配置
+	BlogPath     string           `yaml:"blog_path"`      // blog content目录，HTTP触发摘要任务时使用
+	Concurrency  int              `yaml:"concurrency"`    // 摘要任务并发处理的文件数，默认10
+	ReviewMode   bool             `yaml:"review_mode"`    // 审核模式，AI生成的摘要审核通过后才写入文章
+	RepoPath     string           `yaml:"repo_path"`      // blog所在的本地git仓库，为空时使用blog_path所在的仓库
+	Scan         *ScanConfig      `yaml:"scan"`           // 文章扫描规则
+	Weight       *WeightConfig    `yaml:"weight"`         // 文章权重公式，未配置时字数过少为200，否则为100
+	Clip         *ClipConfig      `yaml:"clip"`           // 外部网页摘录
+	Feeds        *FeedsConfig     `yaml:"feeds"`          // RSS/Atom订阅
+	Links        *LinksConfig     `yaml:"links"`          // 失效链接检查
+	Interlink    *InterlinkConfig `yaml:"interlink"`      // 站内互链建议
+	AltText      *AltTextConfig   `yaml:"alt_text"`       // 图片alt文字生成
+	Cover        *CoverConfig     `yaml:"cover"`          // 文章封面图生成
+	OGCard       *OGCardConfig    `yaml:"og_card"`        // 文章社交分享卡片生成
+}
+
+// ClipConfig 外部网页摘录(clip命令)配置
+type ClipConfig struct {
+	Dir      string `yaml:"dir"`       // 摘录笔记目录，默认 <blog_path>/clips
+	MaxDepth int    `yaml:"max_depth"` // 批量摘录时爬取的最大深度，默认1
+}
+
+// FeedsConfig RSS/Atom订阅配置，新文章AI摘要后汇总为每日摘要文章或逐篇生成摘录笔记
+type FeedsConfig struct {
+	Urls            []string `yaml:"urls"`             // 订阅地址
+	Output          string   `yaml:"output"`           // digest(默认，每日摘要文章)、notes(逐篇摘录笔记)
+	Dir             string   `yaml:"dir"`              // 输出目录，默认 <blog_path>/feeds
+	IntervalMinutes int      `yaml:"interval_minutes"` // HTTP服务轮询间隔(分钟)，默认60
+	MaxEntries      int      `yaml:"max_entries"`      // 每个订阅源每次最多摘要的新文章数，默认10，其余仅记录
+}
+
+// LinksConfig 失效链接检查(links命令)配置
+type LinksConfig struct {
+	External    bool     `yaml:"external"`    // 检查站外链接
+	Concurrency int      `yaml:"concurrency"` // 站外链接并发检查数，默认8(同一站点仍受爬虫的并发、请求间隔限制)
+	CacheHours  int      `yaml:"cache_hours"` // 站外链接检查结果的缓存小时数，默认24，小于0时不缓存
+	Ignore      []string `yaml:"ignore"`      // 忽略的链接前缀或glob，默认忽略 /tags/、/categories/ 等Hugo生成的页面
+}
+
+// InterlinkConfig 站内互链建议(interlink命令)配置
+type InterlinkConfig struct {
+	MaxPerPost    int     `yaml:"max_per_post"`   // 每篇文章最多的建议数，默认5
+	MinSimilarity float64 `yaml:"min_similarity"` // 配置了embedding提示词时，文章相似度低于该值不建议，默认0.8
+}
+
+// AltTextConfig 图片alt文字生成(alt-text命令)配置
+type AltTextConfig struct {
+	Vision       bool `yaml:"vision"`        // 由支持图片输入的模型(alt-text-vision提示词)看图生成，svg等不支持的图片仍按上下文生成
+	ContextChars int  `yaml:"context_chars"` // 交给AI的图片上下文字符数，默认600
+	MaxImageKB   int  `yaml:"max_image_kb"`  // vision模式上传的本地图片大小上限，超过时按上下文生成，默认4096
+}
+
+// CoverConfig 文章封面图生成(cover命令)配置
+type CoverConfig struct {
+	Provider       string `yaml:"provider"`        // 文生图服务: openai(默认，OpenAI Images) | sd(本地Stable Diffusion WebUI API)
+	Model          string `yaml:"model"`           // 模型，openai默认dall-e-3，sd为checkpoint名称(为空时使用当前模型)
+	Size           string `yaml:"size"`            // 图片尺寸，默认1792x1024
+	SDURL          string `yaml:"sd_url"`          // Stable Diffusion服务地址，默认 http://127.0.0.1:7860
+	Steps          int    `yaml:"steps"`           // Stable Diffusion采样步数，默认30
+	Seed           int64  `yaml:"seed"`            // Stable Diffusion随机种子，<=0时随机
+	NegativePrompt string `yaml:"negative_prompt"` // Stable Diffusion反向提示词
+	PromptTemplate string `yaml:"prompt_template"` // 提示词模板(Go text/template，可用 .Title .Summary .Description .Keywords .Tags)
+	Key            string `yaml:"key"`             // 写入的front matter字段，默认cover(同时加入images列表)
+	Dir            string `yaml:"dir"`             // 非page bundle文章的封面图保存在static下的目录，默认images/covers
+}
+
+// OGCardConfig 文章社交分享卡片(og-card命令)配置，在模板图片上绘制标题、描述、标签及站点名
+type OGCardConfig struct {
+	Template   string   `yaml:"template"`    // 模板PNG，为空时使用纯色背景，有模板时卡片尺寸为模板尺寸
+	Width      int      `yaml:"width"`       // 卡片宽度，默认1200
+	Height     int      `yaml:"height"`      // 卡片高度，默认630
+	TitleFonts []string `yaml:"title_fonts"` // 标题字体(TrueType、OpenType字体 .ttf/.otf/.ttc/.otc，字体集合可用 path#index)，按顺序回退，中文字体放在西文字体之后
+	Fonts      []string `yaml:"fonts"`       // 描述、标签、站点名的字体，默认同title_fonts
+	TitleSize  float64  `yaml:"title_size"`  // 标题字号(像素)，默认64，标题过长时缩小
+	TextSize   float64  `yaml:"text_size"`   // 描述字号(像素)，默认30
+	Padding    int      `yaml:"padding"`     // 边距，默认80
+	Background string   `yaml:"background"`  // 背景色，默认#0f172a
+	Foreground string   `yaml:"foreground"`  // 标题颜色，默认#f8fafc
+	Muted      string   `yaml:"muted"`       // 描述颜色，默认#94a3b8
+	Accent     string   `yaml:"accent"`      // 站点名、标签及装饰条颜色，默认#38bdf8
+	SiteName   string   `yaml:"site_name"`   // 站点名，默认为site.base_url的域名
+	Key        string   `yaml:"key"`         // 写入的front matter字段，默认og_image(同时加入images列表)
+	Dir        string   `yaml:"dir"`         // 非page bundle文章的卡片保存在static下的目录，默认images/og
+}
+
+// WeightConfig 文章权重公式，Hugo按权重从小到大排序，各调整值为负时排序靠前：
+// 权重 = base + 时间权重 + 字数权重 + 置顶/精选 + 分类权重 + 站内链接权重，限制在[min, max]
+type WeightConfig struct {
+	Base         int                       `yaml:"base"`          // 默认100
+	Min          int                       `yaml:"min"`           // 默认1
+	Max          int                       `yaml:"max"`           // 为0时不限制
+	Recency      *WeightRecencyConfig      `yaml:"recency"`       // 时间权重
+	WordBuckets  []*WeightWordBucketConfig `yaml:"word_buckets"`  // 字数权重
+	Pinned       int                       `yaml:"pinned"`        // front matter中 pinned/featured: true 的调整值
+	Categories   map[string]int            `yaml:"categories"`    // 分类 => 调整值
+	InboundLinks *WeightInboundLinksConfig `yaml:"inbound_links"` // 被站内其他文章链接的调整值
+}
+
+// WeightRecencyConfig 时间权重，发布当天调整max_adjust，每过half_life_days减半
+type WeightRecencyConfig struct {
+	HalfLifeDays float64 `yaml:"half_life_days"`
+	MaxAdjust    int     `yaml:"max_adjust"`
+}
+
+// WeightWordBucketConfig 字数>=min_words的最大档位生效
+type WeightWordBucketConfig struct {
+	MinWords int `yaml:"min_words"`
+	Adjust   int `yaml:"adjust"`
+}
+
+// WeightInboundLinksConfig 每个站内链接调整per_link，合计不超过max
+type WeightInboundLinksConfig struct {
+	PerLink int `yaml:"per_link"`
+	Max     int `yaml:"max"`
+}
+
+// ScanConfig 文章扫描规则，glob相对于blog_path，**匹配任意层目录，不含/的glob匹配文件名；
+// blog_path下的.blogsummaryignore(gitignore语法)始终生效
+type ScanConfig struct {
+	Include  []string             `yaml:"include"`  // 为空时扫描所有*.md
+	Exclude  []string             `yaml:"exclude"`  // 排除的文件
+	Sections []*ScanSectionConfig `yaml:"sections"` // 栏目规则
+}
+
+// ScanSectionConfig 栏目扫描规则，include、exclude相对于栏目目录
+type ScanSectionConfig struct {
+	Path    string   `yaml:"path"` // 栏目目录，相对于blog_path，例如 posts
+	Include []string `yaml:"include"`
+	Exclude []string `yaml:"exclude"`
+	Skip    bool     `yaml:"skip"` // 跳过整个栏目
+}
+
+// CrawlerConfig 抓取外部网页的爬虫配置
+type CrawlerConfig struct {
+	UserAgent    string `yaml:"user_agent"`    // 请求的User-Agent，同时用于匹配robots.txt的User-agent
+	DelayMs      int    `yaml:"delay_ms"`      // 同一站点两次请求的最小间隔(毫秒)，默认1000，robots.txt的Crawl-delay更大时以其为准
+	IgnoreRobots bool   `yaml:"ignore_robots"` // 不遵守robots.txt
+	Sitemap      bool   `yaml:"sitemap"`       // 批量爬取时将站点sitemap中的url作为起始页面
+	RevisitHours int    `yaml:"revisit_hours"` // 距上次访问不足该小时数的页面不再请求(断点续爬)，0时每次都发起条件请求
+}
+
+// WebhookConfig git推送webhook配置
+type WebhookConfig struct {
+	Secret string `yaml:"secret"` // GitHub/Gitea签名密钥，GitLab的Secret token，为空时拒绝所有请求
+	Branch string `yaml:"branch"` // 仅处理该分支的推送，为空时处理所有分支
+	Pull   bool   `yaml:"pull"`   // 提交任务前在本地仓库执行git pull --ff-only
+}
+
+// ImageHostConfig 图床配置，图片按内容hash保存，上传时生成缩放及WebP版本
+type ImageHostConfig struct {
+	Dir         string `yaml:"dir"`          // 本地存储目录，默认 <root_path>/data/images
+	BaseURL     string `yaml:"base_url"`     // 图片访问地址前缀，默认/i(由HTTP服务提供)
+	Token       string `yaml:"token"`        // 上传接口的访问令牌(Authorization: Bearer)，必须配置，为空时拒绝上传
+	MaxSizeMB   int    `yaml:"max_size_mb"`  // 单张图片大小上限，默认20
+	MaxPixels   int    `yaml:"max_pixels"`   // 单张图片像素数(宽x高)上限，解码前检查，默认4000万
+	Widths      []int  `yaml:"widths"`       // 缩放版本的宽度，默认 [480, 960, 1920]，只生成小于原图的宽度
+	JPEGQuality int    `yaml:"jpeg_quality"` // 缩放版本的JPEG质量，默认85
+	WebP        bool   `yaml:"webp"`         // 生成WebP版本，需要安装libwebp的cwebp
+	CWebP       string `yaml:"cwebp"`        // cwebp命令，默认cwebp
+	WebPQuality int    `yaml:"webp_quality"` // WebP质量，默认80
+}
+
+// ServerConfig HTTP服务配置
+type ServerConfig struct {
+	Debug      bool   `yaml:"debug"`       // echo调试模式(错误响应包含内部错误)，默认关闭
+	AdminUser  string `yaml:"admin_user"`  // 管理页面、/api接口Basic认证的用户名，默认admin
+	AdminToken string `yaml:"admin_token"` // 管理页面、/api接口的访问令牌(Bearer，或作为Basic认证的密码)，为空时拒绝访问
+}
+
+// ShortMarkConfig 文章短标记配置
+type ShortMarkConfig struct {
+	Length   int    `yaml:"length"`   // 短标记长度，默认8
+	Alphabet string `yaml:"alphabet"` // 短标记字符集，默认base62
+}
+
+// SiteConfig Hugo站点配置，用于生成文章链接
+type SiteConfig struct {
+	BaseURL    string `yaml:"base_url"`    // 站点地址，例如 https://tkstorm.com
+	ContentDir string `yaml:"content_dir"` // Hugo content目录
+	Permalink  string `yaml:"permalink"`   // 文章链接模板，例如 /:sections/:slug/
+	StaticDir  string `yaml:"static_dir"`  // Hugo static目录，默认与content目录同级的static
 }
 
 // Config 应用配置
@@ -27,6 +211,11 @@ type AppConfig struct {
 	RootPath    string             `yaml:"root_path"` // 根目录
 	OpenAIProxy *OpenAIProxyConfig `yaml:"openai_proxy"`
 	BlogSummary *BlogSummaryConfig `yaml:"blog_summary"`
+	Site        *SiteConfig        `yaml:"site"`
+	Webhook     *WebhookConfig     `yaml:"webhook"`
+	Crawler     *CrawlerConfig     `yaml:"crawler"`
+	ImageHost   *ImageHostConfig   `yaml:"image_host"`
+	Server      *ServerConfig      `yaml:"server"`
 }
 
 var (
@@ -75,7 +264,312 @@ func GetDBFilePath() string {
 	return filepath.Join(appConfig.RootPath, appConfig.BlogSummary.SQLiteDBFile)
 }
 
+// GetBlogPath blog content目录
+func GetBlogPath() string {
+	return appConfig.BlogSummary.BlogPath
+}
+
+// GetJobConcurrency 摘要任务并发数，未配置时返回0(使用默认值)
+func GetJobConcurrency() int {
+	if appConfig == nil || appConfig.BlogSummary == nil {
+		return 0
+	}
+	return appConfig.BlogSummary.Concurrency
+}
+
+// GetReviewMode 是否开启摘要审核模式
+func GetReviewMode() bool {
+	if appConfig == nil || appConfig.BlogSummary == nil {
+		return false
+	}
+	return appConfig.BlogSummary.ReviewMode
+}
+
+// GetGitRepoPath blog所在的本地git仓库目录，未配置时使用blog_path
+func GetGitRepoPath() string {
+	if appConfig == nil || appConfig.BlogSummary == nil {
+		return ""
+	}
+	if appConfig.BlogSummary.RepoPath != "" {
+		return appConfig.BlogSummary.RepoPath
+	}
+	return appConfig.BlogSummary.BlogPath
+}
+
+// GetWeightConfig 文章权重公式，未配置时返回nil(使用默认权重)
+func GetWeightConfig() *WeightConfig {
+	if appConfig == nil || appConfig.BlogSummary == nil {
+		return nil
+	}
+	return appConfig.BlogSummary.Weight
+}
+
+// GetScanConfig 文章扫描规则，未配置时返回空规则(扫描所有*.md)
+func GetScanConfig() *ScanConfig {
+	if appConfig == nil || appConfig.BlogSummary == nil || appConfig.BlogSummary.Scan == nil {
+		return &ScanConfig{}
+	}
+	return appConfig.BlogSummary.Scan
+}
+
+// GetClipConfig 外部网页摘录配置，未配置的项使用默认值
+func GetClipConfig() *ClipConfig {
+	clip := &ClipConfig{}
+	if appConfig != nil && appConfig.BlogSummary != nil {
+		if appConfig.BlogSummary.Clip != nil {
+			*clip = *appConfig.BlogSummary.Clip
+		}
+		if clip.Dir == "" && appConfig.BlogSummary.BlogPath != "" {
+			clip.Dir = filepath.Join(appConfig.BlogSummary.BlogPath, "clips")
+		}
+	}
+	if clip.MaxDepth <= 0 {
+		clip.MaxDepth = 1
+	}
+	return clip
+}
+
+// GetFeedsConfig RSS/Atom订阅配置，未配置的项使用默认值
+func GetFeedsConfig() *FeedsConfig {
+	feeds := &FeedsConfig{}
+	if appConfig != nil && appConfig.BlogSummary != nil {
+		if appConfig.BlogSummary.Feeds != nil {
+			*feeds = *appConfig.BlogSummary.Feeds
+		}
+		if feeds.Dir == "" && appConfig.BlogSummary.BlogPath != "" {
+			feeds.Dir = filepath.Join(appConfig.BlogSummary.BlogPath, "feeds")
+		}
+	}
+	if feeds.Output == "" {
+		feeds.Output = "digest"
+	}
+	if feeds.IntervalMinutes <= 0 {
+		feeds.IntervalMinutes = 60
+	}
+	if feeds.MaxEntries <= 0 {
+		feeds.MaxEntries = 10
+	}
+	return feeds
+}
+
+// GetLinksConfig 失效链接检查配置，未配置的项使用默认值
+func GetLinksConfig() *LinksConfig {
+	links := &LinksConfig{}
+	if appConfig != nil && appConfig.BlogSummary != nil && appConfig.BlogSummary.Links != nil {
+		*links = *appConfig.BlogSummary.Links
+	}
+	if links.Concurrency <= 0 {
+		links.Concurrency = 8
+	}
+	if links.CacheHours == 0 {
+		links.CacheHours = 24
+	}
+	if links.Ignore == nil {
+		links.Ignore = []string{"/tags/", "/categories/", "/page/"}
+	}
+	return links
+}
+
+// GetInterlinkConfig 站内互链建议配置，未配置的项使用默认值
+func GetInterlinkConfig() *InterlinkConfig {
+	interlink := &InterlinkConfig{}
+	if appConfig != nil && appConfig.BlogSummary != nil && appConfig.BlogSummary.Interlink != nil {
+		*interlink = *appConfig.BlogSummary.Interlink
+	}
+	if interlink.MaxPerPost <= 0 {
+		interlink.MaxPerPost = 5
+	}
+	if interlink.MinSimilarity <= 0 {
+		interlink.MinSimilarity = 0.8
+	}
+	return interlink
+}
+
+// GetAltTextConfig 图片alt文字生成配置，未配置的项使用默认值
+func GetAltTextConfig() *AltTextConfig {
+	altText := &AltTextConfig{}
+	if appConfig != nil && appConfig.BlogSummary != nil && appConfig.BlogSummary.AltText != nil {
+		*altText = *appConfig.BlogSummary.AltText
+	}
+	if altText.ContextChars <= 0 {
+		altText.ContextChars = 600
+	}
+	if altText.MaxImageKB <= 0 {
+		altText.MaxImageKB = 4096
+	}
+	return altText
+}
+
+// GetCoverConfig 文章封面图生成配置，未配置的项使用默认值
+func GetCoverConfig() *CoverConfig {
+	cover := &CoverConfig{}
+	if appConfig != nil && appConfig.BlogSummary != nil && appConfig.BlogSummary.Cover != nil {
+		*cover = *appConfig.BlogSummary.Cover
+	}
+	if cover.Provider == "" {
+		cover.Provider = "openai"
+	}
+	if cover.Model == "" && cover.Provider == "openai" {
+		cover.Model = "dall-e-3"
+	}
+	if cover.Size == "" {
+		cover.Size = "1792x1024"
+	}
+	if cover.SDURL == "" {
+		cover.SDURL = "http://127.0.0.1:7860"
+	}
+	if cover.Steps <= 0 {
+		cover.Steps = 30
+	}
+	if cover.Key == "" {
+		cover.Key = "cover"
+	}
+	if cover.Dir == "" {
+		cover.Dir = "images/covers"
+	}
+	return cover
+}
+
+// GetOGCardConfig 文章社交分享卡片配置，未配置的项使用默认值
+func GetOGCardConfig() *OGCardConfig {
+	card := &OGCardConfig{}
+	if appConfig != nil && appConfig.BlogSummary != nil && appConfig.BlogSummary.OGCard != nil {
+		*card = *appConfig.BlogSummary.OGCard
+	}
+	if card.Width <= 0 {
+		card.Width = 1200
+	}
+	if card.Height <= 0 {
+		card.Height = 630
+	}
+	if len(card.Fonts) == 0 {
+		card.Fonts = card.TitleFonts
+	}
+	if card.TitleSize <= 0 {
+		card.TitleSize = 64
+	}
+	if card.TextSize <= 0 {
+		card.TextSize = 30
+	}
+	if card.Padding <= 0 {
+		card.Padding = 80
+	}
+	if card.Background == "" {
+		card.Background = "#0f172a"
+	}
+	if card.Foreground == "" {
+		card.Foreground = "#f8fafc"
+	}
+	if card.Muted == "" {
+		card.Muted = "#94a3b8"
+	}
+	if card.Accent == "" {
+		card.Accent = "#38bdf8"
+	}
+	if card.SiteName == "" {
+		if u, err := url.Parse(GetSiteConfig().BaseURL); err == nil {
+			card.SiteName = u.Host
+		}
+	}
+	if card.Key == "" {
+		card.Key = "og_image"
+	}
+	if card.Dir == "" {
+		card.Dir = "images/og"
+	}
+	return card
+}
+
+// GetCrawlerConfig 爬虫配置，未配置的项使用默认值
+func GetCrawlerConfig() *CrawlerConfig {
+	crawler := &CrawlerConfig{}
+	if appConfig != nil && appConfig.Crawler != nil {
+		*crawler = *appConfig.Crawler
+	}
+	if crawler.DelayMs <= 0 {
+		crawler.DelayMs = 1000
+	}
+	return crawler
+}
+
+// GetWebhookConfig git推送webhook配置，未配置时返回空配置(拒绝所有请求)
+func GetWebhookConfig() *WebhookConfig {
+	if appConfig == nil || appConfig.Webhook == nil {
+		return &WebhookConfig{}
+	}
+	return appConfig.Webhook
+}
+
+// GetServerConfig HTTP服务配置，未配置的项使用默认值(未配置admin_token时拒绝访问管理接口)
+func GetServerConfig() *ServerConfig {
+	server := &ServerConfig{}
+	if appConfig != nil && appConfig.Server != nil {
+		*server = *appConfig.Server
+	}
+	if server.AdminUser == "" {
+		server.AdminUser = "admin"
+	}
+	return server
+}
+
+// GetImageHostConfig 图床配置，未配置的项使用默认值
+func GetImageHostConfig() *ImageHostConfig {
+	host := &ImageHostConfig{}
+	if appConfig != nil && appConfig.ImageHost != nil {
+		*host = *appConfig.ImageHost
+	}
+	if host.Dir == "" {
+		root := ""
+		if appConfig != nil {
+			root = appConfig.RootPath
+		}
+		host.Dir = filepath.Join(root, "data", "images")
+	}
+	if host.BaseURL == "" {
+		host.BaseURL = "/i"
+	}
+	if host.MaxSizeMB <= 0 {
+		host.MaxSizeMB = 20
+	}
+	if host.MaxPixels <= 0 {
+		host.MaxPixels = 40_000_000
+	}
+	if host.Widths == nil {
+		host.Widths = []int{480, 960, 1920}
+	}
+	if host.JPEGQuality <= 0 || host.JPEGQuality > 100 {
+		host.JPEGQuality = 85
+	}
+	if host.CWebP == "" {
+		host.CWebP = "cwebp"
+	}
+	if host.WebPQuality <= 0 || host.WebPQuality > 100 {
+		host.WebPQuality = 80
+	}
+	return host
+}
+
 // GetOpenAIProxy 底层OpenAI Http Proxy配置
 func GetOpenAIProxy() *OpenAIProxyConfig {
 	return appConfig.OpenAIProxy
 }
+
+// GetShortMarkConfig 文章短标记配置，未配置时返回空配置(使用默认值)
+func GetShortMarkConfig() *ShortMarkConfig {
+	if appConfig == nil || appConfig.BlogSummary == nil || appConfig.BlogSummary.ShortMark == nil {
+		return &ShortMarkConfig{}
+	}
+	return appConfig.BlogSummary.ShortMark
+}
+
+// GetSiteConfig Hugo站点配置，permalink默认为 /:sections/:slug/
+func GetSiteConfig() *SiteConfig {
+	site := &SiteConfig{}
+	if appConfig != nil && appConfig.Site != nil {
+		*site = *appConfig.Site
+	}
+	if site.Permalink == "" {
+		site.Permalink = "/:sections/:slug/"
+	}
+	return site
+}
@@ -15,6 +15,12 @@ create table main.blog_articles
     aliases     text,
     short_mark  text,
     date        text,
+    lang        text,
+    seo_title   text,
+    slug        text,
+    content_hash text,
+    summary_hash text,
+    inbound_links integer,
     updated_at  text,
     deleted_at  text,
     created_at  text    not null
@@ -22,3 +28,249 @@ create table main.blog_articles
 
 create index main.blog_articles_path_index
     on main.blog_articles (path);
+
+create index main.blog_articles_short_mark_index
+    on main.blog_articles (short_mark);
+
+create table main.jobs
+(
+    id          integer not null
+        primary key autoincrement,
+    created_at  text,
+    updated_at  text,
+    finished_at text,
+    path        text,
+    blog_root   text,
+    ref         text,
+    head_commit text,
+    status      text,
+    total       integer,
+    done        integer,
+    skipped     integer,
+    failed      integer
+);
+
+create table main.job_items
+(
+    id         integer not null
+        primary key autoincrement,
+    created_at text,
+    updated_at text,
+    job_id     integer,
+    path       text,
+    status     text,
+    reason     text,
+    attempts   integer
+);
+
+create index main.job_items_job_id_status_index
+    on main.job_items (job_id, status);
+
+create table main.blog_sections
+(
+    id            integer not null
+        primary key autoincrement,
+    created_at    text,
+    updated_at    text,
+    path          text,
+    title         text,
+    children_hash text,
+    children      integer,
+    keywords      text,
+    summary       text,
+    description   text
+);
+
+create index main.blog_sections_path_index
+    on main.blog_sections (path);
+
+create table main.web_clips
+(
+    id           integer not null
+        primary key autoincrement,
+    created_at   text,
+    updated_at   text,
+    url          text,
+    title        text,
+    filepath     text,
+    keywords     text,
+    summary      text,
+    description  text,
+    content_hash text
+);
+
+create index main.web_clips_url_index
+    on main.web_clips (url);
+
+create table main.crawl_visits
+(
+    id            integer not null
+        primary key autoincrement,
+    created_at    text,
+    updated_at    text,
+    url           text,
+    status_code   integer,
+    etag          text,
+    last_modified text,
+    content_hash  text,
+    links         text,
+    visited_at    text
+);
+
+create unique index main.crawl_visits_url_uindex
+    on main.crawl_visits (url);
+
+create table main.feeds
+(
+    id            integer not null
+        primary key autoincrement,
+    created_at    text,
+    updated_at    text,
+    url           text,
+    title         text,
+    etag          text,
+    last_modified text,
+    checked_at    text
+);
+
+create unique index main.feeds_url_uindex
+    on main.feeds (url);
+
+create table main.feed_entries
+(
+    id          integer not null
+        primary key autoincrement,
+    created_at  text,
+    updated_at  text,
+    feed_url    text,
+    feed_title  text,
+    guid        text,
+    url         text,
+    title       text,
+    published   text,
+    status      text,
+    keywords    text,
+    summary     text,
+    description text,
+    filepath    text
+);
+
+create unique index main.feed_entries_feed_url_guid_uindex
+    on main.feed_entries (feed_url, guid);
+
+create index main.feed_entries_filepath_index
+    on main.feed_entries (filepath);
+
+create table main.link_checks
+(
+    id          integer not null
+        primary key autoincrement,
+    created_at  text,
+    updated_at  text,
+    url         text,
+    status_code integer,
+    error       text,
+    checked_at  text
+);
+
+create unique index main.link_checks_url_uindex
+    on main.link_checks (url);
+
+create table main.article_embeddings
+(
+    id         integer not null
+        primary key autoincrement,
+    created_at text,
+    updated_at text,
+    path       text,
+    model      text,
+    text_hash  text,
+    vector     text
+);
+
+create unique index main.article_embeddings_path_uindex
+    on main.article_embeddings (path);
+
+create table main.image_alt_texts
+(
+    id         integer not null
+        primary key autoincrement,
+    created_at text,
+    updated_at text,
+    path       text,
+    target     text,
+    alt_text   text,
+    mode       text,
+    status     text
+);
+
+create unique index main.image_alt_texts_path_target_uindex
+    on main.image_alt_texts (path, target);
+
+create table main.cover_images
+(
+    id             integer not null
+        primary key autoincrement,
+    created_at     text,
+    updated_at     text,
+    path           text,
+    provider       text,
+    model          text,
+    prompt         text,
+    revised_prompt text,
+    seed           integer,
+    file           text,
+    ref            text
+);
+
+create unique index main.cover_images_path_uindex
+    on main.cover_images (path);
+
+create table main.og_cards
+(
+    id         integer not null
+        primary key autoincrement,
+    created_at text,
+    updated_at text,
+    path       text,
+    hash       text,
+    file       text,
+    ref        text
+);
+
+create unique index main.og_cards_path_uindex
+    on main.og_cards (path);
+
+create table main.images
+(
+    id          integer not null
+        primary key autoincrement,
+    created_at  text,
+    updated_at  text,
+    hash        text,
+    name        text,
+    mime_type   text,
+    storage_key text,
+    size        integer,
+    width       integer,
+    height      integer,
+    variants    text
+);
+
+create unique index main.images_hash_uindex
+    on main.images (hash);
+
+create table main.translation_caches
+(
+    id         integer not null
+        primary key autoincrement,
+    created_at text,
+    updated_at text,
+    cache_key  text,
+    lang       text,
+    model      text,
+    content    text
+);
+
+create unique index main.translation_caches_cache_key_uindex
+    on main.translation_caches (cache_key);
@@ -6,7 +6,7 @@ import (
 )
 
 func LogAndWrapf(err error, format string, args ...interface{}) error {
-	err = errors.Wrapf(err, format, args)
+	err = errors.Wrapf(err, format, args...)
 	log.Error(err)
 	return err
 }
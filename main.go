@@ -1,20 +1,134 @@
 package main
 
 import (
+	"context"
 	"net/http"
+	"time"
 
 	"github.com/labstack/echo/v4"
+	"github.com/lupguo/copilot_develop/app/application"
+	"github.com/lupguo/copilot_develop/app/domain/repos"
+	"github.com/lupguo/copilot_develop/app/domain/service"
+	"github.com/lupguo/copilot_develop/app/infras/crawler"
+	"github.com/lupguo/copilot_develop/app/infras/dbs"
+	"github.com/lupguo/copilot_develop/app/infras/gitx"
+	"github.com/lupguo/copilot_develop/app/infras/imagehost"
+	"github.com/lupguo/copilot_develop/app/infras/openaix"
+	"github.com/lupguo/copilot_develop/app/interfaces"
+	"github.com/lupguo/copilot_develop/config"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/pflag"
 )
 
+var configFile string // 应用配置文件
+
+func init() {
+	pflag.StringVar(&configFile, "conf", "./config.yaml", "Path to the app YAML config file")
+}
+
 func main() {
+	pflag.Parse()
+
 	e := echo.New()
-	e.Debug = true
 	e.HideBanner = true
 
+	if err := config.ParseConfig(configFile); err != nil {
+		e.Logger.Fatalf("parse config got err: %s", err)
+	}
+	e.Debug = config.GetServerConfig().Debug
+	copilot, err := buildCopilotDevelop()
+	if err != nil {
+		e.Logger.Fatalf("build copilot develop got err: %s", err)
+	}
+
 	// 首页
 	e.GET("/", func(c echo.Context) error {
 		return c.String(http.StatusOK, "Hello, World!")
 	})
 
+	// 短链解析、管理页面及REST API(需要admin_token)
+	copilot.RegisterRoutes(e)
+
+	// 后台处理摘要任务
+	go copilot.StartJobWorkers(context.Background())
+
+	// 后台轮询RSS/Atom订阅
+	go copilot.StartFeedPoller(context.Background())
+
 	e.Logger.Fatal(e.Start(":1301"))
 }
+
+func buildCopilotDevelop() (*interfaces.CopilotDevelop, error) {
+	// sqlite infra
+	sqliteDbInfra, err := dbs.NewBlogSummarySqliteInfra(config.GetDBFilePath())
+	if err != nil {
+		return nil, errors.Wrap(err, "NewBlogSummarySqliteInfra got err")
+	}
+	if err = sqliteDbInfra.InitBlogSummaryDB(context.Background()); err != nil {
+		return nil, errors.Wrap(err, "InitBlogSummaryDB got err")
+	}
+
+	// openAI Infra
+	openAIProxy, err := openaix.NewOpenAIHttpProxyClient()
+	if err != nil {
+		return nil, errors.Wrap(err, "NewOpenAIHttpProxyClient got err")
+	}
+
+	// openAI Service
+	aiService, err := service.NewAIService(openAIProxy, config.GetPromptConfigPath())
+	if err != nil {
+		return nil, errors.Wrap(err, "NewAIService got err")
+	}
+
+	blogSummaryApp := application.NewBlogSummaryApp(aiService, sqliteDbInfra)
+	blogSummaryApp.SetConcurrency(config.GetJobConcurrency())
+	blogSummaryApp.SetReviewMode(config.GetReviewMode())
+	blogSummaryApp.SetScanRules(application.ScanRulesFromConfig(config.GetScanConfig()))
+	blogSummaryApp.SetWeightRules(application.WeightRulesFromConfig(config.GetWeightConfig()))
+
+	// blog git仓库，push webhook按变更文件提交任务时使用
+	gitRepo, err := gitx.NewGitRepo(context.Background(), config.GetGitRepoPath())
+	if err != nil {
+		log.Warnf("init blog git repo got err, push webhook is disabled: %s", err)
+	} else {
+		blogSummaryApp.SetGitRepo(gitRepo)
+	}
+
+	// RSS/Atom订阅：抓取订阅及原文全文的爬虫
+	crawlerCfg := config.GetCrawlerConfig()
+	webCrawler, err := crawler.NewCrawler(&crawler.Options{
+		Delay:        time.Duration(crawlerCfg.DelayMs) * time.Millisecond,
+		UserAgent:    crawlerCfg.UserAgent,
+		IgnoreRobots: crawlerCfg.IgnoreRobots,
+		Visits:       sqliteDbInfra,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "NewCrawler got err")
+	}
+	feedOpts := application.FeedOptionsFromConfig(config.GetFeedsConfig())
+	blogFeedApp := application.NewBlogFeedApp(aiService, sqliteDbInfra, webCrawler, feedOpts)
+
+	// 图床：本地目录存储，WebP版本依赖cwebp命令
+	imageHostCfg := config.GetImageHostConfig()
+	imageStorage, err := imagehost.NewLocalStorage(imageHostCfg.Dir, imageHostCfg.BaseURL)
+	if err != nil {
+		return nil, errors.Wrap(err, "NewLocalStorage got err")
+	}
+	var webpEncoder repos.IReposWebPEncoder
+	if imageHostCfg.WebP {
+		encoder, err := imagehost.NewCWebPEncoder(imageHostCfg.CWebP)
+		if err != nil {
+			log.Warnf("init cwebp encoder got err, webp variants are disabled: %s", err)
+		} else {
+			webpEncoder = encoder
+		}
+	}
+	imageHostOpts := application.ImageHostOptionsFromConfig(imageHostCfg)
+	imageHostApp := application.NewBlogImageHostApp(sqliteDbInfra, imageStorage, webpEncoder, imageHostOpts)
+
+	copilot := interfaces.NewCopilotDevelop(blogSummaryApp)
+	copilot.SetFeedApp(blogFeedApp)
+	copilot.SetImageHostApp(imageHostApp)
+	return copilot, nil
+}